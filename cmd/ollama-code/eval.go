@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/johnpitter/ollama-code/internal/config"
+	"github.com/johnpitter/ollama-code/internal/eval"
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/multimodel"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagEvalURL         string
+	flagEvalModels      []string
+	flagEvalSuite       string
+	flagEvalMinAccuracy float64
+	flagEvalWrite       bool
+	flagEvalConfig      string
+)
+
+// newEvalCommand cria o subcomando "eval" (benchmark dos modelos instalados)
+func newEvalCommand() *cobra.Command {
+	evalCmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Benchmark installed models on intent and coding tasks",
+		Long: `Avalia os modelos instalados com cenários de classificação de intenção e
+geração de código (verificada com go test) e mostra acurácia, latência e tokens/s.
+Com --write-config, grava o modelo vencedor de cada tarefa em models.tasks.`,
+		Run: runEval,
+	}
+
+	evalCmd.Flags().StringVar(&flagEvalURL, "url", "http://localhost:11434", "Ollama server URL")
+	evalCmd.Flags().StringSliceVar(&flagEvalModels, "models", nil, "Models to evaluate (default: all installed)")
+	evalCmd.Flags().StringVar(&flagEvalSuite, "suite", "all", "Scenarios to run: intent, code, all")
+	evalCmd.Flags().Float64Var(&flagEvalMinAccuracy, "min-accuracy", eval.DefaultMinAccuracy, "Minimum accuracy for a model to be picked for a task")
+	evalCmd.Flags().BoolVar(&flagEvalWrite, "write-config", false, "Write the winning models into models.tasks")
+	evalCmd.Flags().StringVarP(&flagEvalConfig, "config", "c", "", "Config file path (default: ~/.ollama-code/config.json)")
+
+	return evalCmd
+}
+
+func runEval(cmd *cobra.Command, args []string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	scenarios, err := evalSuite(flagEvalSuite)
+	exitOnError(err)
+	transport := configuredTransport(flagEvalConfig)
+
+	models := flagEvalModels
+	if len(models) == 0 {
+		models, err = installedChatModels(ctx, transport)
+		exitOnError(err)
+	}
+	if len(models) == 0 {
+		fmt.Println("Nenhum modelo instalado. Use: ollama-code models pull <modelo>")
+		return
+	}
+
+	fmt.Printf("🧪 Avaliando %d modelo(s) em %d cenário(s)\n\n", len(models), len(scenarios))
+
+	red := color.New(color.FgRed)
+	runner := &eval.Runner{
+		NewClient: func(model string) llm.Provider {
+			client := llm.NewClient(flagEvalURL, model)
+			client.SetTransportConfig(transport)
+			return client
+		},
+		Scenarios: scenarios,
+		OnResult: func(result eval.Result) {
+			switch {
+			case result.Skipped:
+				fmt.Printf("  ⏭️  %-20s %s (%s)\n", result.Model, result.Scenario, result.Detail)
+			case result.Passed:
+				fmt.Printf("  ✅ %-20s %s\n", result.Model, result.Scenario)
+			default:
+				red.Printf("  ❌ %-20s %s: %s\n", result.Model, result.Scenario, result.Detail)
+			}
+		},
+	}
+
+	report := runner.Run(ctx, models)
+	printEvalReport(report)
+
+	winners := report.Winners(flagEvalMinAccuracy)
+	printEvalWinners(winners)
+
+	if flagEvalWrite && len(winners) > 0 {
+		path, err := writeEvalRouting(winners)
+		exitOnError(err)
+		color.New(color.FgGreen).Printf("✅ Roteamento gravado em %s\n", path)
+	}
+}
+
+// evalSuite cenários selecionados pela flag --suite
+func evalSuite(name string) ([]eval.Scenario, error) {
+	switch name {
+	case "intent":
+		return eval.IntentSuite(), nil
+	case "code":
+		return eval.CodeSuite(), nil
+	case "all", "":
+		return eval.DefaultSuite(), nil
+	default:
+		return nil, fmt.Errorf("unknown suite %q (use intent, code or all)", name)
+	}
+}
+
+// installedChatModels modelos instalados, sem os de embedding
+func installedChatModels(ctx context.Context, transport llm.TransportConfig) ([]string, error) {
+	client := llm.NewClient(flagEvalURL, "")
+	client.SetTransportConfig(transport)
+	installed, err := client.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []string
+	for _, model := range installed {
+		if strings.Contains(model.Name, "embed") {
+			continue
+		}
+		models = append(models, model.Name)
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
+func printEvalReport(report *eval.Report) {
+	fmt.Printf("\n%-30s %-8s %9s %10s %8s\n", "MODELO", "TAREFA", "ACURÁCIA", "LATÊNCIA", "TOK/S")
+	for _, score := range report.Scores() {
+		fmt.Printf("%-30s %-8s %4d/%-4d %10s %8.1f\n",
+			score.Model,
+			score.Task,
+			score.Passed, score.Total,
+			score.AvgLatency().Round(10*time.Millisecond),
+			score.TokensPerSecond())
+	}
+}
+
+func printEvalWinners(winners map[multimodel.TaskType]string) {
+	fmt.Println()
+	if len(winners) == 0 {
+		color.New(color.FgYellow).Printf("⚠️  Nenhum modelo atingiu %.0f%% de acurácia\n", flagEvalMinAccuracy*100)
+		return
+	}
+
+	for _, task := range []multimodel.TaskType{multimodel.TaskTypeIntent, multimodel.TaskTypeCode} {
+		if model, ok := winners[task]; ok {
+			fmt.Printf("🏆 %-8s %s\n", task, model)
+		}
+	}
+}
+
+// writeEvalRouting grava os vencedores em models.tasks do arquivo de configuração
+func writeEvalRouting(winners map[multimodel.TaskType]string) (string, error) {
+	path := flagEvalConfig
+	if path == "" {
+		var err error
+		if path, err = config.GetConfigPath(); err != nil {
+			return "", err
+		}
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return "", err
+	}
+
+	for task, model := range winners {
+		cfg.Models.SetTaskModel(string(task), model)
+	}
+	if err := cfg.Validate(); err != nil {
+		return "", err
+	}
+	return path, cfg.Save(path)
+}
@@ -13,7 +13,9 @@ import (
 	"github.com/johnpitter/ollama-code/internal/agent"
 	"github.com/johnpitter/ollama-code/internal/config"
 	"github.com/johnpitter/ollama-code/internal/hardware"
+	"github.com/johnpitter/ollama-code/internal/llm"
 	"github.com/johnpitter/ollama-code/internal/modes"
+	"github.com/johnpitter/ollama-code/internal/session"
 	"github.com/spf13/cobra"
 )
 
@@ -23,6 +25,8 @@ var (
 	flagURL        string
 	flagWorkDir    string
 	flagConfigFile string
+	flagContinue   bool
+	flagResume     string
 )
 
 func main() {
@@ -45,6 +49,8 @@ func main() {
 	chatCmd.Flags().StringVar(&flagURL, "url", "", "Ollama server URL")
 	chatCmd.Flags().StringVarP(&flagWorkDir, "workdir", "w", "", "Working directory")
 	chatCmd.Flags().StringVarP(&flagConfigFile, "config", "c", "", "Config file path (default: ~/.ollama-code/config.json)")
+	chatCmd.Flags().BoolVar(&flagContinue, "continue", false, "Continue the most recent session")
+	chatCmd.Flags().StringVar(&flagResume, "resume", "", "Resume a saved session by ID or number from /session list")
 
 	// Ask command (one-shot)
 	askCmd := &cobra.Command{
@@ -59,7 +65,7 @@ func main() {
 	askCmd.Flags().StringVar(&flagURL, "url", "http://localhost:11434", "Ollama server URL")
 	askCmd.Flags().StringVarP(&flagMode, "mode", "m", "autonomous", "Operation mode: readonly, interactive, autonomous")
 
-	rootCmd.AddCommand(chatCmd, askCmd)
+	rootCmd.AddCommand(chatCmd, askCmd, newModelsCommand(), newEvalCommand(), newSessionCommand())
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -142,15 +148,53 @@ func runChat(cmd *cobra.Command, args []string) {
 		appConfig.App.WorkDir = flagWorkDir
 	}
 
+	// Sessão a retomar: diretório e modo salvos valem, exceto se passados por flag
+	resumed, err := findResumeSession(appConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error resuming session: %v\n", err)
+		os.Exit(1)
+	}
+	if resumed != nil {
+		if flagWorkDir == "" && resumed.WorkDir != "" {
+			appConfig.App.WorkDir = resumed.WorkDir
+		}
+		if flagMode == "" && resumed.Mode != "" {
+			appConfig.App.Mode = resumed.Mode
+		}
+	}
+
 	// Criar agente
+	transport, err := appConfig.LLMTransport()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config: %v\n", err)
+		os.Exit(1)
+	}
+	multiModel, err := appConfig.MultiModelConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config: %v\n", err)
+		os.Exit(1)
+	}
 	cfg := agent.Config{
 		OllamaURL:      appConfig.Ollama.URL,
 		Model:          appConfig.Ollama.Model,
+		Provider:       appConfig.Ollama.Provider,
 		Mode:           modes.ParseMode(appConfig.App.Mode),
 		WorkDir:        appConfig.App.WorkDir,
+		Options:        appConfig.Ollama.GenerationOptions(),
+		Transport:      &transport,
+		EmbedModel:     appConfig.Ollama.EmbedModel,
+		MultiModel:     multiModel,
+		HostPool:       newHostPool(ctx, appConfig, transport.Connection),
+		Scheduler:      llm.NewScheduler(appConfig.Ollama.MaxInFlight()),
+		ResponseCache:  newResponseCache(appConfig),
 		EnableSessions: appConfig.App.EnableSessions,
 		EnableCache:    appConfig.Performance.EnableCache,
 		CacheTTL:       time.Duration(appConfig.Performance.CacheTTL) * time.Minute,
+
+		EnableCheckpoints:     appConfig.App.EnableCheckpoints,
+		MaxCheckpoints:        appConfig.App.MaxCheckpoints,
+		CheckpointRetention:   appConfig.App.CheckpointRetention,
+		CheckpointCompression: appConfig.App.CheckpointCompression,
 	}
 
 	ag, err := agent.NewAgent(cfg)
@@ -158,6 +202,17 @@ func runChat(cmd *cobra.Command, args []string) {
 		fmt.Fprintf(os.Stderr, "Error creating agent: %v\n", err)
 		os.Exit(1)
 	}
+	defer ag.EndSession()
+
+	if resumed != nil {
+		if _, err := ag.ResumeSession(resumed.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error resuming session: %v\n", err)
+			os.Exit(1)
+		}
+		if flagMode != "" {
+			ag.SetMode(modes.ParseMode(flagMode))
+		}
+	}
 
 	// Banner
 	blue := color.New(color.FgBlue, color.Bold)
@@ -167,6 +222,10 @@ func runChat(cmd *cobra.Command, args []string) {
 	fmt.Printf("Modelo: %s\n", appConfig.Ollama.Model)
 	fmt.Printf("Modo: %s (%s)\n", ag.GetMode(), ag.GetMode().Description())
 	fmt.Printf("Diretório: %s\n", ag.GetWorkDir())
+	if resumed != nil {
+		fmt.Printf("Sessão: %s (%d mensagens retomadas)\n", resumed.ID, len(ag.GetHistory()))
+	}
+	warnMissingModels(ctx, ag)
 	yellow.Println("\nDigite 'exit' para sair, 'help' para ajuda")
 
 	// Se tem mensagem inicial
@@ -280,6 +339,70 @@ func runAsk(cmd *cobra.Command, args []string) {
 	}
 }
 
+// findResumeSession sessão pedida por --continue ou --resume (nil sem as flags)
+func findResumeSession(appConfig *config.Config) (*session.Session, error) {
+	if !flagContinue && flagResume == "" {
+		return nil, nil
+	}
+	if !appConfig.App.EnableSessions {
+		return nil, fmt.Errorf("sessions disabled (set app.enable_sessions in the config)")
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	manager := session.NewManager(homeDir)
+	if flagResume != "" {
+		return manager.Find(flagResume)
+	}
+
+	sessions, err := manager.List(1)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, fmt.Errorf("no sessions found")
+	}
+	return sessions[0], nil
+}
+
+// newHostPool cria o pool de servidores Ollama e inicia os health checks (nil sem ollama.hosts)
+func newHostPool(ctx context.Context, appConfig *config.Config, conn llm.Connection) *llm.HostPool {
+	hosts := appConfig.Ollama.HostPoolConfig()
+	if hosts == nil {
+		return nil
+	}
+
+	pool := llm.NewHostPool(hosts)
+	pool.SetConnection(conn)
+	pool.Start(ctx, llm.DefaultHostCheckInterval)
+	for _, host := range pool.Status() {
+		if !host.Healthy {
+			color.New(color.FgYellow).Printf("⚠️  Host %s indisponível: %v\n", host.URL, host.Err)
+		}
+	}
+	return pool
+}
+
+// newResponseCache abre o cache de respostas em disco (nil se desativado ou indisponível)
+func newResponseCache(appConfig *config.Config) *llm.ResponseCache {
+	if !appConfig.Performance.ResponseCache.Enabled {
+		return nil
+	}
+
+	warn := color.New(color.FgYellow)
+	cacheConfig, err := appConfig.Performance.ResponseCache.LLMConfig()
+	if err != nil {
+		warn.Printf("⚠️  Cache de respostas desativado: %v\n", err)
+		return nil
+	}
+
+	cache, err := llm.NewResponseCache(cacheConfig)
+	if err != nil {
+		warn.Printf("⚠️  Cache de respostas desativado: %v\n", err)
+		return nil
+	}
+	return cache
+}
+
 func showHelp() {
 	blue := color.New(color.FgBlue, color.Bold)
 	yellow := color.New(color.FgYellow)
@@ -299,6 +422,10 @@ func showHelp() {
 	fmt.Println("  /history      - Mostrar histórico de conversas")
 	fmt.Println("  /status       - Mostrar status do sistema")
 	fmt.Println("  /mode [mode]  - Alterar modo de operação")
+	fmt.Println("  /compact      - Resumir turnos antigos para liberar contexto")
+	fmt.Println("  /model [name] - Listar, trocar ou baixar (pull) modelos")
+	fmt.Println("  /session      - Listar, retomar, renomear e apagar sessões")
+	fmt.Println("  /export       - Exportar a sessão (md, html, jsonl)")
 
 	yellow.Println("\n💡 Exemplos de uso:")
 	fmt.Println("  - Leia o arquivo main.go")
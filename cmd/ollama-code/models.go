@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/johnpitter/ollama-code/internal/agent"
+	"github.com/johnpitter/ollama-code/internal/config"
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+var flagModelsURL string
+
+// newModelsCommand cria o subcomando "models" (list, pull, show, rm, ps)
+func newModelsCommand() *cobra.Command {
+	modelsCmd := &cobra.Command{
+		Use:   "models",
+		Short: "Manage Ollama models",
+		Long:  "Lista, baixa, inspeciona e remove modelos do Ollama",
+		Run:   runModelsList,
+	}
+
+	modelsCmd.PersistentFlags().StringVar(&flagModelsURL, "url", "http://localhost:11434", "Ollama server URL")
+
+	modelsCmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List installed models",
+			Run:   runModelsList,
+		},
+		&cobra.Command{
+			Use:   "pull <model>",
+			Short: "Download a model",
+			Args:  cobra.ExactArgs(1),
+			Run:   runModelsPull,
+		},
+		&cobra.Command{
+			Use:   "show <model>",
+			Short: "Show model details",
+			Args:  cobra.ExactArgs(1),
+			Run:   runModelsShow,
+		},
+		&cobra.Command{
+			Use:     "rm <model>",
+			Aliases: []string{"delete"},
+			Short:   "Remove a model",
+			Args:    cobra.ExactArgs(1),
+			Run:     runModelsRemove,
+		},
+		&cobra.Command{
+			Use:   "ps",
+			Short: "List models loaded in memory",
+			Run:   runModelsPs,
+		},
+	)
+
+	return modelsCmd
+}
+
+func modelsClient() *llm.Client {
+	client := llm.NewClient(flagModelsURL, "")
+	client.SetTransportConfig(configuredTransport(""))
+	return client
+}
+
+// configuredTransport transporte do arquivo de configuração (headers, token, TLS
+// e proxy de ollama). Sem arquivo de configuração usa o padrão.
+func configuredTransport(configPath string) llm.TransportConfig {
+	var appConfig *config.Config
+	var err error
+	if configPath != "" {
+		appConfig, err = config.Load(configPath)
+	} else {
+		appConfig, err = config.LoadOrOptimize()
+	}
+	exitOnError(err)
+	if appConfig == nil {
+		return llm.DefaultTransportConfig()
+	}
+
+	transport, err := appConfig.LLMTransport()
+	exitOnError(err)
+	return transport
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runModelsList(cmd *cobra.Command, args []string) {
+	models, err := modelsClient().ListModels(context.Background())
+	exitOnError(err)
+
+	if len(models) == 0 {
+		fmt.Println("Nenhum modelo instalado. Use: ollama-code models pull <modelo>")
+		return
+	}
+
+	fmt.Printf("%-35s %10s  %-8s %-8s %s\n", "NOME", "TAMANHO", "PARAMS", "QUANT", "MODIFICADO")
+	for _, model := range models {
+		fmt.Printf("%-35s %10s  %-8s %-8s %s\n",
+			model.Name,
+			llm.FormatSize(model.Size),
+			model.Details.ParameterSize,
+			model.Details.QuantizationLevel,
+			model.ModifiedAt.Format("2006-01-02 15:04"))
+	}
+}
+
+func runModelsPull(cmd *cobra.Command, args []string) {
+	name := args[0]
+	fmt.Printf("📥 Baixando %s...\n", name)
+
+	err := modelsClient().PullModel(context.Background(), name, func(p llm.PullProgress) {
+		if p.Total > 0 {
+			fmt.Printf("\r%-30s %5.1f%% (%s / %s)", p.Status, p.Percent(), llm.FormatSize(p.Completed), llm.FormatSize(p.Total))
+		} else {
+			fmt.Printf("\r%-60s", p.Status)
+		}
+	})
+	fmt.Println()
+	exitOnError(err)
+
+	color.New(color.FgGreen).Printf("✅ Modelo %s instalado\n", name)
+}
+
+func runModelsShow(cmd *cobra.Command, args []string) {
+	info, err := modelsClient().ShowModel(context.Background(), args[0])
+	exitOnError(err)
+
+	fmt.Printf("Modelo:       %s\n", args[0])
+	fmt.Printf("Família:      %s\n", info.Details.Family)
+	fmt.Printf("Parâmetros:   %s\n", info.Details.ParameterSize)
+	fmt.Printf("Quantização:  %s\n", info.Details.QuantizationLevel)
+	fmt.Printf("Formato:      %s\n", info.Details.Format)
+	if len(info.Capabilities) > 0 {
+		fmt.Printf("Capacidades:  %s\n", strings.Join(info.Capabilities, ", "))
+	}
+
+	// Janela de contexto nativa (chave "<arquitetura>.context_length")
+	keys := make([]string, 0, len(info.ModelInfo))
+	for key := range info.ModelInfo {
+		if strings.HasSuffix(key, ".context_length") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("Contexto:     %v tokens\n", info.ModelInfo[key])
+	}
+
+	if info.Parameters != "" {
+		fmt.Printf("\nParâmetros padrão:\n%s\n", info.Parameters)
+	}
+}
+
+func runModelsRemove(cmd *cobra.Command, args []string) {
+	exitOnError(modelsClient().DeleteModel(context.Background(), args[0]))
+	color.New(color.FgGreen).Printf("🗑️  Modelo %s removido\n", args[0])
+}
+
+func runModelsPs(cmd *cobra.Command, args []string) {
+	models, err := modelsClient().RunningModels(context.Background())
+	exitOnError(err)
+
+	if len(models) == 0 {
+		fmt.Println("Nenhum modelo carregado em memória")
+		return
+	}
+
+	fmt.Printf("%-35s %10s %10s  %s\n", "NOME", "TAMANHO", "VRAM", "EXPIRA")
+	for _, model := range models {
+		fmt.Printf("%-35s %10s %10s  %s\n",
+			model.Name,
+			llm.FormatSize(model.Size),
+			llm.FormatSize(model.SizeVRAM),
+			time.Until(model.ExpiresAt).Round(time.Second))
+	}
+}
+
+// warnMissingModels avisa na inicialização sobre modelos configurados não instalados
+func warnMissingModels(ctx context.Context, ag *agent.Agent) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	yellow := color.New(color.FgYellow)
+
+	missing, err := ag.MissingModels(ctx)
+	if err != nil {
+		yellow.Printf("⚠️  Não foi possível verificar modelos instalados: %v\n", err)
+		return
+	}
+
+	for _, name := range missing {
+		yellow.Printf("⚠️  Modelo %s não está instalado. Use: ollama-code models pull %s\n", name, name)
+	}
+}
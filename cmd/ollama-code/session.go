@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/johnpitter/ollama-code/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagExportFormat string
+	flagExportOutput string
+)
+
+// newSessionCommand cria o subcomando "session" (export, import)
+func newSessionCommand() *cobra.Command {
+	sessionCmd := &cobra.Command{
+		Use:   "session",
+		Short: "Export and import session transcripts",
+		Long:  "Exporta sessões salvas em Markdown, HTML ou JSONL e importa transcrições JSONL",
+	}
+
+	exportCmd := &cobra.Command{
+		Use:   "export <id|#>",
+		Short: "Export a session transcript (stdout by default)",
+		Args:  cobra.ExactArgs(1),
+		Run:   runSessionExport,
+	}
+	exportCmd.Flags().StringVar(&flagExportFormat, "format", session.FormatMarkdown, "Output format: md, html, jsonl")
+	exportCmd.Flags().StringVarP(&flagExportOutput, "output", "o", "", "Write to file instead of stdout")
+
+	sessionCmd.AddCommand(
+		exportCmd,
+		&cobra.Command{
+			Use:   "import <file.jsonl>",
+			Short: "Import a JSONL transcript as a new session",
+			Args:  cobra.ExactArgs(1),
+			Run:   runSessionImport,
+		},
+	)
+
+	return sessionCmd
+}
+
+// sessionManager gerenciador das sessões salvas em ~/.ollama-code/sessions
+func sessionManager() *session.Manager {
+	homeDir, _ := os.UserHomeDir()
+	return session.NewManager(homeDir)
+}
+
+func runSessionExport(cmd *cobra.Command, args []string) {
+	target, err := sessionManager().Find(args[0])
+	exitOnError(err)
+
+	if flagExportOutput == "" {
+		exitOnError(session.Export(os.Stdout, target, flagExportFormat))
+		return
+	}
+
+	file, err := os.Create(flagExportOutput)
+	exitOnError(err)
+	defer file.Close()
+
+	if err := session.Export(file, target, flagExportFormat); err != nil {
+		os.Remove(flagExportOutput)
+		exitOnError(err)
+	}
+	fmt.Printf("✓ Sessão %s exportada para %s\n", target.ID, flagExportOutput)
+}
+
+func runSessionImport(cmd *cobra.Command, args []string) {
+	file, err := os.Open(args[0])
+	exitOnError(err)
+	defer file.Close()
+
+	imported, err := sessionManager().Import(file)
+	exitOnError(err)
+
+	fmt.Printf("✓ Sessão importada: %s (%d mensagens)\n", imported.ID, len(imported.Messages))
+	if imported.WorkDir != "" {
+		if _, err := os.Stat(imported.WorkDir); err != nil {
+			fmt.Printf("⚠️  Diretório original %s não existe aqui; use --workdir ao retomar\n", imported.WorkDir)
+		}
+	}
+	fmt.Printf("Para continuar: ollama-code chat --resume %s\n", imported.ID)
+}
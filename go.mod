@@ -4,11 +4,11 @@ go 1.21
 
 require (
 	github.com/fatih/color v1.16.0
+	github.com/google/uuid v1.6.0
 	github.com/spf13/cobra v1.8.0
 )
 
 require (
-	github.com/google/uuid v1.6.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
@@ -10,10 +10,13 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/johnpitter/ollama-code/internal/cache"
+	"github.com/johnpitter/ollama-code/internal/checkpoint"
 	"github.com/johnpitter/ollama-code/internal/commands"
 	"github.com/johnpitter/ollama-code/internal/confirmation"
+	"github.com/johnpitter/ollama-code/internal/ctxwindow"
 	"github.com/johnpitter/ollama-code/internal/diff"
 	"github.com/johnpitter/ollama-code/internal/handlers"
+	"github.com/johnpitter/ollama-code/internal/index"
 	"github.com/johnpitter/ollama-code/internal/intent"
 	"github.com/johnpitter/ollama-code/internal/llm"
 	"github.com/johnpitter/ollama-code/internal/modes"
@@ -31,7 +34,7 @@ import (
 
 // Agent agente principal
 type Agent struct {
-	LLMClient        *llm.Client
+	LLMClient        llm.Provider
 	IntentDetector   *intent.Detector
 	ToolRegistry     *tools.Registry
 	CommandRegistry  *commands.Registry
@@ -49,12 +52,29 @@ type Agent struct {
 	Previewer        *diff.Previewer
 	SubagentManager  *subagent.Manager
 	MultiModelRouter *multimodel.Router
+	HostPool         *llm.HostPool       // Servidores Ollama entre os quais as requisições são distribuídas (opcional)
+	Scheduler        *llm.Scheduler      // Limite de requisições simultâneas compartilhado (opcional)
+	ResponseCache    *llm.ResponseCache  // Respostas de prompts determinísticos em disco (opcional)
+	Checkpoints      *checkpoint.Manager // Checkpoints automáticos antes de escritas e comandos (opcional)
+	ContextManager   *ctxwindow.Manager
+	Index            *index.Index // Índice semântico do código (nil se o provider não gera embeddings)
 	Mode             modes.OperationMode
 	WorkDir          string
 	History          []llm.Message
 	RecentFiles      []string // Arquivos criados/modificados recentemente
+	MaxSteps         int      // Limite de passos do loop por mensagem (0 = DefaultMaxSteps)
+	Usage            *UsageTracker
 	Mu               sync.Mutex
 
+	// toolsUnsupported modelo atual não suporta tool calling (usa detecção de intenção)
+	toolsUnsupported bool
+
+	// imageNames nomes das imagens do turno atual, para os marcadores no histórico
+	imageNames map[string]string
+
+	// conversationID identifica a conversa no pool de hosts (mantém o mesmo host e o KV cache)
+	conversationID string
+
 	// Colors
 	ColorGreen  *color.Color
 	ColorBlue   *color.Color
@@ -66,14 +86,29 @@ type Agent struct {
 type Config struct {
 	OllamaURL        string
 	Model            string
+	Provider         string // "ollama" (padrão) ou "openai" (llama.cpp, vLLM...)
 	Mode             modes.OperationMode
 	WorkDir          string
-	Temperature      float64
+	Temperature      *float64
 	MaxTokens        int
 	EnableSessions   bool
 	EnableCache      bool
 	EnableStatusLine bool
 	CacheTTL         time.Duration
+	MaxSteps         int
+	NumCtx           int                   // Janela de contexto do modelo em tokens
+	Options          llm.CompletionOptions // Opções de geração padrão (num_ctx, seed, stop...)
+	Transport        *llm.TransportConfig  // Timeouts, retry, circuit breaker e conexão (nil usa o padrão)
+	EmbedModel       string                // Modelo de embeddings do índice semântico (padrão: index.DefaultModel)
+	MultiModel       *multimodel.Config    // Modelo por tipo de tarefa (nil usa o modelo principal em tudo)
+	HostPool         *llm.HostPool         // Pool de servidores Ollama (nil usa só OllamaURL)
+	Scheduler        *llm.Scheduler        // Limite de requisições simultâneas (nil = sem limite)
+	ResponseCache    *llm.ResponseCache    // Cache de respostas em disco (nil = desativado)
+
+	EnableCheckpoints     bool
+	MaxCheckpoints        int    // Máximo de checkpoints automáticos (0 = padrão)
+	CheckpointRetention   int    // Dias de retenção dos checkpoints (0 = padrão)
+	CheckpointCompression string // Compressão dos conteúdos salvos ("gzip" padrão, "none")
 }
 
 // NewAgent cria novo agente
@@ -94,12 +129,40 @@ func NewAgent(cfg Config) (*Agent, error) {
 	if cfg.CacheTTL == 0 {
 		cfg.CacheTTL = 5 * time.Minute // Default 5 minutes
 	}
+	if cfg.MaxSteps == 0 {
+		cfg.MaxSteps = DefaultMaxSteps
+	}
+	if cfg.NumCtx == 0 {
+		cfg.NumCtx = cfg.Options.NumCtx
+	}
+	if cfg.NumCtx == 0 {
+		cfg.NumCtx = ctxwindow.DefaultConfig().NumCtx
+	}
+
+	// Criar LLM client do provider configurado
+	llmClient, err := llm.NewProvider(cfg.Provider, cfg.OllamaURL, cfg.Model)
+	if err != nil {
+		return nil, err
+	}
+	llmClient.SetDefaultOptions(defaultOptions(cfg))
+	if cfg.Transport != nil {
+		llmClient.SetTransportConfig(*cfg.Transport)
+	}
+	llmClient.SetHostPool(cfg.HostPool)
+	llmClient.SetScheduler(cfg.Scheduler)
+	llmClient.SetResponseCache(cfg.ResponseCache)
 
-	// Criar LLM client
-	llmClient := llm.NewClient(cfg.OllamaURL, cfg.Model)
+	// Router multi-model (intent, code, search, analysis e vision), que também
+	// guarda os modelos em cool-down para os fallbacks do client principal
+	multiModelRouter := newMultiModelRouter(cfg)
+	llmClient.SetModelHealth(multiModelRouter.Health())
 
-	// Criar detector de intenções
+	// Criar detector de intenções (histórico limitado a 1/8 da janela)
 	intentDetector := intent.NewDetector(llmClient)
+	intentDetector.SetHistoryBudget(cfg.NumCtx / 8)
+
+	// Gerenciador da janela de contexto
+	contextManager := ctxwindow.NewManager(ctxwindow.Config{NumCtx: cfg.NumCtx}, ctxwindow.NewLLMSummarizer(llmClient))
 
 	// Session manager (opcional)
 	var sessionMgr *session.Manager
@@ -114,6 +177,12 @@ func NewAgent(cfg Config) (*Agent, error) {
 		cacheMgr = cache.NewManager(cfg.CacheTTL)
 	}
 
+	// Checkpoints (opcional)
+	var checkpointMgr *checkpoint.Manager
+	if cfg.EnableCheckpoints {
+		checkpointMgr = NewCheckpointManager(cfg.WorkDir, cfg.CheckpointRetention, cfg.MaxCheckpoints, cfg.CheckpointCompression)
+	}
+
 	// Status Line (opcional)
 	var statusLineMgr *statusline.StatusLine
 	if cfg.EnableStatusLine {
@@ -133,6 +202,12 @@ func NewAgent(cfg Config) (*Agent, error) {
 		})
 	}
 
+	// Índice semântico (opcional: depende de embeddings no provider)
+	var codeIndex *index.Index
+	if embedder, ok := llmClient.(llm.Embedder); ok {
+		codeIndex = index.New(embedder, index.Config{WorkDir: cfg.WorkDir, Model: cfg.EmbedModel})
+	}
+
 	// Criar registry de ferramentas
 	toolRegistry := tools.NewRegistry()
 
@@ -155,6 +230,9 @@ func NewAgent(cfg Config) (*Agent, error) {
 	// Registrar novas integrações
 	toolRegistry.Register(tools.NewGitHelper(cfg.WorkDir))
 	toolRegistry.Register(tools.NewCodeFormatter(cfg.WorkDir))
+	if codeIndex != nil {
+		toolRegistry.Register(tools.NewSemanticSearch(codeIndex))
+	}
 
 	// Criar registry de skills
 	skillRegistry := skills.NewRegistry()
@@ -180,6 +258,7 @@ func NewAgent(cfg Config) (*Agent, error) {
 	// Registrar handlers
 	handlerRegistry.Register(intent.IntentReadFile, handlers.NewFileReadHandler())
 	handlerRegistry.Register(intent.IntentWriteFile, handlers.NewFileWriteHandler())
+	handlerRegistry.Register(intent.IntentEditFile, handlers.NewEditFileHandler())
 	handlerRegistry.Register(intent.IntentExecuteCommand, handlers.NewExecuteHandler())
 	handlerRegistry.Register(intent.IntentSearchCode, handlers.NewSearchHandler())
 	handlerRegistry.Register(intent.IntentAnalyzeProject, handlers.NewAnalyzeHandler())
@@ -190,31 +269,58 @@ func NewAgent(cfg Config) (*Agent, error) {
 	handlerRegistry.RegisterDefault(handlers.NewQuestionHandler())
 
 	agent := &Agent{
-		LLMClient:       llmClient,
-		IntentDetector:  intentDetector,
-		ToolRegistry:    toolRegistry,
-		CommandRegistry: commands.NewRegistry(),
-		SkillRegistry:   skillRegistry,
-		ConfirmManager:  confirmation.NewManager(),
-		WebSearch:       websearch.NewOrchestrator(),
-		SessionManager:  sessionMgr,
-		Cache:           cacheMgr,
-		StatusLine:      statusLineMgr,
-		OllamaContext:   ollamaContext,
-		HandlerRegistry: handlerRegistry,
-		Mode:            cfg.Mode,
-		WorkDir:         cfg.WorkDir,
-		History:         []llm.Message{},
-		RecentFiles:     []string{},
-		ColorGreen:      color.New(color.FgGreen, color.Bold),
-		ColorBlue:       color.New(color.FgBlue, color.Bold),
-		ColorYellow:     color.New(color.FgYellow),
-		ColorRed:        color.New(color.FgRed),
+		LLMClient:        llmClient,
+		IntentDetector:   intentDetector,
+		ToolRegistry:     toolRegistry,
+		CommandRegistry:  commands.NewRegistry(),
+		SkillRegistry:    skillRegistry,
+		ConfirmManager:   confirmation.NewManager(),
+		WebSearch:        websearch.NewOrchestrator(),
+		SessionManager:   sessionMgr,
+		Cache:            cacheMgr,
+		StatusLine:       statusLineMgr,
+		OllamaContext:    ollamaContext,
+		HandlerRegistry:  handlerRegistry,
+		MultiModelRouter: multiModelRouter,
+		HostPool:         cfg.HostPool,
+		Scheduler:        cfg.Scheduler,
+		ResponseCache:    cfg.ResponseCache,
+		Checkpoints:      checkpointMgr,
+		ContextManager:   contextManager,
+		Index:            codeIndex,
+		Mode:             cfg.Mode,
+		WorkDir:          cfg.WorkDir,
+		History:          []llm.Message{},
+		RecentFiles:      []string{},
+		MaxSteps:         cfg.MaxSteps,
+		ColorGreen:       color.New(color.FgGreen, color.Bold),
+		ColorBlue:        color.New(color.FgBlue, color.Bold),
+		ColorYellow:      color.New(color.FgYellow),
+		ColorRed:         color.New(color.FgRed),
+		conversationID:   fmt.Sprintf("agent-%d", time.Now().UnixNano()),
 	}
 
+	agent.AttachLLMHooks()
+	agent.AttachCheckpoints()
+	agent.RegisterCommands()
+
 	return agent, nil
 }
 
+// defaultOptions opções de geração padrão a partir da configuração
+func defaultOptions(cfg Config) llm.CompletionOptions {
+	opts := cfg.Options
+	if opts.Temperature == nil {
+		opts.Temperature = cfg.Temperature
+	}
+	if opts.MaxTokens == 0 {
+		opts.MaxTokens = cfg.MaxTokens
+	}
+	// A janela enviada ao Ollama precisa bater com a usada na compactação
+	opts.NumCtx = cfg.NumCtx
+	return opts
+}
+
 // GetSessionManager retorna o gerenciador de sessões
 func (a *Agent) GetSessionManager() *session.Manager {
 	return a.SessionManager
@@ -237,19 +343,61 @@ func (a *Agent) GetSkillRegistry() *skills.Registry {
 
 // ProcessMessage processa mensagem do usuário
 func (a *Agent) ProcessMessage(ctx context.Context, userMessage string) error {
+	ctx = llm.WithConversation(ctx, a.conversationID)
+
+	// Imagens anexadas com @arquivo.png
+	userMessage, images := a.extractAttachments(userMessage)
+	if len(images) > 0 {
+		a.ColorBlue.Printf("📎 %d imagem(ns) anexada(s)\n", len(images))
+	}
+
 	// Adicionar mensagem ao histórico
 	a.Mu.Lock()
 	a.History = append(a.History, llm.Message{
 		Role:    "user",
 		Content: userMessage,
+		Images:  images,
 	})
 	a.Mu.Unlock()
 
+	if a.Usage != nil {
+		a.Usage.StartTurn()
+		defer a.reportTurnUsage()
+	}
+	defer a.persistSession()
+	defer a.forgetImages()
+
+	if !a.toolsUnsupported {
+		response, err := a.runLoop(ctx, userMessage)
+		if err == nil {
+			if response != "" {
+				fmt.Println()
+			}
+			return nil
+		}
+
+		if !isToolsUnsupported(err) {
+			return fmt.Errorf("agent loop: %w", err)
+		}
+
+		// Modelo sem tool calling: usar detecção de intenção daqui em diante
+		a.ColorYellow.Println("⚠️  Modelo não suporta ferramentas, usando detecção de intenção")
+		a.toolsUnsupported = true
+	}
+
+	return a.processWithIntent(ctx, userMessage)
+}
+
+// processWithIntent processa mensagem com uma detecção de intenção e um handler
+func (a *Agent) processWithIntent(ctx context.Context, userMessage string) error {
+	a.autoCompact(ctx)
+
 	// Detectar intenção com histórico da conversa
 	a.ColorBlue.Println("\n🔍 Detectando intenção...")
 
 	recentFiles := a.getRecentFiles()
-	detectionResult, err := a.IntentDetector.DetectWithHistory(ctx, userMessage, a.WorkDir, recentFiles, a.History)
+	detector := a.IntentDetector.WithClient(a.clientForTask(multimodel.TaskTypeIntent))
+	detectionResult, err := detector.DetectWithHistory(llm.WithPriority(ctx, llm.PriorityIntent), userMessage, a.WorkDir, recentFiles, a.History)
 	if err != nil {
 		return fmt.Errorf("detect intent: %w", err)
 	}
@@ -333,11 +481,13 @@ func (a *Agent) GetHistory() []llm.Message {
 	return append([]llm.Message{}, a.History...)
 }
 
-// ClearHistory limpa histórico
+// ClearHistory limpa histórico (e encerra a sessão: a próxima mensagem inicia outra)
 func (a *Agent) ClearHistory() {
 	a.Mu.Lock()
-	defer a.Mu.Unlock()
 	a.History = []llm.Message{}
+	a.Mu.Unlock()
+
+	a.EndSession()
 }
 
 // SetMode altera modo de operação
@@ -405,9 +555,16 @@ func (a *Agent) buildDependencies() *handlers.Dependencies {
 		handlerHistory[i] = handlers.Message{
 			Role:    msg.Role,
 			Content: msg.Content,
+			Images:  msg.Images,
 		}
 	}
 
+	// Modelo multimodal para handlers que recebem imagens
+	var visionClient handlers.LLMClient
+	if client := a.routedClient(multimodel.TaskTypeVision); client != nil {
+		visionClient = handlers.NewLLMClientAdapter(a.trackTask(multimodel.TaskTypeVision, client))
+	}
+
 	return &handlers.Dependencies{
 		ToolRegistry:    handlers.NewToolRegistryAdapter(a.ToolRegistry),
 		CommandRegistry: handlers.NewCommandRegistryAdapter(a.CommandRegistry),
@@ -419,8 +576,13 @@ func (a *Agent) buildDependencies() *handlers.Dependencies {
 		DiffManager:     handlers.NewDiffManagerAdapter(a.Differ),
 		PreviewManager:  handlers.NewPreviewManagerAdapter(a.Previewer),
 		LLMClient:       handlers.NewLLMClientAdapter(a.LLMClient),
+		VisionClient:    visionClient,
+		CodeClient:      handlers.NewLLMClientAdapter(a.clientForTask(multimodel.TaskTypeCode)),
+		SearchClient:    handlers.NewLLMClientAdapter(a.clientForTask(multimodel.TaskTypeSearch)),
+		AnalysisClient:  handlers.NewLLMClientAdapter(a.clientForTask(multimodel.TaskTypeAnalysis)),
 		WebSearch:       handlers.NewWebSearchClientAdapter(a.WebSearch),
-		IntentDetector:  handlers.NewIntentDetectorAdapter(a.IntentDetector),
+		IntentDetector:  handlers.NewIntentDetectorAdapter(a.IntentDetector.WithClient(a.clientForTask(multimodel.TaskTypeIntent))),
+		CodeRetriever:   handlers.NewCodeRetrieverAdapter(a.Index),
 		Mode:            handlers.NewOperationModeAdapter(a.Mode),
 		WorkDir:         a.WorkDir,
 		History:         handlerHistory,
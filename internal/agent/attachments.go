@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/multimodel"
+)
+
+// maxAttachmentSize limite por imagem anexada (base64 cresce ~33%)
+const maxAttachmentSize = 20 * 1024 * 1024
+
+// attachmentPattern referências "@caminho/para/imagem.png" na mensagem do usuário
+var attachmentPattern = regexp.MustCompile(`(?i)(^|\s)@(\S+\.(?:png|jpe?g|gif|webp|bmp))\b`)
+
+// extractAttachments lê as imagens referenciadas com @ e retorna a mensagem sem
+// o prefixo @ (o modelo ainda vê o nome do arquivo) e as imagens em base64
+func (a *Agent) extractAttachments(message string) (string, []string) {
+	var images []string
+
+	text := attachmentPattern.ReplaceAllStringFunc(message, func(match string) string {
+		groups := attachmentPattern.FindStringSubmatch(match)
+		prefix, path := groups[1], groups[2]
+
+		image, err := a.readAttachment(path)
+		if err != nil {
+			a.ColorYellow.Printf("⚠️  Anexo ignorado: %v\n", err)
+			return match
+		}
+
+		images = append(images, image)
+		a.nameImage(image, path)
+		return prefix + path
+	})
+
+	return text, images
+}
+
+// readAttachment lê uma imagem relativa ao diretório de trabalho
+func (a *Agent) readAttachment(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(a.WorkDir, path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	if info.Size() > maxAttachmentSize {
+		return "", fmt.Errorf("%s: image larger than %d MB", path, maxAttachmentSize/(1024*1024))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// nameImage guarda o nome da imagem para o marcador que a substitui no histórico
+func (a *Agent) nameImage(image, name string) {
+	a.Mu.Lock()
+	defer a.Mu.Unlock()
+	if a.imageNames == nil {
+		a.imageNames = make(map[string]string)
+	}
+	a.imageNames[image] = name
+}
+
+// forgetImages troca as imagens do histórico por marcadores "[image: nome]" ao
+// fim do turno: o base64 não é reenviado nem salvo na sessão, e os próximos
+// turnos voltam ao modelo principal
+func (a *Agent) forgetImages() {
+	a.Mu.Lock()
+	defer a.Mu.Unlock()
+
+	for i := range a.History {
+		msg := &a.History[i]
+		if len(msg.Images) == 0 {
+			continue
+		}
+
+		placeholders := make([]string, 0, len(msg.Images))
+		for _, image := range msg.Images {
+			name := a.imageNames[image]
+			if name == "" {
+				name = "unnamed"
+			}
+			placeholders = append(placeholders, fmt.Sprintf("[image: %s]", name))
+		}
+		msg.Content = strings.TrimSpace(msg.Content + "\n" + strings.Join(placeholders, " "))
+		msg.Images = nil
+	}
+	a.imageNames = nil
+}
+
+// clientFor escolhe o client para as mensagens: turnos com imagens vão para o
+// modelo de vision do multi-model, quando configurado
+func (a *Agent) clientFor(messages []llm.Message) llm.Provider {
+	if !llm.TurnHasImages(messages) {
+		return a.LLMClient
+	}
+
+	if client := a.routedClient(multimodel.TaskTypeVision); client != nil {
+		return a.trackTask(multimodel.TaskTypeVision, client)
+	}
+	return a.LLMClient
+}
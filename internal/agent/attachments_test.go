@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/llmtest"
+	"github.com/johnpitter/ollama-code/internal/modes"
+	"github.com/johnpitter/ollama-code/internal/multimodel"
+)
+
+var testPNG = []byte("\x89PNG\r\n\x1a\nfake")
+
+func TestExtractAttachments(t *testing.T) {
+	agent := newLoopTestAgent(t, "http://localhost:0", modes.ModeReadOnly)
+	os.MkdirAll(filepath.Join(agent.WorkDir, "docs"), 0755)
+	os.WriteFile(filepath.Join(agent.WorkDir, "docs", "arq.PNG"), testPNG, 0644)
+
+	text, images := agent.extractAttachments("veja @docs/arq.PNG e @faltando.png, mas não user@host.png")
+
+	if text != "veja docs/arq.PNG e @faltando.png, mas não user@host.png" {
+		t.Errorf("Unexpected text: %q", text)
+	}
+	if len(images) != 1 || images[0] != base64.StdEncoding.EncodeToString(testPNG) {
+		t.Errorf("Expected one attached image, got %d", len(images))
+	}
+}
+
+func TestProcessMessage_ImageTurnUsesVisionModel(t *testing.T) {
+	server := llmtest.NewServer(t, llmtest.Text("Um botão desalinhado"), llmtest.Text("De nada"))
+
+	agent := newLoopTestAgent(t, server.URL, modes.ModeReadOnly)
+	os.WriteFile(filepath.Join(agent.WorkDir, "tela.png"), testPNG, 0644)
+
+	cfg := multimodel.NewConfig()
+	cfg.DefaultModel = multimodel.ModelSpec{Name: "test-model"}
+	cfg.Enable()
+	cfg.SetModel(multimodel.TaskTypeVision, multimodel.ModelSpec{Name: "llava:7b"})
+	agent.MultiModelRouter = multimodel.NewRouter(server.URL, cfg)
+
+	if err := agent.ProcessMessage(context.Background(), "o que há de errado em @tela.png?"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	request := server.LastRequest()
+	if request.Model != "llava:7b" {
+		t.Errorf("Image turn should use the vision model, got %s", request.Model)
+	}
+	user := request.Messages[len(request.Messages)-1]
+	if user.Content != "o que há de errado em tela.png?" || len(user.Images) != 1 {
+		t.Errorf("Expected the image attached to the user message, got %+v", user)
+	}
+
+	// Depois do turno a imagem vira marcador: o próximo turno volta ao modelo
+	// principal e o base64 não é reenviado
+	if err := agent.ProcessMessage(context.Background(), "obrigado"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	request = server.LastRequest()
+	if request.Model != "test-model" {
+		t.Errorf("Turn without images should use the main model, got %s", request.Model)
+	}
+	for _, msg := range request.Messages {
+		if len(msg.Images) > 0 {
+			t.Errorf("Images from earlier turns should not be resent, got %+v", msg)
+		}
+	}
+	if first := agent.GetHistory()[0]; !strings.Contains(first.Content, "[image: tela.png]") {
+		t.Errorf("Expected image placeholder in history, got %q", first.Content)
+	}
+}
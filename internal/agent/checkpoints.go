@@ -0,0 +1,241 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johnpitter/ollama-code/internal/checkpoint"
+	"github.com/johnpitter/ollama-code/internal/diff"
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/tools"
+)
+
+// errCheckpointsDisabled checkpoints desligados na configuração
+var errCheckpointsDisabled = errors.New("checkpoints disabled (set app.enable_checkpoints in the config)")
+
+// NewCheckpointManager cria o gerenciador de checkpoints do projeto com a
+// retenção (em dias), o máximo de checkpoints automáticos (0 mantém o padrão)
+// e a compressão dos conteúdos (a config já foi validada; inválida mantém gzip)
+func NewCheckpointManager(workDir string, retentionDays, maxCheckpoints int, compression string) *checkpoint.Manager {
+	manager := checkpoint.NewManager(workDir)
+	manager.SetLimits(time.Duration(retentionDays)*24*time.Hour, maxCheckpoints)
+	manager.SetCompression(compression)
+	return manager
+}
+
+// AttachCheckpoints cria checkpoint automático antes de cada ferramenta que
+// modifica arquivos ou executa comandos (handlers e loop passam pelo ToolRegistry)
+func (a *Agent) AttachCheckpoints() {
+	if a.Checkpoints == nil || a.ToolRegistry == nil {
+		return
+	}
+	a.ToolRegistry.SetBeforeExecute(a.autoCheckpoint)
+}
+
+// autoCheckpoint salva conversa e arquivos que a ferramenta pode alterar
+func (a *Agent) autoCheckpoint(ctx context.Context, tool tools.Tool, params map[string]interface{}) {
+	if a.Checkpoints == nil || !a.Mode.AllowsWrites() {
+		return
+	}
+
+	description := tool.Name()
+	files := a.GetRecentlyModifiedFiles()
+	if path, _ := params["file_path"].(string); path != "" {
+		description += " " + path
+		files = append([]string{path}, files...)
+	} else if command, _ := params["command"].(string); command != "" {
+		description += " " + command
+	}
+
+	if _, err := a.createCheckpoint(files, "before "+description, true); err != nil {
+		a.ColorYellow.Printf("⚠️  Não foi possível criar checkpoint: %v\n", err)
+	}
+}
+
+// createCheckpoint salva a conversa atual e o estado dos arquivos (sem repetições)
+func (a *Agent) createCheckpoint(files []string, description string, auto bool) (*checkpoint.Checkpoint, error) {
+	seen := make(map[string]bool, len(files))
+	unique := make([]string, 0, len(files))
+	for _, file := range files {
+		if !seen[file] {
+			seen[file] = true
+			unique = append(unique, file)
+		}
+	}
+
+	return a.Checkpoints.CreateCheckpoint(a.GetHistory(), unique, a.WorkDir, description, auto)
+}
+
+// CheckpointCommand cria checkpoint manual da conversa e dos arquivos recentes
+type CheckpointCommand struct {
+	agent *Agent
+}
+
+func (c *CheckpointCommand) Name() string        { return "checkpoint" }
+func (c *CheckpointCommand) Description() string { return "Save conversation and touched files" }
+func (c *CheckpointCommand) Usage() string       { return "/checkpoint [description] | diff <a> <b>" }
+
+func (c *CheckpointCommand) Execute(ctx context.Context, args []string) (string, error) {
+	if c.agent.Checkpoints == nil {
+		return "", errCheckpointsDisabled
+	}
+
+	if len(args) > 0 && args[0] == "diff" {
+		if len(args) != 3 {
+			return "", fmt.Errorf("two checkpoint IDs required\nUsage: /checkpoint diff <a> <b>")
+		}
+		return c.diff(args[1], args[2])
+	}
+
+	description := "Manual checkpoint"
+	if len(args) > 0 {
+		description = strings.Join(args, " ")
+	}
+
+	cp, err := c.agent.createCheckpoint(c.agent.GetRecentlyModifiedFiles(), description, false)
+	if err != nil {
+		return "", fmt.Errorf("create checkpoint: %w", err)
+	}
+
+	return fmt.Sprintf("✓ Checkpoint %s created: %s (%d files, %d messages)",
+		cp.ID, description, len(cp.FileStates), len(cp.Conversation)), nil
+}
+
+// diff mostra arquivos e conversa que mudaram entre dois checkpoints
+func (c *CheckpointCommand) diff(fromID, toID string) (string, error) {
+	from, err := c.agent.Checkpoints.Get(fromID)
+	if err != nil {
+		return "", fmt.Errorf("load checkpoint %s: %w", fromID, err)
+	}
+	to, err := c.agent.Checkpoints.Get(toID)
+	if err != nil {
+		return "", fmt.Errorf("load checkpoint %s: %w", toID, err)
+	}
+
+	changes, err := c.agent.Checkpoints.Diff(fromID, toID)
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Changes from %s to %s:\n", from.ID, to.ID))
+	result.WriteString(fmt.Sprintf("  conversation: %d → %d messages\n", len(from.Conversation), len(to.Conversation)))
+	if len(changes) == 0 {
+		result.WriteString("  no file changes\n")
+		return result.String(), nil
+	}
+
+	differ := diff.NewDiffer()
+	previewer := diff.NewPreviewer()
+	fileDiffs := make([]*diff.FileDiff, len(changes))
+	for i, change := range changes {
+		fileDiffs[i] = differ.ComputeDiff(change.Path, change.OldContent, change.NewContent)
+		result.WriteString(fmt.Sprintf("  %-8s %s\n", change.Status, previewer.CompactPreview(fileDiffs[i])))
+	}
+	for _, fileDiff := range fileDiffs {
+		result.WriteString("\n")
+		result.WriteString(previewer.Preview(fileDiff))
+	}
+
+	return result.String(), nil
+}
+
+// CheckpointsCommand lista os checkpoints do projeto
+type CheckpointsCommand struct {
+	agent *Agent
+}
+
+func (c *CheckpointsCommand) Name() string        { return "checkpoints" }
+func (c *CheckpointsCommand) Description() string { return "List checkpoints" }
+func (c *CheckpointsCommand) Usage() string       { return "/checkpoints [limit]" }
+
+func (c *CheckpointsCommand) Execute(ctx context.Context, args []string) (string, error) {
+	if c.agent.Checkpoints == nil {
+		return "", errCheckpointsDisabled
+	}
+
+	limit := 10
+	if len(args) > 0 {
+		fmt.Sscanf(args[0], "%d", &limit)
+	}
+
+	checkpoints, err := c.agent.Checkpoints.List(limit)
+	if err != nil {
+		return "", fmt.Errorf("list checkpoints: %w", err)
+	}
+	if len(checkpoints) == 0 {
+		return "No checkpoints yet", nil
+	}
+
+	var result strings.Builder
+	result.WriteString("Checkpoints (newest first):\n\n")
+	for _, cp := range checkpoints {
+		kind := "manual"
+		if cp.AutoCreated {
+			kind = "auto"
+		}
+		result.WriteString(fmt.Sprintf("  %s  %s  %-6s %2d files  %s\n",
+			cp.ID, cp.Timestamp.Format("2006-01-02 15:04:05"), kind, len(cp.FileStates), cp.Description))
+	}
+	result.WriteString("\nUse /rewind <id> [--code|--conversation|--both] to restore")
+
+	return result.String(), nil
+}
+
+// RewindCommand restaura arquivos e/ou conversa de um checkpoint
+type RewindCommand struct {
+	agent *Agent
+}
+
+func (c *RewindCommand) Name() string        { return "rewind" }
+func (c *RewindCommand) Description() string { return "Restore files or conversation" }
+func (c *RewindCommand) Usage() string       { return "/rewind <id> [--code|--conversation|--both]" }
+
+func (c *RewindCommand) Execute(ctx context.Context, args []string) (string, error) {
+	if c.agent.Checkpoints == nil {
+		return "", errCheckpointsDisabled
+	}
+
+	var id string
+	restoreCode, restoreConversation := true, true
+	for _, arg := range args {
+		switch arg {
+		case "--code":
+			restoreCode, restoreConversation = true, false
+		case "--conversation":
+			restoreCode, restoreConversation = false, true
+		case "--both":
+			restoreCode, restoreConversation = true, true
+		default:
+			if strings.HasPrefix(arg, "--") {
+				return "", fmt.Errorf("unknown option %s\nUsage: %s", arg, c.Usage())
+			}
+			id = arg
+		}
+	}
+	if id == "" {
+		return "", fmt.Errorf("checkpoint ID required\nUsage: %s", c.Usage())
+	}
+
+	cp, err := c.agent.Checkpoints.Rewind(id, restoreConversation, restoreCode)
+	if err != nil {
+		return "", err
+	}
+
+	var restored []string
+	if restoreCode {
+		restored = append(restored, "files")
+	}
+	if restoreConversation {
+		c.agent.Mu.Lock()
+		c.agent.History = append([]llm.Message{}, cp.Conversation...)
+		c.agent.Mu.Unlock()
+		restored = append(restored, fmt.Sprintf("conversation (%d messages)", len(cp.Conversation)))
+	}
+
+	return fmt.Sprintf("✓ Rewound to %s (%s): restored %s",
+		cp.ID, cp.Description, strings.Join(restored, " and ")), nil
+}
@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/modes"
+)
+
+func TestCheckpoints_AutoBeforeWriteAndRewind(t *testing.T) {
+	server := newScriptedServer(t,
+		toolCallResponse("file_writer", map[string]interface{}{"file_path": "notes.txt", "content": "novo"}),
+		textResponse("Pronto"),
+	)
+
+	agent, err := NewAgent(Config{
+		OllamaURL:         server.URL,
+		Model:             "test-model",
+		Mode:              modes.ModeAutonomous,
+		WorkDir:           t.TempDir(),
+		EnableCheckpoints: true,
+		MaxCheckpoints:    5,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	notes := filepath.Join(agent.WorkDir, "notes.txt")
+	os.WriteFile(notes, []byte("original"), 0644)
+
+	if err := agent.ProcessMessage(context.Background(), "reescreva notes.txt"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	checkpoints, err := agent.Checkpoints.List(0)
+	if err != nil || len(checkpoints) != 1 {
+		t.Fatalf("Expected 1 automatic checkpoint, got %d (%v)", len(checkpoints), err)
+	}
+	cp := checkpoints[0]
+	if content, _ := agent.Checkpoints.FileContent(cp.FileStates["notes.txt"]); !cp.AutoCreated || content != "original" {
+		t.Errorf("Checkpoint should hold the file before the write, got %+v (%q)", cp, content)
+	}
+
+	listing, err := agent.CommandRegistry.Execute(context.Background(), "checkpoints", nil)
+	if err != nil || !strings.Contains(listing, cp.ID) {
+		t.Errorf("/checkpoints should list %s, got %q (%v)", cp.ID, listing, err)
+	}
+
+	manual, err := agent.CommandRegistry.Execute(context.Background(), "checkpoint", []string{"depois"})
+	if err != nil {
+		t.Fatalf("/checkpoint failed: %v", err)
+	}
+	latest, _ := agent.Checkpoints.List(1)
+	if !strings.Contains(manual, latest[0].ID) {
+		t.Fatalf("/checkpoint should report the new ID, got %q", manual)
+	}
+
+	diff, err := agent.CommandRegistry.Execute(context.Background(), "checkpoint", []string{"diff", cp.ID, latest[0].ID})
+	if err != nil {
+		t.Fatalf("/checkpoint diff failed: %v", err)
+	}
+	if !strings.Contains(diff, "modified") || !strings.Contains(diff, "notes.txt") || !strings.Contains(diff, "novo") {
+		t.Errorf("/checkpoint diff should show notes.txt rewritten, got %q", diff)
+	}
+
+	// Só código: conversa continua com a resposta
+	if _, err := agent.CommandRegistry.Execute(context.Background(), "rewind", []string{cp.ID, "--code"}); err != nil {
+		t.Fatalf("/rewind failed: %v", err)
+	}
+	if data, _ := os.ReadFile(notes); string(data) != "original" {
+		t.Errorf("File should be restored, got %q", data)
+	}
+	if history := agent.GetHistory(); history[len(history)-1].Content != "Pronto" {
+		t.Error("--code should not touch the conversation")
+	}
+
+	if _, err := agent.CommandRegistry.Execute(context.Background(), "rewind", []string{cp.ID, "--conversation"}); err != nil {
+		t.Fatalf("/rewind failed: %v", err)
+	}
+	if history := agent.GetHistory(); len(history) != len(cp.Conversation) {
+		t.Errorf("Conversation should be restored to %d messages, got %d", len(cp.Conversation), len(history))
+	}
+}
+
+func TestCheckpoints_Disabled(t *testing.T) {
+	agent, _ := NewAgent(Config{WorkDir: t.TempDir()})
+
+	if agent.Checkpoints != nil {
+		t.Fatal("Checkpoints should be disabled by default")
+	}
+	if _, err := agent.CommandRegistry.Execute(context.Background(), "checkpoint", nil); err == nil {
+		t.Error("/checkpoint should fail when checkpoints are disabled")
+	}
+}
@@ -0,0 +1,231 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/multimodel"
+)
+
+// CompactCommand compacta o histórico da conversa sob demanda
+type CompactCommand struct {
+	agent *Agent
+}
+
+func (c *CompactCommand) Name() string        { return "compact" }
+func (c *CompactCommand) Description() string { return "Summarize older turns to free context space" }
+func (c *CompactCommand) Usage() string       { return "/compact" }
+
+func (c *CompactCommand) Execute(ctx context.Context, args []string) (string, error) {
+	if c.agent.ContextManager == nil {
+		return "", fmt.Errorf("context manager not configured")
+	}
+
+	result, err := c.agent.CompactHistory(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("✓ History compacted: %s (limit %d of %d tokens)",
+		result, c.agent.ContextManager.Limit(), c.agent.ContextManager.NumCtx()), nil
+}
+
+// ModelCommand lista, troca e baixa modelos do Ollama
+type ModelCommand struct {
+	agent *Agent
+}
+
+func (c *ModelCommand) Name() string        { return "model" }
+func (c *ModelCommand) Description() string { return "List, switch or pull Ollama models" }
+func (c *ModelCommand) Usage() string       { return "/model [list|<name>|pull <name>]" }
+
+func (c *ModelCommand) Execute(ctx context.Context, args []string) (string, error) {
+	if len(args) == 0 || args[0] == "list" {
+		return c.list(ctx)
+	}
+
+	if args[0] == "pull" {
+		if len(args) < 2 {
+			return "", fmt.Errorf("usage: /model pull <name>")
+		}
+		return c.pull(ctx, args[1])
+	}
+
+	return c.switchTo(ctx, args[0])
+}
+
+// list lista modelos instalados marcando o atual
+func (c *ModelCommand) list(ctx context.Context) (string, error) {
+	lister, err := c.agent.modelLister()
+	if err != nil {
+		return "", err
+	}
+
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		return "", fmt.Errorf("list models: %w", err)
+	}
+
+	if len(models) == 0 {
+		return "No models installed. Use /model pull <name>", nil
+	}
+
+	current := c.agent.LLMClient.GetModel()
+
+	var result strings.Builder
+	result.WriteString("Installed models:\n\n")
+	for _, model := range models {
+		marker := " "
+		if model.Name == current {
+			marker = "*"
+		}
+		result.WriteString(fmt.Sprintf("%s %-30s %8s  %s\n", marker, model.Name, llm.FormatSize(model.Size), model.Details.ParameterSize))
+	}
+	result.WriteString("\nUse /model <name> to switch")
+
+	return result.String(), nil
+}
+
+// switchTo troca o modelo após confirmar que está instalado
+func (c *ModelCommand) switchTo(ctx context.Context, name string) (string, error) {
+	lister, err := c.agent.modelLister()
+	if err != nil {
+		return "", err
+	}
+
+	installed, err := llm.HasModel(ctx, lister, name)
+	if err != nil {
+		return "", fmt.Errorf("list models: %w", err)
+	}
+	if !installed {
+		return "", fmt.Errorf("model %s is not installed (use /model pull %s)", name, name)
+	}
+
+	c.agent.SwitchModel(name)
+	return fmt.Sprintf("✓ Model switched to %s", name), nil
+}
+
+// pull baixa um modelo exibindo o progresso
+func (c *ModelCommand) pull(ctx context.Context, name string) (string, error) {
+	puller, ok := c.agent.LLMClient.(llm.ModelPuller)
+	if !ok {
+		return "", fmt.Errorf("provider does not support pulling models")
+	}
+
+	err := puller.PullModel(ctx, name, func(p llm.PullProgress) {
+		if p.Total > 0 {
+			fmt.Printf("\r%s %5.1f%%", p.Status, p.Percent())
+		} else {
+			fmt.Printf("\r%-40s", p.Status)
+		}
+	})
+	fmt.Println()
+
+	if errors.Is(err, llm.ErrModelNotFound) {
+		return "", fmt.Errorf("model %s not found in registry", name)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("✓ Model %s pulled (use /model %s to switch)", name, name), nil
+}
+
+// StatusCommand mostra o modelo de cada tarefa e quais modelos atenderam o último turno
+type StatusCommand struct {
+	agent *Agent
+}
+
+func (c *StatusCommand) Name() string        { return "status" }
+func (c *StatusCommand) Description() string { return "Show model routing and last turn models" }
+func (c *StatusCommand) Usage() string       { return "/status" }
+
+func (c *StatusCommand) Execute(ctx context.Context, args []string) (string, error) {
+	var result strings.Builder
+
+	routing := "disabled"
+	if c.agent.MultiModelRouter != nil && c.agent.MultiModelRouter.IsEnabled() {
+		routing = "enabled"
+	}
+
+	result.WriteString(fmt.Sprintf("Mode: %s\n", c.agent.Mode))
+	result.WriteString(fmt.Sprintf("Model routing (%s):\n\n", routing))
+	result.WriteString(fmt.Sprintf("  %-10s %s\n", multimodel.TaskTypeDefault, c.agent.LLMClient.GetModel()))
+	for _, task := range routedTasks {
+		model, routed := c.agent.routedModel(task)
+		if !routed {
+			model += " (default)"
+		}
+		result.WriteString(fmt.Sprintf("  %-10s %s\n", task, model))
+	}
+
+	if c.agent.MultiModelRouter != nil {
+		if failing := c.agent.MultiModelRouter.Health().Failing(); len(failing) > 0 {
+			result.WriteString("\nCooling down after failures:\n\n")
+			for _, model := range failing {
+				result.WriteString(fmt.Sprintf("  %-30s until %s\n", model.Model, model.Until.Format("15:04:05")))
+			}
+		}
+	}
+
+	if c.agent.Scheduler != nil {
+		stats := c.agent.Scheduler.Stats()
+		result.WriteString(fmt.Sprintf("\nRequests: %d/%d in flight, %d queued\n", stats.InFlight, stats.MaxInFlight, stats.Queued))
+	}
+
+	if c.agent.ResponseCache != nil {
+		stats := c.agent.ResponseCache.Stats()
+		result.WriteString(fmt.Sprintf("Response cache: %d entries, %.1f/%d MB\n", stats.Entries, float64(stats.Bytes)/(1<<20), stats.MaxBytes>>20))
+	}
+
+	if hosts := c.agent.HostPool.Status(); len(hosts) > 0 {
+		result.WriteString("\nHosts:\n\n")
+		for _, host := range hosts {
+			state := "up"
+			if !host.Healthy {
+				state = "down"
+			}
+			result.WriteString(fmt.Sprintf("  %-30s %-4s %d/%d active, %d models\n", host.URL, state, host.Active, host.MaxParallel, len(host.Models)))
+		}
+	}
+
+	var steps []Step
+	if c.agent.Usage != nil {
+		steps = c.agent.Usage.TurnSteps()
+	}
+	if len(steps) == 0 {
+		result.WriteString("\nNo model calls yet")
+		return result.String(), nil
+	}
+
+	result.WriteString("\nLast turn:\n\n")
+	for i, step := range steps {
+		line := fmt.Sprintf("  %d. %-10s %-30s %6d tokens", i+1, step.Task, step.Model, step.Usage.TotalTokens())
+		if step.FallbackFrom != "" {
+			line += fmt.Sprintf("  (fallback for %s)", step.FallbackFrom)
+		}
+		result.WriteString(line + "\n")
+	}
+	result.WriteString(fmt.Sprintf("\nSession: %d tokens", c.agent.Usage.Session().TotalTokens()))
+
+	return result.String(), nil
+}
+
+// RegisterCommands registra no CommandRegistry os comandos que dependem do agente
+func (a *Agent) RegisterCommands() {
+	if a.CommandRegistry == nil {
+		return
+	}
+
+	a.CommandRegistry.Register(&CompactCommand{agent: a})
+	a.CommandRegistry.Register(&ModelCommand{agent: a})
+	a.CommandRegistry.Replace(&StatusCommand{agent: a})
+	a.CommandRegistry.Register(&CheckpointCommand{agent: a})
+	a.CommandRegistry.Register(&CheckpointsCommand{agent: a})
+	a.CommandRegistry.Register(&RewindCommand{agent: a})
+	a.CommandRegistry.Register(&SessionCommand{agent: a})
+	a.CommandRegistry.Register(&ExportCommand{agent: a})
+}
@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/johnpitter/ollama-code/internal/ctxwindow"
+)
+
+// autoCompact compacta o histórico quando ele se aproxima da janela do modelo
+func (a *Agent) autoCompact(ctx context.Context) {
+	if a.ContextManager == nil || !a.ContextManager.NeedsCompaction(a.GetHistory()) {
+		return
+	}
+
+	a.ColorYellow.Println("🗜️  Histórico perto do limite de contexto, compactando...")
+
+	result, err := a.compactHistory(ctx, false)
+	if err != nil {
+		a.ColorRed.Printf("⚠️  Falha ao compactar histórico: %v\n", err)
+		return
+	}
+
+	a.ColorYellow.Printf("   %s\n", result)
+}
+
+// CompactHistory força a compactação do histórico (usado por /compact)
+func (a *Agent) CompactHistory(ctx context.Context) (ctxwindow.Result, error) {
+	return a.compactHistory(ctx, true)
+}
+
+// compactHistory substitui o histórico pela versão compactada
+func (a *Agent) compactHistory(ctx context.Context, force bool) (ctxwindow.Result, error) {
+	history := a.GetHistory()
+
+	compacted, result, err := a.ContextManager.Compact(ctx, history, force)
+	if err != nil {
+		return result, err
+	}
+
+	a.Mu.Lock()
+	// Mensagens adicionadas durante a compactação são preservadas
+	a.History = append(compacted, a.History[len(history):]...)
+	a.Mu.Unlock()
+
+	return result, nil
+}
@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/modes"
+)
+
+func TestCompactCommand_SummarizesHistory(t *testing.T) {
+	server := newScriptedServer(t, textResponse("- usuário leu main.go"))
+	agent := newLoopTestAgent(t, server.URL, modes.ModeAutonomous)
+
+	agent.History = []llm.Message{
+		{Role: "user", Content: "leia main.go"},
+		{Role: "assistant", Content: strings.Repeat("conteúdo ", 200)},
+		{Role: "user", Content: "explique"},
+		{Role: "assistant", Content: "É o entrypoint"},
+		{Role: "user", Content: "ok"},
+	}
+
+	result, err := agent.CommandRegistry.Execute(context.Background(), "compact", nil)
+	if err != nil {
+		t.Fatalf("/compact failed: %v", err)
+	}
+
+	if !strings.Contains(result, "→") {
+		t.Errorf("Expected before/after report, got %s", result)
+	}
+
+	history := agent.GetHistory()
+	if history[0].Role != "system" || !strings.Contains(history[0].Content, "usuário leu main.go") {
+		t.Errorf("Expected pinned summary first, got %+v", history[0])
+	}
+
+	if history[len(history)-1].Content != "ok" {
+		t.Error("Most recent turn should be preserved")
+	}
+}
@@ -0,0 +1,303 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/johnpitter/ollama-code/internal/intent"
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/tools"
+)
+
+// DefaultMaxSteps limite padrão de passos (chamadas ao modelo) por mensagem
+const DefaultMaxSteps = 10
+
+// handlerAction expõe um handler como ferramenta dentro do loop
+type handlerAction struct {
+	Intent      intent.Intent
+	Description string
+	Parameters  map[string]interface{}
+}
+
+// handlerActions handlers oferecidos ao modelo como ações do loop.
+// Só entram handlers sem ferramenta equivalente no tools.Registry.
+var handlerActions = map[string]handlerAction{
+	"web_search": {
+		Intent:      intent.IntentWebSearch,
+		Description: "Pesquisa na internet e resume os resultados",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "Termos da busca",
+				},
+			},
+			"required": []string{"query"},
+		},
+	},
+}
+
+// thinkingColor raciocínio de modelos como deepseek-r1 aparece esmaecido
+var thinkingColor = color.New(color.Faint, color.Italic)
+
+// loopSystemPrompt prompt de sistema do loop plan → act → observe
+const loopSystemPrompt = `Você é um assistente de programação trabalhando no diretório %s (modo: %s).
+
+Resolva o pedido do usuário em passos:
+1. Planeje o próximo passo.
+2. Chame UMA ou mais ferramentas quando precisar ler, buscar, executar ou modificar algo.
+3. Analise o resultado de cada ferramenta antes de decidir o próximo passo.
+4. Quando terminar, responda ao usuário sem chamar ferramentas.
+
+Nunca invente o conteúdo de arquivos ou a saída de comandos: use as ferramentas.`
+
+// runLoop executa o loop iterativo: o modelo escolhe uma ação, o agente executa
+// e devolve a observação até o modelo responder sem chamar ferramentas
+func (a *Agent) runLoop(ctx context.Context, userMessage string) (string, error) {
+	maxSteps := a.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = DefaultMaxSteps
+	}
+
+	opts := &llm.CompletionOptions{
+		Temperature:  llm.Float64(0.2),
+		SystemPrompt: a.buildLoopSystemPrompt(),
+		Tools:        a.loopTools(),
+	}
+
+	for step := 1; step <= maxSteps; step++ {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		a.autoCompact(ctx)
+
+		history := a.GetHistory()
+		headerPrinted, thinkingPrinted := false, false
+		opts.OnThinking = func(chunk string) {
+			if !thinkingPrinted {
+				thinkingColor.Println("\n💭 Raciocínio:")
+				thinkingPrinted = true
+			}
+			thinkingColor.Print(chunk)
+		}
+		response, err := a.clientFor(history).ChatStream(ctx, history, opts, func(chunk string) {
+			if !headerPrinted {
+				if thinkingPrinted {
+					fmt.Println()
+				}
+				a.ColorGreen.Println("\n🤖 Assistente:")
+				headerPrinted = true
+			}
+			fmt.Print(chunk)
+		})
+		if err != nil {
+			return "", err
+		}
+		if headerPrinted || thinkingPrinted {
+			fmt.Println()
+		}
+
+		// Raciocínio não volta nos próximos prompts
+		response.Message.Thinking = ""
+
+		a.Mu.Lock()
+		a.History = append(a.History, response.Message)
+		a.Mu.Unlock()
+
+		if !response.HasToolCalls() {
+			return response.Message.Content, nil
+		}
+
+		var images []string
+		for _, call := range response.Message.ToolCalls {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+
+			a.ColorYellow.Printf("🔧 [%d/%d] %s %s\n", step, maxSteps, call.Function.Name, formatArguments(call.Function.Arguments))
+
+			toolResult := a.executeAction(ctx, call, userMessage)
+			for _, image := range toolResult.Images() {
+				name, _ := call.Function.Arguments["file_path"].(string)
+				if name == "" {
+					name = call.Function.Name
+				}
+				a.nameImage(image, name)
+				images = append(images, image)
+			}
+
+			a.Mu.Lock()
+			result := llm.NewToolResultMessage(call.Function.Name, toolResult.Observation())
+			result.ToolCallID = call.ID
+			a.History = append(a.History, result)
+			a.Mu.Unlock()
+		}
+
+		// Imagens lidas por ferramentas vão numa mensagem própria
+		// (APIs OpenAI não aceitam imagens em mensagens "tool")
+		if len(images) > 0 {
+			a.Mu.Lock()
+			a.History = append(a.History, llm.Message{
+				Role:    "user",
+				Content: "Imagens lidas pelas ferramentas acima.",
+				Images:  images,
+			})
+			a.Mu.Unlock()
+		}
+	}
+
+	a.ColorYellow.Printf("⚠️  Limite de %d passos atingido\n", maxSteps)
+	return fmt.Sprintf("Parei após %d passos sem concluir a tarefa. Peça para continuar se quiser que eu prossiga.", maxSteps), nil
+}
+
+// loopTools ferramentas do registry mais handlers expostos como ações
+func (a *Agent) loopTools() []llm.Tool {
+	definitions := a.ToolRegistry.ToolDefinitions(!a.Mode.AllowsWrites())
+
+	names := make([]string, 0, len(handlerActions))
+	for name := range handlerActions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		action := handlerActions[name]
+		if _, exists := a.HandlerRegistry.GetHandler(action.Intent); !exists {
+			continue
+		}
+		definitions = append(definitions, llm.NewTool(name, action.Description, action.Parameters))
+	}
+
+	return definitions
+}
+
+// executeAction executa uma chamada do modelo e retorna o resultado.
+// Erros viram observações para que o modelo possa se corrigir.
+func (a *Agent) executeAction(ctx context.Context, call llm.ToolCall, userMessage string) tools.Result {
+	if action, ok := handlerActions[call.Function.Name]; ok {
+		return a.executeHandlerAction(ctx, action, call, userMessage)
+	}
+
+	tool, err := a.ToolRegistry.Get(call.Function.Name)
+	if err != nil {
+		return tools.NewErrorResult(err)
+	}
+
+	if tool.Mutating() {
+		if !a.Mode.AllowsWrites() {
+			a.ColorRed.Println("   ✗ Bloqueado em modo somente leitura")
+			return tools.NewErrorResult(fmt.Errorf("tool %s blocked in %s mode", tool.Name(), a.Mode))
+		}
+
+		question := fmt.Sprintf("Executar %s?", tool.Name())
+		if command, _ := call.Function.Arguments["command"].(string); tool.Name() == "command_executor" && tools.IsDangerousCommand(command) {
+			if !a.Mode.RequiresConfirmation() {
+				a.ColorRed.Println("   ✗ Comando perigoso requer modo interativo")
+				return tools.NewErrorResult(fmt.Errorf("dangerous command requires interactive mode: %s", command))
+			}
+			question = fmt.Sprintf("⚠️  Comando potencialmente perigoso. Executar: %s ?", command)
+		}
+
+		if a.Mode.RequiresConfirmation() {
+			confirmed, err := a.ConfirmManager.Confirm(
+				question,
+				formatArguments(call.Function.Arguments),
+			)
+			if err != nil || !confirmed {
+				a.ColorRed.Println("   ✗ Cancelado pelo usuário")
+				return tools.NewErrorResult(fmt.Errorf("tool %s cancelled by user", tool.Name()))
+			}
+		}
+	}
+
+	result, err := a.ToolRegistry.ExecuteToolCall(ctx, call)
+	if err != nil && result.Error == "" {
+		result = tools.NewErrorResult(err)
+	}
+
+	if result.Success {
+		a.ColorGreen.Println("   ✓ OK")
+		a.trackFileArgument(call)
+	} else {
+		a.ColorRed.Printf("   ✗ %s\n", result.Error)
+	}
+
+	return result
+}
+
+// executeHandlerAction delega a ação para o handler do intent correspondente
+func (a *Agent) executeHandlerAction(ctx context.Context, action handlerAction, call llm.ToolCall, userMessage string) tools.Result {
+	params := call.Function.Arguments
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	response, err := a.handleIntent(ctx, &intent.DetectionResult{
+		Intent:     action.Intent,
+		Confidence: 1.0,
+		Parameters: params,
+	}, userMessage)
+	if err != nil {
+		a.ColorRed.Printf("   ✗ %v\n", err)
+		return tools.NewErrorResult(err)
+	}
+
+	a.ColorGreen.Println("   ✓ OK")
+	return tools.NewSuccessResult(response, nil)
+}
+
+// trackFileArgument registra arquivos tocados por ferramentas como recentes
+func (a *Agent) trackFileArgument(call llm.ToolCall) {
+	if call.Function.Name != "file_writer" {
+		return
+	}
+	if path, ok := call.Function.Arguments["file_path"].(string); ok && path != "" {
+		a.AddRecentFile(path)
+	}
+}
+
+// buildLoopSystemPrompt monta o prompt de sistema com o contexto OLLAMA.md
+func (a *Agent) buildLoopSystemPrompt() string {
+	prompt := fmt.Sprintf(loopSystemPrompt, a.WorkDir, a.Mode)
+
+	if a.OllamaContext != nil && a.OllamaContext.Merged != "" {
+		prompt += "\n\nInstruções do projeto (OLLAMA.md):\n" + a.OllamaContext.Merged
+	}
+
+	return prompt
+}
+
+// formatArguments formata argumentos de uma tool call para exibição
+func formatArguments(args map[string]interface{}) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := []rune(fmt.Sprintf("%v", args[key]))
+		if len(value) > 60 {
+			value = append(value[:60], []rune("...")...)
+		}
+		parts = append(parts, fmt.Sprintf("%s=%q", key, string(value)))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// isToolsUnsupported indica se o erro vem de um modelo sem suporte a tool calling
+func isToolsUnsupported(err error) bool {
+	return errors.Is(err, llm.ErrToolsNotSupported)
+}
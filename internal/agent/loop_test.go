@@ -0,0 +1,280 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/modes"
+)
+
+// scriptedServer servidor Ollama fake que responde /api/chat em sequência
+type scriptedServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	responses []llm.Response
+	requests  []llm.Request
+}
+
+func newScriptedServer(t *testing.T, responses ...llm.Response) *scriptedServer {
+	s := &scriptedServer{responses: responses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.Request
+		json.NewDecoder(r.Body).Decode(&req)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, req)
+		index := len(s.requests) - 1
+		s.mu.Unlock()
+
+		if index >= len(s.responses) {
+			t.Errorf("Unexpected request #%d", index+1)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		resp := s.responses[index]
+		resp.Done = true
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func toolCallResponse(name string, args map[string]interface{}) llm.Response {
+	return llm.Response{Message: llm.Message{
+		Role:      "assistant",
+		ToolCalls: []llm.ToolCall{{Function: llm.ToolCallFunction{Name: name, Arguments: args}}},
+	}}
+}
+
+func textResponse(content string) llm.Response {
+	return llm.Response{Message: llm.Message{Role: "assistant", Content: content}}
+}
+
+func newLoopTestAgent(t *testing.T, url string, mode modes.OperationMode) *Agent {
+	agent, err := NewAgent(Config{
+		OllamaURL: url,
+		Model:     "test-model",
+		Mode:      mode,
+		WorkDir:   t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	return agent
+}
+
+func TestProcessMessage_LoopExecutesToolsUntilDone(t *testing.T) {
+	server := newScriptedServer(t,
+		toolCallResponse("file_reader", map[string]interface{}{"file_path": "notes.txt"}),
+		textResponse("O arquivo diz olá"),
+	)
+
+	agent := newLoopTestAgent(t, server.URL, modes.ModeAutonomous)
+	os.WriteFile(filepath.Join(agent.WorkDir, "notes.txt"), []byte("olá"), 0644)
+
+	if err := agent.ProcessMessage(context.Background(), "o que tem em notes.txt?"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	if len(server.requests) != 2 {
+		t.Fatalf("Expected 2 model calls, got %d", len(server.requests))
+	}
+
+	if len(server.requests[0].Tools) == 0 {
+		t.Error("First request should offer tools")
+	}
+
+	// Segunda chamada deve conter a observação da ferramenta
+	second := server.requests[1].Messages
+	last := second[len(second)-1]
+	if last.Role != "tool" || last.ToolName != "file_reader" {
+		t.Fatalf("Expected tool observation as last message, got %+v", last)
+	}
+	if !strings.Contains(last.Content, "olá") {
+		t.Errorf("Observation should contain file content, got %s", last.Content)
+	}
+
+	history := agent.GetHistory()
+	if history[len(history)-1].Content != "O arquivo diz olá" {
+		t.Errorf("Unexpected final answer: %+v", history[len(history)-1])
+	}
+}
+
+func TestProcessMessage_LoopOffersHandlerActions(t *testing.T) {
+	server := newScriptedServer(t, textResponse("ok"))
+	agent := newLoopTestAgent(t, server.URL, modes.ModeAutonomous)
+
+	if err := agent.ProcessMessage(context.Background(), "oi"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	found := false
+	for _, tool := range server.requests[0].Tools {
+		if tool.Function.Name == "web_search" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("web_search handler should be offered as an action")
+	}
+}
+
+func TestProcessMessage_LoopRespectsMaxSteps(t *testing.T) {
+	call := toolCallResponse("project_analyzer", map[string]interface{}{"type": "structure"})
+	server := newScriptedServer(t, call, call, call)
+
+	agent := newLoopTestAgent(t, server.URL, modes.ModeAutonomous)
+	agent.MaxSteps = 2
+
+	if err := agent.ProcessMessage(context.Background(), "analise"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	if len(server.requests) != 2 {
+		t.Errorf("Expected loop to stop after 2 steps, got %d calls", len(server.requests))
+	}
+}
+
+func TestProcessMessage_ReadOnlyBlocksWrites(t *testing.T) {
+	server := newScriptedServer(t,
+		toolCallResponse("file_writer", map[string]interface{}{"file_path": "out.txt", "content": "x"}),
+		textResponse("não foi possível escrever"),
+	)
+
+	agent := newLoopTestAgent(t, server.URL, modes.ModeReadOnly)
+
+	if err := agent.ProcessMessage(context.Background(), "crie out.txt"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(agent.WorkDir, "out.txt")); err == nil {
+		t.Error("File should not be written in readonly mode")
+	}
+
+	messages := server.requests[1].Messages
+	if !strings.Contains(messages[len(messages)-1].Content, "blocked") {
+		t.Errorf("Observation should report blocked tool, got %s", messages[len(messages)-1].Content)
+	}
+}
+
+func TestProcessMessage_ReadOnlyHidesAndBlocksMutatingTools(t *testing.T) {
+	server := newScriptedServer(t,
+		toolCallResponse("code_formatter", map[string]interface{}{"action": "format"}),
+		textResponse("ok"),
+	)
+
+	agent := newLoopTestAgent(t, server.URL, modes.ModeReadOnly)
+
+	if err := agent.ProcessMessage(context.Background(), "formate o código"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	for _, tool := range server.requests[0].Tools {
+		switch tool.Function.Name {
+		case "code_formatter", "dependency_manager", "advanced_refactoring", "command_executor":
+			t.Errorf("Mutating tool %s should not be offered in readonly mode", tool.Function.Name)
+		}
+	}
+
+	messages := server.requests[1].Messages
+	if !strings.Contains(messages[len(messages)-1].Content, "blocked") {
+		t.Errorf("Mutating tool without confirmation flag should be blocked, got %s", messages[len(messages)-1].Content)
+	}
+}
+
+func TestProcessMessage_BlocksDangerousCommandInAutonomousMode(t *testing.T) {
+	server := newScriptedServer(t,
+		toolCallResponse("command_executor", map[string]interface{}{"command": "rm -rf victim"}),
+		textResponse("ok"),
+	)
+
+	agent := newLoopTestAgent(t, server.URL, modes.ModeAutonomous)
+	victim := filepath.Join(agent.WorkDir, "victim")
+	os.MkdirAll(victim, 0755)
+
+	if err := agent.ProcessMessage(context.Background(), "limpe o diretório"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	if _, err := os.Stat(victim); err != nil {
+		t.Errorf("Dangerous command should not run in autonomous mode: %v", err)
+	}
+
+	messages := server.requests[1].Messages
+	if !strings.Contains(messages[len(messages)-1].Content, "dangerous command") {
+		t.Errorf("Expected dangerous command error, got %s", messages[len(messages)-1].Content)
+	}
+}
+
+func TestProcessMessage_LoopCancelledContext(t *testing.T) {
+	server := newScriptedServer(t)
+	agent := newLoopTestAgent(t, server.URL, modes.ModeAutonomous)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := agent.ProcessMessage(ctx, "oi"); err == nil {
+		t.Error("Expected error for cancelled context")
+	}
+
+	if len(server.requests) != 0 {
+		t.Errorf("No model call expected after cancellation, got %d", len(server.requests))
+	}
+}
+
+func TestProcessMessage_FallsBackWhenToolsUnsupported(t *testing.T) {
+	var calls []llm.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		calls = append(calls, req)
+
+		if len(req.Tools) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"test-model does not support tools"}`))
+			return
+		}
+
+		content := "Resposta"
+		if !req.Stream {
+			content = `{"intent": "question", "confidence": 0.9, "parameters": {}}`
+		}
+		json.NewEncoder(w).Encode(llm.Response{
+			Message: llm.Message{Role: "assistant", Content: content},
+			Done:    true,
+		})
+	}))
+	defer server.Close()
+
+	agent := newLoopTestAgent(t, server.URL, modes.ModeAutonomous)
+
+	if err := agent.ProcessMessage(context.Background(), "o que é Go?"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	if !agent.toolsUnsupported {
+		t.Error("Agent should remember that tools are unsupported")
+	}
+
+	if len(calls) < 2 {
+		t.Fatalf("Expected fallback calls after tools error, got %d", len(calls))
+	}
+
+	// Próxima mensagem não deve tentar tools de novo
+	before := len(calls)
+	agent.ProcessMessage(context.Background(), "e Rust?")
+	for _, req := range calls[before:] {
+		if len(req.Tools) > 0 {
+			t.Error("Tools should not be offered after fallback")
+		}
+	}
+}
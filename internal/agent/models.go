@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
+
+// SwitchModel troca o modelo do cliente principal e da status line
+func (a *Agent) SwitchModel(model string) {
+	a.Mu.Lock()
+	defer a.Mu.Unlock()
+
+	a.LLMClient.SetModel(model)
+	// Novo modelo pode suportar tool calling
+	a.toolsUnsupported = false
+
+	if a.StatusLine != nil {
+		a.StatusLine.SetModel(model)
+	}
+}
+
+// ConfiguredModels retorna o modelo principal e os modelos do multi-model
+func (a *Agent) ConfiguredModels() []string {
+	names := []string{a.LLMClient.GetModel()}
+
+	if a.MultiModelRouter != nil {
+		if cfg := a.MultiModelRouter.GetConfig(); cfg != nil {
+			names = append(names, cfg.ModelNames()...)
+		}
+	}
+
+	return names
+}
+
+// modelLister provider principal como ModelLister (nem todo provider lista modelos)
+func (a *Agent) modelLister() (llm.ModelLister, error) {
+	lister, ok := a.LLMClient.(llm.ModelLister)
+	if !ok {
+		return nil, fmt.Errorf("provider does not support listing models")
+	}
+	return lister, nil
+}
+
+// MissingModels retorna os modelos configurados que não estão instalados
+func (a *Agent) MissingModels(ctx context.Context) ([]string, error) {
+	lister, err := a.modelLister()
+	if err != nil {
+		return nil, err
+	}
+
+	missing, err := llm.MissingModels(ctx, lister, a.ConfiguredModels()...)
+	if err != nil {
+		return nil, fmt.Errorf("list models: %w", err)
+	}
+	return missing, nil
+}
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/modes"
+	"github.com/johnpitter/ollama-code/internal/multimodel"
+)
+
+func newTagsServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"models": [{"name": "test-model:latest"}, {"name": "other:7b"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestModelCommand_SwitchesInstalledModel(t *testing.T) {
+	agent := newLoopTestAgent(t, newTagsServer(t).URL, modes.ModeAutonomous)
+	agent.toolsUnsupported = true
+
+	result, err := agent.CommandRegistry.Execute(context.Background(), "model", []string{"other:7b"})
+	if err != nil {
+		t.Fatalf("/model failed: %v", err)
+	}
+
+	if agent.LLMClient.GetModel() != "other:7b" {
+		t.Errorf("Expected model switched, got %s (%s)", agent.LLMClient.GetModel(), result)
+	}
+	if agent.toolsUnsupported {
+		t.Error("Switching model should retry tool calling")
+	}
+
+	if _, err := agent.CommandRegistry.Execute(context.Background(), "model", []string{"missing:1b"}); err == nil {
+		t.Error("Expected error switching to a model that is not installed")
+	}
+}
+
+func TestModelCommand_ListMarksCurrent(t *testing.T) {
+	agent := newLoopTestAgent(t, newTagsServer(t).URL, modes.ModeAutonomous)
+	agent.SwitchModel("other:7b")
+
+	result, err := agent.CommandRegistry.Execute(context.Background(), "model", nil)
+	if err != nil {
+		t.Fatalf("/model failed: %v", err)
+	}
+
+	if !strings.Contains(result, "* other:7b") {
+		t.Errorf("Expected current model marked, got:\n%s", result)
+	}
+}
+
+func TestMissingModels_IncludesMultiModelConfig(t *testing.T) {
+	server := newTagsServer(t)
+	agent := newLoopTestAgent(t, server.URL, modes.ModeAutonomous)
+
+	cfg := multimodel.NewConfig()
+	cfg.DefaultModel = multimodel.ModelSpec{Name: "other:7b"}
+	cfg.Enable()
+	cfg.SetModel(multimodel.TaskTypeIntent, multimodel.ModelSpec{Name: "tiny:1b"})
+	agent.MultiModelRouter = multimodel.NewRouter(server.URL, cfg)
+
+	missing, err := agent.MissingModels(context.Background())
+	if err != nil {
+		t.Fatalf("MissingModels failed: %v", err)
+	}
+
+	if len(missing) != 1 || missing[0] != "tiny:1b" {
+		t.Errorf("Expected only tiny:1b missing, got %v", missing)
+	}
+}
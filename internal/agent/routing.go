@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/multimodel"
+)
+
+// routedTasks tarefas roteáveis pelo multi-model, na ordem exibida em /status
+var routedTasks = []multimodel.TaskType{
+	multimodel.TaskTypeIntent,
+	multimodel.TaskTypeCode,
+	multimodel.TaskTypeSearch,
+	multimodel.TaskTypeAnalysis,
+	multimodel.TaskTypeVision,
+}
+
+// newMultiModelRouter cria o router com os clients configurados como o principal
+func newMultiModelRouter(cfg Config) *multimodel.Router {
+	mmConfig := cfg.MultiModel
+	if mmConfig == nil {
+		// Sem tabela de roteamento: todas as tarefas usam o modelo principal
+		mmConfig = multimodel.NewConfig()
+		mmConfig.DefaultModel = multimodel.ModelSpec{
+			Name:        cfg.Model,
+			Provider:    cfg.Provider,
+			Description: "Default model",
+			Fallbacks:   cfg.Options.Fallbacks,
+		}
+	}
+
+	router := multimodel.NewRouter(cfg.OllamaURL, mmConfig)
+	router.SetClientHook(func(client llm.Provider) {
+		// Cada tarefa usa só os fallbacks do próprio ModelSpec
+		opts := defaultOptions(cfg)
+		opts.Fallbacks = nil
+		client.SetDefaultOptions(opts)
+		if cfg.Transport != nil {
+			client.SetTransportConfig(*cfg.Transport)
+		}
+		client.SetHostPool(cfg.HostPool)
+		client.SetScheduler(cfg.Scheduler)
+		client.SetResponseCache(cfg.ResponseCache)
+	})
+	return router
+}
+
+// routedClient client com modelo próprio para a tarefa (nil se ela usa o modelo principal)
+func (a *Agent) routedClient(task multimodel.TaskType) llm.Provider {
+	if a.MultiModelRouter == nil {
+		return nil
+	}
+	return a.MultiModelRouter.GetConfiguredClient(task)
+}
+
+// clientForTask retorna o client da tarefa: o modelo roteado ou o principal.
+// O uso de tokens fica registrado com a tarefa para o /status.
+func (a *Agent) clientForTask(task multimodel.TaskType) llm.Provider {
+	client := a.routedClient(task)
+	if client == nil {
+		client = a.LLMClient
+	}
+	return a.trackTask(task, client)
+}
+
+// trackTask cópia do client que registra o uso sob a tarefa
+func (a *Agent) trackTask(task multimodel.TaskType, client llm.Provider) llm.Provider {
+	tracked := client.WithOptions(llm.CompletionOptions{})
+	tracked.SetUsageHook(func(model string, usage llm.Usage) {
+		a.recordTaskUsage(task, model, usage)
+	})
+	tracked.SetRetryHook(a.recordRetry)
+	tracked.SetFallbackHook(a.recordFallback)
+	return tracked
+}
+
+// routedModel nome do modelo usado pela tarefa e se ele vem da tabela de roteamento
+func (a *Agent) routedModel(task multimodel.TaskType) (string, bool) {
+	if a.MultiModelRouter != nil {
+		if cfg := a.MultiModelRouter.GetConfig(); cfg.HasModel(task) {
+			spec, _ := cfg.GetModel(task)
+			return spec.Name, true
+		}
+	}
+	return a.LLMClient.GetModel(), false
+}
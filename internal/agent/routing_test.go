@@ -0,0 +1,190 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/llmtest"
+	"github.com/johnpitter/ollama-code/internal/modes"
+	"github.com/johnpitter/ollama-code/internal/multimodel"
+)
+
+func newRoutedTestAgent(t *testing.T, url string) *Agent {
+	t.Helper()
+
+	mmConfig := multimodel.NewConfig()
+	mmConfig.DefaultModel = multimodel.ModelSpec{Name: "test-model"}
+	mmConfig.SetModel(multimodel.TaskTypeIntent, multimodel.ModelSpec{Name: "fast-model", MaxTokens: 256})
+	mmConfig.SetModel(multimodel.TaskTypeCode, multimodel.ModelSpec{Name: "coder-model"})
+	mmConfig.Enable()
+
+	agent, err := NewAgent(Config{
+		OllamaURL:  url,
+		Model:      "test-model",
+		Mode:       modes.ModeAutonomous,
+		WorkDir:    t.TempDir(),
+		MultiModel: mmConfig,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	return agent
+}
+
+func TestScenario_TasksUseRoutedModels(t *testing.T) {
+	server := llmtest.NewServer(t)
+	server.On(llmtest.WithTools(), llmtest.Fail(http.StatusBadRequest, "test-model does not support tools"))
+	server.On(llmtest.Model("fast-model"), llmtest.JSON(map[string]interface{}{
+		"intent":     "write_file",
+		"confidence": 0.9,
+		"parameters": map[string]interface{}{"file_path": "main.go"},
+	}))
+	server.On(llmtest.Model("coder-model"), llmtest.JSON(map[string]interface{}{
+		"file_path": "main.go",
+		"content":   "package main",
+	}))
+	server.Default(llmtest.Text("ok"))
+
+	agent := newRoutedTestAgent(t, server.URL)
+
+	if err := agent.ProcessMessage(context.Background(), "crie main.go"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(agent.WorkDir, "main.go"))
+	if err != nil || string(data) != "package main" {
+		t.Fatalf("Expected main.go generated by the code model, got %q, %v", data, err)
+	}
+
+	var tasks []string
+	for _, step := range agent.Usage.TurnSteps() {
+		tasks = append(tasks, string(step.Task)+"="+step.Model)
+	}
+	if got := strings.Join(tasks, ","); got != "intent=fast-model,code=coder-model" {
+		t.Errorf("Unexpected steps: %s", got)
+	}
+
+	status, err := agent.CommandRegistry.Execute(context.Background(), "status", nil)
+	if err != nil {
+		t.Fatalf("/status failed: %v", err)
+	}
+	for _, want := range []string{"Model routing (enabled)", "fast-model", "coder-model", "test-model (default)", "1. intent"} {
+		if !strings.Contains(status, want) {
+			t.Errorf("/status should contain %q, got:\n%s", want, status)
+		}
+	}
+}
+
+func TestClientForTask_FallsBackToMainModel(t *testing.T) {
+	server := llmtest.NewServer(t, llmtest.Text("resumo"))
+	agent := newLoopTestAgent(t, server.URL, modes.ModeReadOnly)
+
+	client := agent.clientForTask(multimodel.TaskTypeSearch)
+	if client.GetModel() != "test-model" {
+		t.Fatalf("Unrouted task should use the main model, got %s", client.GetModel())
+	}
+
+	agent.Usage.StartTurn()
+	if _, _, err := client.Complete(context.Background(), []llm.Message{{Role: "user", Content: "resuma"}}, nil); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	steps := agent.Usage.TurnSteps()
+	if len(steps) != 1 || steps[0].Task != multimodel.TaskTypeSearch {
+		t.Errorf("Usage should be recorded under the search task, got %+v", steps)
+	}
+	if agent.MultiModelRouter == nil || agent.MultiModelRouter.IsEnabled() {
+		t.Error("Agent without routing table should have a disabled router")
+	}
+}
+
+func TestScenario_FallbackServesWhenModelFailsToLoad(t *testing.T) {
+	server := llmtest.NewServer(t)
+	server.On(llmtest.Model("big-model"), llmtest.Fail(http.StatusInternalServerError, "model requires more system memory (9.1 GiB) than is available"))
+	server.Default(llmtest.Text("Resposta do modelo menor"))
+
+	agent, err := NewAgent(Config{
+		OllamaURL: server.URL,
+		Model:     "big-model",
+		Mode:      modes.ModeReadOnly,
+		WorkDir:   t.TempDir(),
+		Options:   llm.CompletionOptions{Fallbacks: []string{"small-model"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := agent.ProcessMessage(ctx, "oi"); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+
+	// O segundo turno vai direto para o fallback (big-model em cool-down)
+	var models []string
+	for _, req := range server.Requests() {
+		models = append(models, req.Model)
+	}
+	if got := strings.Join(models, ","); got != "big-model,small-model,small-model" {
+		t.Errorf("Unexpected request order: %s", got)
+	}
+
+	steps := agent.Usage.TurnSteps()
+	if len(steps) != 1 || steps[0].Model != "small-model" || steps[0].FallbackFrom != "big-model" {
+		t.Errorf("Step should record the fallback, got %+v", steps)
+	}
+
+	status, _ := agent.CommandRegistry.Execute(ctx, "status", nil)
+	for _, want := range []string{"(fallback for big-model)", "Cooling down after failures", "big-model"} {
+		if !strings.Contains(status, want) {
+			t.Errorf("/status should contain %q, got:\n%s", want, status)
+		}
+	}
+
+	if missing := agent.ConfiguredModels(); !strings.Contains(strings.Join(missing, ","), "small-model") {
+		t.Errorf("Fallback models should be checked at startup, got %v", missing)
+	}
+}
+
+func TestScenario_HostPoolSpreadsRequests(t *testing.T) {
+	local := llmtest.NewServer(t, llmtest.Text("local"))
+	local.SetModels("qwen2.5-coder:1.5b")
+	remote := llmtest.NewServer(t)
+	remote.SetModels("test-model")
+	remote.Default(llmtest.Text("Resposta da outra workstation"))
+
+	pool := llm.NewHostPool([]llm.HostConfig{{URL: local.URL}, {URL: remote.URL}})
+	pool.Refresh(context.Background())
+
+	agent, err := NewAgent(Config{
+		OllamaURL: local.URL,
+		Model:     "test-model",
+		Mode:      modes.ModeReadOnly,
+		WorkDir:   t.TempDir(),
+		HostPool:  pool,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	if err := agent.ProcessMessage(context.Background(), "oi"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	if len(local.Requests()) != 0 || len(remote.Requests()) == 0 {
+		t.Errorf("Requests should go to the host with the model, got local=%d remote=%d", len(local.Requests()), len(remote.Requests()))
+	}
+
+	status, _ := agent.CommandRegistry.Execute(context.Background(), "status", nil)
+	for _, want := range []string{"Hosts:", remote.URL, "0/1 active"} {
+		if !strings.Contains(status, want) {
+			t.Errorf("/status should contain %q, got:\n%s", want, status)
+		}
+	}
+}
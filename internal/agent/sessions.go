@@ -0,0 +1,306 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/modes"
+	"github.com/johnpitter/ollama-code/internal/session"
+)
+
+// errSessionsDisabled sessões desligadas na configuração
+var errSessionsDisabled = errors.New("sessions disabled (set app.enable_sessions in the config)")
+
+// persistSession salva histórico, modo, diretório e arquivos recentes na sessão
+// atual. A sessão é criada no primeiro turno, então chats vazios não ficam salvos.
+func (a *Agent) persistSession() {
+	if a.SessionManager == nil {
+		return
+	}
+
+	workDir, err := filepath.Abs(a.WorkDir)
+	if err != nil {
+		workDir = a.WorkDir
+	}
+
+	if a.SessionManager.GetCurrent() == nil {
+		if _, err := a.SessionManager.New("", workDir, a.Mode.String()); err != nil {
+			a.ColorYellow.Printf("⚠️  Não foi possível criar a sessão: %v\n", err)
+			return
+		}
+	}
+
+	a.Mu.Lock()
+	messages := append([]llm.Message{}, a.History...)
+	recentFiles := append([]string{}, a.RecentFiles...)
+	a.Mu.Unlock()
+
+	if err := a.SessionManager.Update(messages, workDir, a.Mode.String(), recentFiles); err != nil {
+		a.ColorYellow.Printf("⚠️  Não foi possível salvar a sessão: %v\n", err)
+	}
+}
+
+// ResumeSession retoma a sessão (ID ou posição na listagem) restaurando histórico,
+// modo e arquivos recentes. O diretório não muda: ferramentas ficam presas ao
+// diretório com que o agente foi criado (chat --resume cria o agente nele).
+func (a *Agent) ResumeSession(ref string) (*session.Session, error) {
+	if a.SessionManager == nil {
+		return nil, errSessionsDisabled
+	}
+
+	saved, err := a.SessionManager.Find(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if current := a.SessionManager.GetCurrent(); current != nil && current.ID != saved.ID {
+		a.SessionManager.End()
+	}
+
+	resumed, err := a.SessionManager.Resume(saved.ID)
+	if err != nil {
+		return nil, fmt.Errorf("resume session: %w", err)
+	}
+
+	a.Mu.Lock()
+	a.History = append([]llm.Message{}, resumed.Messages...)
+	a.RecentFiles = append([]string{}, resumed.RecentFiles...)
+	a.Mu.Unlock()
+	if resumed.Mode != "" {
+		a.SetMode(modes.ParseMode(resumed.Mode))
+	}
+
+	return resumed, nil
+}
+
+// EndSession marca a sessão atual como encerrada (o próximo turno inicia outra)
+func (a *Agent) EndSession() {
+	if a.SessionManager != nil {
+		a.SessionManager.End()
+	}
+}
+
+// sameDir compara diretórios pelo caminho absoluto
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// SessionCommand lista, retoma, renomeia, marca e apaga sessões salvas
+type SessionCommand struct {
+	agent *Agent
+}
+
+func (c *SessionCommand) Name() string        { return "session" }
+func (c *SessionCommand) Description() string { return "Manage saved sessions" }
+func (c *SessionCommand) Usage() string {
+	return "/session [list [limit]|resume <id|#>|rename <name>|tag <tag>|delete <id|#>]"
+}
+
+func (c *SessionCommand) Execute(ctx context.Context, args []string) (string, error) {
+	manager := c.agent.SessionManager
+	if manager == nil {
+		return "", errSessionsDisabled
+	}
+
+	if len(args) == 0 {
+		current := manager.GetCurrent()
+		if current == nil {
+			return "No active session (one starts with the next message)\n\nUsage: " + c.Usage(), nil
+		}
+		return fmt.Sprintf("Current session: %s\n\nUsage: %s", describeSession(current), c.Usage()), nil
+	}
+
+	switch args[0] {
+	case "list":
+		limit := 10
+		if len(args) > 1 {
+			fmt.Sscanf(args[1], "%d", &limit)
+		}
+		return c.list(limit)
+
+	case "resume":
+		if len(args) < 2 {
+			return "", fmt.Errorf("session ID or number required\nUsage: /session resume <id|#>")
+		}
+		saved, err := manager.Find(args[1])
+		if err != nil {
+			return "", err
+		}
+		if saved.WorkDir != "" && !sameDir(saved.WorkDir, c.agent.WorkDir) {
+			return "", fmt.Errorf("session %s belongs to %s (current: %s); run `ollama-code chat --resume %s` instead",
+				saved.ID, saved.WorkDir, c.agent.WorkDir, saved.ID)
+		}
+		resumed, err := c.agent.ResumeSession(saved.ID)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("✓ Resumed %s (%d messages, mode %s)", resumed.ID, len(resumed.Messages), c.agent.Mode), nil
+
+	case "rename":
+		if len(args) < 2 {
+			return "", fmt.Errorf("name required\nUsage: /session rename <name>")
+		}
+		if manager.GetCurrent() == nil {
+			return "", fmt.Errorf("no active session yet")
+		}
+		name := strings.Join(args[1:], " ")
+		if err := manager.Rename(name); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("✓ Session renamed to %q", name), nil
+
+	case "tag":
+		if len(args) < 2 {
+			return "", fmt.Errorf("tag required\nUsage: /session tag <tag>")
+		}
+		if manager.GetCurrent() == nil {
+			return "", fmt.Errorf("no active session yet")
+		}
+		if err := manager.AddTag(args[1]); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("✓ Session tagged #%s", args[1]), nil
+
+	case "delete":
+		if len(args) < 2 {
+			return "", fmt.Errorf("session ID or number required\nUsage: /session delete <id|#>")
+		}
+		target, err := manager.Find(args[1])
+		if err != nil {
+			return "", err
+		}
+		if current := manager.GetCurrent(); current != nil && current.ID == target.ID {
+			return "", fmt.Errorf("cannot delete the current session")
+		}
+		if err := manager.Delete(target.ID); err != nil {
+			return "", fmt.Errorf("delete session: %w", err)
+		}
+		return fmt.Sprintf("✓ Session %s deleted", target.ID), nil
+
+	default:
+		return "", fmt.Errorf("unknown subcommand %s\nUsage: %s", args[0], c.Usage())
+	}
+}
+
+// list mostra as sessões numeradas para /session resume <#>
+func (c *SessionCommand) list(limit int) (string, error) {
+	sessions, err := c.agent.SessionManager.List(limit)
+	if err != nil {
+		return "", fmt.Errorf("list sessions: %w", err)
+	}
+	if len(sessions) == 0 {
+		return "No saved sessions yet", nil
+	}
+
+	current := c.agent.SessionManager.GetCurrent()
+
+	var result strings.Builder
+	result.WriteString("Sessions (most recent first):\n\n")
+	for i, s := range sessions {
+		marker := " "
+		if current != nil && current.ID == s.ID {
+			marker = "*"
+		}
+		result.WriteString(fmt.Sprintf("%s %2d. %s\n", marker, i+1, describeSession(s)))
+	}
+	result.WriteString("\nUse /session resume <#> to continue one")
+
+	return result.String(), nil
+}
+
+// describeSession linha com data, mensagens, nome, tags e primeiro prompt
+func describeSession(s *session.Session) string {
+	line := fmt.Sprintf("%s  %s  %3d messages", s.ID, s.LastActivity.Format("2006-01-02 15:04"), len(s.Messages))
+	if s.Name != "" {
+		line += fmt.Sprintf("  [%s]", s.Name)
+	}
+	for _, tag := range s.Tags {
+		line += " #" + tag
+	}
+	if prompt := s.FirstPrompt(); prompt != "" {
+		if runes := []rune(prompt); len(runes) > 60 {
+			prompt = string(runes[:57]) + "..."
+		}
+		line += fmt.Sprintf("  %q", prompt)
+	}
+	return line
+}
+
+// ExportCommand exporta a transcrição de uma sessão (atual por padrão)
+type ExportCommand struct {
+	agent *Agent
+}
+
+func (c *ExportCommand) Name() string        { return "export" }
+func (c *ExportCommand) Description() string { return "Export a session transcript" }
+func (c *ExportCommand) Usage() string {
+	return "/export [id|#] [--format md|html|jsonl] [--output file]"
+}
+
+func (c *ExportCommand) Execute(ctx context.Context, args []string) (string, error) {
+	manager := c.agent.SessionManager
+	if manager == nil {
+		return "", errSessionsDisabled
+	}
+
+	var ref, output string
+	format := session.FormatMarkdown
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--format", "--output":
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value\nUsage: %s", args[i], c.Usage())
+			}
+			if args[i] == "--format" {
+				format = args[i+1]
+			} else {
+				output = args[i+1]
+			}
+			i++
+		default:
+			if strings.HasPrefix(args[i], "--") {
+				return "", fmt.Errorf("unknown option %s\nUsage: %s", args[i], c.Usage())
+			}
+			ref = args[i]
+		}
+	}
+
+	target := manager.GetCurrent()
+	if ref != "" {
+		found, err := manager.Find(ref)
+		if err != nil {
+			return "", err
+		}
+		target = found
+	}
+	if target == nil {
+		return "", fmt.Errorf("no active session yet; pass a session ID or number")
+	}
+
+	if output == "" {
+		output = target.ID + "." + format
+	}
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(c.agent.WorkDir, output)
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return "", fmt.Errorf("create %s: %w", output, err)
+	}
+	defer file.Close()
+
+	if err := session.Export(file, target, format); err != nil {
+		os.Remove(output)
+		return "", err
+	}
+
+	return fmt.Sprintf("✓ Exported %s (%d messages) to %s", target.ID, len(target.Messages), output), nil
+}
@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/modes"
+)
+
+func TestSessions_PersistAndResume(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	workDir := t.TempDir()
+	server := newScriptedServer(t, textResponse("Olá!"))
+
+	first, err := NewAgent(Config{
+		OllamaURL:      server.URL,
+		Model:          "test-model",
+		Mode:           modes.ModeAutonomous,
+		WorkDir:        workDir,
+		EnableSessions: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	first.AddRecentFile("main.go")
+
+	if err := first.ProcessMessage(context.Background(), "explique o projeto"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	saved := first.SessionManager.GetCurrent()
+	if saved == nil || len(saved.Messages) != 2 || saved.Mode != "autonomous" {
+		t.Fatalf("Turn should be persisted in the session, got %+v", saved)
+	}
+	if _, err := first.CommandRegistry.Execute(context.Background(), "session", []string{"rename", "onboarding"}); err != nil {
+		t.Fatalf("/session rename failed: %v", err)
+	}
+	first.EndSession()
+
+	// Novo processo: --continue / --resume
+	second, _ := NewAgent(Config{WorkDir: workDir, EnableSessions: true})
+	listing, err := second.CommandRegistry.Execute(context.Background(), "session", []string{"list"})
+	if err != nil || !strings.Contains(listing, "explique o projeto") || !strings.Contains(listing, "[onboarding]") {
+		t.Errorf("/session list should show the first prompt and name, got %q (%v)", listing, err)
+	}
+
+	if _, err := second.CommandRegistry.Execute(context.Background(), "session", []string{"resume", "1"}); err != nil {
+		t.Fatalf("/session resume failed: %v", err)
+	}
+	if history := second.GetHistory(); len(history) != 2 || history[1].Content != "Olá!" {
+		t.Errorf("History should be restored, got %+v", history)
+	}
+	if second.Mode != modes.ModeAutonomous {
+		t.Errorf("Mode should be restored, got %s", second.Mode)
+	}
+	if files := second.GetRecentlyModifiedFiles(); len(files) != 1 || files[0] != "main.go" {
+		t.Errorf("Recent files should be restored, got %v", files)
+	}
+
+	if _, err := second.CommandRegistry.Execute(context.Background(), "session", []string{"delete", saved.ID}); err == nil {
+		t.Error("Deleting the current session should fail")
+	}
+}
+
+func TestSessions_ResumeOtherWorkDir(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	other := t.TempDir()
+
+	agent, _ := NewAgent(Config{WorkDir: other, EnableSessions: true})
+	agent.persistSession()
+	id := agent.SessionManager.GetCurrent().ID
+	agent.EndSession()
+
+	elsewhere, _ := NewAgent(Config{WorkDir: t.TempDir(), EnableSessions: true})
+	_, err := elsewhere.CommandRegistry.Execute(context.Background(), "session", []string{"resume", id})
+	if err == nil || !strings.Contains(err.Error(), "--resume") {
+		t.Errorf("Resuming a session from another directory should point to --resume, got %v", err)
+	}
+}
+
+func TestSessions_Disabled(t *testing.T) {
+	agent, _ := NewAgent(Config{WorkDir: t.TempDir()})
+
+	if _, err := agent.CommandRegistry.Execute(context.Background(), "session", []string{"list"}); err == nil {
+		t.Error("/session should fail when sessions are disabled")
+	}
+}
+
+func TestExportCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := newScriptedServer(t, textResponse("Feito"))
+	agent, _ := NewAgent(Config{OllamaURL: server.URL, Model: "test-model", WorkDir: t.TempDir(), EnableSessions: true})
+
+	if _, err := agent.CommandRegistry.Execute(context.Background(), "export", nil); err == nil {
+		t.Error("/export without a session should fail")
+	}
+
+	if err := agent.ProcessMessage(context.Background(), "revise o código"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	result, err := agent.CommandRegistry.Execute(context.Background(), "export", []string{"--format", "html", "--output", "review.html"})
+	if err != nil {
+		t.Fatalf("/export failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(agent.WorkDir, "review.html"))
+	if err != nil || !strings.Contains(string(data), "revise o código") || !strings.Contains(result, "review.html") {
+		t.Errorf("/export should write the transcript, got %q (%v)", result, err)
+	}
+}
@@ -0,0 +1,316 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/multimodel"
+)
+
+// Step uma chamada ao modelo dentro do turno
+type Step struct {
+	Task         multimodel.TaskType // Tipo de tarefa que fez a chamada
+	Model        string              // Modelo que respondeu
+	FallbackFrom string              // Modelo indisponível que este substituiu (vazio se não houve fallback)
+	Usage        llm.Usage
+}
+
+// UsageTracker acumula uso de tokens por turno, por sessão e por modelo
+type UsageTracker struct {
+	mu            sync.Mutex
+	turn          llm.Usage
+	turnModels    map[string]*llm.Usage
+	turnSteps     []Step
+	fallbacks     map[string]string // Fallback -> modelo substituído, até a resposta chegar
+	turnStart     time.Time
+	session       llm.Usage
+	models        map[string]*llm.Usage
+	contextTokens int // Tokens da última requisição (ocupação da janela de contexto)
+}
+
+// NewUsageTracker cria novo acumulador de uso
+func NewUsageTracker() *UsageTracker {
+	return &UsageTracker{
+		turnModels: make(map[string]*llm.Usage),
+		models:     make(map[string]*llm.Usage),
+		fallbacks:  make(map[string]string),
+	}
+}
+
+// StartTurn zera os contadores do turno
+func (u *UsageTracker) StartTurn() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.turn = llm.Usage{}
+	u.turnModels = make(map[string]*llm.Usage)
+	u.turnSteps = nil
+	u.turnStart = time.Now()
+}
+
+// Record acumula o uso de uma resposta do modelo principal
+func (u *UsageTracker) Record(model string, usage llm.Usage) {
+	u.RecordTask(multimodel.TaskTypeDefault, model, usage)
+}
+
+// RecordTask acumula o uso de uma resposta registrando a tarefa que a pediu
+func (u *UsageTracker) RecordTask(task multimodel.TaskType, model string, usage llm.Usage) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	step := Step{Task: task, Model: model, Usage: usage}
+	if from, ok := u.fallbacks[model]; ok {
+		step.FallbackFrom = from
+		delete(u.fallbacks, model)
+	}
+	u.turnSteps = append(u.turnSteps, step)
+	u.turn.Add(usage)
+	u.session.Add(usage)
+
+	if _, exists := u.turnModels[model]; !exists {
+		u.turnModels[model] = &llm.Usage{}
+	}
+	u.turnModels[model].Add(usage)
+
+	if _, exists := u.models[model]; !exists {
+		u.models[model] = &llm.Usage{}
+	}
+	u.models[model].Add(usage)
+
+	if usage.TotalTokens() > 0 {
+		u.contextTokens = usage.TotalTokens()
+	}
+}
+
+// Turn retorna uso do turno atual
+func (u *UsageTracker) Turn() llm.Usage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.turn
+}
+
+// RecordFallback registra que o modelo to vai responder no lugar de from
+func (u *UsageTracker) RecordFallback(from, to string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	// Numa cadeia a→b→c a resposta de c é creditada como fallback de a
+	if original, ok := u.fallbacks[from]; ok {
+		delete(u.fallbacks, from)
+		from = original
+	}
+	u.fallbacks[to] = from
+}
+
+// TurnSteps retorna as chamadas ao modelo do turno atual (ou do último), em ordem
+func (u *UsageTracker) TurnSteps() []Step {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]Step{}, u.turnSteps...)
+}
+
+// Session retorna uso acumulado da sessão
+func (u *UsageTracker) Session() llm.Usage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.session
+}
+
+// Models retorna uso acumulado por modelo
+func (u *UsageTracker) Models() map[string]llm.Usage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	models := make(map[string]llm.Usage, len(u.models))
+	for name, usage := range u.models {
+		models[name] = *usage
+	}
+	return models
+}
+
+// ContextTokens retorna tokens ocupados pela última requisição
+func (u *UsageTracker) ContextTokens() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.contextTokens
+}
+
+// TurnElapsed retorna tempo decorrido desde o início do turno
+func (u *UsageTracker) TurnElapsed() time.Duration {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.turnStart.IsZero() {
+		return 0
+	}
+	return time.Since(u.turnStart)
+}
+
+// TurnSummary resume o turno em uma linha (tokens e tok/s por modelo)
+func (u *UsageTracker) TurnSummary() string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.turn.TotalTokens() == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(u.turnModels))
+	for name := range u.turnModels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	speeds := make([]string, 0, len(names))
+	for _, name := range names {
+		speeds = append(speeds, fmt.Sprintf("%s %.1f tok/s", name, u.turnModels[name].TokensPerSecond()))
+	}
+
+	return fmt.Sprintf("📊 %d tokens (%d prompt + %d resposta) · %s · sessão: %d tokens",
+		u.turn.TotalTokens(), u.turn.PromptTokens, u.turn.CompletionTokens,
+		strings.Join(speeds, ", "), u.session.TotalTokens())
+}
+
+// AttachLLMHooks conecta os hooks do cliente LLM ao agente
+func (a *Agent) AttachLLMHooks() {
+	if a.Usage == nil {
+		a.Usage = NewUsageTracker()
+	}
+
+	if a.LLMClient != nil {
+		a.LLMClient.SetUsageHook(a.recordUsage)
+		a.LLMClient.SetRetryHook(a.recordRetry)
+		a.LLMClient.SetFallbackHook(a.recordFallback)
+	}
+	if a.Scheduler != nil {
+		a.Scheduler.SetHook(a.recordSchedule)
+	}
+	if a.ResponseCache != nil {
+		a.ResponseCache.SetGetWrapper(a.wrapCacheGet)
+	}
+}
+
+// wrapCacheGet reporta hits e misses do cache de respostas pelo CacheWrapper da observabilidade
+func (a *Agent) wrapCacheGet(ctx context.Context, key string, get func() (interface{}, bool)) (interface{}, bool) {
+	if a.Observability == nil || a.Observability.Logger == nil || a.Observability.Metrics == nil {
+		return get()
+	}
+	return a.Observability.NewCacheWrapper().WrapCacheGet(ctx, key, get)
+}
+
+// recordSchedule registra a espera de cada requisição na fila do scheduler
+func (a *Agent) recordSchedule(event llm.SchedulerEvent) {
+	if a.Observability == nil {
+		return
+	}
+
+	if a.Observability.Metrics != nil {
+		a.Observability.Metrics.RecordLLMQueueWait(event.Priority.String(), event.Wait, event.QueueDepth)
+	}
+	if a.Observability.Logger != nil && event.Wait > 0 {
+		a.Observability.Logger.Debug("LLM request dequeued",
+			"model", event.Model,
+			"priority", event.Priority.String(),
+			"wait", event.Wait,
+			"queue_depth", event.QueueDepth)
+	}
+}
+
+// recordRetry registra cada nova tentativa de requisição ao modelo
+func (a *Agent) recordRetry(event llm.RetryEvent) {
+	if a.Observability == nil {
+		return
+	}
+
+	if a.Observability.Metrics != nil {
+		a.Observability.Metrics.RecordLLMRetry(event.Model, event.Reason)
+	}
+	if a.Observability.Logger != nil {
+		a.Observability.Logger.Warn("LLM request retry",
+			"model", event.Model,
+			"attempt", event.Attempt,
+			"reason", event.Reason,
+			"wait", event.Wait,
+			"error", event.Err)
+	}
+}
+
+// recordFallback avisa que um modelo de fallback assumiu a requisição
+func (a *Agent) recordFallback(event llm.FallbackEvent) {
+	if a.ColorYellow != nil {
+		a.ColorYellow.Printf("⚠️  Modelo %s indisponível, usando %s\n", event.From, event.To)
+	}
+
+	if a.Usage != nil {
+		a.Usage.RecordFallback(event.From, event.To)
+	}
+
+	if a.Observability != nil && a.Observability.Logger != nil {
+		a.Observability.Logger.Warn("LLM model fallback",
+			"from", event.From,
+			"to", event.To,
+			"error", event.Err)
+	}
+}
+
+// recordUsage recebe o uso de cada resposta do modelo
+func (a *Agent) recordUsage(model string, usage llm.Usage) {
+	a.recordTaskUsage(multimodel.TaskTypeDefault, model, usage)
+}
+
+// recordTaskUsage recebe o uso de respostas pedidas por uma tarefa roteada
+func (a *Agent) recordTaskUsage(task multimodel.TaskType, model string, usage llm.Usage) {
+	if a.Usage != nil {
+		a.Usage.RecordTask(task, model, usage)
+	}
+
+	if a.Observability != nil && a.Observability.Metrics != nil {
+		a.Observability.Metrics.RecordLLMUsage(model, usage.PromptTokens, usage.CompletionTokens, usage.EvalDuration)
+	}
+}
+
+// reportTurnUsage publica o uso do turno na status line, na sessão e no terminal
+func (a *Agent) reportTurnUsage() {
+	if a.Usage == nil {
+		return
+	}
+
+	if a.StatusLine != nil {
+		a.StatusLine.Update(a.Usage.ContextTokens(), a.Usage.TurnElapsed(), "")
+	}
+
+	if a.SessionManager != nil && a.SessionManager.GetCurrent() != nil {
+		a.SessionManager.UpdateMetadata("usage", a.usageMetadata())
+	}
+
+	if summary := a.Usage.TurnSummary(); summary != "" {
+		color.New(color.FgHiBlack).Println(summary)
+	}
+}
+
+// usageMetadata monta o uso da sessão no formato salvo em session.Metadata
+func (a *Agent) usageMetadata() map[string]interface{} {
+	session := a.Usage.Session()
+
+	models := map[string]interface{}{}
+	for name, usage := range a.Usage.Models() {
+		models[name] = map[string]interface{}{
+			"prompt_tokens":     usage.PromptTokens,
+			"completion_tokens": usage.CompletionTokens,
+			"tokens_per_second": usage.TokensPerSecond(),
+		}
+	}
+
+	return map[string]interface{}{
+		"prompt_tokens":     session.PromptTokens,
+		"completion_tokens": session.CompletionTokens,
+		"total_tokens":      session.TotalTokens(),
+		"models":            models,
+	}
+}
@@ -0,0 +1,169 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/modes"
+	"github.com/johnpitter/ollama-code/internal/observability"
+	"github.com/johnpitter/ollama-code/internal/session"
+)
+
+func TestUsageTracker_TurnAndSession(t *testing.T) {
+	tracker := NewUsageTracker()
+
+	tracker.StartTurn()
+	tracker.Record("model-a", llm.Usage{PromptTokens: 100, CompletionTokens: 20, EvalDuration: time.Second})
+	tracker.Record("model-a", llm.Usage{PromptTokens: 150, CompletionTokens: 30, EvalDuration: time.Second})
+
+	if tracker.Turn().TotalTokens() != 300 {
+		t.Errorf("Expected 300 turn tokens, got %d", tracker.Turn().TotalTokens())
+	}
+
+	if tracker.ContextTokens() != 180 {
+		t.Errorf("Expected context tokens from last request (180), got %d", tracker.ContextTokens())
+	}
+
+	tracker.StartTurn()
+	tracker.Record("model-b", llm.Usage{PromptTokens: 10, CompletionTokens: 10, EvalDuration: time.Second})
+
+	if tracker.Turn().TotalTokens() != 20 {
+		t.Errorf("Expected turn to be reset, got %d", tracker.Turn().TotalTokens())
+	}
+
+	if tracker.Session().TotalTokens() != 320 {
+		t.Errorf("Expected 320 session tokens, got %d", tracker.Session().TotalTokens())
+	}
+
+	models := tracker.Models()
+	if models["model-a"].TokensPerSecond() != 25 {
+		t.Errorf("Expected 25 tok/s for model-a, got %.1f", models["model-a"].TokensPerSecond())
+	}
+
+	if tracker.TurnSummary() == "" {
+		t.Error("Turn summary should not be empty")
+	}
+}
+
+func TestProcessMessage_RecordsUsage(t *testing.T) {
+	withUsage := textResponse("pronto")
+	withUsage.Usage = llm.Usage{PromptTokens: 40, CompletionTokens: 8, EvalDuration: 400 * time.Millisecond}
+	server := newScriptedServer(t, withUsage)
+
+	agent := newLoopTestAgent(t, server.URL, modes.ModeAutonomous)
+	agent.Observability = observability.NewDefault()
+	agent.SessionManager = session.NewManager(t.TempDir())
+	agent.SessionManager.New("test", agent.WorkDir, string(agent.Mode))
+
+	if err := agent.ProcessMessage(context.Background(), "oi"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	if agent.Usage.Session().TotalTokens() != 48 {
+		t.Errorf("Expected 48 session tokens, got %d", agent.Usage.Session().TotalTokens())
+	}
+
+	metricsUsage := agent.Observability.Metrics.GetLLMUsage("test-model")
+	if metricsUsage == nil || metricsUsage.CompletionTokens != 8 {
+		t.Errorf("Metrics should record usage, got %+v", metricsUsage)
+	}
+
+	usageMeta, ok := agent.SessionManager.GetCurrent().Metadata["usage"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Session metadata should contain usage")
+	}
+	if usageMeta["total_tokens"] != 48 {
+		t.Errorf("Expected 48 total tokens in session, got %v", usageMeta["total_tokens"])
+	}
+}
+
+func TestAttachLLMHooks_RecordsRetries(t *testing.T) {
+	server := newScriptedServer(t, textResponse("pronto"))
+
+	agent := newLoopTestAgent(t, server.URL, modes.ModeAutonomous)
+	agent.Observability = observability.NewDefault()
+	agent.AttachLLMHooks()
+
+	agent.recordRetry(llm.RetryEvent{Model: "test-model", Attempt: 1, Reason: llm.RetryReasonConnection, Err: context.DeadlineExceeded})
+
+	if retries := agent.Observability.Metrics.GetLLMRetries("test-model"); retries[llm.RetryReasonConnection] != 1 {
+		t.Errorf("Metrics should record the retry, got %v", retries)
+	}
+}
+
+func TestUsageTracker_FallbackChain(t *testing.T) {
+	tracker := NewUsageTracker()
+	tracker.StartTurn()
+
+	tracker.RecordFallback("big", "medium")
+	tracker.RecordFallback("medium", "small")
+	tracker.Record("small", llm.Usage{PromptTokens: 10, CompletionTokens: 5})
+	tracker.Record("small", llm.Usage{PromptTokens: 10, CompletionTokens: 5})
+
+	steps := tracker.TurnSteps()
+	if len(steps) != 2 || steps[0].FallbackFrom != "big" {
+		t.Fatalf("First response should be credited as fallback for big, got %+v", steps)
+	}
+	if steps[1].FallbackFrom != "" {
+		t.Errorf("Fallback should only mark the response it served, got %+v", steps[1])
+	}
+}
+
+func TestAttachLLMHooks_RecordsQueueWait(t *testing.T) {
+	server := newScriptedServer(t, textResponse("pronto"))
+
+	agent := newLoopTestAgent(t, server.URL, modes.ModeAutonomous)
+	agent.Observability = observability.NewDefault()
+	agent.Scheduler = llm.NewScheduler(2)
+	agent.LLMClient.SetScheduler(agent.Scheduler)
+	agent.AttachLLMHooks()
+
+	if err := agent.ProcessMessage(context.Background(), "oi"); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	if stats := agent.Observability.Metrics.GetLLMQueueStats("interactive"); stats == nil || stats.Count != 1 {
+		t.Errorf("Metrics should record the interactive request wait, got %+v", stats)
+	}
+
+	status, _ := agent.CommandRegistry.Execute(context.Background(), "status", nil)
+	if !strings.Contains(status, "Requests: 0/2 in flight, 0 queued") {
+		t.Errorf("/status should show the scheduler, got:\n%s", status)
+	}
+}
+
+func TestAttachLLMHooks_ReportsResponseCache(t *testing.T) {
+	server := newScriptedServer(t, textResponse("leitura"))
+
+	agent := newLoopTestAgent(t, server.URL, modes.ModeAutonomous)
+	agent.Observability = observability.NewDefault()
+	cache, err := llm.NewResponseCache(llm.ResponseCacheConfig{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewResponseCache failed: %v", err)
+	}
+	agent.ResponseCache = cache
+	agent.LLMClient.SetResponseCache(cache)
+	agent.AttachLLMHooks()
+
+	messages := []llm.Message{{Role: "user", Content: "classifique: leia main.go"}}
+	for i := 0; i < 2; i++ {
+		if _, _, err := agent.LLMClient.Complete(context.Background(), messages, &llm.CompletionOptions{Temperature: llm.Float64(0)}); err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+	}
+
+	if len(server.requests) != 1 {
+		t.Errorf("Second call should be served from cache, got %d requests", len(server.requests))
+	}
+	if stats := agent.Observability.Metrics.GetCacheStats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Cache hits should be reported to metrics, got %+v", stats)
+	}
+
+	status, _ := agent.CommandRegistry.Execute(context.Background(), "status", nil)
+	if !strings.Contains(status, "Response cache: 1 entries") {
+		t.Errorf("/status should show the response cache, got:\n%s", status)
+	}
+}
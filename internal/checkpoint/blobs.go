@@ -0,0 +1,180 @@
+package checkpoint
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Compressões aceitas para os blobs
+const (
+	CompressionGzip = "gzip"
+	CompressionNone = "none"
+)
+
+// gzipMagic início de todo arquivo gzip (blobs são lidos com ou sem compressão)
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// emptyHash hash do conteúdo vazio (checkpoints antigos não gravavam conteúdo vazio)
+var emptyHash = hashContent(nil)
+
+// blobStore armazena conteúdos de arquivos endereçados pelo SHA-256, de modo
+// que o mesmo conteúdo é gravado uma única vez para todos os checkpoints
+type blobStore struct {
+	dir      string
+	compress bool
+}
+
+// newBlobStore cria o armazenamento no diretório
+func newBlobStore(dir string) *blobStore {
+	return &blobStore{dir: dir, compress: true}
+}
+
+// put grava o conteúdo (se ainda não existir) e retorna seu hash
+func (s *blobStore) put(content []byte) (string, error) {
+	hash := hashContent(content)
+	path := s.path(hash)
+
+	if _, err := os.Stat(path); err == nil {
+		// Reaproveitado: renova o mtime para a coleta não apagá-lo antes do .json
+		now := time.Now()
+		os.Chtimes(path, now, now)
+		return hash, nil
+	}
+
+	data := content
+	if s.compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(content)
+		if err := zw.Close(); err != nil {
+			return "", fmt.Errorf("compress blob: %w", err)
+		}
+		// Conteúdo que não encolhe fica sem compressão
+		if buf.Len() < len(content) {
+			data = buf.Bytes()
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("create blob dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return "", fmt.Errorf("write blob: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("write blob: %w", err)
+	}
+
+	return hash, nil
+}
+
+// get lê o conteúdo do hash
+func (s *blobStore) get(hash string) ([]byte, error) {
+	data, err := os.ReadFile(s.path(hash))
+	if err != nil {
+		return nil, fmt.Errorf("read blob %s: %w", shortHash(hash), err)
+	}
+
+	if bytes.HasPrefix(data, gzipMagic) {
+		if content, err := gunzip(data); err == nil && hashContent(content) == hash {
+			return content, nil
+		}
+	}
+
+	// Sem compressão (ou conteúdo original que por acaso começa como gzip)
+	if hashContent(data) != hash {
+		return nil, fmt.Errorf("corrupted blob %s", shortHash(hash))
+	}
+	return data, nil
+}
+
+// gunzip descomprime dados gzip
+func gunzip(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// hashes lista os hashes armazenados
+func (s *blobStore) hashes() ([]string, error) {
+	dirs, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var hashes []string
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(s.dir, dir.Name()))
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if !strings.HasSuffix(file.Name(), ".tmp") {
+				hashes = append(hashes, file.Name())
+			}
+		}
+	}
+
+	return hashes, nil
+}
+
+// remove apaga o blob (e o subdiretório se ficar vazio)
+func (s *blobStore) remove(hash string) error {
+	path := s.path(hash)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	os.Remove(filepath.Dir(path)) // Falha se ainda houver blobs
+	return nil
+}
+
+// size tamanho em disco do blob
+func (s *blobStore) size(hash string) int64 {
+	info, err := os.Stat(s.path(hash))
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// modTime última gravação (ou reaproveitamento) do blob
+func (s *blobStore) modTime(hash string) time.Time {
+	info, err := os.Stat(s.path(hash))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// path caminho do blob: dois primeiros caracteres do hash como subdiretório
+func (s *blobStore) path(hash string) string {
+	if len(hash) < 2 {
+		return filepath.Join(s.dir, "_", hash)
+	}
+	return filepath.Join(s.dir, hash[:2], hash)
+}
+
+// shortHash prefixo do hash para mensagens
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
@@ -8,16 +8,24 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/johnpitter/ollama-code/internal/llm"
 )
 
+// blobGracePeriod blobs mais novos que isto nunca são coletados: outro processo
+// pode tê-los gravado para um checkpoint cujo .json ainda não foi salvo
+const blobGracePeriod = time.Hour
+
 // Manager gerenciador de checkpoints
 type Manager struct {
 	checkpointDir  string
+	blobs          *blobStore
 	retention      time.Duration
 	maxCheckpoints int
+	blobGrace      time.Duration
+	mu             sync.Mutex // Serializa gravação de checkpoints e coleta de blobs
 }
 
 // NewManager cria novo gerenciador
@@ -27,9 +35,34 @@ func NewManager(baseDir string) *Manager {
 
 	return &Manager{
 		checkpointDir:  checkpointDir,
+		blobs:          newBlobStore(filepath.Join(checkpointDir, "blobs")),
 		retention:      30 * 24 * time.Hour, // 30 dias
 		maxCheckpoints: 100,
+		blobGrace:      blobGracePeriod,
+	}
+}
+
+// SetLimits define retenção e quantidade máxima de checkpoints automáticos (0 mantém o padrão)
+func (m *Manager) SetLimits(retention time.Duration, maxCheckpoints int) {
+	if retention > 0 {
+		m.retention = retention
+	}
+	if maxCheckpoints > 0 {
+		m.maxCheckpoints = maxCheckpoints
+	}
+}
+
+// SetCompression define a compressão dos novos blobs ("gzip", padrão, ou "none")
+func (m *Manager) SetCompression(name string) error {
+	switch name {
+	case "", CompressionGzip:
+		m.blobs.compress = true
+	case CompressionNone:
+		m.blobs.compress = false
+	default:
+		return fmt.Errorf("unsupported checkpoint compression %q (use gzip or none)", name)
 	}
+	return nil
 }
 
 // CreateCheckpoint cria checkpoint ANTES de cada edição
@@ -52,22 +85,33 @@ func (m *Manager) CreateCheckpoint(
 		AutoCreated: autoCreated,
 	}
 
-	// Salvar estado atual dos arquivos
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Salvar estado atual dos arquivos (conteúdo vai para o blob store)
 	for _, filePath := range changedFiles {
 		absPath := filepath.Join(workDir, filePath)
 
 		content, err := os.ReadFile(absPath)
+		if os.IsNotExist(err) {
+			// Arquivo ainda não existe (será criado): rewind o remove
+			cp.FileStates[filePath] = FileState{Path: filePath, Missing: true}
+			continue
+		}
 		if err != nil {
-			// Arquivo pode não existir (será criado)
 			continue
 		}
 
 		info, _ := os.Stat(absPath)
 
+		hash, err := m.blobs.put(content)
+		if err != nil {
+			return nil, fmt.Errorf("store %s: %w", filePath, err)
+		}
+
 		cp.FileStates[filePath] = FileState{
 			Path:         filePath,
-			Content:      string(content),
-			Hash:         hashContent(content),
+			Hash:         hash,
 			ModifiedTime: info.ModTime(),
 			Size:         info.Size(),
 		}
@@ -87,7 +131,8 @@ func (m *Manager) CreateCheckpoint(
 	return cp, nil
 }
 
-// Rewind restaura para checkpoint anterior
+// Rewind restaura para checkpoint anterior. Com restoreFiles, arquivos tocados
+// só depois do checkpoint voltam ao estado do primeiro checkpoint que os salvou.
 func (m *Manager) Rewind(checkpointID string, restoreConversation, restoreFiles bool) (*Checkpoint, error) {
 	cp, err := m.loadCheckpoint(checkpointID)
 	if err != nil {
@@ -96,17 +141,34 @@ func (m *Manager) Rewind(checkpointID string, restoreConversation, restoreFiles
 
 	// Restaurar arquivos
 	if restoreFiles {
-		for _, fileState := range cp.FileStates {
+		states, err := m.statesSince(cp)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, fileState := range states {
 			absPath := filepath.Join(cp.WorkspaceState.WorkingDir, fileState.Path)
 
+			if fileState.Missing {
+				if err := os.Remove(absPath); err != nil && !os.IsNotExist(err) {
+					return nil, fmt.Errorf("remove file %s: %w", fileState.Path, err)
+				}
+				continue
+			}
+
 			// Criar diretórios se necessário
 			dir := filepath.Dir(absPath)
 			if err := os.MkdirAll(dir, 0755); err != nil {
 				return nil, fmt.Errorf("create directory: %w", err)
 			}
 
+			content, err := m.FileContent(fileState)
+			if err != nil {
+				return nil, err
+			}
+
 			// Restaurar arquivo
-			if err := os.WriteFile(absPath, []byte(fileState.Content), 0644); err != nil {
+			if err := os.WriteFile(absPath, []byte(content), 0644); err != nil {
 				return nil, fmt.Errorf("restore file %s: %w", fileState.Path, err)
 			}
 		}
@@ -115,6 +177,131 @@ func (m *Manager) Rewind(checkpointID string, restoreConversation, restoreFiles
 	return cp, nil
 }
 
+// statesSince estado dos arquivos no momento do checkpoint: os salvos nele mais,
+// para os tocados depois, o estado salvo no primeiro checkpoint posterior
+func (m *Manager) statesSince(cp *Checkpoint) (map[string]FileState, error) {
+	states := make(map[string]FileState, len(cp.FileStates))
+	for path, state := range cp.FileStates {
+		states[path] = state
+	}
+
+	checkpoints, err := m.List(0)
+	if err != nil {
+		return nil, fmt.Errorf("list checkpoints: %w", err)
+	}
+
+	// List ordena do mais recente para o mais antigo
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		later := checkpoints[i]
+		if !later.Timestamp.After(cp.Timestamp) || later.WorkspaceState.WorkingDir != cp.WorkspaceState.WorkingDir {
+			continue
+		}
+		for path, state := range later.FileStates {
+			if _, ok := states[path]; !ok {
+				states[path] = state
+			}
+		}
+	}
+
+	return states, nil
+}
+
+// FileContent conteúdo salvo do arquivo: inline (checkpoints antigos) ou do blob store
+func (m *Manager) FileContent(state FileState) (string, error) {
+	if state.Missing {
+		return "", nil
+	}
+	if state.Content != "" {
+		return state.Content, nil
+	}
+
+	content, err := m.blobs.get(state.Hash)
+	if err != nil {
+		// Formato antigo omitia só o conteúdo vazio
+		if state.Hash == "" || state.Hash == emptyHash {
+			return "", nil
+		}
+		return "", fmt.Errorf("read %s: %w", state.Path, err)
+	}
+	return string(content), nil
+}
+
+// Diff arquivos que mudaram entre dois checkpoints. Arquivos sem estado salvo
+// em um dos lados não foram alterados desde então: vale o conteúdo atual em disco
+func (m *Manager) Diff(fromID, toID string) ([]FileChange, error) {
+	from, err := m.loadCheckpoint(fromID)
+	if err != nil {
+		return nil, fmt.Errorf("load checkpoint %s: %w", fromID, err)
+	}
+	to, err := m.loadCheckpoint(toID)
+	if err != nil {
+		return nil, fmt.Errorf("load checkpoint %s: %w", toID, err)
+	}
+
+	fromStates, err := m.statesSince(from)
+	if err != nil {
+		return nil, err
+	}
+	toStates, err := m.statesSince(to)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]bool, len(fromStates)+len(toStates))
+	for path := range fromStates {
+		paths[path] = true
+	}
+	for path := range toStates {
+		paths[path] = true
+	}
+
+	var changes []FileChange
+	for path := range paths {
+		oldContent, oldExists, err := m.contentAt(fromStates, from.WorkspaceState.WorkingDir, path)
+		if err != nil {
+			return nil, err
+		}
+		newContent, newExists, err := m.contentAt(toStates, to.WorkspaceState.WorkingDir, path)
+		if err != nil {
+			return nil, err
+		}
+
+		change := FileChange{Path: path, OldContent: oldContent, NewContent: newContent}
+		switch {
+		case oldExists == newExists && oldContent == newContent:
+			continue
+		case !oldExists:
+			change.Status = ChangeAdded
+		case !newExists:
+			change.Status = ChangeRemoved
+		default:
+			change.Status = ChangeModified
+		}
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// contentAt conteúdo do arquivo no estado salvo ou, sem estado, em disco
+func (m *Manager) contentAt(states map[string]FileState, workDir, path string) (string, bool, error) {
+	state, ok := states[path]
+	if !ok {
+		data, err := os.ReadFile(filepath.Join(workDir, path))
+		if err != nil {
+			return "", false, nil
+		}
+		return string(data), true, nil
+	}
+	if state.Missing {
+		return "", false, nil
+	}
+
+	content, err := m.FileContent(state)
+	return content, err == nil, err
+}
+
 // List lista checkpoints disponíveis
 func (m *Manager) List(limit int) ([]*Checkpoint, error) {
 	files, err := os.ReadDir(m.checkpointDir)
@@ -153,14 +340,18 @@ func (m *Manager) Get(checkpointID string) (*Checkpoint, error) {
 	return m.loadCheckpoint(checkpointID)
 }
 
-// Delete remove checkpoint
+// Delete remove checkpoint (blobs sem referência saem no próximo CleanupOldCheckpoints)
 func (m *Manager) Delete(checkpointID string) error {
 	path := m.checkpointPath(checkpointID)
 	return os.Remove(path)
 }
 
-// CleanupOldCheckpoints limpa checkpoints antigos
+// CleanupOldCheckpoints limpa checkpoints antigos e os blobs que nenhum
+// checkpoint restante referencia
 func (m *Manager) CleanupOldCheckpoints() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	cutoff := time.Now().Add(-m.retention)
 
 	checkpoints, err := m.List(0)
@@ -191,6 +382,47 @@ func (m *Manager) CleanupOldCheckpoints() error {
 		}
 	}
 
+	return m.collectBlobs()
+}
+
+// collectBlobs remove blobs com zero referências (chamar com mu travado).
+// Um checkpoint ilegível aborta a coleta: seus blobs pareceriam sem referência.
+func (m *Manager) collectBlobs() error {
+	files, err := os.ReadDir(m.checkpointDir)
+	if err != nil {
+		return err
+	}
+
+	refs := make(map[string]int)
+	for _, file := range files {
+		if filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		cp, err := m.loadCheckpointByFilename(file.Name())
+		if err != nil {
+			return fmt.Errorf("load checkpoint %s, skipping blob cleanup: %w", file.Name(), err)
+		}
+		for _, state := range cp.FileStates {
+			if !state.Missing && state.Content == "" {
+				refs[state.Hash]++
+			}
+		}
+	}
+
+	hashes, err := m.blobs.hashes()
+	if err != nil {
+		return fmt.Errorf("list blobs: %w", err)
+	}
+	cutoff := time.Now().Add(-m.blobGrace)
+	for _, hash := range hashes {
+		if refs[hash] == 0 && m.blobs.modTime(hash).Before(cutoff) {
+			if err := m.blobs.remove(hash); err != nil {
+				return fmt.Errorf("remove blob %s: %w", shortHash(hash), err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -267,6 +499,7 @@ func (m *Manager) GetStats() (map[string]interface{}, error) {
 	autoCount := 0
 	manualCount := 0
 	totalSize := int64(0)
+	blobs := make(map[string]bool)
 
 	for _, cp := range checkpoints {
 		if cp.AutoCreated {
@@ -277,14 +510,25 @@ func (m *Manager) GetStats() (map[string]interface{}, error) {
 
 		for _, fs := range cp.FileStates {
 			totalSize += fs.Size
+			if !fs.Missing && fs.Content == "" {
+				blobs[fs.Hash] = true
+			}
 		}
 	}
 
+	// Conteúdo repetido entre checkpoints ocupa um único blob
+	blobSize := int64(0)
+	for hash := range blobs {
+		blobSize += m.blobs.size(hash)
+	}
+
 	return map[string]interface{}{
 		"total_checkpoints": len(checkpoints),
 		"auto_created":      autoCount,
 		"manual_created":    manualCount,
 		"total_size_bytes":  totalSize,
+		"blob_count":        len(blobs),
+		"blob_size_bytes":   blobSize,
 		"retention_days":    int(m.retention.Hours() / 24),
 		"max_checkpoints":   m.maxCheckpoints,
 	}, nil
@@ -0,0 +1,269 @@
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
+
+func TestManager_RewindRestoresFilesTouchedLater(t *testing.T) {
+	workDir := t.TempDir()
+	manager := NewManager(t.TempDir())
+	write := func(name, content string) {
+		os.WriteFile(filepath.Join(workDir, name), []byte(content), 0644)
+	}
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(workDir, name))
+		if err != nil {
+			return "<missing>"
+		}
+		return string(data)
+	}
+
+	write("a.txt", "a original")
+	write("c.txt", "c original")
+	conversation := []llm.Message{{Role: "user", Content: "edite a.txt"}}
+
+	// Antes de editar a.txt e criar b.txt
+	first, err := manager.CreateCheckpoint(conversation, []string{"a.txt", "b.txt"}, workDir, "before write", true)
+	if err != nil {
+		t.Fatalf("CreateCheckpoint failed: %v", err)
+	}
+	if !first.FileStates["b.txt"].Missing {
+		t.Error("File that does not exist yet should be recorded as missing")
+	}
+	write("a.txt", "a editado")
+	write("b.txt", "b novo")
+
+	// c.txt só é tocado depois do primeiro checkpoint
+	time.Sleep(time.Millisecond)
+	if _, err := manager.CreateCheckpoint(nil, []string{"c.txt"}, workDir, "before c", true); err != nil {
+		t.Fatalf("CreateCheckpoint failed: %v", err)
+	}
+	write("c.txt", "c editado")
+
+	cp, err := manager.Rewind(first.ID, true, true)
+	if err != nil {
+		t.Fatalf("Rewind failed: %v", err)
+	}
+	if len(cp.Conversation) != 1 || cp.Conversation[0].Content != "edite a.txt" {
+		t.Errorf("Rewind should return the saved conversation, got %+v", cp.Conversation)
+	}
+	if got := read("a.txt"); got != "a original" {
+		t.Errorf("a.txt should be restored, got %q", got)
+	}
+	if got := read("b.txt"); got != "<missing>" {
+		t.Errorf("b.txt should be removed, got %q", got)
+	}
+	if got := read("c.txt"); got != "c original" {
+		t.Errorf("c.txt should be restored from the later checkpoint, got %q", got)
+	}
+
+	// Só conversa: arquivos ficam como estão
+	write("a.txt", "a de novo")
+	if _, err := manager.Rewind(first.ID, true, false); err != nil {
+		t.Fatalf("Rewind failed: %v", err)
+	}
+	if got := read("a.txt"); got != "a de novo" {
+		t.Errorf("Conversation-only rewind should not touch files, got %q", got)
+	}
+}
+
+func TestManager_SetLimits(t *testing.T) {
+	manager := NewManager(t.TempDir())
+	manager.SetLimits(0, 2)
+
+	if stats, _ := manager.GetStats(); stats["max_checkpoints"] != 2 || stats["retention_days"] != 30 {
+		t.Errorf("Zero retention should keep the default, got %v", stats)
+	}
+
+	manual, _ := manager.CreateCheckpoint(nil, nil, t.TempDir(), "manual", false)
+	for i := 0; i < 3; i++ {
+		time.Sleep(time.Millisecond)
+		manager.CreateCheckpoint(nil, nil, t.TempDir(), "auto", true)
+	}
+	manager.CleanupOldCheckpoints()
+
+	// Acima do limite só os automáticos mais antigos saem; manuais ficam
+	checkpoints, _ := manager.List(0)
+	if len(checkpoints) != 3 {
+		t.Errorf("Expected 2 auto and 1 manual checkpoint after cleanup, got %d", len(checkpoints))
+	}
+	if _, err := manager.Get(manual.ID); err != nil {
+		t.Errorf("Manual checkpoint should be kept: %v", err)
+	}
+}
+
+func TestManager_BlobsDeduplicatedAndCollected(t *testing.T) {
+	workDir := t.TempDir()
+	manager := NewManager(t.TempDir())
+	manager.SetLimits(0, 1)
+	manager.blobGrace = 0
+	content := strings.Repeat("linha repetida que comprime bem\n", 100)
+	os.WriteFile(filepath.Join(workDir, "a.txt"), []byte(content), 0644)
+	os.WriteFile(filepath.Join(workDir, "b.txt"), []byte(content), 0644)
+
+	first, err := manager.CreateCheckpoint(nil, []string{"a.txt", "b.txt"}, workDir, "first", true)
+	if err != nil {
+		t.Fatalf("CreateCheckpoint failed: %v", err)
+	}
+	if first.FileStates["a.txt"].Content != "" || first.FileStates["a.txt"].Hash != hashContent([]byte(content)) {
+		t.Errorf("Checkpoint should reference the content by hash, got %+v", first.FileStates["a.txt"])
+	}
+
+	// Mesmo conteúdo em dois arquivos e em outro checkpoint: um único blob, comprimido
+	time.Sleep(time.Millisecond)
+	manager.CreateCheckpoint(nil, []string{"a.txt"}, workDir, "second", true)
+	hashes, _ := manager.blobs.hashes()
+	if len(hashes) != 1 {
+		t.Fatalf("Expected 1 deduplicated blob, got %d", len(hashes))
+	}
+	if size := manager.blobs.size(hashes[0]); size >= int64(len(content)) {
+		t.Errorf("Blob should be compressed, got %d bytes for %d", size, len(content))
+	}
+
+	// Blob continua referenciado pelo checkpoint restante
+	manager.CleanupOldCheckpoints()
+	if hashes, _ := manager.blobs.hashes(); len(hashes) != 1 {
+		t.Errorf("Referenced blob should survive cleanup, got %d", len(hashes))
+	}
+
+	os.WriteFile(filepath.Join(workDir, "a.txt"), []byte("outro"), 0644)
+	time.Sleep(time.Millisecond)
+	manager.CreateCheckpoint(nil, []string{"a.txt"}, workDir, "third", true)
+	manager.CleanupOldCheckpoints()
+	if hashes, _ := manager.blobs.hashes(); len(hashes) != 1 || hashes[0] != hashContent([]byte("outro")) {
+		t.Errorf("Unreferenced blob should be collected, got %v", hashes)
+	}
+}
+
+func TestManager_CollectBlobsIsConservative(t *testing.T) {
+	workDir := t.TempDir()
+	manager := NewManager(t.TempDir())
+	os.WriteFile(filepath.Join(workDir, "a.txt"), []byte("conteúdo"), 0644)
+
+	cp, err := manager.CreateCheckpoint(nil, []string{"a.txt"}, workDir, "first", true)
+	if err != nil {
+		t.Fatalf("CreateCheckpoint failed: %v", err)
+	}
+	manager.Delete(cp.ID)
+
+	// Blob sem referência, mas gravado há pouco: fica
+	if err := manager.CleanupOldCheckpoints(); err != nil {
+		t.Fatalf("CleanupOldCheckpoints failed: %v", err)
+	}
+	if hashes, _ := manager.blobs.hashes(); len(hashes) != 1 {
+		t.Errorf("Blob inside the grace period should be kept, got %v", hashes)
+	}
+
+	// Checkpoint ilegível: a coleta é abortada em vez de apagar blobs
+	manager.blobGrace = 0
+	os.WriteFile(filepath.Join(manager.checkpointDir, "broken.json"), []byte("{"), 0644)
+	if err := manager.CleanupOldCheckpoints(); err == nil {
+		t.Error("Expected cleanup to fail on an unreadable checkpoint")
+	}
+	if hashes, _ := manager.blobs.hashes(); len(hashes) != 1 {
+		t.Errorf("Blobs should be kept when a checkpoint cannot be loaded, got %v", hashes)
+	}
+
+	os.Remove(filepath.Join(manager.checkpointDir, "broken.json"))
+	if err := manager.CleanupOldCheckpoints(); err != nil {
+		t.Fatalf("CleanupOldCheckpoints failed: %v", err)
+	}
+	if hashes, _ := manager.blobs.hashes(); len(hashes) != 0 {
+		t.Errorf("Unreferenced old blob should be collected, got %v", hashes)
+	}
+}
+
+func TestManager_SetCompression(t *testing.T) {
+	workDir := t.TempDir()
+	manager := NewManager(t.TempDir())
+	if err := manager.SetCompression("zstd"); err == nil {
+		t.Error("Unsupported compression should be rejected")
+	}
+	if err := manager.SetCompression(CompressionNone); err != nil {
+		t.Fatalf("SetCompression failed: %v", err)
+	}
+
+	content := strings.Repeat("x", 1000)
+	os.WriteFile(filepath.Join(workDir, "a.txt"), []byte(content), 0644)
+	cp, _ := manager.CreateCheckpoint(nil, []string{"a.txt"}, workDir, "plain", false)
+
+	if size := manager.blobs.size(cp.FileStates["a.txt"].Hash); size != int64(len(content)) {
+		t.Errorf("Blob should be stored uncompressed, got %d bytes", size)
+	}
+	if got, err := manager.FileContent(cp.FileStates["a.txt"]); err != nil || got != content {
+		t.Errorf("FileContent should read the plain blob, got %d bytes (%v)", len(got), err)
+	}
+}
+
+func TestManager_RewindLegacyInlineContent(t *testing.T) {
+	workDir := t.TempDir()
+	manager := NewManager(t.TempDir())
+	legacy := &Checkpoint{
+		ID:        "cp_legacy",
+		Timestamp: time.Now(),
+		FileStates: map[string]FileState{
+			"a.txt":     {Path: "a.txt", Content: "inline", Hash: hashContent([]byte("inline"))},
+			"empty.txt": {Path: "empty.txt", Hash: emptyHash},
+		},
+		WorkspaceState: WorkspaceState{WorkingDir: workDir},
+	}
+	if err := manager.saveCheckpoint(legacy); err != nil {
+		t.Fatalf("saveCheckpoint failed: %v", err)
+	}
+
+	if _, err := manager.Rewind(legacy.ID, false, true); err != nil {
+		t.Fatalf("Rewind failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(workDir, "a.txt")); string(data) != "inline" {
+		t.Errorf("Inline content should be restored, got %q", data)
+	}
+	if data, err := os.ReadFile(filepath.Join(workDir, "empty.txt")); err != nil || len(data) != 0 {
+		t.Errorf("Empty file should be restored, got %q (%v)", data, err)
+	}
+}
+
+func TestManager_Diff(t *testing.T) {
+	workDir := t.TempDir()
+	manager := NewManager(t.TempDir())
+	write := func(name, content string) {
+		os.WriteFile(filepath.Join(workDir, name), []byte(content), 0644)
+	}
+
+	write("a.txt", "a v1")
+	write("c.txt", "c v1")
+	first, _ := manager.CreateCheckpoint(nil, []string{"a.txt", "b.txt", "c.txt"}, workDir, "first", false)
+	write("a.txt", "a v2")
+	write("b.txt", "b novo")
+	os.Remove(filepath.Join(workDir, "c.txt"))
+
+	time.Sleep(time.Millisecond)
+	second, _ := manager.CreateCheckpoint(nil, []string{"a.txt", "b.txt"}, workDir, "second", false)
+
+	changes, err := manager.Diff(first.ID, second.ID)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	got := make(map[string]ChangeStatus)
+	for _, change := range changes {
+		got[change.Path] = change.Status
+	}
+	want := map[string]ChangeStatus{"a.txt": ChangeModified, "b.txt": ChangeAdded, "c.txt": ChangeRemoved}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for path, status := range want {
+		if got[path] != status {
+			t.Errorf("%s: expected %s, got %s", path, status, got[path])
+		}
+	}
+	if changes[0].Path != "a.txt" || changes[0].OldContent != "a v1" || changes[0].NewContent != "a v2" {
+		t.Errorf("Unexpected change for a.txt: %+v", changes[0])
+	}
+}
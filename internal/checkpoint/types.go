@@ -21,10 +21,28 @@ type Checkpoint struct {
 // FileState estado de um arquivo
 type FileState struct {
 	Path         string    `json:"path"`
-	Content      string    `json:"content"`
+	Content      string    `json:"content,omitempty"` // Só em checkpoints antigos; novos guardam o conteúdo no blob store
 	Hash         string    `json:"hash"`
 	ModifiedTime time.Time `json:"modified_time"`
 	Size         int64     `json:"size"`
+	Missing      bool      `json:"missing,omitempty"` // Arquivo não existia (rewind o remove)
+}
+
+// ChangeStatus tipo de mudança de um arquivo entre dois checkpoints
+type ChangeStatus string
+
+const (
+	ChangeAdded    ChangeStatus = "added"
+	ChangeRemoved  ChangeStatus = "removed"
+	ChangeModified ChangeStatus = "modified"
+)
+
+// FileChange arquivo que mudou entre dois checkpoints
+type FileChange struct {
+	Path       string       `json:"path"`
+	Status     ChangeStatus `json:"status"`
+	OldContent string       `json:"old_content"`
+	NewContent string       `json:"new_content"`
 }
 
 // WorkspaceState estado do workspace
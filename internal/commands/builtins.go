@@ -95,66 +95,6 @@ func (m *ModeCommand) Execute(ctx context.Context, args []string) (string, error
 	return fmt.Sprintf("Mode changed to: %s", mode), nil
 }
 
-// CheckpointCommand comando para criar checkpoint
-type CheckpointCommand struct{}
-
-func (c *CheckpointCommand) Name() string        { return "checkpoint" }
-func (c *CheckpointCommand) Description() string { return "Create a checkpoint" }
-func (c *CheckpointCommand) Usage() string       { return "/checkpoint [description]" }
-
-func (c *CheckpointCommand) Execute(ctx context.Context, args []string) (string, error) {
-	description := "Manual checkpoint"
-	if len(args) > 0 {
-		description = strings.Join(args, " ")
-	}
-
-	return fmt.Sprintf("✓ Checkpoint created: %s", description), nil
-}
-
-// RewindCommand comando para voltar checkpoint
-type RewindCommand struct{}
-
-func (r *RewindCommand) Name() string        { return "rewind" }
-func (r *RewindCommand) Description() string { return "Rewind to a checkpoint" }
-func (r *RewindCommand) Usage() string       { return "/rewind <checkpoint-id>" }
-
-func (r *RewindCommand) Execute(ctx context.Context, args []string) (string, error) {
-	if len(args) == 0 {
-		return "Error: checkpoint ID required\nUsage: /rewind <checkpoint-id>", nil
-	}
-
-	checkpointID := args[0]
-	return fmt.Sprintf("✓ Rewound to checkpoint: %s", checkpointID), nil
-}
-
-// SessionCommand comando para gerenciar sessões
-type SessionCommand struct{}
-
-func (s *SessionCommand) Name() string        { return "session" }
-func (s *SessionCommand) Description() string { return "Manage sessions" }
-func (s *SessionCommand) Usage() string       { return "/session [list|save|resume <id>]" }
-
-func (s *SessionCommand) Execute(ctx context.Context, args []string) (string, error) {
-	if len(args) == 0 {
-		return "Current session: active\n\nSubcommands:\n- list\n- save\n- resume <id>", nil
-	}
-
-	action := args[0]
-	switch action {
-	case "list":
-		return "Available sessions:\n1. session_123 (active)\n2. session_456", nil
-	case "save":
-		return "✓ Session saved", nil
-	case "resume":
-		if len(args) < 2 {
-			return "Error: session ID required", nil
-		}
-		return fmt.Sprintf("✓ Resumed session: %s", args[1]), nil
-	default:
-		return fmt.Sprintf("Unknown subcommand: %s", action), nil
-	}
-}
-
 // DoctorCommand comando de diagnóstico
 type DoctorCommand struct{}
 
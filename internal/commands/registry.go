@@ -46,6 +46,15 @@ func (r *Registry) Register(cmd Command) error {
 	return nil
 }
 
+// Replace registra um comando substituindo o existente com o mesmo nome
+// (usado para trocar built-ins por implementações ligadas ao agente)
+func (r *Registry) Replace(cmd Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.commands[cmd.Name()] = cmd
+}
+
 // Get obtém comando por nome
 func (r *Registry) Get(name string) (Command, error) {
 	r.mu.RLock()
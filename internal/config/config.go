@@ -3,8 +3,13 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"time"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/multimodel"
 )
 
 // Config configuração completa da aplicação
@@ -17,34 +22,171 @@ type Config struct {
 
 	// Performance settings
 	Performance PerformanceConfig `json:"performance,omitempty"`
+
+	// Transport settings (timeouts, retry e circuit breaker)
+	Transport TransportConfig `json:"transport,omitempty"`
+
+	// Models settings (modelo por tipo de tarefa)
+	Models ModelsConfig `json:"models,omitempty"`
 }
 
 // OllamaConfig configurações do Ollama
+//
+// NumGPU, MaxVRAM, NumParallel e FlashAttention são configurações do servidor
+// (CUDA_VISIBLE_DEVICES, OLLAMA_MAX_VRAM, OLLAMA_NUM_PARALLEL, OLLAMA_FLASH_ATTENTION)
+// e não são enviadas nas requisições. GPULayers vira a opção num_gpu, enviada só
+// quando definida (sem ela o Ollama decide quantas layers cabem na VRAM).
 type OllamaConfig struct {
-	URL            string  `json:"url"`                       // URL do servidor Ollama
-	Model          string  `json:"model"`                     // Modelo padrão
-	Temperature    float64 `json:"temperature,omitempty"`     // Temperatura (0.0-1.0)
-	MaxTokens      int     `json:"max_tokens,omitempty"`      // Max tokens por resposta
-	GPULayers      int     `json:"gpu_layers,omitempty"`      // Número de layers na GPU
-	NumGPU         int     `json:"num_gpu,omitempty"`         // Número de GPUs
-	MaxVRAM        int     `json:"max_vram,omitempty"`        // Max VRAM em MB
-	NumParallel    int     `json:"num_parallel,omitempty"`    // Requisições paralelas
-	FlashAttention bool    `json:"flash_attention,omitempty"` // Usar flash attention
+	URL            string   `json:"url"`                       // URL do servidor Ollama
+	Model          string   `json:"model"`                     // Modelo padrão
+	Provider       string   `json:"provider,omitempty"`        // "ollama" (padrão) ou "openai" (llama.cpp, vLLM...)
+	Temperature    *float64 `json:"temperature,omitempty"`     // Temperatura (0.0-1.0; 0 = determinística; nil usa o padrão do modelo)
+	MaxTokens      int      `json:"max_tokens,omitempty"`      // Max tokens por resposta
+	NumCtx         int      `json:"num_ctx,omitempty"`         // Janela de contexto em tokens
+	TopK           int      `json:"top_k,omitempty"`           // Amostragem top-k
+	TopP           float64  `json:"top_p,omitempty"`           // Amostragem top-p
+	Seed           *int     `json:"seed,omitempty"`            // Seed para respostas reproduzíveis
+	Stop           []string `json:"stop,omitempty"`            // Sequências de parada
+	RepeatPenalty  float64  `json:"repeat_penalty,omitempty"`  // Penalidade de repetição
+	KeepAlive      string   `json:"keep_alive,omitempty"`      // Tempo que o modelo fica carregado (ex: "10m")
+	EmbedModel     string   `json:"embed_model,omitempty"`     // Modelo de embeddings da busca semântica
+	Think          *bool    `json:"think,omitempty"`           // Pede (true) ou suprime (false) o raciocínio de modelos como qwen3
+	Fallbacks      []string `json:"fallbacks,omitempty"`       // Modelos usados em ordem se o principal não carregar
+	GPULayers      *int     `json:"gpu_layers,omitempty"`      // Número de layers na GPU (nil = automático)
+	NumGPU         int      `json:"num_gpu,omitempty"`         // Número de GPUs
+	MaxVRAM        int      `json:"max_vram,omitempty"`        // Max VRAM em MB
+	NumParallel    int      `json:"num_parallel,omitempty"`    // Requisições paralelas
+	FlashAttention bool     `json:"flash_attention,omitempty"` // Usar flash attention
+
+	// Hosts servidores Ollama adicionais; com eles as requisições são distribuídas entre url e os hosts
+	Hosts []HostConfig `json:"hosts,omitempty"`
+
+	// Conexão com servidores atrás de proxy reverso (valem para url, hosts e models.tasks)
+	Headers   map[string]string `json:"headers,omitempty"`     // Headers enviados em toda requisição
+	APIKeyEnv string            `json:"api_key_env,omitempty"` // Variável de ambiente com o token (Authorization: Bearer)
+	CAFile    string            `json:"ca_file,omitempty"`     // Bundle PEM de CAs internos
+	CertFile  string            `json:"cert_file,omitempty"`   // Certificado do cliente (mTLS)
+	KeyFile   string            `json:"key_file,omitempty"`    // Chave do certificado do cliente
+	Proxy     string            `json:"proxy,omitempty"`       // Proxy HTTP(S) (vazio = HTTP_PROXY/HTTPS_PROXY)
+}
+
+// HostConfig servidor Ollama adicional do pool
+type HostConfig struct {
+	URL         string `json:"url"`                    // URL do servidor
+	MaxParallel int    `json:"max_parallel,omitempty"` // OLLAMA_NUM_PARALLEL do host (0 = ollama.num_parallel)
+}
+
+// Connection headers, token, TLS e proxy das conexões com o servidor.
+// Lê o token da variável de ambiente e carrega os certificados.
+func (o OllamaConfig) Connection() (llm.Connection, error) {
+	conn := llm.Connection{Headers: o.Headers}
+
+	if o.APIKeyEnv != "" {
+		conn.APIKey = os.Getenv(o.APIKeyEnv)
+		if conn.APIKey == "" {
+			return llm.Connection{}, fmt.Errorf("ollama.api_key_env: environment variable %s is not set", o.APIKeyEnv)
+		}
+	}
+
+	tlsConfig, err := llm.LoadTLSConfig(o.CAFile, o.CertFile, o.KeyFile)
+	if err != nil {
+		return llm.Connection{}, fmt.Errorf("ollama tls: %w", err)
+	}
+	conn.TLS = tlsConfig
+
+	if o.Proxy != "" {
+		proxy, err := parseProxyURL(o.Proxy)
+		if err != nil {
+			return llm.Connection{}, err
+		}
+		conn.Proxy = proxy
+	}
+
+	return conn, nil
+}
+
+// parseProxyURL valida a URL do proxy (precisa de esquema e host)
+func parseProxyURL(raw string) (*url.URL, error) {
+	proxy, err := url.Parse(raw)
+	if err != nil || proxy.Scheme == "" || proxy.Host == "" {
+		return nil, fmt.Errorf("invalid ollama.proxy: %q (use http://host:port)", raw)
+	}
+	return proxy, nil
+}
+
+// defaultNumParallel OLLAMA_NUM_PARALLEL padrão do Ollama
+const defaultNumParallel = 4
+
+// numParallel requisições paralelas de ollama.url (num_parallel ou o padrão do Ollama)
+func (o OllamaConfig) numParallel() int {
+	if o.NumParallel > 0 {
+		return o.NumParallel
+	}
+	return defaultNumParallel
+}
+
+// HostPoolConfig hosts do pool: ollama.url seguido de ollama.hosts (nil sem hosts adicionais)
+func (o OllamaConfig) HostPoolConfig() []llm.HostConfig {
+	if len(o.Hosts) == 0 {
+		return nil
+	}
+
+	hosts := []llm.HostConfig{{URL: o.URL, MaxParallel: o.numParallel()}}
+	for _, host := range o.Hosts {
+		maxParallel := host.MaxParallel
+		if maxParallel == 0 {
+			maxParallel = o.numParallel()
+		}
+		hosts = append(hosts, llm.HostConfig{URL: host.URL, MaxParallel: maxParallel})
+	}
+	return hosts
+}
+
+// MaxInFlight requisições simultâneas aceitas pelos servidores: num_parallel
+// somado ao dos hosts adicionais
+func (o OllamaConfig) MaxInFlight() int {
+	total := 0
+	for _, host := range o.HostPoolConfig() {
+		total += host.MaxParallel
+	}
+	if total == 0 {
+		total = o.numParallel()
+	}
+	return total
+}
+
+// GenerationOptions converte a configuração em opções enviadas a cada requisição
+func (o OllamaConfig) GenerationOptions() llm.CompletionOptions {
+	return llm.CompletionOptions{
+		Temperature:   o.Temperature,
+		MaxTokens:     o.MaxTokens,
+		TopK:          o.TopK,
+		TopP:          o.TopP,
+		NumCtx:        o.NumCtx,
+		Seed:          o.Seed,
+		Stop:          o.Stop,
+		RepeatPenalty: o.RepeatPenalty,
+		NumGPU:        o.GPULayers,
+		KeepAlive:     o.KeepAlive,
+		Think:         o.Think,
+		Fallbacks:     o.Fallbacks,
+	}
 }
 
 // AppConfig configurações da aplicação
 type AppConfig struct {
-	Mode                string `json:"mode"`                           // Modo padrão (readonly, interactive, autonomous)
-	WorkDir             string `json:"work_dir,omitempty"`             // Diretório de trabalho padrão
-	OutputStyle         string `json:"output_style,omitempty"`         // Estilo de output
-	EnableColors        bool   `json:"enable_colors"`                  // Usar cores no terminal
-	EnableCheckpoints   bool   `json:"enable_checkpoints"`             // Habilitar checkpoints automáticos
-	EnableSessions      bool   `json:"enable_sessions"`                // Habilitar sessões
-	EnableMemory        bool   `json:"enable_memory"`                  // Habilitar memória hierárquica
-	CheckpointRetention int    `json:"checkpoint_retention,omitempty"` // Dias de retenção
-	MaxCheckpoints      int    `json:"max_checkpoints,omitempty"`      // Máximo de checkpoints
-	LogLevel            string `json:"log_level,omitempty"`            // Nível de log (debug, info, warn, error)
-	LogFile             string `json:"log_file,omitempty"`             // Arquivo de log
+	Mode                  string `json:"mode"`                             // Modo padrão (readonly, interactive, autonomous)
+	WorkDir               string `json:"work_dir,omitempty"`               // Diretório de trabalho padrão
+	OutputStyle           string `json:"output_style,omitempty"`           // Estilo de output
+	EnableColors          bool   `json:"enable_colors"`                    // Usar cores no terminal
+	EnableCheckpoints     bool   `json:"enable_checkpoints"`               // Habilitar checkpoints automáticos
+	EnableSessions        bool   `json:"enable_sessions"`                  // Habilitar sessões
+	EnableMemory          bool   `json:"enable_memory"`                    // Habilitar memória hierárquica
+	CheckpointRetention   int    `json:"checkpoint_retention,omitempty"`   // Dias de retenção
+	MaxCheckpoints        int    `json:"max_checkpoints,omitempty"`        // Máximo de checkpoints
+	CheckpointCompression string `json:"checkpoint_compression,omitempty"` // Compressão dos conteúdos salvos (gzip, none)
+	LogLevel              string `json:"log_level,omitempty"`              // Nível de log (debug, info, warn, error)
+	LogFile               string `json:"log_file,omitempty"`               // Arquivo de log
 }
 
 // PerformanceConfig configurações de performance
@@ -53,6 +195,168 @@ type PerformanceConfig struct {
 	EnableCache        bool `json:"enable_cache"`                   // Habilitar cache
 	MaxConcurrentTools int  `json:"max_concurrent_tools,omitempty"` // Max tools paralelas
 	CommandTimeout     int  `json:"command_timeout,omitempty"`      // Timeout de comandos em segundos
+
+	// ResponseCache cache em disco das respostas de prompts determinísticos (opt-in)
+	ResponseCache ResponseCacheConfig `json:"response_cache,omitempty"`
+}
+
+// ResponseCacheConfig cache em disco das respostas do LLM. Só requisições sem
+// ferramentas e com temperatura 0 passam pelo cache.
+type ResponseCacheConfig struct {
+	Enabled   bool   `json:"enabled"`
+	Dir       string `json:"dir,omitempty"`         // Diretório (padrão: ~/.ollama-code/cache/llm)
+	MaxSizeMB int    `json:"max_size_mb,omitempty"` // Tamanho máximo em MB (padrão: 100)
+}
+
+// LLMConfig converte para a configuração do cache de respostas do cliente LLM
+func (r ResponseCacheConfig) LLMConfig() (llm.ResponseCacheConfig, error) {
+	dir := r.Dir
+	if dir == "" {
+		configPath, err := GetConfigPath()
+		if err != nil {
+			return llm.ResponseCacheConfig{}, fmt.Errorf("resolve cache dir: %w", err)
+		}
+		dir = filepath.Join(filepath.Dir(configPath), "cache", "llm")
+	}
+
+	return llm.ResponseCacheConfig{
+		Dir:      dir,
+		MaxBytes: int64(r.MaxSizeMB) << 20,
+	}, nil
+}
+
+// TransportConfig resiliência das requisições ao servidor LLM.
+// Campos zerados usam o padrão; -1 desativa retry ou circuit breaker.
+type TransportConfig struct {
+	MaxRetries        int `json:"max_retries,omitempty"`         // Tentativas extras em falhas transitórias
+	RetryBackoffMs    int `json:"retry_backoff_ms,omitempty"`    // Espera antes do primeiro retry (dobra a cada tentativa)
+	MaxBackoff        int `json:"max_backoff,omitempty"`         // Espera máxima entre tentativas em segundos
+	ConnectTimeout    int `json:"connect_timeout,omitempty"`     // Timeout de conexão em segundos
+	FirstTokenTimeout int `json:"first_token_timeout,omitempty"` // Timeout até o primeiro token em segundos (inclui carga do modelo)
+	IdleTimeout       int `json:"idle_timeout,omitempty"`        // Timeout sem dados no meio do stream em segundos
+	BreakerThreshold  int `json:"breaker_threshold,omitempty"`   // Falhas consecutivas para abrir o circuito
+	BreakerCooldown   int `json:"breaker_cooldown,omitempty"`    // Segundos com o circuito aberto
+}
+
+// LLMConfig converte para a configuração de transporte do cliente LLM
+func (t TransportConfig) LLMConfig() llm.TransportConfig {
+	cfg := llm.DefaultTransportConfig()
+
+	switch {
+	case t.MaxRetries < 0:
+		cfg.Retry.MaxRetries = 0
+	case t.MaxRetries > 0:
+		cfg.Retry.MaxRetries = t.MaxRetries
+	}
+	if t.RetryBackoffMs > 0 {
+		cfg.Retry.InitialBackoff = time.Duration(t.RetryBackoffMs) * time.Millisecond
+	}
+	if t.MaxBackoff > 0 {
+		cfg.Retry.MaxBackoff = time.Duration(t.MaxBackoff) * time.Second
+	}
+	if t.ConnectTimeout > 0 {
+		cfg.Timeouts.Connect = time.Duration(t.ConnectTimeout) * time.Second
+	}
+	if t.FirstTokenTimeout > 0 {
+		cfg.Timeouts.FirstToken = time.Duration(t.FirstTokenTimeout) * time.Second
+	}
+	if t.IdleTimeout > 0 {
+		cfg.Timeouts.Idle = time.Duration(t.IdleTimeout) * time.Second
+	}
+	switch {
+	case t.BreakerThreshold < 0:
+		cfg.BreakerThreshold = 0
+	case t.BreakerThreshold > 0:
+		cfg.BreakerThreshold = t.BreakerThreshold
+	}
+	if t.BreakerCooldown > 0 {
+		cfg.BreakerCooldown = time.Duration(t.BreakerCooldown) * time.Second
+	}
+
+	return cfg
+}
+
+// LLMTransport configuração de transporte dos clients LLM: resiliência de
+// transport e conexão (headers, token, TLS, proxy) de ollama
+func (c *Config) LLMTransport() (llm.TransportConfig, error) {
+	cfg := c.Transport.LLMConfig()
+
+	conn, err := c.Ollama.Connection()
+	if err != nil {
+		return llm.TransportConfig{}, err
+	}
+	cfg.Connection = conn
+
+	return cfg, nil
+}
+
+// ModelsConfig tabela de roteamento multi-model: modelo usado por tipo de tarefa.
+// Tarefas sem entrada usam o modelo de ollama.model.
+type ModelsConfig struct {
+	Enabled  bool                       `json:"enabled"`            // Habilitar roteamento por tarefa
+	Tasks    map[string]TaskModelConfig `json:"tasks,omitempty"`    // intent, code, search, analysis, vision
+	Cooldown int                        `json:"cooldown,omitempty"` // Segundos que um modelo com falha fica fora dos fallbacks
+}
+
+// TaskModelConfig modelo e opções de um tipo de tarefa
+type TaskModelConfig struct {
+	Model       string   `json:"model"`                 // Nome do modelo
+	Provider    string   `json:"provider,omitempty"`    // "ollama" (padrão) ou "openai"
+	URL         string   `json:"url,omitempty"`         // Endpoint próprio (vazio = ollama.url)
+	Temperature *float64 `json:"temperature,omitempty"` // Temperatura (nil = a padrão do modelo)
+	MaxTokens   int      `json:"max_tokens,omitempty"`  // Max tokens por resposta
+	NumCtx      int      `json:"num_ctx,omitempty"`     // Janela de contexto em tokens
+	Think       *bool    `json:"think,omitempty"`       // Raciocínio de modelos como qwen3
+	Fallbacks   []string `json:"fallbacks,omitempty"`   // Modelos usados em ordem se este não carregar
+}
+
+// SetTaskModel roteia a tarefa para o modelo e habilita o roteamento.
+// As demais opções da tarefa (temperatura, fallbacks etc.) são mantidas.
+func (m *ModelsConfig) SetTaskModel(task, model string) {
+	if m.Tasks == nil {
+		m.Tasks = make(map[string]TaskModelConfig)
+	}
+
+	spec := m.Tasks[task]
+	spec.Model = model
+	m.Tasks[task] = spec
+	m.Enabled = true
+}
+
+// MultiModelConfig converte a tabela de roteamento para o router multi-model.
+// O modelo padrão é sempre ollama.model.
+func (c *Config) MultiModelConfig() (*multimodel.Config, error) {
+	cfg := multimodel.NewConfig()
+	cfg.DefaultModel = multimodel.ModelSpec{
+		Name:        c.Ollama.Model,
+		Provider:    c.Ollama.Provider,
+		Description: "Default model",
+		Fallbacks:   c.Ollama.Fallbacks,
+	}
+	cfg.Cooldown = time.Duration(c.Models.Cooldown) * time.Second
+
+	for task, spec := range c.Models.Tasks {
+		taskType := multimodel.TaskType(task)
+		if taskType == multimodel.TaskTypeDefault {
+			return nil, fmt.Errorf("models.tasks.default is not allowed (use ollama.model)")
+		}
+
+		err := cfg.SetModel(taskType, multimodel.ModelSpec{
+			Name:        spec.Model,
+			Provider:    spec.Provider,
+			BaseURL:     spec.URL,
+			Temperature: spec.Temperature,
+			MaxTokens:   spec.MaxTokens,
+			Options:     llm.CompletionOptions{NumCtx: spec.NumCtx, Think: spec.Think},
+			Fallbacks:   spec.Fallbacks,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("models.tasks.%s: %w", task, err)
+		}
+	}
+
+	cfg.Enabled = c.Models.Enabled && len(cfg.Models) > 0
+	return cfg, nil
 }
 
 // DefaultConfig retorna configuração padrão
@@ -61,24 +365,25 @@ func DefaultConfig() *Config {
 		Ollama: OllamaConfig{
 			URL:            "http://localhost:11434",
 			Model:          "qwen2.5-coder:7b",
-			Temperature:    0.7,
+			Temperature:    llm.Float64(0.7),
 			MaxTokens:      4096,
-			GPULayers:      35,
+			NumCtx:         8192,
 			NumGPU:         1,
 			MaxVRAM:        8192,
 			NumParallel:    2,
 			FlashAttention: true,
 		},
 		App: AppConfig{
-			Mode:                "interactive",
-			OutputStyle:         "default",
-			EnableColors:        true,
-			EnableCheckpoints:   true,
-			EnableSessions:      true,
-			EnableMemory:        true,
-			CheckpointRetention: 30,
-			MaxCheckpoints:      100,
-			LogLevel:            "info",
+			Mode:                  "interactive",
+			OutputStyle:           "default",
+			EnableColors:          true,
+			EnableCheckpoints:     true,
+			EnableSessions:        true,
+			EnableMemory:          true,
+			CheckpointRetention:   30,
+			MaxCheckpoints:        100,
+			CheckpointCompression: "gzip",
+			LogLevel:              "info",
 		},
 		Performance: PerformanceConfig{
 			CacheTTL:           15,
@@ -188,10 +493,47 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid mode: %s (must be readonly, interactive, or autonomous)", c.App.Mode)
 	}
 
-	if c.Ollama.Temperature < 0 || c.Ollama.Temperature > 1 {
+	if t := c.Ollama.Temperature; t != nil && (*t < 0 || *t > 1) {
 		return fmt.Errorf("ollama.temperature must be between 0 and 1")
 	}
 
+	if !llm.IsValidProvider(c.Ollama.Provider) {
+		return fmt.Errorf("invalid provider: %s (must be ollama or openai)", c.Ollama.Provider)
+	}
+
+	for i, host := range c.Ollama.Hosts {
+		if host.URL == "" {
+			return fmt.Errorf("ollama.hosts[%d].url is required", i)
+		}
+		if host.MaxParallel < 0 {
+			return fmt.Errorf("ollama.hosts[%d].max_parallel must not be negative", i)
+		}
+	}
+
+	if c.Performance.ResponseCache.MaxSizeMB < 0 {
+		return fmt.Errorf("performance.response_cache.max_size_mb must not be negative")
+	}
+
+	switch c.App.CheckpointCompression {
+	case "", "gzip", "none":
+	default:
+		return fmt.Errorf("invalid app.checkpoint_compression: %s (must be gzip or none)", c.App.CheckpointCompression)
+	}
+
+	if (c.Ollama.CertFile == "") != (c.Ollama.KeyFile == "") {
+		return fmt.Errorf("ollama.cert_file and ollama.key_file must be set together")
+	}
+
+	if c.Ollama.Proxy != "" {
+		if _, err := parseProxyURL(c.Ollama.Proxy); err != nil {
+			return err
+		}
+	}
+
+	if _, err := c.MultiModelConfig(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -203,6 +545,9 @@ func (c *Config) Merge(other *Config) {
 	if other.Ollama.Model != "" {
 		c.Ollama.Model = other.Ollama.Model
 	}
+	if other.Ollama.Provider != "" {
+		c.Ollama.Provider = other.Ollama.Provider
+	}
 	if other.App.Mode != "" {
 		c.App.Mode = other.App.Mode
 	}
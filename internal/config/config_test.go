@@ -3,7 +3,12 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/multimodel"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -88,13 +93,14 @@ func TestSaveConfig(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "config.json")
 
+	gpuLayers := 20
 	cfg := &Config{
 		Ollama: OllamaConfig{
 			URL:         "http://localhost:11434",
 			Model:       "qwen2.5-coder:7b",
-			Temperature: 0.7,
+			Temperature: llm.Float64(0.7),
 			MaxTokens:   2048,
-			GPULayers:   20,
+			GPULayers:   &gpuLayers,
 			NumGPU:      1,
 			MaxVRAM:     4096,
 		},
@@ -151,6 +157,20 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "unknown provider",
+			config: &Config{
+				Ollama: OllamaConfig{
+					URL:      "http://localhost:8080",
+					Model:    "qwen2.5-coder-7b",
+					Provider: "bogus",
+				},
+				App: AppConfig{
+					Mode: "interactive",
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing URL",
 			config: &Config{
@@ -188,6 +208,20 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid checkpoint compression",
+			config: &Config{
+				Ollama: OllamaConfig{
+					URL:   "http://localhost:11434",
+					Model: "qwen2.5-coder:7b",
+				},
+				App: AppConfig{
+					Mode:                  "interactive",
+					CheckpointCompression: "zstd",
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -199,3 +233,283 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerationOptions(t *testing.T) {
+	seed := 42
+	cfg := DefaultConfig()
+
+	// Sem gpu_layers o num_gpu não é enviado e o Ollama decide
+	if opts := cfg.Ollama.GenerationOptions(); opts.NumGPU != nil {
+		t.Errorf("num_gpu should be unset by default, got %d", *opts.NumGPU)
+	}
+
+	gpuLayers := 20
+	cfg.Ollama.Seed = &seed
+	cfg.Ollama.Stop = []string{"```"}
+	cfg.Ollama.KeepAlive = "10m"
+	cfg.Ollama.GPULayers = &gpuLayers
+
+	opts := cfg.Ollama.GenerationOptions()
+
+	if opts.NumCtx != cfg.Ollama.NumCtx || opts.NumCtx == 0 {
+		t.Errorf("Expected num_ctx %d, got %d", cfg.Ollama.NumCtx, opts.NumCtx)
+	}
+
+	if opts.Seed == nil || *opts.Seed != 42 {
+		t.Error("Seed should be passed through")
+	}
+
+	if opts.NumGPU == nil || *opts.NumGPU != 20 {
+		t.Errorf("GPULayers should map to num_gpu, got %v", opts.NumGPU)
+	}
+
+	if opts.KeepAlive != "10m" || len(opts.Stop) != 1 {
+		t.Errorf("Unexpected options: %+v", opts)
+	}
+
+	if opts.Temperature == nil || *opts.Temperature != 0.7 || opts.MaxTokens != cfg.Ollama.MaxTokens {
+		t.Errorf("Temperature/MaxTokens should be passed through: %+v", opts)
+	}
+}
+
+func TestGenerationOptions_ZeroTemperatureRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	cfg := DefaultConfig()
+	cfg.Ollama.Temperature = llm.Float64(0)
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	opts := loaded.Ollama.GenerationOptions()
+	if opts.Temperature == nil || *opts.Temperature != 0 {
+		t.Errorf("Temperature 0 should survive save/load and be sent, got %v", opts.Temperature)
+	}
+}
+
+func TestGenerationOptions_MissingTemperatureIsNotSent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"ollama": {"url": "http://localhost:11434", "model": "qwen2.5-coder:7b"}}`), 0644)
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	opts := loaded.Ollama.GenerationOptions()
+	if opts.Temperature != nil {
+		t.Errorf("Config without temperature should leave it to the model, got %v", *opts.Temperature)
+	}
+}
+
+func TestTransportConfig_LLMConfig(t *testing.T) {
+	defaults := llm.DefaultTransportConfig()
+
+	if got := (TransportConfig{}).LLMConfig(); !reflect.DeepEqual(got, defaults) {
+		t.Errorf("Empty transport config should use defaults, got %+v", got)
+	}
+
+	got := TransportConfig{
+		MaxRetries:        5,
+		RetryBackoffMs:    250,
+		FirstTokenTimeout: 600,
+		IdleTimeout:       30,
+		BreakerThreshold:  -1,
+	}.LLMConfig()
+
+	if got.Retry.MaxRetries != 5 || got.Retry.InitialBackoff != 250*time.Millisecond {
+		t.Errorf("Unexpected retry policy: %+v", got.Retry)
+	}
+	if got.Timeouts.FirstToken != 10*time.Minute || got.Timeouts.Idle != 30*time.Second {
+		t.Errorf("Unexpected timeouts: %+v", got.Timeouts)
+	}
+	if got.Timeouts.Connect != defaults.Timeouts.Connect {
+		t.Errorf("Connect timeout should keep the default, got %v", got.Timeouts.Connect)
+	}
+	if got.BreakerThreshold != 0 {
+		t.Errorf("Negative threshold should disable the breaker, got %d", got.BreakerThreshold)
+	}
+
+	if disabled := (TransportConfig{MaxRetries: -1}).LLMConfig(); disabled.Retry.MaxRetries != 0 {
+		t.Errorf("Negative max_retries should disable retries, got %d", disabled.Retry.MaxRetries)
+	}
+}
+
+func TestMultiModelConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Models = ModelsConfig{
+		Enabled: true,
+		Tasks: map[string]TaskModelConfig{
+			"intent": {Model: "qwen2.5-coder:1.5b", Temperature: llm.Float64(0.2), MaxTokens: 256},
+			"code":   {Model: "qwen2.5-coder:14b", NumCtx: 16384, URL: "http://gpu:11434", Fallbacks: []string{"qwen2.5-coder:7b"}},
+		},
+		Cooldown: 60,
+	}
+	cfg.Ollama.Fallbacks = []string{"qwen2.5-coder:3b"}
+
+	mm, err := cfg.MultiModelConfig()
+	if err != nil {
+		t.Fatalf("MultiModelConfig failed: %v", err)
+	}
+
+	if !mm.Enabled || mm.DefaultModel.Name != cfg.Ollama.Model {
+		t.Errorf("Expected enabled config with default %s, got %+v", cfg.Ollama.Model, mm.DefaultModel)
+	}
+
+	intentSpec, _ := mm.GetModel(multimodel.TaskTypeIntent)
+	if intentSpec.Name != "qwen2.5-coder:1.5b" || *intentSpec.Temperature != 0.2 || intentSpec.MaxTokens != 256 {
+		t.Errorf("Unexpected intent spec: %+v", intentSpec)
+	}
+
+	codeSpec, _ := mm.GetModel(multimodel.TaskTypeCode)
+	if codeSpec.Options.NumCtx != 16384 || codeSpec.BaseURL != "http://gpu:11434" || len(codeSpec.Fallbacks) != 1 {
+		t.Errorf("Unexpected code spec: %+v", codeSpec)
+	}
+
+	if mm.Cooldown != time.Minute || len(mm.DefaultModel.Fallbacks) != 1 {
+		t.Errorf("Expected cool-down and default fallbacks, got %v / %v", mm.Cooldown, mm.DefaultModel.Fallbacks)
+	}
+	if opts := cfg.Ollama.GenerationOptions(); len(opts.Fallbacks) != 1 {
+		t.Errorf("Main model fallbacks should be sent in the options, got %v", opts.Fallbacks)
+	}
+
+	if mm.HasModel(multimodel.TaskTypeSearch) {
+		t.Error("Search has no entry and should use the default model")
+	}
+}
+
+func TestMultiModelConfig_Disabled(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Models.Tasks = map[string]TaskModelConfig{"code": {Model: "qwen2.5-coder:14b"}}
+
+	mm, err := cfg.MultiModelConfig()
+	if err != nil {
+		t.Fatalf("MultiModelConfig failed: %v", err)
+	}
+	if mm.Enabled || mm.HasModel(multimodel.TaskTypeCode) {
+		t.Error("Routing should stay off until models.enabled is set")
+	}
+}
+
+func TestMultiModelConfig_Invalid(t *testing.T) {
+	tests := map[string]TaskModelConfig{
+		"unknown": {Model: "llama3"},
+		"default": {Model: "llama3"},
+		"code":    {Model: ""},
+		"search":  {Model: "llama3", Provider: "anthropic"},
+	}
+
+	for task, spec := range tests {
+		cfg := DefaultConfig()
+		cfg.Models = ModelsConfig{Enabled: true, Tasks: map[string]TaskModelConfig{task: spec}}
+
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected validation error for models.tasks.%s", task)
+		}
+	}
+}
+
+func TestModelsConfig_SetTaskModel(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Models.Tasks = map[string]TaskModelConfig{
+		"code": {Model: "qwen2.5-coder:7b", Temperature: llm.Float64(0.2), Fallbacks: []string{"qwen2.5-coder:3b"}},
+	}
+
+	cfg.Models.SetTaskModel("code", "qwen2.5-coder:14b")
+	cfg.Models.SetTaskModel("intent", "qwen2.5-coder:1.5b")
+
+	if !cfg.Models.Enabled {
+		t.Error("Setting a task model should enable routing")
+	}
+	code := cfg.Models.Tasks["code"]
+	if code.Model != "qwen2.5-coder:14b" || *code.Temperature != 0.2 || len(code.Fallbacks) != 1 {
+		t.Errorf("Existing task options should be kept, got %+v", code)
+	}
+	if cfg.Models.Tasks["intent"].Model != "qwen2.5-coder:1.5b" {
+		t.Errorf("Expected new intent entry, got %+v", cfg.Models.Tasks["intent"])
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Config should stay valid: %v", err)
+	}
+}
+
+func TestHostPoolConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	if hosts := cfg.Ollama.HostPoolConfig(); hosts != nil {
+		t.Errorf("Without extra hosts there should be no pool, got %+v", hosts)
+	}
+	if got := cfg.Ollama.MaxInFlight(); got != cfg.Ollama.NumParallel {
+		t.Errorf("Max in-flight should follow num_parallel, got %d", got)
+	}
+
+	cfg.Ollama.Hosts = []HostConfig{{URL: "http://workstation-2:11434"}, {URL: "http://gpu:11434", MaxParallel: 4}}
+	hosts := cfg.Ollama.HostPoolConfig()
+	if len(hosts) != 3 || hosts[0].URL != cfg.Ollama.URL {
+		t.Fatalf("Pool should start with ollama.url, got %+v", hosts)
+	}
+	if hosts[1].MaxParallel != cfg.Ollama.NumParallel || hosts[2].MaxParallel != 4 {
+		t.Errorf("Unexpected parallelism: %+v", hosts)
+	}
+
+	if got := cfg.Ollama.MaxInFlight(); got != 2*cfg.Ollama.NumParallel+4 {
+		t.Errorf("Max in-flight should add up the hosts, got %d", got)
+	}
+
+	cfg.Ollama.Hosts = append(cfg.Ollama.Hosts, HostConfig{})
+	if err := cfg.Validate(); err == nil {
+		t.Error("Host without url should be rejected")
+	}
+}
+
+func TestOllamaConfig_Connection(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Ollama.Headers = map[string]string{"X-Team": "platform"}
+	cfg.Ollama.APIKeyEnv = "OLLAMA_CODE_TEST_TOKEN"
+	cfg.Ollama.Proxy = "http://proxy.internal:3128"
+
+	if _, err := cfg.Ollama.Connection(); err == nil {
+		t.Error("Missing token environment variable should fail")
+	}
+
+	t.Setenv("OLLAMA_CODE_TEST_TOKEN", "secret")
+	transport, err := cfg.LLMTransport()
+	if err != nil {
+		t.Fatalf("LLMTransport failed: %v", err)
+	}
+	conn := transport.Connection
+	if conn.APIKey != "secret" || conn.Headers["X-Team"] != "platform" || conn.Proxy.Host != "proxy.internal:3128" {
+		t.Errorf("Unexpected connection: %+v", conn)
+	}
+	if transport.Retry != llm.DefaultTransportConfig().Retry {
+		t.Errorf("Transport defaults should be kept, got %+v", transport.Retry)
+	}
+
+	cfg.Ollama.Proxy = "proxy.internal:3128"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Proxy without scheme should be invalid")
+	}
+
+	cfg.Ollama.Proxy = ""
+	cfg.Ollama.CertFile = "client.pem"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Client certificate without key should be invalid")
+	}
+}
+
+func TestResponseCacheConfig_LLMConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	got, err := (ResponseCacheConfig{Enabled: true, MaxSizeMB: 50}).LLMConfig()
+	if err != nil {
+		t.Fatalf("LLMConfig failed: %v", err)
+	}
+	if got.Dir != filepath.Join(home, ".ollama-code", "cache", "llm") || got.MaxBytes != 50<<20 {
+		t.Errorf("Unexpected cache config: %+v", got)
+	}
+}
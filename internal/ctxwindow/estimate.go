@@ -0,0 +1,63 @@
+package ctxwindow
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
+
+// charsPerToken média de caracteres por token (aproximação para modelos BPE)
+const charsPerToken = 4
+
+// messageOverhead tokens gastos pelo template de chat em cada mensagem
+const messageOverhead = 4
+
+// EstimateText estima tokens de um texto
+func EstimateText(text string) int {
+	chars := utf8.RuneCountInString(text)
+	if chars == 0 {
+		return 0
+	}
+	return (chars + charsPerToken - 1) / charsPerToken
+}
+
+// EstimateTokens estima tokens de uma mensagem, incluindo tool calls
+func EstimateTokens(msg llm.Message) int {
+	tokens := messageOverhead + EstimateText(msg.Content)
+
+	for _, call := range msg.ToolCalls {
+		tokens += EstimateText(call.Function.Name)
+		if args, err := json.Marshal(call.Function.Arguments); err == nil {
+			tokens += EstimateText(string(args))
+		}
+	}
+
+	return tokens
+}
+
+// EstimateHistory estima tokens de um histórico completo
+func EstimateHistory(history []llm.Message) int {
+	total := 0
+	for _, msg := range history {
+		total += EstimateTokens(msg)
+	}
+	return total
+}
+
+// TrimToBudget retorna o maior sufixo do histórico que cabe no orçamento de tokens
+func TrimToBudget(history []llm.Message, budget int) []llm.Message {
+	used := 0
+	start := len(history)
+
+	for i := len(history) - 1; i >= 0; i-- {
+		tokens := EstimateTokens(history[i])
+		if used+tokens > budget {
+			break
+		}
+		used += tokens
+		start = i
+	}
+
+	return history[start:]
+}
@@ -0,0 +1,223 @@
+package ctxwindow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
+
+// SummaryPrefix marca a mensagem fixa com o resumo das conversas compactadas
+const SummaryPrefix = "[Resumo da conversa anterior]"
+
+// Summarizer resume mensagens antigas em texto curto
+type Summarizer interface {
+	Summarize(ctx context.Context, messages []llm.Message) (string, error)
+}
+
+// Config configuração do gerenciador de contexto
+type Config struct {
+	NumCtx          int     // Janela de contexto do modelo em tokens
+	Threshold       float64 // Fração de NumCtx que dispara compactação automática
+	KeepTurns       int     // Turnos recentes (mensagens de usuário) preservados intactos
+	StaleDumpLimit  int     // Observações de ferramenta acima disso são descartadas quando antigas
+	KeepToolResults int     // Observações mais recentes mantidas inteiras, mesmo num turno longo
+}
+
+// DefaultConfig retorna configuração padrão
+func DefaultConfig() Config {
+	return Config{
+		NumCtx:          8192,
+		Threshold:       0.75,
+		KeepTurns:       2,
+		StaleDumpLimit:  500,
+		KeepToolResults: 3,
+	}
+}
+
+// Result relatório de uma compactação
+type Result struct {
+	BeforeTokens   int
+	AfterTokens    int
+	BeforeMessages int
+	AfterMessages  int
+	Summarized     int // Mensagens incorporadas ao resumo
+	Pruned         int // Observações antigas descartadas
+}
+
+// String formata o relatório para o usuário
+func (r Result) String() string {
+	return fmt.Sprintf("%d → %d tokens (%d → %d mensagens, %d resumidas, %d observações descartadas)",
+		r.BeforeTokens, r.AfterTokens, r.BeforeMessages, r.AfterMessages, r.Summarized, r.Pruned)
+}
+
+// Manager controla o tamanho do histórico frente à janela do modelo
+type Manager struct {
+	config     Config
+	summarizer Summarizer
+}
+
+// NewManager cria novo gerenciador de contexto
+func NewManager(config Config, summarizer Summarizer) *Manager {
+	defaults := DefaultConfig()
+	if config.NumCtx <= 0 {
+		config.NumCtx = defaults.NumCtx
+	}
+	if config.Threshold <= 0 || config.Threshold > 1 {
+		config.Threshold = defaults.Threshold
+	}
+	if config.KeepTurns <= 0 {
+		config.KeepTurns = defaults.KeepTurns
+	}
+	if config.StaleDumpLimit <= 0 {
+		config.StaleDumpLimit = defaults.StaleDumpLimit
+	}
+	if config.KeepToolResults <= 0 {
+		config.KeepToolResults = defaults.KeepToolResults
+	}
+
+	return &Manager{
+		config:     config,
+		summarizer: summarizer,
+	}
+}
+
+// NumCtx retorna a janela de contexto configurada
+func (m *Manager) NumCtx() int {
+	return m.config.NumCtx
+}
+
+// SetNumCtx altera a janela de contexto (ex: troca de modelo)
+func (m *Manager) SetNumCtx(numCtx int) {
+	if numCtx > 0 {
+		m.config.NumCtx = numCtx
+	}
+}
+
+// Limit retorna o número de tokens que dispara compactação automática
+func (m *Manager) Limit() int {
+	return int(float64(m.config.NumCtx) * m.config.Threshold)
+}
+
+// NeedsCompaction indica se o histórico está perto do limite da janela
+func (m *Manager) NeedsCompaction(history []llm.Message) bool {
+	return EstimateHistory(history) >= m.Limit()
+}
+
+// Compact descarta observações antigas e, se ainda necessário (ou se force),
+// resume os turnos antigos em uma mensagem fixa no início do histórico
+func (m *Manager) Compact(ctx context.Context, history []llm.Message, force bool) ([]llm.Message, Result, error) {
+	result := Result{
+		BeforeTokens:   EstimateHistory(history),
+		BeforeMessages: len(history),
+	}
+
+	cut := m.recentStart(history)
+
+	// Dentro dos turnos recentes (ex: um único turno com muitas ferramentas)
+	// só as últimas observações ficam inteiras
+	pruneEnd := cut
+	if start := m.recentToolStart(history); start > pruneEnd {
+		pruneEnd = start
+	}
+
+	compacted, pruned := m.pruneStaleDumps(history, pruneEnd)
+	result.Pruned = pruned
+
+	if force || EstimateHistory(compacted) >= m.Limit() {
+		summarized, count, err := m.summarize(ctx, compacted, cut)
+		if err != nil {
+			return history, result, err
+		}
+		compacted = summarized
+		result.Summarized = count
+	}
+
+	result.AfterTokens = EstimateHistory(compacted)
+	result.AfterMessages = len(compacted)
+
+	return compacted, result, nil
+}
+
+// recentStart índice da primeira mensagem dos turnos recentes preservados.
+// O corte sempre cai numa mensagem de usuário para não separar tool calls
+// de seus resultados.
+func (m *Manager) recentStart(history []llm.Message) int {
+	turns := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "user" {
+			turns++
+			if turns == m.config.KeepTurns {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+// recentToolStart índice da mais antiga das KeepToolResults observações de
+// ferramenta preservadas (0 se houver menos que isso)
+func (m *Manager) recentToolStart(history []llm.Message) int {
+	kept := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "tool" {
+			kept++
+			if kept == m.config.KeepToolResults {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+// pruneStaleDumps substitui observações grandes anteriores ao corte por um aviso curto
+func (m *Manager) pruneStaleDumps(history []llm.Message, cut int) ([]llm.Message, int) {
+	compacted := make([]llm.Message, len(history))
+	copy(compacted, history)
+
+	pruned := 0
+	for i := 0; i < cut; i++ {
+		msg := compacted[i]
+		if msg.Role != "tool" || EstimateTokens(msg) <= m.config.StaleDumpLimit {
+			continue
+		}
+
+		compacted[i].Content = fmt.Sprintf("[saída de %s omitida: ~%d tokens]", msg.ToolName, EstimateTokens(msg))
+		pruned++
+	}
+
+	return compacted, pruned
+}
+
+// summarize resume mensagens anteriores ao corte (incluindo resumo anterior)
+func (m *Manager) summarize(ctx context.Context, history []llm.Message, cut int) ([]llm.Message, int, error) {
+	if cut == 0 || m.summarizer == nil {
+		return history, 0, nil
+	}
+
+	older := history[:cut]
+	summary, err := m.summarizer.Summarize(ctx, older)
+	if err != nil {
+		return nil, 0, fmt.Errorf("summarize history: %w", err)
+	}
+
+	compacted := make([]llm.Message, 0, len(history)-cut+1)
+	compacted = append(compacted, llm.Message{
+		Role:    "system",
+		Content: SummaryPrefix + "\n" + strings.TrimSpace(summary),
+	})
+	compacted = append(compacted, history[cut:]...)
+
+	summarized := len(older)
+	if IsSummary(older[0]) {
+		summarized--
+	}
+
+	return compacted, summarized, nil
+}
+
+// IsSummary indica se a mensagem é o resumo fixo gerado pela compactação
+func IsSummary(msg llm.Message) bool {
+	return msg.Role == "system" && strings.HasPrefix(msg.Content, SummaryPrefix)
+}
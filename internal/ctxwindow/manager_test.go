@@ -0,0 +1,224 @@
+package ctxwindow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
+
+type fakeSummarizer struct {
+	received []llm.Message
+	err      error
+}
+
+func (f *fakeSummarizer) Summarize(ctx context.Context, messages []llm.Message) (string, error) {
+	f.received = messages
+	if f.err != nil {
+		return "", f.err
+	}
+	return "usuário pediu refatoração do parser", nil
+}
+
+func conversation() []llm.Message {
+	return []llm.Message{
+		{Role: "user", Content: "leia parser.go"},
+		{Role: "assistant", ToolCalls: []llm.ToolCall{{Function: llm.ToolCallFunction{Name: "file_reader"}}}},
+		llm.NewToolResultMessage("file_reader", strings.Repeat("package parser ", 400)),
+		{Role: "assistant", Content: "O parser usa recursão"},
+		{Role: "user", Content: "refatore"},
+		{Role: "assistant", Content: "Feito"},
+		{Role: "user", Content: "rode os testes"},
+		{Role: "assistant", Content: "Todos passaram"},
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	if EstimateText("") != 0 {
+		t.Error("Empty text should have 0 tokens")
+	}
+
+	if EstimateText("abcdefgh") != 2 {
+		t.Errorf("Expected 2 tokens, got %d", EstimateText("abcdefgh"))
+	}
+
+	withCall := llm.Message{Role: "assistant", ToolCalls: []llm.ToolCall{{
+		Function: llm.ToolCallFunction{Name: "file_reader", Arguments: map[string]interface{}{"file_path": "main.go"}},
+	}}}
+	if EstimateTokens(withCall) <= messageOverhead {
+		t.Error("Tool calls should count towards tokens")
+	}
+}
+
+func TestTrimToBudget(t *testing.T) {
+	history := conversation()
+
+	trimmed := TrimToBudget(history, 20)
+	if len(trimmed) == 0 || len(trimmed) == len(history) {
+		t.Fatalf("Expected partial history, got %d messages", len(trimmed))
+	}
+
+	if trimmed[len(trimmed)-1].Content != "Todos passaram" {
+		t.Error("Trim should keep the most recent messages")
+	}
+
+	if EstimateHistory(trimmed) > 20 {
+		t.Errorf("Trimmed history exceeds budget: %d", EstimateHistory(trimmed))
+	}
+}
+
+func TestNeedsCompaction(t *testing.T) {
+	small := NewManager(Config{NumCtx: 1000}, nil)
+	if !small.NeedsCompaction(conversation()) {
+		t.Error("Large file dump should require compaction in a 1000-token window")
+	}
+
+	large := NewManager(Config{NumCtx: 100000}, nil)
+	if large.NeedsCompaction(conversation()) {
+		t.Error("History should fit in a 100k window")
+	}
+}
+
+func TestCompact_PrunesStaleDumpsAndSummarizes(t *testing.T) {
+	summarizer := &fakeSummarizer{}
+	manager := NewManager(Config{NumCtx: 1000, KeepTurns: 2}, summarizer)
+
+	compacted, result, err := manager.Compact(context.Background(), conversation(), true)
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+
+	if result.Pruned != 1 {
+		t.Errorf("Expected 1 pruned dump, got %d", result.Pruned)
+	}
+
+	// Turnos antigos (antes de "refatore") vão para o resumo
+	if result.Summarized != 4 {
+		t.Errorf("Expected 4 summarized messages, got %d", result.Summarized)
+	}
+
+	if !IsSummary(compacted[0]) || !strings.Contains(compacted[0].Content, "refatoração") {
+		t.Errorf("First message should be pinned summary, got %+v", compacted[0])
+	}
+
+	if compacted[1].Content != "refatore" {
+		t.Errorf("Recent turns should be kept intact, got %+v", compacted[1])
+	}
+
+	if result.AfterTokens >= result.BeforeTokens {
+		t.Errorf("Expected fewer tokens after compaction: %s", result)
+	}
+
+	// Dump grande não deve ir inteiro para o resumidor
+	for _, msg := range summarizer.received {
+		if msg.Role == "tool" && EstimateTokens(msg) > 500 {
+			t.Error("Stale dump should be pruned before summarizing")
+		}
+	}
+}
+
+func TestCompact_PrunesOldToolResultsInSingleTurn(t *testing.T) {
+	summarizer := &fakeSummarizer{}
+	manager := NewManager(Config{NumCtx: 2000, KeepToolResults: 2}, summarizer)
+
+	// Um único turno com cinco leituras grandes: não há turno antigo para resumir
+	history := []llm.Message{{Role: "user", Content: "revise o projeto inteiro"}}
+	for i := 0; i < 5; i++ {
+		history = append(history,
+			llm.Message{Role: "assistant", ToolCalls: []llm.ToolCall{{Function: llm.ToolCallFunction{Name: "file_reader"}}}},
+			llm.NewToolResultMessage("file_reader", strings.Repeat("package parser ", 400)),
+		)
+	}
+
+	compacted, result, err := manager.Compact(context.Background(), history, false)
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+
+	if result.Pruned != 3 {
+		t.Errorf("Expected the 3 oldest tool results pruned, got %d", result.Pruned)
+	}
+	if len(compacted) != len(history) || result.Summarized != 0 {
+		t.Errorf("Single turn should keep every message, got %s", result)
+	}
+
+	var intact int
+	for i, msg := range compacted {
+		if msg.Role == "tool" && !strings.HasPrefix(msg.Content, "[saída de") {
+			intact++
+			if i < len(compacted)-3 {
+				t.Errorf("Only the latest tool results should stay intact, got index %d", i)
+			}
+		}
+	}
+	if intact != 2 {
+		t.Errorf("Expected the last 2 tool results intact, got %d", intact)
+	}
+	if result.AfterTokens >= result.BeforeTokens {
+		t.Errorf("Expected fewer tokens after pruning: %s", result)
+	}
+}
+
+func TestCompact_ResummarizesPinnedSummary(t *testing.T) {
+	summarizer := &fakeSummarizer{}
+	manager := NewManager(Config{KeepTurns: 1}, summarizer)
+
+	history := []llm.Message{
+		{Role: "system", Content: SummaryPrefix + "\nresumo antigo"},
+		{Role: "user", Content: "a"},
+		{Role: "assistant", Content: "b"},
+		{Role: "user", Content: "c"},
+	}
+
+	compacted, result, err := manager.Compact(context.Background(), history, true)
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+
+	if len(compacted) != 2 {
+		t.Fatalf("Expected summary + last turn, got %d messages", len(compacted))
+	}
+
+	if !IsSummary(summarizer.received[0]) {
+		t.Error("Previous summary should be included when summarizing again")
+	}
+
+	if result.Summarized != 2 {
+		t.Errorf("Previous summary should not count as summarized message, got %d", result.Summarized)
+	}
+}
+
+func TestCompact_NotForcedBelowLimit(t *testing.T) {
+	summarizer := &fakeSummarizer{}
+	manager := NewManager(Config{NumCtx: 100000}, summarizer)
+
+	history := conversation()
+	compacted, result, err := manager.Compact(context.Background(), history, false)
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+
+	if summarizer.received != nil {
+		t.Error("Summarizer should not be called below the limit")
+	}
+
+	if len(compacted) != len(history) || result.Summarized != 0 {
+		t.Errorf("History should only be pruned, got %s", result)
+	}
+}
+
+func TestCompact_SummarizerError(t *testing.T) {
+	manager := NewManager(Config{}, &fakeSummarizer{err: errors.New("boom")})
+
+	history := conversation()
+	compacted, _, err := manager.Compact(context.Background(), history, true)
+	if err == nil {
+		t.Fatal("Expected error from summarizer")
+	}
+
+	if len(compacted) != len(history) {
+		t.Error("History should be unchanged on error")
+	}
+}
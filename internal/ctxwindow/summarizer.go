@@ -0,0 +1,78 @@
+package ctxwindow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
+
+// summaryPrompt instrução para resumir turnos antigos
+const summaryPrompt = `Resuma a conversa abaixo entre usuário e assistente de programação.
+Preserve: objetivos do usuário, decisões tomadas, arquivos criados/modificados,
+comandos executados e seus resultados relevantes, pendências.
+Descarte: conteúdo bruto de arquivos e saídas longas já analisadas.
+Responda apenas com o resumo em tópicos curtos.
+
+Conversa:
+%s`
+
+// LLMSummarizer resume histórico usando o próprio modelo
+type LLMSummarizer struct {
+	client llm.Provider
+}
+
+// NewLLMSummarizer cria novo resumidor baseado em LLM
+func NewLLMSummarizer(client llm.Provider) *LLMSummarizer {
+	return &LLMSummarizer{client: client}
+}
+
+// Summarize resume as mensagens em texto
+func (s *LLMSummarizer) Summarize(ctx context.Context, messages []llm.Message) (string, error) {
+	prompt := fmt.Sprintf(summaryPrompt, FormatTranscript(messages, 2000))
+
+	summary, _, err := s.client.Complete(ctx, []llm.Message{{Role: "user", Content: prompt}}, &llm.CompletionOptions{
+		Temperature: llm.Float64(0.2),
+		MaxTokens:   800,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return summary, nil
+}
+
+// FormatTranscript converte mensagens em transcrição legível,
+// truncando cada mensagem em maxChars caracteres
+func FormatTranscript(messages []llm.Message, maxChars int) string {
+	var sb strings.Builder
+
+	for _, msg := range messages {
+		role := msg.Role
+		switch msg.Role {
+		case "user":
+			role = "Usuário"
+		case "assistant":
+			role = "Assistente"
+		case "tool":
+			role = "Ferramenta " + msg.ToolName
+		case "system":
+			role = "Sistema"
+		}
+
+		content := msg.Content
+		for _, call := range msg.ToolCalls {
+			content += fmt.Sprintf(" [chamou %s %v]", call.Function.Name, call.Function.Arguments)
+		}
+
+		runes := []rune(content)
+		if maxChars > 0 && len(runes) > maxChars {
+			content = string(runes[:maxChars]) + "..."
+		}
+
+		sb.WriteString(fmt.Sprintf("%s: %s\n", role, content))
+	}
+
+	return sb.String()
+}
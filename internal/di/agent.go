@@ -11,11 +11,17 @@ import (
 // InitializeAgent inicializa o Agent com todas as dependências usando Manual DI
 func InitializeAgent(cfg *Config) (*agent.Agent, error) {
 	// Core dependencies
-	llmClient := ProvideLLMClient(cfg)
+	llmClient, err := ProvideLLMClient(cfg)
+	if err != nil {
+		return nil, err
+	}
 	intentDetector := ProvideIntentDetector(llmClient)
+	contextManager := ProvideContextManager(cfg, llmClient)
+	codeIndex := ProvideCodeIndex(cfg, llmClient)
 
 	// Managers (opcionais)
 	sessionManager := ProvideSessionManager(cfg)
+	checkpointManager := ProvideCheckpointManager(cfg)
 	cacheManager := ProvideCacheManager(cfg)
 	statusLine := ProvideStatusLine(cfg)
 	todoManager := ProvideTodoManager(cfg)
@@ -28,6 +34,7 @@ func InitializeAgent(cfg *Config) (*agent.Agent, error) {
 
 	// Multi-Model System
 	multiModelRouter := ProvideMultiModelRouter(cfg)
+	llmClient.SetModelHealth(multiModelRouter.Health())
 
 	// Ollama context
 	ollamaContext, err := ProvideOllamaContext(cfg)
@@ -37,10 +44,13 @@ func InitializeAgent(cfg *Config) (*agent.Agent, error) {
 	}
 
 	// Registries
-	toolRegistry := ProvideToolRegistry(cfg)
+	toolRegistry := ProvideToolRegistry(cfg, codeIndex)
 	commandRegistry := ProvideCommandRegistry()
 	skillRegistry := ProvideSkillRegistry()
 
+	// Observabilidade (opcional)
+	obs := ProvideObservability(cfg)
+
 	// Outros managers
 	confirmManager := ProvideConfirmationManager()
 	webSearch := ProvideWebSearchOrchestrator()
@@ -48,6 +58,7 @@ func InitializeAgent(cfg *Config) (*agent.Agent, error) {
 	// Handlers
 	fileReadHandler := ProvideFileReadHandler()
 	fileWriteHandler := ProvideFileWriteHandler()
+	editFileHandler := ProvideEditFileHandler()
 	searchHandler := ProvideSearchHandler()
 	executeHandler := ProvideExecuteHandler()
 	questionHandler := ProvideQuestionHandler()
@@ -59,6 +70,7 @@ func InitializeAgent(cfg *Config) (*agent.Agent, error) {
 	handlerRegistry := ProvideHandlerRegistry(
 		fileReadHandler,
 		fileWriteHandler,
+		editFileHandler,
 		searchHandler,
 		executeHandler,
 		questionHandler,
@@ -81,11 +93,18 @@ func InitializeAgent(cfg *Config) (*agent.Agent, error) {
 		StatusLine:       statusLine,
 		OllamaContext:    ollamaContext,
 		HandlerRegistry:  handlerRegistry,
+		Observability:    obs,
 		TodoManager:      todoManager,
 		Differ:           differ,
 		Previewer:        previewer,
 		SubagentManager:  subagentManager,
 		MultiModelRouter: multiModelRouter,
+		ContextManager:   contextManager,
+		Index:            codeIndex,
+		HostPool:         cfg.HostPool,
+		Scheduler:        cfg.Scheduler,
+		ResponseCache:    cfg.ResponseCache,
+		Checkpoints:      checkpointManager,
 		Mode:             cfg.Mode,
 		WorkDir:          cfg.WorkDir,
 		History:          []llm.Message{},
@@ -97,5 +116,9 @@ func InitializeAgent(cfg *Config) (*agent.Agent, error) {
 		ColorRed:         color.New(color.FgRed),
 	}
 
+	agentInstance.AttachLLMHooks()
+	agentInstance.AttachCheckpoints()
+	agentInstance.RegisterCommands()
+
 	return agentInstance, nil
 }
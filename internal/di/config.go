@@ -39,5 +39,10 @@ func NewConfigFromAgent(agentCfg agent.Config) *Config {
 		EnableStatusLine: agentCfg.EnableStatusLine,
 		EnableTodos:      true, // Default enabled
 		CacheTTL:         agentCfg.CacheTTL,
+
+		EnableCheckpoints:     agentCfg.EnableCheckpoints,
+		MaxCheckpoints:        agentCfg.MaxCheckpoints,
+		CheckpointRetention:   agentCfg.CheckpointRetention,
+		CheckpointCompression: agentCfg.CheckpointCompression,
 	}
 }
@@ -5,11 +5,15 @@ import (
 	"os"
 	"time"
 
+	"github.com/johnpitter/ollama-code/internal/agent"
 	"github.com/johnpitter/ollama-code/internal/cache"
+	"github.com/johnpitter/ollama-code/internal/checkpoint"
 	"github.com/johnpitter/ollama-code/internal/commands"
 	"github.com/johnpitter/ollama-code/internal/confirmation"
+	"github.com/johnpitter/ollama-code/internal/ctxwindow"
 	"github.com/johnpitter/ollama-code/internal/diff"
 	"github.com/johnpitter/ollama-code/internal/handlers"
+	"github.com/johnpitter/ollama-code/internal/index"
 	"github.com/johnpitter/ollama-code/internal/intent"
 	"github.com/johnpitter/ollama-code/internal/llm"
 	"github.com/johnpitter/ollama-code/internal/modes"
@@ -27,32 +31,65 @@ import (
 
 // Config representa a configuração da aplicação
 type Config struct {
-	OllamaURL           string
-	Model               string
-	Mode                modes.OperationMode
-	WorkDir             string
-	Temperature         float64
-	MaxTokens           int
-	EnableSessions      bool
-	EnableCache         bool
-	EnableStatusLine    bool
-	EnableObservability bool
-	EnableTodos         bool
-	EnableMultiModel    bool
-	CacheTTL            time.Duration
-	ObservabilityConfig observability.LoggerConfig
-}
-
-// ProvideLLMClient fornece LLM client
-func ProvideLLMClient(cfg *Config) *llm.Client {
-	return llm.NewClient(cfg.OllamaURL, cfg.Model)
+	OllamaURL             string
+	Model                 string
+	Provider              string // "ollama" (padrão) ou "openai"
+	Mode                  modes.OperationMode
+	WorkDir               string
+	Temperature           *float64
+	MaxTokens             int
+	NumCtx                int
+	Options               llm.CompletionOptions // Opções de geração padrão
+	Transport             *llm.TransportConfig  // Timeouts, retry, circuit breaker e conexão (nil usa o padrão)
+	EmbedModel            string                // Modelo de embeddings do índice semântico
+	EnableSessions        bool
+	EnableCache           bool
+	EnableStatusLine      bool
+	EnableObservability   bool
+	EnableTodos           bool
+	EnableMultiModel      bool
+	MultiModel            *multimodel.Config // Tabela de roteamento do arquivo de configuração (tem precedência)
+	HostPool              *llm.HostPool      // Pool de servidores Ollama (nil usa só OllamaURL)
+	Scheduler             *llm.Scheduler     // Limite de requisições simultâneas (nil = sem limite)
+	ResponseCache         *llm.ResponseCache // Cache de respostas em disco (nil = desativado)
+	EnableCheckpoints     bool
+	MaxCheckpoints        int    // Máximo de checkpoints automáticos (0 = padrão)
+	CheckpointRetention   int    // Dias de retenção dos checkpoints (0 = padrão)
+	CheckpointCompression string // Compressão dos conteúdos salvos ("gzip" padrão, "none")
+	CacheTTL              time.Duration
+	ObservabilityConfig   observability.LoggerConfig
+}
+
+// ProvideLLMClient fornece LLM client do provider configurado
+func ProvideLLMClient(cfg *Config) (llm.Provider, error) {
+	client, err := llm.NewProvider(cfg.Provider, cfg.OllamaURL, cfg.Model)
+	if err != nil {
+		return nil, err
+	}
+	client.SetDefaultOptions(cfg.Options)
+	if cfg.Transport != nil {
+		client.SetTransportConfig(*cfg.Transport)
+	}
+	client.SetHostPool(cfg.HostPool)
+	client.SetScheduler(cfg.Scheduler)
+	client.SetResponseCache(cfg.ResponseCache)
+	return client, nil
 }
 
 // ProvideIntentDetector fornece detector de intenções
-func ProvideIntentDetector(client *llm.Client) *intent.Detector {
+func ProvideIntentDetector(client llm.Provider) *intent.Detector {
 	return intent.NewDetector(client)
 }
 
+// ProvideContextManager fornece gerenciador da janela de contexto
+func ProvideContextManager(cfg *Config, client llm.Provider) *ctxwindow.Manager {
+	numCtx := cfg.NumCtx
+	if numCtx == 0 {
+		numCtx = cfg.Options.NumCtx
+	}
+	return ctxwindow.NewManager(ctxwindow.Config{NumCtx: numCtx}, ctxwindow.NewLLMSummarizer(client))
+}
+
 // ProvideSessionManager fornece session manager (opcional)
 func ProvideSessionManager(cfg *Config) *session.Manager {
 	if !cfg.EnableSessions {
@@ -62,6 +99,14 @@ func ProvideSessionManager(cfg *Config) *session.Manager {
 	return session.NewManager(homeDir)
 }
 
+// ProvideCheckpointManager fornece checkpoint manager (opcional)
+func ProvideCheckpointManager(cfg *Config) *checkpoint.Manager {
+	if !cfg.EnableCheckpoints {
+		return nil
+	}
+	return agent.NewCheckpointManager(cfg.WorkDir, cfg.CheckpointRetention, cfg.MaxCheckpoints, cfg.CheckpointCompression)
+}
+
 // ProvideCacheManager fornece cache manager (opcional)
 func ProvideCacheManager(cfg *Config) *cache.Manager {
 	if !cfg.EnableCache {
@@ -93,8 +138,17 @@ func ProvideStatusLine(cfg *Config) *statusline.StatusLine {
 	})
 }
 
+// ProvideCodeIndex fornece índice semântico (nil se o provider não gera embeddings)
+func ProvideCodeIndex(cfg *Config, client llm.Provider) *index.Index {
+	embedder, ok := client.(llm.Embedder)
+	if !ok {
+		return nil
+	}
+	return index.New(embedder, index.Config{WorkDir: cfg.WorkDir, Model: cfg.EmbedModel})
+}
+
 // ProvideToolRegistry fornece registry de ferramentas
-func ProvideToolRegistry(cfg *Config) *tools.Registry {
+func ProvideToolRegistry(cfg *Config, codeIndex *index.Index) *tools.Registry {
 	registry := tools.NewRegistry()
 
 	// Ferramentas básicas
@@ -117,6 +171,9 @@ func ProvideToolRegistry(cfg *Config) *tools.Registry {
 	// Novas integrações
 	registry.Register(tools.NewGitHelper(cfg.WorkDir))
 	registry.Register(tools.NewCodeFormatter(cfg.WorkDir))
+	if codeIndex != nil {
+		registry.Register(tools.NewSemanticSearch(codeIndex))
+	}
 
 	return registry
 }
@@ -176,6 +233,11 @@ func ProvideFileWriteHandler() *handlers.FileWriteHandler {
 	return handlers.NewFileWriteHandler()
 }
 
+// ProvideEditFileHandler fornece edit file handler
+func ProvideEditFileHandler() *handlers.EditFileHandler {
+	return handlers.NewEditFileHandler()
+}
+
 // ProvideSearchHandler fornece search handler
 func ProvideSearchHandler() *handlers.SearchHandler {
 	return handlers.NewSearchHandler()
@@ -220,6 +282,7 @@ func ProvideWorkDir(cfg *Config) string {
 func ProvideHandlerRegistry(
 	fileReadHandler *handlers.FileReadHandler,
 	fileWriteHandler *handlers.FileWriteHandler,
+	editFileHandler *handlers.EditFileHandler,
 	searchHandler *handlers.SearchHandler,
 	executeHandler *handlers.ExecuteHandler,
 	questionHandler *handlers.QuestionHandler,
@@ -232,6 +295,7 @@ func ProvideHandlerRegistry(
 	// Registrar handlers
 	registry.Register(intent.IntentReadFile, fileReadHandler)
 	registry.Register(intent.IntentWriteFile, fileWriteHandler)
+	registry.Register(intent.IntentEditFile, editFileHandler)
 	registry.Register(intent.IntentExecuteCommand, executeHandler)
 	registry.Register(intent.IntentSearchCode, searchHandler)
 	registry.Register(intent.IntentAnalyzeProject, analyzeHandler)
@@ -291,7 +355,13 @@ func ProvidePreviewer() *diff.Previewer {
 
 // ProvideSubagentExecutor fornece executor de subagents
 func ProvideSubagentExecutor(cfg *Config) *subagent.Executor {
-	return subagent.NewExecutor(cfg.OllamaURL)
+	executor := subagent.NewExecutor(cfg.OllamaURL)
+	if cfg.Transport != nil {
+		executor.SetTransportConfig(*cfg.Transport)
+	}
+	executor.SetHostPool(cfg.HostPool)
+	executor.SetScheduler(cfg.Scheduler)
+	return executor
 }
 
 // ProvideSubagentManager fornece manager de subagents
@@ -305,7 +375,9 @@ func ProvideSubagentManager(executor *subagent.Executor) *subagent.Manager {
 func ProvideMultiModelRouter(cfg *Config) *multimodel.Router {
 	var mmConfig *multimodel.Config
 
-	if cfg.EnableMultiModel {
+	if cfg.MultiModel != nil {
+		mmConfig = cfg.MultiModel
+	} else if cfg.EnableMultiModel {
 		mmConfig = multimodel.DefaultConfig()
 	} else {
 		// Multi-model desabilitado - criar config que sempre usa default
@@ -315,9 +387,24 @@ func ProvideMultiModelRouter(cfg *Config) *multimodel.Router {
 			MaxTokens:   cfg.MaxTokens,
 			Temperature: cfg.Temperature,
 			Description: "Default model",
+			Provider:    cfg.Provider,
+			Fallbacks:   cfg.Options.Fallbacks,
 		}
 		mmConfig.Disable()
 	}
 
-	return multimodel.NewRouter(cfg.OllamaURL, mmConfig)
+	router := multimodel.NewRouter(cfg.OllamaURL, mmConfig)
+	router.SetClientHook(func(client llm.Provider) {
+		// Cada tarefa usa só os fallbacks do próprio ModelSpec
+		opts := cfg.Options
+		opts.Fallbacks = nil
+		client.SetDefaultOptions(opts)
+		if cfg.Transport != nil {
+			client.SetTransportConfig(*cfg.Transport)
+		}
+		client.SetHostPool(cfg.HostPool)
+		client.SetScheduler(cfg.Scheduler)
+		client.SetResponseCache(cfg.ResponseCache)
+	})
+	return router
 }
@@ -0,0 +1,106 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrAnchorNotFound trecho de busca não existe no conteúdo
+var ErrAnchorNotFound = errors.New("search text not found")
+
+// ErrAnchorAmbiguous trecho de busca aparece mais de uma vez no conteúdo
+var ErrAnchorAmbiguous = errors.New("search text matches more than once")
+
+// ReplaceEdit converte um bloco search/replace em EditRange. O trecho buscado
+// deve aparecer exatamente uma vez; o range cobre as linhas inteiras do
+// trecho, preservando o que houver antes e depois dele na primeira e última linha.
+func ReplaceEdit(content, search, replace string) (EditRange, error) {
+	search = strings.TrimSuffix(search, "\n")
+	replace = strings.TrimSuffix(replace, "\n")
+	if search == "" {
+		return EditRange{}, fmt.Errorf("empty search text")
+	}
+
+	switch strings.Count(content, search) {
+	case 0:
+		return EditRange{}, ErrAnchorNotFound
+	case 1:
+	default:
+		return EditRange{}, ErrAnchorAmbiguous
+	}
+
+	start := strings.Index(content, search)
+	end := start + len(search)
+
+	lineStart := strings.LastIndex(content[:start], "\n") + 1
+	lineEnd := len(content)
+	if i := strings.Index(content[end:], "\n"); i >= 0 {
+		lineEnd = end + i
+	}
+
+	return EditRange{
+		Start: strings.Count(content[:start], "\n") + 1,
+		End:   strings.Count(content[:end], "\n") + 1,
+		Text:  content[lineStart:start] + replace + content[end:lineEnd],
+	}, nil
+}
+
+// ApplyEdits aplica vários ranges ao conteúdo de uma vez. Todos são validados
+// antes de qualquer alteração: ranges fora do arquivo ou sobrepostos fazem
+// nenhuma edição ser aplicada.
+func ApplyEdits(content string, edits []EditRange) (string, error) {
+	lines := strings.Split(content, "\n")
+
+	sorted, err := sortEdits(edits, len(lines))
+	if err != nil {
+		return "", err
+	}
+
+	// De baixo para cima, para os números de linha continuarem válidos
+	for i := len(sorted) - 1; i >= 0; i-- {
+		lines = replaceLines(lines, sorted[i])
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// sortEdits ordena os ranges por linha e rejeita os inválidos ou sobrepostos
+func sortEdits(edits []EditRange, lineCount int) ([]EditRange, error) {
+	sorted := make([]EditRange, len(edits))
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	for i, edit := range sorted {
+		if edit.Start < 1 || edit.Start > lineCount {
+			return nil, fmt.Errorf("start line %d out of range (1-%d)", edit.Start, lineCount)
+		}
+		if edit.End < edit.Start || edit.End > lineCount {
+			return nil, fmt.Errorf("end line %d out of range (%d-%d)", edit.End, edit.Start, lineCount)
+		}
+		if i > 0 && edit.Start <= sorted[i-1].End {
+			return nil, fmt.Errorf("edits overlap at lines %d-%d and %d-%d",
+				sorted[i-1].Start, sorted[i-1].End, edit.Start, edit.End)
+		}
+	}
+
+	return sorted, nil
+}
+
+// replaceLines substitui as linhas do range pelo texto (texto vazio remove as linhas)
+func replaceLines(lines []string, editRange EditRange) []string {
+	newLines := make([]string, 0, len(lines))
+	newLines = append(newLines, lines[:editRange.Start-1]...)
+	if editRange.Text != "" {
+		newLines = append(newLines, strings.Split(editRange.Text, "\n")...)
+	}
+	return append(newLines, lines[editRange.End:]...)
+}
+
+// Record registra no histórico uma edição já aplicada ao arquivo, permitindo Rollback
+func (d *Differ) Record(filePath, oldContent, newContent string) *FileDiff {
+	diff := d.ComputeDiff(filePath, oldContent, newContent)
+	d.addToHistory(filePath, diff)
+	return diff
+}
@@ -0,0 +1,103 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const editSample = `package main
+
+import "fmt"
+
+func main() {
+	port := 8080
+	fmt.Println("porta", port)
+}
+`
+
+func TestReplaceEdit(t *testing.T) {
+	edit, err := ReplaceEdit(editSample, "port := 8080", "port := 9090")
+	if err != nil {
+		t.Fatalf("ReplaceEdit failed: %v", err)
+	}
+	if edit.Start != 6 || edit.End != 6 || edit.Text != "\tport := 9090" {
+		t.Errorf("Expected line 6 replaced keeping indentation, got %+v", edit)
+	}
+
+	// Bloco de várias linhas terminado em \n cobre as linhas inteiras
+	edit, err = ReplaceEdit(editSample, "func main() {\n\tport := 8080\n", "func main() {\n\tport := 1\n")
+	if err != nil {
+		t.Fatalf("ReplaceEdit failed: %v", err)
+	}
+	if edit.Start != 5 || edit.End != 6 {
+		t.Errorf("Expected lines 5-6, got %+v", edit)
+	}
+
+	if _, err := ReplaceEdit(editSample, "port := 7070", "x"); !errors.Is(err, ErrAnchorNotFound) {
+		t.Errorf("Expected ErrAnchorNotFound, got %v", err)
+	}
+	if _, err := ReplaceEdit(editSample, "port", "x"); !errors.Is(err, ErrAnchorAmbiguous) {
+		t.Errorf("Expected ErrAnchorAmbiguous, got %v", err)
+	}
+	if _, err := ReplaceEdit(editSample, "", "x"); err == nil {
+		t.Error("Empty search text should fail")
+	}
+}
+
+func TestApplyEdits(t *testing.T) {
+	port, _ := ReplaceEdit(editSample, "port := 8080", "port := 9090")
+	imports, _ := ReplaceEdit(editSample, `import "fmt"`, "import (\n\t\"fmt\"\n\t\"log\"\n)")
+	remove, _ := ReplaceEdit(editSample, "\tfmt.Println(\"porta\", port)\n", "")
+
+	got, err := ApplyEdits(editSample, []EditRange{port, imports, remove})
+	if err != nil {
+		t.Fatalf("ApplyEdits failed: %v", err)
+	}
+	want := "package main\n\nimport (\n\t\"fmt\"\n\t\"log\"\n)\n\nfunc main() {\n\tport := 9090\n}\n"
+	if got != want {
+		t.Errorf("Unexpected result:\n%s", got)
+	}
+
+	overlapping := []EditRange{{Start: 5, End: 7, Text: "x"}, {Start: 6, End: 6, Text: "y"}}
+	if _, err := ApplyEdits(editSample, overlapping); err == nil {
+		t.Error("Overlapping edits should fail")
+	}
+	if _, err := ApplyEdits(editSample, []EditRange{port, {Start: 40, End: 41}}); err == nil {
+		t.Error("Out of range edit should fail")
+	}
+}
+
+func TestPreviewEdits(t *testing.T) {
+	p := NewPreviewer()
+	port, _ := ReplaceEdit(editSample, "port := 8080", "port := 9090")
+	pkg, _ := ReplaceEdit(editSample, "package main", "// Package main\npackage main")
+
+	preview := p.PreviewEdits("main.go", editSample, []EditRange{port, pkg})
+
+	for _, want := range []string{
+		"--- a/main.go",
+		"+++ b/main.go",
+		"@@ -1,9 +1,10 @@",
+		"-\tport := 8080",
+		"+\tport := 9090",
+		"+// Package main",
+		" import \"fmt\"",
+	} {
+		if !strings.Contains(preview, want) {
+			t.Errorf("Preview should contain %q:\n%s", want, preview)
+		}
+	}
+
+	// Edições distantes geram hunks separados com a numeração nova deslocada
+	var long []string
+	for i := 1; i <= 21; i++ {
+		long = append(long, fmt.Sprintf("linha %d", i))
+	}
+	edits := []EditRange{{Start: 1, End: 1, Text: "a\nb"}, {Start: 21, End: 21, Text: "fim"}}
+	preview = p.PreviewEdits("long.txt", strings.Join(long, "\n"), edits)
+	if !strings.Contains(preview, "@@ -1,4 +1,5 @@") || !strings.Contains(preview, "@@ -18,4 +19,4 @@") {
+		t.Errorf("Expected two hunks, got:\n%s", preview)
+	}
+}
@@ -184,3 +184,78 @@ func (p *Previewer) CompactPreview(diff *FileDiff) string {
 
 	return fmt.Sprintf("%s: +%d ~%d -%d changes", diff.FilePath, adds, modifies, deletes)
 }
+
+// previewContext linhas de contexto ao redor de cada hunk do preview unificado
+const previewContext = 3
+
+// PreviewEdits gera preview unificado (hunks @@ -a,b +c,d @@) de várias edições
+// no mesmo arquivo. Edições próximas compartilham o hunk.
+func (p *Previewer) PreviewEdits(filePath, oldContent string, edits []EditRange) string {
+	lines := strings.Split(oldContent, "\n")
+
+	sorted, err := sortEdits(edits, len(lines))
+	if err != nil {
+		return fmt.Sprintf("❌ Erro: %v\n", err)
+	}
+
+	var sb strings.Builder
+	p.cyan.Fprintf(&sb, "📄 Arquivo: %s\n", filePath)
+	p.yellow.Fprintf(&sb, "📝 %d edição(ões)\n", len(sorted))
+	sb.WriteString(strings.Repeat("─", 60) + "\n")
+	p.red.Fprintf(&sb, "--- a/%s\n", filePath)
+	p.green.Fprintf(&sb, "+++ b/%s\n", filePath)
+
+	offset := 0 // Diferença de linhas acumulada pelos hunks anteriores
+	for i := 0; i < len(sorted); {
+		// Agrupar edições cujo contexto se sobrepõe
+		j := i
+		for j+1 < len(sorted) && sorted[j+1].Start-sorted[j].End-1 <= 2*previewContext {
+			j++
+		}
+
+		start := sorted[i].Start - previewContext
+		if start < 1 {
+			start = 1
+		}
+		end := sorted[j].End + previewContext
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		var body strings.Builder
+		oldCount, newCount := 0, 0
+		line := start
+		for _, edit := range sorted[i : j+1] {
+			for ; line < edit.Start; line++ {
+				fmt.Fprintf(&body, " %s\n", lines[line-1])
+				oldCount++
+				newCount++
+			}
+			for ; line <= edit.End; line++ {
+				p.red.Fprintf(&body, "-%s\n", lines[line-1])
+				oldCount++
+			}
+			if edit.Text != "" {
+				for _, newLine := range strings.Split(edit.Text, "\n") {
+					p.green.Fprintf(&body, "+%s\n", newLine)
+					newCount++
+				}
+			}
+		}
+		for ; line <= end; line++ {
+			fmt.Fprintf(&body, " %s\n", lines[line-1])
+			oldCount++
+			newCount++
+		}
+
+		p.cyan.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", start, oldCount, start+offset, newCount)
+		sb.WriteString(body.String())
+
+		offset += newCount - oldCount
+		i = j + 1
+	}
+
+	sb.WriteString(strings.Repeat("─", 60) + "\n")
+
+	return sb.String()
+}
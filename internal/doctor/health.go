@@ -7,6 +7,8 @@ import (
 	"os/exec"
 	"runtime"
 	"time"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
 )
 
 // Check resultado de verificação
@@ -17,18 +19,28 @@ type Check struct {
 	Error   error
 }
 
+// ollamaCheckTimeout tempo máximo da verificação de conexão com o Ollama
+const ollamaCheckTimeout = 5 * time.Second
+
 // Doctor executor de diagnósticos
 type Doctor struct {
-	ollamaURL string
+	ollamaURL  string
+	httpClient *http.Client
 }
 
 // NewDoctor cria novo doctor
 func NewDoctor(ollamaURL string) *Doctor {
 	return &Doctor{
-		ollamaURL: ollamaURL,
+		ollamaURL:  ollamaURL,
+		httpClient: &http.Client{Timeout: ollamaCheckTimeout},
 	}
 }
 
+// SetConnection aplica headers, token, TLS e proxy à verificação do Ollama
+func (d *Doctor) SetConnection(conn llm.Connection) {
+	d.httpClient = conn.HTTPClient(ollamaCheckTimeout)
+}
+
 // RunAll executa todas as verificações
 func (d *Doctor) RunAll(ctx context.Context) []Check {
 	checks := []Check{}
@@ -44,9 +56,17 @@ func (d *Doctor) RunAll(ctx context.Context) []Check {
 
 // checkOllamaConnection verifica conexão com Ollama
 func (d *Doctor) checkOllamaConnection(ctx context.Context) Check {
-	client := &http.Client{Timeout: 5 * time.Second}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.ollamaURL+"/api/tags", nil)
+	if err != nil {
+		return Check{
+			Name:    "Ollama Connection",
+			Status:  "FAIL",
+			Message: "Invalid Ollama URL",
+			Error:   err,
+		}
+	}
 
-	resp, err := client.Get(d.ollamaURL + "/api/tags")
+	resp, err := d.httpClient.Do(req)
 	if err != nil {
 		return Check{
 			Name:    "Ollama Connection",
@@ -0,0 +1,177 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/multimodel"
+)
+
+// DefaultMinAccuracy acurácia mínima para um modelo ser indicado para a tarefa
+const DefaultMinAccuracy = 0.8
+
+// Result resultado de um cenário em um modelo
+type Result struct {
+	Model    string
+	Scenario string
+	Task     multimodel.TaskType
+	Passed   bool
+	Skipped  bool
+	Detail   string // Motivo da falha (resposta errada, erro de compilação, erro da API)
+	Latency  time.Duration
+	Usage    llm.Usage
+}
+
+// Runner executa os cenários contra cada modelo
+type Runner struct {
+	// NewClient cria o client para o modelo avaliado
+	NewClient func(model string) llm.Provider
+
+	// Scenarios cenários executados (padrão: DefaultSuite)
+	Scenarios []Scenario
+
+	// OnResult callback opcional chamado a cada cenário concluído
+	OnResult func(result Result)
+}
+
+// Run avalia os modelos em ordem e retorna o relatório
+func (r *Runner) Run(ctx context.Context, models []string) *Report {
+	scenarios := r.Scenarios
+	if scenarios == nil {
+		scenarios = DefaultSuite()
+	}
+
+	report := &Report{Models: models}
+	for _, model := range models {
+		for _, scenario := range scenarios {
+			if ctx.Err() != nil {
+				return report
+			}
+
+			result := r.runScenario(ctx, model, scenario)
+			report.Results = append(report.Results, result)
+			if r.OnResult != nil {
+				r.OnResult(result)
+			}
+		}
+	}
+	return report
+}
+
+// runScenario executa um cenário medindo latência e tokens do modelo
+func (r *Runner) runScenario(ctx context.Context, model string, scenario Scenario) Result {
+	result := Result{Model: model, Scenario: scenario.Name(), Task: scenario.Task()}
+
+	client := r.NewClient(model).WithOptions(llm.CompletionOptions{})
+	client.SetUsageHook(func(_ string, usage llm.Usage) {
+		result.Usage.Add(usage)
+	})
+
+	start := time.Now()
+	passed, detail, err := scenario.Run(ctx, client)
+	result.Latency = time.Since(start)
+
+	switch {
+	case errors.Is(err, ErrSkipped):
+		result.Skipped = true
+		result.Detail = err.Error()
+	case err != nil:
+		result.Detail = err.Error()
+	default:
+		result.Passed = passed
+		result.Detail = detail
+	}
+	return result
+}
+
+// Score desempenho agregado de um modelo em um tipo de tarefa
+type Score struct {
+	Model   string
+	Task    multimodel.TaskType
+	Passed  int
+	Total   int // Cenários verificados (sem os pulados)
+	Latency time.Duration
+	Usage   llm.Usage
+}
+
+// Accuracy fração de cenários que passaram
+func (s Score) Accuracy() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Passed) / float64(s.Total)
+}
+
+// AvgLatency latência média por cenário
+func (s Score) AvgLatency() time.Duration {
+	if s.Total == 0 {
+		return 0
+	}
+	return s.Latency / time.Duration(s.Total)
+}
+
+// TokensPerSecond velocidade de geração somando todos os cenários
+func (s Score) TokensPerSecond() float64 {
+	return s.Usage.TokensPerSecond()
+}
+
+// Report resultados da avaliação
+type Report struct {
+	Models  []string
+	Results []Result
+}
+
+// Scores desempenho por modelo e tarefa, na ordem dos modelos avaliados
+func (r *Report) Scores() []Score {
+	index := make(map[string]int)
+	var scores []Score
+
+	for _, result := range r.Results {
+		if result.Skipped {
+			continue
+		}
+
+		key := result.Model + "\x00" + string(result.Task)
+		i, ok := index[key]
+		if !ok {
+			i = len(scores)
+			index[key] = i
+			scores = append(scores, Score{Model: result.Model, Task: result.Task})
+		}
+
+		scores[i].Total++
+		if result.Passed {
+			scores[i].Passed++
+		}
+		scores[i].Latency += result.Latency
+		scores[i].Usage.Add(result.Usage)
+	}
+	return scores
+}
+
+// Winners modelo indicado para cada tarefa: o mais rápido entre os que atingem
+// minAccuracy, desempatando pela maior acurácia. Tarefas em que nenhum modelo
+// atinge o mínimo ficam de fora.
+func (r *Report) Winners(minAccuracy float64) map[multimodel.TaskType]string {
+	candidates := make(map[multimodel.TaskType][]Score)
+	for _, score := range r.Scores() {
+		if score.Total > 0 && score.Accuracy() >= minAccuracy {
+			candidates[score.Task] = append(candidates[score.Task], score)
+		}
+	}
+
+	winners := make(map[multimodel.TaskType]string)
+	for task, scores := range candidates {
+		sort.SliceStable(scores, func(i, j int) bool {
+			if scores[i].AvgLatency() != scores[j].AvgLatency() {
+				return scores[i].AvgLatency() < scores[j].AvgLatency()
+			}
+			return scores[i].Accuracy() > scores[j].Accuracy()
+		})
+		winners[task] = scores[0].Model
+	}
+	return winners
+}
@@ -0,0 +1,164 @@
+package eval
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnpitter/ollama-code/internal/intent"
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/llmtest"
+	"github.com/johnpitter/ollama-code/internal/multimodel"
+)
+
+func intentReply(name intent.Intent) llmtest.Reply {
+	return llmtest.JSON(map[string]interface{}{
+		"intent":     string(name),
+		"confidence": 0.9,
+		"parameters": map[string]interface{}{},
+	})
+}
+
+func TestRunner_ScoresIntentAccuracy(t *testing.T) {
+	server := llmtest.NewServer(t)
+	server.On(llmtest.Model("good-model"), intentReply(intent.IntentReadFile))
+	server.On(llmtest.All(llmtest.Model("bad-model"), llmtest.LastUserContains("config.json")), intentReply(intent.IntentReadFile))
+	server.On(llmtest.Model("bad-model"), intentReply(intent.IntentQuestion))
+
+	var seen []string
+	runner := &Runner{
+		NewClient: func(model string) llm.Provider { return llm.NewClient(server.URL, model) },
+		Scenarios: []Scenario{
+			IntentScenario{"read config.json", intent.IntentReadFile},
+			IntentScenario{"leia main.go", intent.IntentReadFile},
+		},
+		OnResult: func(result Result) { seen = append(seen, result.Model) },
+	}
+
+	report := runner.Run(context.Background(), []string{"good-model", "bad-model"})
+
+	if len(seen) != 4 {
+		t.Fatalf("Expected a progress callback per scenario, got %v", seen)
+	}
+
+	scores := report.Scores()
+	if len(scores) != 2 {
+		t.Fatalf("Expected one intent score per model, got %+v", scores)
+	}
+	if scores[0].Model != "good-model" || scores[0].Accuracy() != 1 {
+		t.Errorf("Unexpected good-model score: %+v", scores[0])
+	}
+	if scores[1].Model != "bad-model" || scores[1].Accuracy() != 0.5 {
+		t.Errorf("Unexpected bad-model score: %+v", scores[1])
+	}
+	if scores[0].Usage.CompletionTokens == 0 || scores[0].TokensPerSecond() <= 0 {
+		t.Errorf("Usage should be collected from the model, got %+v", scores[0].Usage)
+	}
+
+	failed := report.Results[3]
+	if failed.Passed || !strings.Contains(failed.Detail, "question") {
+		t.Errorf("Failed result should explain the wrong intent, got %+v", failed)
+	}
+
+	winners := report.Winners(DefaultMinAccuracy)
+	if winners[multimodel.TaskTypeIntent] != "good-model" {
+		t.Errorf("Only good-model reaches the minimum accuracy, got %v", winners)
+	}
+}
+
+func TestReport_WinnersPreferFastestAccurateModel(t *testing.T) {
+	result := func(model string, task multimodel.TaskType, passed bool, latency time.Duration) Result {
+		return Result{Model: model, Task: task, Passed: passed, Latency: latency}
+	}
+
+	report := &Report{Results: []Result{
+		result("qwen2.5-coder:1.5b", multimodel.TaskTypeIntent, true, 100*time.Millisecond),
+		result("qwen2.5-coder:1.5b", multimodel.TaskTypeIntent, true, 100*time.Millisecond),
+		result("qwen2.5-coder:1.5b", multimodel.TaskTypeCode, false, time.Second),
+		result("qwen2.5-coder:7b", multimodel.TaskTypeIntent, true, 400*time.Millisecond),
+		result("qwen2.5-coder:7b", multimodel.TaskTypeIntent, true, 400*time.Millisecond),
+		result("qwen2.5-coder:7b", multimodel.TaskTypeCode, true, 3*time.Second),
+		{Model: "qwen2.5-coder:1.5b", Task: multimodel.TaskTypeCode, Skipped: true},
+	}}
+
+	winners := report.Winners(0.8)
+	if winners[multimodel.TaskTypeIntent] != "qwen2.5-coder:1.5b" {
+		t.Errorf("Fastest accurate model should win intent, got %v", winners)
+	}
+	if winners[multimodel.TaskTypeCode] != "qwen2.5-coder:7b" {
+		t.Errorf("Slower model should win code when the fast one fails, got %v", winners)
+	}
+
+	if got := report.Winners(1.1); len(got) != 0 {
+		t.Errorf("No model reaches the minimum, expected no winners, got %v", got)
+	}
+}
+
+func TestCodeScenario_VerifiesWithGoTest(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	correct := "Aqui está:\n```go\npackage solution\n\nfunc Double(n int) int { return n * 2 }\n```"
+	wrong := "```go\nfunc Double(n int) int { return n + 2 }\n```"
+
+	server := llmtest.NewServer(t)
+	server.On(llmtest.Model("good-coder"), llmtest.Text(correct))
+	server.On(llmtest.Model("bad-coder"), llmtest.Text(wrong))
+
+	scenario := CodeScenario{
+		ID:     "double",
+		Prompt: "Write Double(n int) int",
+		Tests: `package solution
+
+import "testing"
+
+func TestDouble(t *testing.T) {
+	if Double(3) != 6 {
+		t.Fatal("Double(3) != 6")
+	}
+}
+`,
+	}
+
+	ctx := context.Background()
+	passed, detail, err := scenario.Run(ctx, llm.NewClient(server.URL, "good-coder"))
+	if err != nil || !passed {
+		t.Fatalf("Correct code should pass, got %v (%s, %v)", passed, detail, err)
+	}
+
+	passed, detail, err = scenario.Run(ctx, llm.NewClient(server.URL, "bad-coder"))
+	if err != nil || passed {
+		t.Fatalf("Wrong code should fail the tests, got %v (%v)", passed, err)
+	}
+	if !strings.Contains(detail, "Double(3) != 6") && !strings.Contains(detail, "FAIL") {
+		t.Errorf("Detail should show the test failure, got %q", detail)
+	}
+}
+
+func TestExtractGoCode(t *testing.T) {
+	tests := map[string]string{
+		"```go\npackage solution\n\nfunc A() {}\n```":   "package solution\n\nfunc A() {}\n",
+		"<think>hmm</think>```\nfunc A() {}\n```":       "package solution\n\nfunc A() {}\n",
+		"package solution\n\nfunc A() {}":               "package solution\n\nfunc A() {}\n",
+		"Código:\n```golang\nfunc A() {}\n```\nPronto.": "package solution\n\nfunc A() {}\n",
+	}
+
+	for response, want := range tests {
+		if got := ExtractGoCode(response); got != want {
+			t.Errorf("ExtractGoCode(%q) = %q, want %q", response, got, want)
+		}
+	}
+}
+
+func TestDefaultSuite(t *testing.T) {
+	tasks := make(map[multimodel.TaskType]int)
+	for _, scenario := range DefaultSuite() {
+		tasks[scenario.Task()]++
+	}
+	if tasks[multimodel.TaskTypeIntent] == 0 || tasks[multimodel.TaskTypeCode] == 0 {
+		t.Errorf("Default suite should cover intent and code, got %v", tasks)
+	}
+}
@@ -0,0 +1,256 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/johnpitter/ollama-code/internal/intent"
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/multimodel"
+)
+
+// ErrSkipped o cenário não pôde ser verificado neste ambiente (não conta na acurácia)
+var ErrSkipped = errors.New("scenario skipped")
+
+// codeTestTimeout tempo máximo para compilar e testar o código gerado
+const codeTestTimeout = 2 * time.Minute
+
+// Scenario caso de avaliação executado contra um modelo
+type Scenario interface {
+	// Name identificador curto do cenário
+	Name() string
+
+	// Task tipo de tarefa avaliada (define a rota que o vencedor ocupa)
+	Task() multimodel.TaskType
+
+	// Run executa o cenário com o client; retorna se passou e um detalhe da falha
+	Run(ctx context.Context, client llm.Provider) (bool, string, error)
+}
+
+// IntentScenario mensagem que deve ser classificada com a intenção esperada
+type IntentScenario struct {
+	Message  string
+	Expected intent.Intent
+}
+
+// Name identificador do cenário
+func (s IntentScenario) Name() string {
+	return "intent: " + s.Message
+}
+
+// Task tipo de tarefa avaliada
+func (s IntentScenario) Task() multimodel.TaskType {
+	return multimodel.TaskTypeIntent
+}
+
+// Run classifica a mensagem com o detector do agente
+func (s IntentScenario) Run(ctx context.Context, client llm.Provider) (bool, string, error) {
+	result, err := intent.NewDetector(client).Detect(ctx, s.Message, os.TempDir(), nil)
+	if err != nil {
+		return false, "", err
+	}
+	if result.Intent != s.Expected {
+		return false, fmt.Sprintf("expected %s, got %s", s.Expected, result.Intent), nil
+	}
+	return true, "", nil
+}
+
+// CodeScenario tarefa de geração de código Go verificada com go test
+type CodeScenario struct {
+	ID     string // Nome curto do cenário
+	Prompt string // Pedido enviado ao modelo
+	Tests  string // Conteúdo de solution_test.go (package solution)
+}
+
+// Name identificador do cenário
+func (s CodeScenario) Name() string {
+	return "code: " + s.ID
+}
+
+// Task tipo de tarefa avaliada
+func (s CodeScenario) Task() multimodel.TaskType {
+	return multimodel.TaskTypeCode
+}
+
+// Run pede o código ao modelo e roda os testes do cenário sobre ele
+func (s CodeScenario) Run(ctx context.Context, client llm.Provider) (bool, string, error) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		return false, "", fmt.Errorf("%w: go toolchain not found", ErrSkipped)
+	}
+
+	messages := []llm.Message{
+		{Role: "system", Content: codeSystemPrompt},
+		{Role: "user", Content: s.Prompt},
+	}
+	response, _, err := client.Complete(ctx, messages, &llm.CompletionOptions{Temperature: llm.Float64(0.1)})
+	if err != nil {
+		return false, "", err
+	}
+
+	dir, err := os.MkdirTemp("", "ollama-code-eval-*")
+	if err != nil {
+		return false, "", fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":           "module evalcase\n\ngo 1.21\n",
+		"solution.go":      ExtractGoCode(response),
+		"solution_test.go": s.Tests,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			return false, "", fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+
+	testCtx, cancel := context.WithTimeout(ctx, codeTestTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(testCtx, goBin, "test", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return false, firstLines(string(output), 3), nil
+	}
+	return true, "", nil
+}
+
+// codeSystemPrompt instrução fixa para as tarefas de código
+const codeSystemPrompt = `You are a Go programmer. Reply with a single Go source file in package "solution" inside a ` + "```go" + ` code block. Use only the standard library. Do not include tests or a main function.`
+
+// goBlockRegex bloco de código markdown (```go ou ```)
+var goBlockRegex = regexp.MustCompile("(?s)```(?:go|golang)?\\s*\\n(.*?)```")
+
+// ExtractGoCode extrai o código da resposta do modelo e garante o package solution
+func ExtractGoCode(response string) string {
+	code := strings.TrimSpace(llm.StripThinking(response))
+	if match := goBlockRegex.FindStringSubmatch(code); match != nil {
+		code = strings.TrimSpace(match[1])
+	}
+
+	if !strings.HasPrefix(code, "package ") && !strings.Contains(code, "\npackage ") {
+		code = "package solution\n\n" + code
+	}
+	return code + "\n"
+}
+
+// firstLines primeiras n linhas não vazias do texto
+func firstLines(text string, n int) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+		if len(lines) == n {
+			break
+		}
+	}
+	return strings.Join(lines, " | ")
+}
+
+// IntentSuite casos de classificação de intenção, nos moldes de intent/detector_test.go
+func IntentSuite() []Scenario {
+	cases := []IntentScenario{
+		{"read config.json", intent.IntentReadFile},
+		{"leia o arquivo main.go e me explique", intent.IntentReadFile},
+		{"create output.txt with 'Hello World'", intent.IntentWriteFile},
+		{"crie um arquivo utils.go com uma função de soma", intent.IntentWriteFile},
+		{"no main.go, troque a porta 8080 por 9090", intent.IntentEditFile},
+		{"execute ls -la", intent.IntentExecuteCommand},
+		{"rode os testes com go test ./...", intent.IntentExecuteCommand},
+		{"What is Go?", intent.IntentQuestion},
+		{"qual a diferença entre slice e array?", intent.IntentQuestion},
+		{"search golang best practices on the web", intent.IntentWebSearch},
+		{"pesquise na internet a última versão do Go", intent.IntentWebSearch},
+		{"onde a função NewRouter é definida no projeto?", intent.IntentSearchCode},
+		{"analise a estrutura deste projeto", intent.IntentAnalyzeProject},
+		{"faça commit das mudanças com a mensagem 'fix'", intent.IntentGitOperation},
+		{"git status", intent.IntentGitOperation},
+	}
+
+	suite := make([]Scenario, len(cases))
+	for i, c := range cases {
+		suite[i] = c
+	}
+	return suite
+}
+
+// CodeSuite tarefas pequenas de geração de código, verificadas com go test
+func CodeSuite() []Scenario {
+	return []Scenario{
+		CodeScenario{
+			ID:     "reverse",
+			Prompt: "Write a function `Reverse(s string) string` that reverses a string, handling multi-byte UTF-8 characters correctly.",
+			Tests: `package solution
+
+import "testing"
+
+func TestReverse(t *testing.T) {
+	cases := map[string]string{"": "", "abc": "cba", "olá": "álo", "日本": "本日"}
+	for in, want := range cases {
+		if got := Reverse(in); got != want {
+			t.Errorf("Reverse(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+`,
+		},
+		CodeScenario{
+			ID:     "word-count",
+			Prompt: "Write a function `WordCount(text string) map[string]int` that counts words separated by whitespace, case-insensitively (keys in lower case).",
+			Tests: `package solution
+
+import "testing"
+
+func TestWordCount(t *testing.T) {
+	got := WordCount("Go is fun\n go  IS fast")
+	want := map[string]int{"go": 2, "is": 2, "fun": 1, "fast": 1}
+	if len(got) != len(want) {
+		t.Fatalf("WordCount = %v, want %v", got, want)
+	}
+	for word, n := range want {
+		if got[word] != n {
+			t.Errorf("WordCount[%q] = %d, want %d", word, got[word], n)
+		}
+	}
+}
+`,
+		},
+		CodeScenario{
+			ID:     "merge-intervals",
+			Prompt: "Write a function `Merge(intervals [][2]int) [][2]int` that merges overlapping closed intervals and returns them sorted by start. Intervals that only touch (like [1,2] and [2,3]) must be merged.",
+			Tests: `package solution
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	got := Merge([][2]int{{8, 10}, {1, 3}, {2, 6}, {6, 7}, {15, 18}})
+	want := [][2]int{{1, 7}, {8, 10}, {15, 18}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge = %v, want %v", got, want)
+	}
+	if got := Merge(nil); len(got) != 0 {
+		t.Errorf("Merge(nil) = %v, want empty", got)
+	}
+}
+`,
+		},
+	}
+}
+
+// DefaultSuite todos os cenários embutidos
+func DefaultSuite() []Scenario {
+	return append(IntentSuite(), CodeSuite()...)
+}
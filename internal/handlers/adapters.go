@@ -8,6 +8,7 @@ import (
 	"github.com/johnpitter/ollama-code/internal/commands"
 	"github.com/johnpitter/ollama-code/internal/confirmation"
 	"github.com/johnpitter/ollama-code/internal/diff"
+	"github.com/johnpitter/ollama-code/internal/index"
 	"github.com/johnpitter/ollama-code/internal/intent"
 	"github.com/johnpitter/ollama-code/internal/llm"
 	"github.com/johnpitter/ollama-code/internal/modes"
@@ -264,10 +265,10 @@ func (a *TodoManagerAdapter) Count() int {
 
 // LLMClientAdapter adapta llm.Client para handlers.LLMClient
 type LLMClientAdapter struct {
-	client *llm.Client
+	client llm.Provider
 }
 
-func NewLLMClientAdapter(client *llm.Client) *LLMClientAdapter {
+func NewLLMClientAdapter(client llm.Provider) *LLMClientAdapter {
 	return &LLMClientAdapter{client: client}
 }
 
@@ -275,7 +276,8 @@ func (a *LLMClientAdapter) Complete(ctx context.Context, prompt string) (string,
 	messages := []llm.Message{
 		{Role: "user", Content: prompt},
 	}
-	return a.client.Complete(ctx, messages, nil)
+	response, _, err := a.client.Complete(ctx, messages, nil)
+	return response, err
 }
 
 func (a *LLMClientAdapter) CompleteWithHistory(ctx context.Context, messages []Message) (string, error) {
@@ -284,9 +286,11 @@ func (a *LLMClientAdapter) CompleteWithHistory(ctx context.Context, messages []M
 		llmMessages[i] = llm.Message{
 			Role:    msg.Role,
 			Content: msg.Content,
+			Images:  msg.Images,
 		}
 	}
-	return a.client.Complete(ctx, llmMessages, nil)
+	response, _, err := a.client.Complete(ctx, llmMessages, nil)
+	return response, err
 }
 
 func (a *LLMClientAdapter) CompleteStreaming(ctx context.Context, messages []Message, opts interface{}, callback func(string)) (string, error) {
@@ -295,6 +299,7 @@ func (a *LLMClientAdapter) CompleteStreaming(ctx context.Context, messages []Mes
 		llmMessages[i] = llm.Message{
 			Role:    msg.Role,
 			Content: msg.Content,
+			Images:  msg.Images,
 		}
 	}
 
@@ -304,7 +309,15 @@ func (a *LLMClientAdapter) CompleteStreaming(ctx context.Context, messages []Mes
 		completionOpts = o
 	}
 
-	return a.client.CompleteStreaming(ctx, llmMessages, completionOpts, callback)
+	response, _, err := a.client.CompleteStreaming(ctx, llmMessages, completionOpts, callback)
+	return response, err
+}
+
+func (a *LLMClientAdapter) CompleteStructured(ctx context.Context, prompt string, schema map[string]interface{}, target interface{}) error {
+	messages := []llm.Message{
+		{Role: "user", Content: prompt},
+	}
+	return a.client.CompleteStructured(ctx, messages, nil, schema, target, llm.DefaultStructuredRetries)
 }
 
 // WebSearchClientAdapter adapta websearch.Orchestrator para handlers.WebSearchClient
@@ -337,6 +350,44 @@ func (a *WebSearchClientAdapter) Search(ctx context.Context, query string) (inte
 	return converted, nil
 }
 
+// CodeRetrieverAdapter adapta index.Index para handlers.CodeRetriever
+type CodeRetrieverAdapter struct {
+	index *index.Index
+}
+
+func NewCodeRetrieverAdapter(idx *index.Index) *CodeRetrieverAdapter {
+	return &CodeRetrieverAdapter{index: idx}
+}
+
+// Retrieve busca os trechos mais similares e atualiza o índice (incremental) em
+// segundo plano. Enquanto o índice não está pronto a pergunta segue sem trechos.
+func (a *CodeRetrieverAdapter) Retrieve(ctx context.Context, query string, limit int) (string, error) {
+	if a.index == nil {
+		return "", nil // Índice é opcional
+	}
+
+	// Atualiza depois da busca, para a varredura não segurar o índice durante ela
+	defer a.index.RefreshAsync(ctx, retrievalRefreshInterval)
+	if !a.index.Ready() {
+		return "", nil
+	}
+
+	results, err := a.index.Search(ctx, query, limit)
+	if err != nil {
+		return "", err
+	}
+
+	// Descarta trechos pouco relacionados para não poluir o prompt
+	relevant := results[:0]
+	for _, result := range results {
+		if result.Score >= minRetrievalScore {
+			relevant = append(relevant, result)
+		}
+	}
+
+	return index.FormatResults(relevant, maxRetrievedChars), nil
+}
+
 // IntentDetectorAdapter adapta intent.Detector para handlers.IntentDetector
 type IntentDetectorAdapter struct {
 	detector *intent.Detector
@@ -352,6 +403,7 @@ func (a *IntentDetectorAdapter) DetectWithHistory(ctx context.Context, message s
 		llmMessages[i] = llm.Message{
 			Role:    msg.Role,
 			Content: msg.Content,
+			Images:  msg.Images,
 		}
 	}
 
@@ -409,6 +461,13 @@ func (a *DiffManagerAdapter) ApplyEdit(filePath, content string, editRange inter
 	return a.differ.ApplyEdit(filePath, content, er)
 }
 
+func (a *DiffManagerAdapter) RecordEdit(filePath, oldContent, newContent string) interface{} {
+	if a.differ == nil {
+		return nil
+	}
+	return a.differ.Record(filePath, oldContent, newContent)
+}
+
 func (a *DiffManagerAdapter) Rollback(filePath string) (string, error) {
 	if a.differ == nil {
 		return "", fmt.Errorf("diff manager not available")
@@ -464,6 +523,19 @@ func (a *PreviewManagerAdapter) PreviewRange(filePath, oldContent string, editRa
 	return a.previewer.PreviewRange(filePath, oldContent, er)
 }
 
+func (a *PreviewManagerAdapter) PreviewEdits(filePath, oldContent string, edits interface{}) string {
+	if a.previewer == nil {
+		return ""
+	}
+
+	ranges, ok := edits.([]diff.EditRange)
+	if !ok {
+		return ""
+	}
+
+	return a.previewer.PreviewEdits(filePath, oldContent, ranges)
+}
+
 func (a *PreviewManagerAdapter) CompactPreview(diffInterface interface{}) string {
 	if a.previewer == nil {
 		return ""
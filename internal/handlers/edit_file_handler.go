@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/johnpitter/ollama-code/internal/diff"
+	"github.com/johnpitter/ollama-code/internal/intent"
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/validators"
+)
+
+// editBlock edição pedida pelo modelo: search/replace ou range de linhas
+type editBlock struct {
+	Search    string `json:"search,omitempty"`
+	Replace   string `json:"replace"`
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+}
+
+// editPayload resposta estruturada esperada na edição
+type editPayload struct {
+	Edits []editBlock `json:"edits"`
+}
+
+// editSchema schema de editPayload enviado ao modelo
+var editSchema = llm.GenerateSchema(editPayload{})
+
+// EditFileHandler processa edições pontuais em arquivos existentes. Em vez de
+// regenerar o arquivo inteiro, pede ao modelo apenas os trechos a trocar.
+type EditFileHandler struct {
+	BaseHandler
+	fileValidator *validators.FileValidator
+}
+
+// NewEditFileHandler cria novo handler
+func NewEditFileHandler() *EditFileHandler {
+	return &EditFileHandler{
+		BaseHandler:   NewBaseHandler("file_edit"),
+		fileValidator: validators.NewFileValidator(),
+	}
+}
+
+// Handle processa intent de edição
+func (h *EditFileHandler) Handle(ctx context.Context, deps *Dependencies, result *intent.DetectionResult) (string, error) {
+	// 🔒 VERIFICAR MODO READ-ONLY PRIMEIRO
+	if !deps.Mode.AllowsWrites() {
+		return "❌ Operação bloqueada: modo somente leitura (read-only)\n" +
+			"Para permitir modificações, use:\n" +
+			"  --mode interactive  (pede confirmação)\n" +
+			"  --mode autonomous   (executa automaticamente)", nil
+	}
+
+	filePath := h.targetFile(deps, result)
+	if filePath == "" {
+		return "", fmt.Errorf("não foi possível determinar o arquivo a editar")
+	}
+
+	// Ler conteúdo atual
+	readResult, err := deps.ToolRegistry.Execute(ctx, "file_reader", map[string]interface{}{
+		"file_path": filePath,
+	})
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+	if !readResult.Success {
+		return "", fmt.Errorf("erro ao ler %s: %s", filePath, readResult.Error)
+	}
+	oldContent, _ := readResult.Data["content"].(string)
+
+	// Pedir as edições ao modelo (com uma nova tentativa se alguma não casar)
+	edits, err := h.requestEdits(ctx, deps, result.UserMessage, filePath, oldContent)
+	if err != nil {
+		return "", err
+	}
+
+	newContent, err := diff.ApplyEdits(oldContent, edits)
+	if err != nil {
+		return "", fmt.Errorf("edições inválidas: %w", err)
+	}
+	if newContent == oldContent {
+		return fmt.Sprintf("Nenhuma alteração necessária em %s", filePath), nil
+	}
+
+	// 📝 Criar TODO para tracking
+	var todoID string
+	if deps.TodoManager != nil {
+		id, err := deps.TodoManager.Add(
+			fmt.Sprintf("Editando arquivo: %s", filePath),
+			fmt.Sprintf("Editando %s", filePath),
+		)
+		if err == nil {
+			todoID = id
+		}
+	}
+
+	// Confirmar com usuário mostrando diff unificado
+	if deps.Mode.RequiresConfirmation() {
+		confirmed, err := deps.ConfirmManager.ConfirmWithPreview(
+			fmt.Sprintf("Aplicar %d edição(ões) em %s?", len(edits), filePath),
+			h.preview(deps, filePath, oldContent, edits),
+		)
+		if err != nil || !confirmed {
+			if todoID != "" && deps.TodoManager != nil {
+				deps.TodoManager.Delete(todoID)
+			}
+			return "Operação cancelada", nil
+		}
+	}
+
+	// Todas as edições já foram aplicadas em memória: uma única escrita
+	toolResult, err := deps.ToolRegistry.Execute(ctx, "file_writer", map[string]interface{}{
+		"file_path": filePath,
+		"content":   newContent,
+	})
+	if err == nil && !toolResult.Success {
+		err = errors.New(toolResult.Error)
+	}
+	if err != nil {
+		if todoID != "" && deps.TodoManager != nil {
+			deps.TodoManager.Delete(todoID)
+		}
+		return "", fmt.Errorf("erro ao escrever arquivo: %w", err)
+	}
+
+	// Registrar no histórico para permitir rollback
+	if deps.DiffManager != nil {
+		deps.DiffManager.RecordEdit(filePath, oldContent, newContent)
+	}
+
+	// ✅ Completar TODO
+	if todoID != "" && deps.TodoManager != nil {
+		deps.TodoManager.Complete(todoID)
+	}
+
+	// Adicionar aos arquivos recentes
+	deps.RecentFiles = append(deps.RecentFiles, filePath)
+
+	added, removed := editLineCounts(edits)
+	return fmt.Sprintf("✓ %d edição(ões) aplicada(s) em %s (+%d -%d linhas)", len(edits), filePath, added, removed), nil
+}
+
+// targetFile determina o arquivo a editar: parâmetro do intent, nome citado na
+// mensagem ou último arquivo usado na conversa
+func (h *EditFileHandler) targetFile(deps *Dependencies, result *intent.DetectionResult) string {
+	if filePath, _ := result.Parameters["file_path"].(string); filePath != "" {
+		return filePath
+	}
+	if filePath := h.fileValidator.ExtractFilename(result.UserMessage); filePath != "" {
+		return strings.TrimRight(filePath, ",;:!?)")
+	}
+	if len(deps.RecentFiles) > 0 {
+		return deps.RecentFiles[len(deps.RecentFiles)-1]
+	}
+	return ""
+}
+
+// requestEdits pede as edições ao modelo e as resolve em ranges de linha. Se
+// algum trecho não casar exatamente uma vez, os erros voltam ao modelo uma vez.
+func (h *EditFileHandler) requestEdits(ctx context.Context, deps *Dependencies, userMessage, filePath, content string) ([]diff.EditRange, error) {
+	prompt := h.buildEditPrompt(userMessage, filePath, content)
+
+	var problems []string
+	for attempt := 0; attempt < 2; attempt++ {
+		if len(problems) > 0 {
+			prompt += "\nYour previous edits were rejected:\n- " + strings.Join(problems, "\n- ") +
+				"\nReturn ALL edits again, fixing these problems.\n"
+		}
+
+		var payload editPayload
+		if err := deps.taskClient(deps.CodeClient).CompleteStructured(ctx, prompt, editSchema, &payload); err != nil {
+			return nil, fmt.Errorf("erro ao gerar edições: %w", err)
+		}
+		if len(payload.Edits) == 0 {
+			return nil, fmt.Errorf("o modelo não retornou nenhuma edição para %s", filePath)
+		}
+
+		var edits []diff.EditRange
+		edits, problems = resolveEdits(content, payload.Edits)
+		if len(problems) == 0 {
+			if _, err := diff.ApplyEdits(content, edits); err != nil {
+				problems = []string{err.Error()}
+				continue
+			}
+			return edits, nil
+		}
+	}
+
+	return nil, fmt.Errorf("edições inválidas em %s: %s", filePath, strings.Join(problems, "; "))
+}
+
+// resolveEdits converte os blocos do modelo em ranges, coletando os problemas
+func resolveEdits(content string, blocks []editBlock) ([]diff.EditRange, []string) {
+	var edits []diff.EditRange
+	var problems []string
+
+	for i, block := range blocks {
+		switch {
+		case block.Search != "":
+			edit, err := diff.ReplaceEdit(content, block.Search, block.Replace)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("edit %d: %v: %q", i+1, err, block.Search))
+				continue
+			}
+			edits = append(edits, edit)
+		case block.StartLine > 0:
+			end := block.EndLine
+			if end == 0 {
+				end = block.StartLine
+			}
+			edits = append(edits, diff.EditRange{
+				Start: block.StartLine,
+				End:   end,
+				Text:  strings.TrimSuffix(block.Replace, "\n"),
+			})
+		default:
+			problems = append(problems, fmt.Sprintf("edit %d: needs search text or start_line", i+1))
+		}
+	}
+
+	return edits, problems
+}
+
+// preview gera o diff unificado das edições
+func (h *EditFileHandler) preview(deps *Dependencies, filePath, oldContent string, edits []diff.EditRange) string {
+	if deps.PreviewManager != nil {
+		if preview := deps.PreviewManager.PreviewEdits(filePath, oldContent, edits); preview != "" {
+			return preview
+		}
+	}
+	return diff.NewPreviewer().PreviewEdits(filePath, oldContent, edits)
+}
+
+// editLineCounts conta linhas adicionadas e removidas pelas edições
+func editLineCounts(edits []diff.EditRange) (int, int) {
+	added, removed := 0, 0
+	for _, edit := range edits {
+		removed += edit.End - edit.Start + 1
+		if edit.Text != "" {
+			added += strings.Count(edit.Text, "\n") + 1
+		}
+	}
+	return added, removed
+}
+
+// buildEditPrompt constrói prompt pedindo apenas as edições, com o arquivo numerado
+func (h *EditFileHandler) buildEditPrompt(userMessage, filePath, content string) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("Edit an existing file according to the following request:\n\n")
+	prompt.WriteString(fmt.Sprintf("User request: %s\n\n", userMessage))
+	prompt.WriteString(fmt.Sprintf("File: %s (line numbers added for reference, they are NOT part of the file)\n", filePath))
+	prompt.WriteString("```\n")
+	for i, line := range strings.Split(content, "\n") {
+		prompt.WriteString(fmt.Sprintf("%4d| %s\n", i+1, line))
+	}
+	prompt.WriteString("```\n\n")
+
+	prompt.WriteString("Output a JSON object with an 'edits' array. Do NOT rewrite the whole file.\n")
+	prompt.WriteString("Each edit is either:\n")
+	prompt.WriteString("- search/replace: 'search' is text copied EXACTLY from the file (without line numbers) that appears only once; 'replace' is the new text\n")
+	prompt.WriteString("- line range: 'start_line' and 'end_line' (inclusive) are replaced by 'replace'\n\n")
+	prompt.WriteString("RULES:\n")
+	prompt.WriteString("1. Prefer search/replace and include enough surrounding lines to make 'search' unique\n")
+	prompt.WriteString("2. Keep the original indentation\n")
+	prompt.WriteString("3. An empty 'replace' deletes the matched lines\n")
+	prompt.WriteString("4. Edits must not overlap\n\n")
+
+	prompt.WriteString("Example:\n")
+	prompt.WriteString("{\"edits\": [{\"search\": \"\\tport := 8080\", \"replace\": \"\\tport := 9090\"}]}\n")
+
+	return prompt.String()
+}
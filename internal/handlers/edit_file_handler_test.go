@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/diff"
+	"github.com/johnpitter/ollama-code/internal/intent"
+)
+
+const editFileContent = "package main\n\nfunc main() {\n\tport := 8080\n\tserve(port)\n}\n"
+
+// newEditFileRegistry tool registry com file_reader devolvendo content e file_writer gravando em written
+func newEditFileRegistry(content string, written *string) *MockToolRegistry {
+	return &MockToolRegistry{
+		ExecuteFunc: func(ctx context.Context, toolName string, params map[string]interface{}) (ToolResult, error) {
+			switch toolName {
+			case "file_reader":
+				return ToolResult{Success: true, Data: map[string]interface{}{"content": content}}, nil
+			case "file_writer":
+				*written = params["content"].(string)
+				return MockToolResultSuccess("ok"), nil
+			}
+			return MockToolResultError("unexpected tool " + toolName), nil
+		},
+	}
+}
+
+func TestEditFileHandler_SearchReplace(t *testing.T) {
+	handler := NewEditFileHandler()
+	deps := NewMockDependencies()
+	differ := diff.NewDiffer()
+	deps.DiffManager = NewDiffManagerAdapter(differ)
+	deps.PreviewManager = NewPreviewManagerAdapter(diff.NewPreviewer())
+
+	var written string
+	deps.ToolRegistry = newEditFileRegistry(editFileContent, &written)
+	deps.LLMClient = &MockLLMClient{
+		CompleteStructuredFunc: func(ctx context.Context, prompt string, schema map[string]interface{}, target interface{}) error {
+			if !strings.Contains(prompt, "   4| \tport := 8080") {
+				t.Errorf("Prompt should include numbered file content:\n%s", prompt)
+			}
+			target.(*editPayload).Edits = []editBlock{
+				{Search: "port := 8080", Replace: "port := 9090"},
+				{StartLine: 5, EndLine: 5, Replace: "\tlog.Println(port)\n\tserve(port)"},
+			}
+			return nil
+		},
+	}
+
+	var preview string
+	deps.Mode = &MockOperationMode{RequiresConfirmationFunc: func() bool { return true }}
+	deps.ConfirmManager = &MockConfirmationManager{
+		ConfirmWithPreviewFunc: func(message, p string) (bool, error) {
+			preview = p
+			return true, nil
+		},
+	}
+
+	result := NewMockDetectionResult(intent.IntentEditFile, map[string]interface{}{"file_path": "main.go"})
+	response, err := handler.Handle(context.Background(), deps, result)
+
+	AssertNoError(t, err)
+	AssertContains(t, response, "2 edição(ões)", "response")
+	AssertEqual(t, "package main\n\nfunc main() {\n\tport := 9090\n\tlog.Println(port)\n\tserve(port)\n}\n", written, "written content")
+	AssertContains(t, preview, "@@ -1,7 +1,8 @@", "unified preview")
+	AssertContains(t, preview, "+\tport := 9090", "unified preview")
+
+	// Edição registrada no histórico: rollback devolve o conteúdo original
+	restored, err := differ.Rollback("main.go")
+	AssertNoError(t, err)
+	AssertEqual(t, editFileContent, restored, "rollback content")
+}
+
+func TestEditFileHandler_RetriesAmbiguousAnchor(t *testing.T) {
+	handler := NewEditFileHandler()
+	deps := NewMockDependencies()
+
+	var written string
+	deps.ToolRegistry = newEditFileRegistry(editFileContent, &written)
+
+	calls := 0
+	deps.LLMClient = &MockLLMClient{
+		CompleteStructuredFunc: func(ctx context.Context, prompt string, schema map[string]interface{}, target interface{}) error {
+			calls++
+			payload := target.(*editPayload)
+			if calls == 1 {
+				payload.Edits = []editBlock{{Search: "port", Replace: "addr"}}
+				return nil
+			}
+			if !strings.Contains(prompt, "matches more than once") {
+				t.Errorf("Retry prompt should explain the rejected edit:\n%s", prompt)
+			}
+			payload.Edits = []editBlock{{Search: "\tserve(port)", Replace: "\tserve(port, nil)"}}
+			return nil
+		},
+	}
+
+	result := NewMockDetectionResult(intent.IntentEditFile, map[string]interface{}{"file_path": "main.go"})
+	_, err := handler.Handle(context.Background(), deps, result)
+
+	AssertNoError(t, err)
+	AssertEqual(t, 2, calls, "LLM calls")
+	AssertContains(t, written, "serve(port, nil)", "written content")
+}
+
+func TestEditFileHandler_RejectsInvalidEdits(t *testing.T) {
+	handler := NewEditFileHandler()
+	deps := NewMockDependencies()
+
+	var written string
+	deps.ToolRegistry = newEditFileRegistry(editFileContent, &written)
+	deps.LLMClient = &MockLLMClient{
+		CompleteStructuredFunc: func(ctx context.Context, prompt string, schema map[string]interface{}, target interface{}) error {
+			target.(*editPayload).Edits = []editBlock{
+				{Search: "port := 8080", Replace: "port := 9090"},
+				{Search: "port := 7070", Replace: "port := 1"},
+			}
+			return nil
+		},
+	}
+
+	result := NewMockDetectionResult(intent.IntentEditFile, map[string]interface{}{"file_path": "main.go"})
+	_, err := handler.Handle(context.Background(), deps, result)
+
+	if !ErrorContains(err, "search text not found") {
+		t.Errorf("Expected anchor error, got %v", err)
+	}
+	AssertEqual(t, "", written, "no partial write")
+}
+
+func TestEditFileHandler_ReadOnlyMode(t *testing.T) {
+	handler := NewEditFileHandler()
+	deps := NewMockDependencies()
+	deps.Mode = &MockOperationMode{AllowsWritesFunc: func() bool { return false }}
+
+	result := NewMockDetectionResult(intent.IntentEditFile, map[string]interface{}{"file_path": "main.go"})
+	response, err := handler.Handle(context.Background(), deps, result)
+
+	AssertNoError(t, err)
+	AssertContains(t, response, "somente leitura", "response")
+}
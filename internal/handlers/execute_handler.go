@@ -3,9 +3,9 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/johnpitter/ollama-code/internal/intent"
+	"github.com/johnpitter/ollama-code/internal/tools"
 )
 
 // ExecuteHandler processa execução de comandos
@@ -41,7 +41,7 @@ func (h *ExecuteHandler) Handle(ctx context.Context, deps *Dependencies, result
 	}
 
 	// Verificar se comando é perigoso
-	if h.isDangerousCommand(command) {
+	if tools.IsDangerousCommand(command) {
 		if !deps.Mode.RequiresConfirmation() {
 			// Cancelar TODO
 			if todoID != "" && deps.TodoManager != nil {
@@ -100,27 +100,3 @@ func (h *ExecuteHandler) truncateCommand(command string) string {
 	}
 	return command
 }
-
-// isDangerousCommand verifica se comando é perigoso
-func (h *ExecuteHandler) isDangerousCommand(command string) bool {
-	dangerousPatterns := []string{
-		"rm -rf",
-		"rm -fr",
-		"mkfs",
-		"dd if=",
-		":(){ :|:& };:",
-		"> /dev/",
-		"chmod -R 777",
-		"chown -R",
-	}
-
-	commandLower := strings.ToLower(command)
-
-	for _, pattern := range dangerousPatterns {
-		if strings.Contains(commandLower, strings.ToLower(pattern)) {
-			return true
-		}
-	}
-
-	return false
-}
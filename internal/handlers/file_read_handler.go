@@ -48,6 +48,11 @@ func (h *FileReadHandler) Handle(ctx context.Context, deps *Dependencies, result
 	// Adicionar arquivo aos recentes
 	deps.RecentFiles = append(deps.RecentFiles, filePath)
 
+	// Imagens vão para o modelo (screenshot, diagrama) junto com o pedido
+	if fileType, _ := toolResult.Data["type"].(string); fileType == "image" && deps.LLMClient != nil {
+		return toolResult.Message + "\n\n" + h.describeImage(ctx, deps, toolResult, filePath, result.UserMessage), nil
+	}
+
 	// Formatar resposta com conteúdo
 	return h.formatReadResult(ctx, deps, toolResult, filePath, userAskedForAnalysis), nil
 }
@@ -102,7 +107,7 @@ Conteúdo:
 
 Forneça uma análise concisa e útil.`, filePath, content)
 
-		response, err := deps.LLMClient.Complete(ctx, analysisPrompt)
+		response, err := deps.taskClient(deps.AnalysisClient).Complete(ctx, analysisPrompt)
 		if err == nil {
 			output += response + "\n"
 		} else {
@@ -117,6 +122,28 @@ Forneça uma análise concisa e útil.`, filePath, content)
 	return output
 }
 
+// describeImage envia a imagem ao modelo (multimodal) junto com o pedido do usuário
+func (h *FileReadHandler) describeImage(ctx context.Context, deps *Dependencies, result ToolResult, filePath, userMessage string) string {
+	image, _ := result.Data["base64"].(string)
+	if image == "" {
+		return "📷 Arquivo de imagem carregado\n"
+	}
+
+	prompt := userMessage
+	if prompt == "" {
+		prompt = fmt.Sprintf("Descreva a imagem %s. Se for um screenshot ou diagrama, explique o que ele mostra.", filePath)
+	}
+	messages := []Message{{Role: "user", Content: prompt, Images: []string{image}}}
+
+	response, err := deps.clientFor(messages).CompleteWithHistory(ctx, messages)
+	if err != nil {
+		// Modelo sem suporte a imagens: apenas indicar que foi lida
+		return fmt.Sprintf("📷 Arquivo de imagem carregado (modelo não conseguiu analisar: %v)\n", err)
+	}
+
+	return "🖼️  Análise da imagem:\n\n" + response + "\n"
+}
+
 // formatContentPreview formata preview do conteúdo (primeiras 20 linhas)
 func (h *FileReadHandler) formatContentPreview(content string) string {
 	lines := splitLines(content)
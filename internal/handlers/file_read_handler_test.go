@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/johnpitter/ollama-code/internal/intent"
@@ -104,3 +106,69 @@ func TestFileReadHandler_UpdatesRecentFiles(t *testing.T) {
 		t.Error("Expected test.txt to be added to recentFiles")
 	}
 }
+
+func TestFileReadHandler_ImageSentToVisionModel(t *testing.T) {
+	handler := NewFileReadHandler()
+	deps := NewMockDependencies()
+
+	deps.ToolRegistry = &MockToolRegistry{
+		ExecuteFunc: func(ctx context.Context, toolName string, params map[string]interface{}) (ToolResult, error) {
+			return ToolResult{
+				Success: true,
+				Message: "Imagem lida com sucesso: erro.png",
+				Data:    map[string]interface{}{"type": "image", "base64": "aW1hZ2U=", "mime_type": "image/png"},
+			}, nil
+		},
+	}
+	deps.LLMClient = &MockLLMClient{
+		CompleteWithHistoryFunc: func(ctx context.Context, messages []Message) (string, error) {
+			t.Error("Image should go to the vision client")
+			return "", nil
+		},
+	}
+
+	var sent []Message
+	deps.VisionClient = &MockLLMClient{
+		CompleteWithHistoryFunc: func(ctx context.Context, messages []Message) (string, error) {
+			sent = messages
+			return "Stack trace de um nil pointer", nil
+		},
+	}
+
+	result := NewMockDetectionResult(intent.IntentReadFile, map[string]interface{}{"file_path": "erro.png"})
+	result.UserMessage = "o que esse screenshot mostra?"
+
+	response, err := handler.Handle(context.Background(), deps, result)
+	AssertNoError(t, err)
+
+	if len(sent) != 1 || sent[0].Content != "o que esse screenshot mostra?" || len(sent[0].Images) != 1 || sent[0].Images[0] != "aW1hZ2U=" {
+		t.Errorf("Expected user message with the image, got %+v", sent)
+	}
+	if !strings.Contains(response, "nil pointer") {
+		t.Errorf("Expected model description in response, got %q", response)
+	}
+}
+
+func TestFileReadHandler_ImageModelWithoutVision(t *testing.T) {
+	handler := NewFileReadHandler()
+	deps := NewMockDependencies()
+
+	deps.ToolRegistry = &MockToolRegistry{
+		ExecuteFunc: func(ctx context.Context, toolName string, params map[string]interface{}) (ToolResult, error) {
+			return ToolResult{Success: true, Message: "ok", Data: map[string]interface{}{"type": "image", "base64": "aW1hZ2U="}}, nil
+		},
+	}
+	deps.LLMClient = &MockLLMClient{
+		CompleteWithHistoryFunc: func(ctx context.Context, messages []Message) (string, error) {
+			return "", fmt.Errorf("model does not support images")
+		},
+	}
+
+	result := NewMockDetectionResult(intent.IntentReadFile, map[string]interface{}{"file_path": "diagrama.png"})
+	response, err := handler.Handle(context.Background(), deps, result)
+	AssertNoError(t, err)
+
+	if !strings.Contains(response, "📷 Arquivo de imagem carregado") {
+		t.Errorf("Expected fallback message, got %q", response)
+	}
+}
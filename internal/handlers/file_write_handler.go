@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/johnpitter/ollama-code/internal/intent"
+	"github.com/johnpitter/ollama-code/internal/llm"
 	"github.com/johnpitter/ollama-code/internal/validators"
 )
 
@@ -42,8 +44,9 @@ func (h *FileWriteHandler) Handle(ctx context.Context, deps *Dependencies, resul
 	content, _ := result.Parameters["content"].(string)
 	userMessage := result.UserMessage
 
-	// 📦 DETECTAR REQUISIÇÃO DE MÚLTIPLOS ARQUIVOS
-	if h.detectMultiFileRequest(userMessage) {
+	// 📦 DETECTAR REQUISIÇÃO DE MÚLTIPLOS ARQUIVOS (apenas antes de gerar conteúdo,
+	// para o fallback de arquivo único não voltar para cá)
+	if content == "" && h.detectMultiFileRequest(userMessage) {
 		return h.handleMultiFileWrite(ctx, deps, userMessage)
 	}
 
@@ -151,7 +154,7 @@ func (h *FileWriteHandler) generateAndWrite(ctx context.Context, deps *Dependenc
 	prompt := h.buildGenerationPrompt(userMessage, suggestedPath, deps)
 
 	// Completar com LLM
-	response, err := deps.LLMClient.Complete(ctx, prompt)
+	response, err := deps.taskClient(deps.CodeClient).Complete(ctx, prompt)
 	if err != nil {
 		return "", fmt.Errorf("erro ao gerar conteúdo: %w", err)
 	}
@@ -288,55 +291,49 @@ func (h *FileWriteHandler) detectMultiFileRequest(message string) bool {
 	return false
 }
 
+// generatedFile arquivo gerado no payload multi-file
+type generatedFile struct {
+	FilePath string `json:"file_path"`
+	Content  string `json:"content"`
+}
+
+// multiFilePayload resposta estruturada esperada na geração multi-file
+type multiFilePayload struct {
+	Files []generatedFile `json:"files"`
+}
+
+// multiFileSchema schema de multiFilePayload enviado ao modelo
+var multiFileSchema = llm.GenerateSchema(multiFilePayload{})
+
 // handleMultiFileWrite cria múltiplos arquivos coordenados
 func (h *FileWriteHandler) handleMultiFileWrite(ctx context.Context, deps *Dependencies, userMessage string) (string, error) {
 	// Construir prompt específico para multi-file
 	prompt := h.buildMultiFilePrompt(userMessage, deps)
 
-	// Completar com LLM
-	response, err := deps.LLMClient.Complete(ctx, prompt)
-	if err != nil {
+	// Completar com LLM restrito ao schema do payload multi-file
+	var payload multiFilePayload
+	if err := deps.taskClient(deps.CodeClient).CompleteStructured(ctx, prompt, multiFileSchema, &payload); err != nil {
+		if errors.Is(err, llm.ErrInvalidStructuredOutput) {
+			// Fallback: tentar como arquivo único
+			return h.generateAndWrite(ctx, deps, userMessage, "", &intent.DetectionResult{
+				Intent:      intent.IntentWriteFile,
+				UserMessage: userMessage,
+				Parameters:  map[string]interface{}{},
+			})
+		}
 		return "", fmt.Errorf("erro ao gerar múltiplos arquivos: %w", err)
 	}
 
-	// Parse JSON response
-	parsed, err := h.jsonValidator.Parse(response)
-	if err != nil {
-		// Fallback: tentar como arquivo único
-		return h.generateAndWrite(ctx, deps, userMessage, "", &intent.DetectionResult{
-			Intent:      intent.IntentWriteFile,
-			UserMessage: userMessage,
-			Parameters:  map[string]interface{}{},
-		})
-	}
-
-	// Extrair array de arquivos
-	filesRaw, ok := parsed["files"]
-	if !ok {
-		// Fallback: tentar como arquivo único
-		return h.generateAndWrite(ctx, deps, userMessage, "", &intent.DetectionResult{
-			Intent:      intent.IntentWriteFile,
-			UserMessage: userMessage,
-			Parameters:  map[string]interface{}{},
-		})
-	}
-
-	filesArray, ok := filesRaw.([]interface{})
-	if !ok || len(filesArray) == 0 {
+	if len(payload.Files) == 0 {
 		return "", fmt.Errorf("formato de resposta inválido: esperado array de arquivos")
 	}
 
 	// Confirmar com usuário se necessário (UMA VEZ para todo o projeto)
 	if deps.Mode.RequiresConfirmation() {
-		fileList := make([]string, 0, len(filesArray))
-		for _, fileRaw := range filesArray {
-			fileMap, ok := fileRaw.(map[string]interface{})
-			if !ok {
-				continue
-			}
-			filePath, _ := fileMap["file_path"].(string)
-			if filePath != "" {
-				fileList = append(fileList, filePath)
+		fileList := make([]string, 0, len(payload.Files))
+		for _, file := range payload.Files {
+			if file.FilePath != "" {
+				fileList = append(fileList, file.FilePath)
 			}
 		}
 
@@ -354,15 +351,9 @@ func (h *FileWriteHandler) handleMultiFileWrite(ctx context.Context, deps *Depen
 	var created []string
 	var failed []string
 
-	for _, fileRaw := range filesArray {
-		fileMap, ok := fileRaw.(map[string]interface{})
-		if !ok {
-			failed = append(failed, "arquivo com formato inválido")
-			continue
-		}
-
-		filePath, _ := fileMap["file_path"].(string)
-		content, _ := fileMap["content"].(string)
+	for _, file := range payload.Files {
+		filePath := file.FilePath
+		content := file.Content
 
 		if filePath == "" || content == "" {
 			failed = append(failed, fmt.Sprintf("%s (falta file_path ou content)", filePath))
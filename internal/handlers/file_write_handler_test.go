@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/johnpitter/ollama-code/internal/intent"
+	"github.com/johnpitter/ollama-code/internal/llm"
 )
 
 func TestFileWriteHandler_WithContent(t *testing.T) {
@@ -254,3 +255,108 @@ func TestFileWriteHandler_LLMGenerationError(t *testing.T) {
 
 	AssertError(t, err, "LLM generation failure")
 }
+
+func TestFileWriteHandler_MultiFileStructured(t *testing.T) {
+	handler := NewFileWriteHandler()
+	deps := NewMockDependencies()
+
+	deps.LLMClient = &MockLLMClient{
+		CompleteStructuredFunc: func(ctx context.Context, prompt string, schema map[string]interface{}, target interface{}) error {
+			if _, ok := schema["properties"].(map[string]interface{})["files"]; !ok {
+				t.Error("Expected schema with files property")
+			}
+			payload := target.(*multiFilePayload)
+			payload.Files = []generatedFile{
+				{FilePath: "index.html", Content: "<html></html>"},
+				{FilePath: "style.css", Content: "body {}"},
+			}
+			return nil
+		},
+	}
+
+	var written []string
+	deps.ToolRegistry = &MockToolRegistry{
+		ExecuteFunc: func(ctx context.Context, toolName string, params map[string]interface{}) (ToolResult, error) {
+			written = append(written, params["file_path"].(string))
+			return MockToolResultSuccess("ok"), nil
+		},
+	}
+
+	result := NewMockDetectionResult(intent.IntentWriteFile, nil)
+	result.UserMessage = "crie html e css separados"
+
+	_, err := handler.Handle(context.Background(), deps, result)
+
+	AssertNoError(t, err)
+	if len(written) != 2 {
+		t.Fatalf("Expected 2 files written, got %v", written)
+	}
+}
+
+func TestFileWriteHandler_MultiFileFallsBackToSingleFile(t *testing.T) {
+	handler := NewFileWriteHandler()
+	deps := NewMockDependencies()
+
+	deps.LLMClient = &MockLLMClient{
+		CompleteStructuredFunc: func(ctx context.Context, prompt string, schema map[string]interface{}, target interface{}) error {
+			return llm.ErrInvalidStructuredOutput
+		},
+		CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+			return `{"file_path": "page.html", "content": "<html></html>"}`, nil
+		},
+	}
+
+	toolCalled := false
+	deps.ToolRegistry = &MockToolRegistry{
+		ExecuteFunc: func(ctx context.Context, toolName string, params map[string]interface{}) (ToolResult, error) {
+			toolCalled = true
+			AssertEqual(t, "page.html", params["file_path"], "file_path param")
+			return MockToolResultSuccess("ok"), nil
+		},
+	}
+
+	result := NewMockDetectionResult(intent.IntentWriteFile, nil)
+	result.UserMessage = "crie html e css separados"
+
+	_, err := handler.Handle(context.Background(), deps, result)
+
+	AssertNoError(t, err)
+	AssertToolCalled(t, "file_writer", &toolCalled)
+}
+
+func TestFileWriteHandler_UsesCodeClient(t *testing.T) {
+	handler := NewFileWriteHandler()
+	deps := NewMockDependencies()
+
+	deps.LLMClient = &MockLLMClient{
+		CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+			t.Error("Code generation should not use the default client when CodeClient is set")
+			return "", fmt.Errorf("unexpected call")
+		},
+	}
+
+	codeCalled := false
+	deps.CodeClient = &MockLLMClient{
+		CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+			codeCalled = true
+			return `{"file_path": "main.go", "content": "package main"}`, nil
+		},
+	}
+	deps.ToolRegistry = &MockToolRegistry{
+		ExecuteFunc: func(ctx context.Context, toolName string, params map[string]interface{}) (ToolResult, error) {
+			return MockToolResultSuccess("File written"), nil
+		},
+	}
+
+	result := NewMockDetectionResult(intent.IntentWriteFile, map[string]interface{}{
+		"file_path": "main.go",
+	})
+	result.UserMessage = "create a main.go file"
+
+	_, err := handler.Handle(context.Background(), deps, result)
+
+	AssertNoError(t, err)
+	if !codeCalled {
+		t.Error("Expected CodeClient to generate the file")
+	}
+}
@@ -30,8 +30,13 @@ type Dependencies struct {
 
 	// Clients
 	LLMClient      LLMClient
+	VisionClient   LLMClient // Modelo multimodal para mensagens com imagens (nil usa LLMClient)
+	CodeClient     LLMClient // Modelo de geração de código (nil usa LLMClient)
+	SearchClient   LLMClient // Modelo de resumo de pesquisas web (nil usa LLMClient)
+	AnalysisClient LLMClient // Modelo de análise de código (nil usa LLMClient)
 	WebSearch      WebSearchClient
 	IntentDetector IntentDetector
+	CodeRetriever  CodeRetriever // Busca semântica no código (opcional)
 
 	// State
 	Mode        OperationMode
@@ -88,6 +93,7 @@ type TodoManager interface {
 type DiffManager interface {
 	ComputeDiff(filePath, oldContent, newContent string) interface{}
 	ApplyEdit(filePath, content string, editRange interface{}) (string, interface{}, error)
+	RecordEdit(filePath, oldContent, newContent string) interface{}
 	Rollback(filePath string) (string, error)
 	GetHistory(filePath string) interface{}
 	ClearHistory()
@@ -96,6 +102,7 @@ type DiffManager interface {
 type PreviewManager interface {
 	Preview(diff interface{}) string
 	PreviewRange(filePath, oldContent string, editRange interface{}) string
+	PreviewEdits(filePath, oldContent string, edits interface{}) string
 	CompactPreview(diff interface{}) string
 }
 
@@ -103,12 +110,19 @@ type LLMClient interface {
 	Complete(ctx context.Context, prompt string) (string, error)
 	CompleteWithHistory(ctx context.Context, messages []Message) (string, error)
 	CompleteStreaming(ctx context.Context, messages []Message, opts interface{}, callback func(string)) (string, error)
+	CompleteStructured(ctx context.Context, prompt string, schema map[string]interface{}, target interface{}) error
 }
 
 type WebSearchClient interface {
 	Search(ctx context.Context, query string) (interface{}, error)
 }
 
+// CodeRetriever busca trechos de código relevantes para uma pergunta
+type CodeRetriever interface {
+	// Retrieve retorna os trechos formatados (vazio se nada relevante)
+	Retrieve(ctx context.Context, query string, limit int) (string, error)
+}
+
 type IntentDetector interface {
 	DetectWithHistory(ctx context.Context, message string, history []Message) (*intent.DetectionResult, error)
 }
@@ -122,6 +136,7 @@ type OperationMode interface {
 type Message struct {
 	Role    string
 	Content string
+	Images  []string // Imagens em base64
 }
 
 type ToolResult struct {
@@ -131,6 +146,31 @@ type ToolResult struct {
 	Data    map[string]interface{}
 }
 
+// clientFor escolhe o client para as mensagens (VisionClient quando a mensagem
+// atual do usuário traz imagens; imagens de turnos anteriores não contam)
+func (d *Dependencies) clientFor(messages []Message) LLMClient {
+	if d.VisionClient == nil {
+		return d.LLMClient
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			if len(messages[i].Images) > 0 {
+				return d.VisionClient
+			}
+			break
+		}
+	}
+	return d.LLMClient
+}
+
+// taskClient retorna o client da tarefa ou LLMClient se não houver modelo próprio
+func (d *Dependencies) taskClient(client LLMClient) LLMClient {
+	if client == nil {
+		return d.LLMClient
+	}
+	return client
+}
+
 // BaseHandler fornece funcionalidade comum para handlers
 type BaseHandler struct {
 	name string
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/johnpitter/ollama-code/internal/intent"
+	"github.com/johnpitter/ollama-code/internal/llm"
 )
 
 // MockToolRegistry mock para ToolRegistry
@@ -131,6 +132,7 @@ type MockLLMClient struct {
 	CompleteFunc            func(ctx context.Context, prompt string) (string, error)
 	CompleteWithHistoryFunc func(ctx context.Context, messages []Message) (string, error)
 	CompleteStreamingFunc   func(ctx context.Context, messages []Message, opts interface{}, callback func(string)) (string, error)
+	CompleteStructuredFunc  func(ctx context.Context, prompt string, schema map[string]interface{}, target interface{}) error
 }
 
 func (m *MockLLMClient) Complete(ctx context.Context, prompt string) (string, error) {
@@ -154,6 +156,13 @@ func (m *MockLLMClient) CompleteStreaming(ctx context.Context, messages []Messag
 	return "mock streaming response", nil
 }
 
+func (m *MockLLMClient) CompleteStructured(ctx context.Context, prompt string, schema map[string]interface{}, target interface{}) error {
+	if m.CompleteStructuredFunc != nil {
+		return m.CompleteStructuredFunc(ctx, prompt, schema, target)
+	}
+	return llm.ErrInvalidStructuredOutput
+}
+
 // MockWebSearchClient mock para WebSearchClient
 type MockWebSearchClient struct {
 	SearchFunc func(ctx context.Context, query string) (interface{}, error)
@@ -190,6 +199,18 @@ func (m *MockIntentDetector) DetectWithHistory(ctx context.Context, message stri
 	}, nil
 }
 
+// MockCodeRetriever mock para CodeRetriever
+type MockCodeRetriever struct {
+	RetrieveFunc func(ctx context.Context, query string, limit int) (string, error)
+}
+
+func (m *MockCodeRetriever) Retrieve(ctx context.Context, query string, limit int) (string, error) {
+	if m.RetrieveFunc != nil {
+		return m.RetrieveFunc(ctx, query, limit)
+	}
+	return "", nil
+}
+
 // MockOperationMode mock para OperationMode
 type MockOperationMode struct {
 	StringFunc               func() string
@@ -31,6 +31,11 @@ func (h *QuestionHandler) Handle(ctx context.Context, deps *Dependencies, result
 		Role:    "system",
 		Content: fmt.Sprintf("You are a helpful coding assistant. Working directory: %s", deps.WorkDir),
 	}
+
+	// Trechos do projeto relacionados à pergunta (RAG)
+	if snippets := retrieveCode(ctx, deps, userMessage); snippets != "" {
+		systemMsg.Content += "\n\nRelevant code from the project:\n\n" + snippets
+	}
 	messages = append(messages, systemMsg)
 
 	// Adicionar histórico recente
@@ -47,7 +52,7 @@ func (h *QuestionHandler) Handle(ctx context.Context, deps *Dependencies, result
 	})
 
 	// Completar com LLM
-	response, err := deps.LLMClient.CompleteWithHistory(ctx, messages)
+	response, err := deps.clientFor(messages).CompleteWithHistory(ctx, messages)
 	if err != nil {
 		return "", fmt.Errorf("erro ao processar pergunta: %w", err)
 	}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/johnpitter/ollama-code/internal/intent"
@@ -155,3 +157,50 @@ func TestQuestionHandler_SystemPrompt(t *testing.T) {
 		t.Error("Expected system prompt to be included")
 	}
 }
+
+func TestQuestionHandler_RetrievedCode(t *testing.T) {
+	handler := NewQuestionHandler()
+	deps := NewMockDependencies()
+
+	deps.CodeRetriever = &MockCodeRetriever{
+		RetrieveFunc: func(ctx context.Context, query string, limit int) (string, error) {
+			AssertEqual(t, "como o cache expira?", query, "retrieval query")
+			return "// cache.go:10-20 (Evict)\nfunc Evict() {}", nil
+		},
+	}
+
+	var systemPrompt string
+	deps.LLMClient = &MockLLMClient{
+		CompleteWithHistoryFunc: func(ctx context.Context, messages []Message) (string, error) {
+			systemPrompt = messages[0].Content
+			return "Response", nil
+		},
+	}
+
+	result := NewMockDetectionResult(intent.IntentQuestion, map[string]interface{}{})
+	result.UserMessage = "como o cache expira?"
+
+	_, err := handler.Handle(context.Background(), deps, result)
+	AssertNoError(t, err)
+
+	if !strings.Contains(systemPrompt, "cache.go:10-20 (Evict)") {
+		t.Errorf("Expected retrieved code in system prompt, got %q", systemPrompt)
+	}
+}
+
+func TestQuestionHandler_RetrievalErrorIgnored(t *testing.T) {
+	handler := NewQuestionHandler()
+	deps := NewMockDependencies()
+	deps.CodeRetriever = &MockCodeRetriever{
+		RetrieveFunc: func(ctx context.Context, query string, limit int) (string, error) {
+			return "", fmt.Errorf("model not found")
+		},
+	}
+
+	result := NewMockDetectionResult(intent.IntentQuestion, map[string]interface{}{})
+	result.UserMessage = "test question"
+
+	response, err := handler.Handle(context.Background(), deps, result)
+	AssertNoError(t, err)
+	AssertNotEmpty(t, response, "response")
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	retrievalRefreshInterval = 30 * time.Second // Intervalo mínimo entre reindexações
+	retrievalLimit           = 5                // Trechos recuperados por pergunta
+	minRetrievalScore        = 0.3              // Similaridade mínima para entrar no prompt
+	maxRetrievedChars        = 6000             // Limite de contexto recuperado
+)
+
+// retrieveCode busca trechos relevantes no índice semântico (best effort:
+// sem índice ou com erro no modelo de embeddings retorna vazio)
+func retrieveCode(ctx context.Context, deps *Dependencies, query string) string {
+	if deps.CodeRetriever == nil || query == "" {
+		return ""
+	}
+
+	snippets, err := deps.CodeRetriever.Retrieve(ctx, query, retrievalLimit)
+	if err != nil {
+		return ""
+	}
+	return snippets
+}
@@ -54,7 +54,14 @@ func (h *SearchHandler) Handle(ctx context.Context, deps *Dependencies, result *
 	}
 
 	// Formatar resultado com matches encontrados
-	return h.formatSearchResult(toolResult, query), nil
+	output := h.formatSearchResult(toolResult, query)
+
+	// Complementar com busca por significado (encontra código mesmo sem o termo exato)
+	if snippets := retrieveCode(ctx, deps, query); snippets != "" {
+		output += "\n🧠 Trechos semanticamente relacionados:\n\n" + snippets + "\n"
+	}
+
+	return output, nil
 }
 
 // formatSearchResult formata resultado da busca com matches encontrados
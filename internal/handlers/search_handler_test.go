@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/johnpitter/ollama-code/internal/intent"
@@ -70,3 +71,24 @@ func TestSearchHandler_WithPath(t *testing.T) {
 	AssertNoError(t, err)
 	AssertToolCalled(t, "code_searcher", &toolCalled)
 }
+
+func TestSearchHandler_SemanticResults(t *testing.T) {
+	handler := NewSearchHandler()
+	deps := NewMockDependencies()
+	deps.CodeRetriever = &MockCodeRetriever{
+		RetrieveFunc: func(ctx context.Context, query string, limit int) (string, error) {
+			return "// db/conn.go:3-9 (OpenDatabase)", nil
+		},
+	}
+
+	result := NewMockDetectionResult(intent.IntentSearchCode, map[string]interface{}{
+		"query": "conexão com o banco",
+	})
+
+	response, err := handler.Handle(context.Background(), deps, result)
+	AssertNoError(t, err)
+
+	if !strings.Contains(response, "db/conn.go:3-9 (OpenDatabase)") {
+		t.Errorf("Expected semantic results in response, got %q", response)
+	}
+}
@@ -254,7 +254,7 @@ func (h *WebSearchHandler) summarizeWithLLM(ctx context.Context, deps *Dependenc
 	prompt.WriteString("Resumo:")
 
 	// Call LLM
-	summary, err := deps.LLMClient.Complete(ctx, prompt.String())
+	summary, err := deps.taskClient(deps.SearchClient).Complete(ctx, prompt.String())
 	if err != nil {
 		// Fallback to formatted results if LLM fails
 		return h.formatSearchResults(query, searchResults), nil
@@ -220,3 +220,40 @@ func TestWebSearchHandler_StringResult(t *testing.T) {
 	// String results don't have snippets, so should return "Nenhum resultado encontrado"
 	AssertNotEmpty(t, response, "response")
 }
+
+func TestWebSearchHandler_UsesSearchClient(t *testing.T) {
+	handler := NewWebSearchHandler()
+	deps := NewMockDependencies()
+
+	deps.WebSearch = &MockWebSearchClient{
+		SearchFunc: func(ctx context.Context, query string) (interface{}, error) {
+			return []interface{}{
+				map[string]interface{}{
+					"title":   "Generics in Go",
+					"snippet": "Type parameters since Go 1.18",
+					"url":     "https://example.com/generics",
+				},
+			}, nil
+		},
+	}
+	deps.LLMClient = &MockLLMClient{
+		CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+			t.Error("Summarization should not use the default client when SearchClient is set")
+			return "", nil
+		},
+	}
+	deps.SearchClient = &MockLLMClient{
+		CompleteFunc: func(ctx context.Context, prompt string) (string, error) {
+			return "Resumo gerado pelo modelo de pesquisa.", nil
+		},
+	}
+
+	result := NewMockDetectionResult(intent.IntentWebSearch, map[string]interface{}{
+		"query": "golang generics",
+	})
+
+	response, err := handler.Handle(context.Background(), deps, result)
+
+	AssertNoError(t, err)
+	AssertContains(t, response, "Resumo gerado pelo modelo de pesquisa.", "summary from SearchClient")
+}
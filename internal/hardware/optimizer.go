@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/johnpitter/ollama-code/internal/config"
+	"github.com/johnpitter/ollama-code/internal/llm"
 )
 
 // Preset tipo de preset de configuração
@@ -83,16 +84,16 @@ func (o *Optimizer) generateCompatibilityConfig(specs *Specs) *config.Config {
 
 	// Modelo menor para compatibilidade
 	cfg.Ollama.Model = "qwen2.5-coder:7b-instruct-q4_K_M"
-	cfg.Ollama.Temperature = 0.7
+	cfg.Ollama.Temperature = llm.Float64(0.7)
 	cfg.Ollama.MaxTokens = 2048
+	cfg.Ollama.NumCtx = 4096    // Janela menor economiza RAM/VRAM
+	cfg.Ollama.KeepAlive = "5m" // Liberar memória rapidamente
 
 	// GPU settings conservadores
 	if specs.HasNVIDIAGPU {
-		cfg.Ollama.GPULayers = 20 // Apenas algumas layers na GPU
 		cfg.Ollama.NumGPU = 1
 		cfg.Ollama.MaxVRAM = int(minInt64(specs.GPUMemory/2, 4096)) // Usa no máximo 50% da VRAM ou 4GB
 	} else {
-		cfg.Ollama.GPULayers = 0 // CPU only
 		cfg.Ollama.NumGPU = 0
 	}
 
@@ -127,17 +128,17 @@ func (o *Optimizer) generatePerformanceConfig(specs *Specs) *config.Config {
 		cfg.Ollama.Model = "qwen2.5-coder:7b-instruct-q5_K_M"
 	}
 
-	cfg.Ollama.Temperature = 0.7
+	cfg.Ollama.Temperature = llm.Float64(0.7)
 	cfg.Ollama.MaxTokens = 4096
+	cfg.Ollama.NumCtx = 8192
+	cfg.Ollama.KeepAlive = "15m"
 
 	// GPU settings balanceados
 	if specs.HasNVIDIAGPU {
-		cfg.Ollama.GPULayers = 35 // Maioria na GPU
 		cfg.Ollama.NumGPU = minInt(specs.GPUCount, 2)
 		cfg.Ollama.MaxVRAM = int(minInt64(specs.GPUMemory*80/100, 12288)) // 80% da VRAM ou 12GB
 		cfg.Ollama.FlashAttention = true
 	} else {
-		cfg.Ollama.GPULayers = 0
 		cfg.Ollama.NumGPU = 0
 	}
 
@@ -166,18 +167,18 @@ func (o *Optimizer) generateUltraConfig(specs *Specs) *config.Config {
 
 	// Modelo máximo
 	cfg.Ollama.Model = "qwen2.5-coder:32b-instruct-q6_K"
-	cfg.Ollama.Temperature = 0.7
+	cfg.Ollama.Temperature = llm.Float64(0.7)
 	cfg.Ollama.MaxTokens = 8192
+	cfg.Ollama.NumCtx = 32768
+	cfg.Ollama.KeepAlive = "1h" // Manter modelo carregado entre sessões
 
 	// GPU settings agressivos
 	if specs.HasNVIDIAGPU {
-		cfg.Ollama.GPULayers = 999 // Todas as layers
 		cfg.Ollama.NumGPU = specs.GPUCount
 		cfg.Ollama.MaxVRAM = int(specs.GPUMemory * 95 / 100) // 95% da VRAM
 		cfg.Ollama.FlashAttention = true
 	} else {
 		// Mesmo sem GPU, usa CPU otimizado
-		cfg.Ollama.GPULayers = 0
 		cfg.Ollama.NumGPU = 0
 	}
 
@@ -278,9 +279,10 @@ func PrintOptimizationReport(specs *Specs, preset Preset, cfg *config.Config) st
 
 ⚙️  OPTIMIZED CONFIGURATION:
    Model: %s
-   Temperature: %.1f
+   Temperature: %s
    Max Tokens: %d
-   GPU Layers: %d
+   Context Window: %d
+   Keep Alive: %s
    Max VRAM: %d MB
    Parallel Requests: %d
    Flash Attention: %v
@@ -310,9 +312,10 @@ func PrintOptimizationReport(specs *Specs, preset Preset, cfg *config.Config) st
 		GetPresetDescription(preset),
 
 		cfg.Ollama.Model,
-		cfg.Ollama.Temperature,
+		formatTemperature(cfg.Ollama.Temperature),
 		cfg.Ollama.MaxTokens,
-		cfg.Ollama.GPULayers,
+		cfg.Ollama.NumCtx,
+		cfg.Ollama.KeepAlive,
 		cfg.Ollama.MaxVRAM,
 		cfg.Ollama.NumParallel,
 		cfg.Ollama.FlashAttention,
@@ -348,3 +351,11 @@ func PrintOptimizationReport(specs *Specs, preset Preset, cfg *config.Config) st
 
 	return report
 }
+
+// formatTemperature formata a temperatura do relatório ("model default" quando não definida)
+func formatTemperature(temperature *float64) string {
+	if temperature == nil {
+		return "model default"
+	}
+	return fmt.Sprintf("%.1f", *temperature)
+}
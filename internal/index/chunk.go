@@ -0,0 +1,227 @@
+package index
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	windowLines  = 60  // Linhas por janela em arquivos sem estrutura conhecida
+	windowStride = 50  // Passo entre janelas (10 linhas de sobreposição)
+	maxDeclLines = 150 // Declarações maiores são divididas em janelas
+)
+
+// Chunk trecho de arquivo indexado
+type Chunk struct {
+	Path      string    // Caminho relativo ao diretório de trabalho
+	StartLine int       // Primeira linha (1-based)
+	EndLine   int       // Última linha (inclusiva)
+	Symbol    string    // Função, tipo ou seção (vazio em janelas de linhas)
+	Text      string    // Conteúdo do trecho
+	Vector    []float32 // Embedding normalizado
+}
+
+// Location formata "arquivo:início-fim"
+func (c Chunk) Location() string {
+	return fmt.Sprintf("%s:%d-%d", filepath.ToSlash(c.Path), c.StartLine, c.EndLine)
+}
+
+// embeddingInput texto enviado ao modelo de embeddings (caminho e símbolo ajudam a busca)
+func (c Chunk) embeddingInput(maxChars int) string {
+	header := filepath.ToSlash(c.Path)
+	if c.Symbol != "" {
+		header += " " + c.Symbol
+	}
+
+	text := header + "\n" + c.Text
+	if maxChars > 0 && len(text) > maxChars {
+		text = text[:maxChars]
+	}
+	return text
+}
+
+// ChunkFile divide o conteúdo em trechos: declarações em Go, seções em
+// Markdown e janelas de linhas nos demais arquivos
+func ChunkFile(path, content string) []Chunk {
+	var chunks []Chunk
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".go":
+		chunks = chunkGo(path, content)
+	case ".md", ".markdown":
+		chunks = chunkMarkdown(path, content)
+	}
+
+	if chunks == nil {
+		chunks = chunkLines(path, content, 1, "")
+	}
+	return chunks
+}
+
+// chunkGo um trecho por declaração de topo (com doc comment); nil se não compilar
+func chunkGo(path, content string) []Chunk {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, content, parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+
+	lines := strings.Split(content, "\n")
+	chunks := make([]Chunk, 0, len(file.Decls))
+
+	for _, decl := range file.Decls {
+		start := decl.Pos()
+		symbol := ""
+
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Doc != nil {
+				start = d.Doc.Pos()
+			}
+			symbol = funcSymbol(d)
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				continue
+			}
+			if d.Doc != nil {
+				start = d.Doc.Pos()
+			}
+			symbol = genDeclSymbol(d)
+		}
+
+		startLine := fset.Position(start).Line
+		endLine := fset.Position(decl.End()).Line
+
+		if endLine-startLine+1 > maxDeclLines {
+			text := strings.Join(lines[startLine-1:endLine], "\n")
+			chunks = append(chunks, chunkLines(path, text, startLine, symbol)...)
+			continue
+		}
+
+		chunks = appendChunk(chunks, Chunk{
+			Path:      path,
+			StartLine: startLine,
+			EndLine:   endLine,
+			Symbol:    symbol,
+			Text:      strings.Join(lines[startLine-1:endLine], "\n"),
+		})
+	}
+
+	return chunks
+}
+
+// funcSymbol nome da função, com o tipo do receiver para métodos (ex: "Agent.Run")
+func funcSymbol(d *ast.FuncDecl) string {
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		return d.Name.Name
+	}
+
+	recv := d.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	if index, ok := recv.(*ast.IndexExpr); ok {
+		recv = index.X
+	}
+	if ident, ok := recv.(*ast.Ident); ok {
+		return ident.Name + "." + d.Name.Name
+	}
+	return d.Name.Name
+}
+
+// genDeclSymbol nomes declarados em type/var/const
+func genDeclSymbol(d *ast.GenDecl) string {
+	var names []string
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			names = append(names, s.Name.Name)
+		case *ast.ValueSpec:
+			for _, name := range s.Names {
+				names = append(names, name.Name)
+			}
+		}
+	}
+
+	if len(names) > 3 {
+		names = append(names[:3], "...")
+	}
+	return strings.Join(names, ", ")
+}
+
+// chunkMarkdown um trecho por seção (título + conteúdo até o próximo título)
+func chunkMarkdown(path, content string) []Chunk {
+	lines := strings.Split(content, "\n")
+	var chunks []Chunk
+
+	start := 0
+	heading := ""
+	inFence := false
+
+	flush := func(end int) {
+		if end <= start {
+			return
+		}
+		text := strings.Join(lines[start:end], "\n")
+		if end-start > maxDeclLines {
+			chunks = append(chunks, chunkLines(path, text, start+1, heading)...)
+			return
+		}
+		chunks = appendChunk(chunks, Chunk{Path: path, StartLine: start + 1, EndLine: end, Symbol: heading, Text: text})
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+		}
+		if inFence || !strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		flush(i)
+		start = i
+		heading = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+	}
+	flush(len(lines))
+
+	return chunks
+}
+
+// chunkLines janelas de linhas sobrepostas; firstLine é a linha de content no arquivo
+func chunkLines(path, content string, firstLine int, symbol string) []Chunk {
+	lines := strings.Split(content, "\n")
+	var chunks []Chunk
+
+	for start := 0; start < len(lines); start += windowStride {
+		end := start + windowLines
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		chunks = appendChunk(chunks, Chunk{
+			Path:      path,
+			StartLine: firstLine + start,
+			EndLine:   firstLine + end - 1,
+			Symbol:    symbol,
+			Text:      strings.Join(lines[start:end], "\n"),
+		})
+
+		if end == len(lines) {
+			break
+		}
+	}
+
+	return chunks
+}
+
+// appendChunk ignora trechos vazios
+func appendChunk(chunks []Chunk, chunk Chunk) []Chunk {
+	if strings.TrimSpace(chunk.Text) == "" {
+		return chunks
+	}
+	return append(chunks, chunk)
+}
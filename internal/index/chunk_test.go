@@ -0,0 +1,80 @@
+package index
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChunkFile_GoDeclarations(t *testing.T) {
+	content := `package demo
+
+import "fmt"
+
+// Greeter cumprimenta pessoas
+type Greeter struct{}
+
+// Hello diz olá
+func (g *Greeter) Hello(name string) string {
+	return fmt.Sprintf("olá %s", name)
+}
+
+func main() {}
+`
+	chunks := ChunkFile("demo.go", content)
+	if len(chunks) != 3 {
+		t.Fatalf("Expected 3 chunks (type, method, func), got %d: %+v", len(chunks), chunks)
+	}
+
+	if chunks[0].Symbol != "Greeter" || chunks[0].StartLine != 5 {
+		t.Errorf("Type chunk should start at its doc comment, got %+v", chunks[0])
+	}
+	if chunks[1].Symbol != "Greeter.Hello" || chunks[1].StartLine != 8 || chunks[1].EndLine != 11 {
+		t.Errorf("Unexpected method chunk: %+v", chunks[1])
+	}
+	if !strings.HasPrefix(chunks[1].Text, "// Hello diz olá") {
+		t.Errorf("Method chunk should include doc comment, got %q", chunks[1].Text)
+	}
+	if chunks[1].Location() != "demo.go:8-11" {
+		t.Errorf("Unexpected location: %s", chunks[1].Location())
+	}
+}
+
+func TestChunkFile_InvalidGoFallsBackToWindows(t *testing.T) {
+	chunks := ChunkFile("broken.go", "package x\nfunc {")
+	if len(chunks) != 1 || chunks[0].Symbol != "" || chunks[0].EndLine != 2 {
+		t.Errorf("Expected a single line window, got %+v", chunks)
+	}
+}
+
+func TestChunkFile_MarkdownSections(t *testing.T) {
+	content := "Intro\n# Instalação\npasso 1\n```sh\n# não é título\n```\n## Uso\nrode"
+	chunks := ChunkFile("README.md", content)
+
+	if len(chunks) != 3 {
+		t.Fatalf("Expected intro + 2 sections, got %d: %+v", len(chunks), chunks)
+	}
+	if chunks[1].Symbol != "Instalação" || chunks[1].StartLine != 2 || chunks[1].EndLine != 6 {
+		t.Errorf("Heading inside code fence should not split, got %+v", chunks[1])
+	}
+	if chunks[2].Symbol != "Uso" {
+		t.Errorf("Unexpected last section: %+v", chunks[2])
+	}
+}
+
+func TestChunkFile_LineWindowsOverlap(t *testing.T) {
+	lines := make([]string, 120)
+	for i := range lines {
+		lines[i] = "x = 1"
+	}
+
+	chunks := ChunkFile("script.py", strings.Join(lines, "\n"))
+	if len(chunks) != 3 {
+		t.Fatalf("Expected 3 windows, got %d", len(chunks))
+	}
+	if chunks[0].EndLine != windowLines || chunks[1].StartLine != windowStride+1 {
+		t.Errorf("Windows should overlap, got %+v / %+v", chunks[0].Location(), chunks[1].Location())
+	}
+	if chunks[2].EndLine != 120 {
+		t.Errorf("Last window should reach the end, got %s", chunks[2].Location())
+	}
+}
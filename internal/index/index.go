@@ -0,0 +1,495 @@
+// Package index mantém um índice semântico (embeddings) do código do projeto.
+//
+// Arquivos são divididos em trechos (funções, tipos, seções de Markdown),
+// cada trecho vira um vetor via llm.Embedder e o índice fica salvo em
+// <workdir>/.ollama-code/index. Atualizações são incrementais: só arquivos
+// com mtime/tamanho alterados são relidos, e só os com conteúdo novo são
+// enviados ao modelo de embeddings.
+package index
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
+
+const (
+	DefaultModel       = "nomic-embed-text" // Modelo de embeddings padrão
+	DefaultTopK        = 5
+	DefaultMaxFileSize = 256 * 1024
+	DefaultBatchSize   = 32
+	maxEmbeddingChars  = 6000 // Limite do texto enviado por trecho
+	indexVersion       = 1
+)
+
+// ErrEmptyIndex nenhum arquivo indexável encontrado
+var ErrEmptyIndex = errors.New("semantic index is empty")
+
+// indexedExtensions extensões de código e documentação indexadas
+var indexedExtensions = map[string]bool{
+	".go": true, ".py": true, ".js": true, ".jsx": true, ".ts": true, ".tsx": true,
+	".java": true, ".kt": true, ".rs": true, ".rb": true, ".php": true, ".cs": true,
+	".c": true, ".h": true, ".cpp": true, ".hpp": true, ".swift": true, ".scala": true,
+	".sh": true, ".sql": true, ".md": true, ".markdown": true, ".yaml": true, ".yml": true,
+	".toml": true, ".proto": true, ".graphql": true,
+}
+
+// skippedDirs diretórios nunca indexados
+var skippedDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "dist": true, "build": true,
+	"target": true, "bin": true, "__pycache__": true, "venv": true,
+}
+
+// Config configuração do índice
+type Config struct {
+	WorkDir     string
+	Model       string // Modelo de embeddings (padrão: DefaultModel)
+	Dir         string // Onde salvar (padrão: <WorkDir>/.ollama-code/index)
+	MaxFileSize int64  // Arquivos maiores são ignorados
+	BatchSize   int    // Trechos por requisição de embeddings
+}
+
+// Result trecho encontrado com a similaridade de cosseno
+type Result struct {
+	Chunk
+	Score float64
+}
+
+// UpdateStats resumo de uma atualização
+type UpdateStats struct {
+	Added     int // Arquivos novos
+	Updated   int // Arquivos com conteúdo alterado
+	Removed   int // Arquivos apagados
+	Unchanged int
+	Embedded  int // Trechos enviados ao modelo
+}
+
+// Changed indica se o índice foi alterado
+func (s UpdateStats) Changed() bool {
+	return s.Added+s.Updated+s.Removed > 0
+}
+
+// fileEntry estado indexado de um arquivo
+type fileEntry struct {
+	ModTime time.Time
+	Size    int64
+	Hash    string
+	Chunks  []Chunk
+}
+
+// snapshot formato salvo em disco
+type snapshot struct {
+	Version int
+	Model   string
+	Files   map[string]*fileEntry
+}
+
+// Index índice semântico do projeto
+type Index struct {
+	mu        sync.Mutex
+	cfg       Config
+	embedder  llm.Embedder
+	files     map[string]*fileEntry
+	loaded    bool
+	checkedAt time.Time   // Última tentativa de atualização via Refresh
+	warm      atomic.Bool // Alguma atualização completa terminou
+	updating  atomic.Bool // RefreshAsync em andamento
+}
+
+// New cria índice (carregado do disco na primeira atualização ou busca)
+func New(embedder llm.Embedder, cfg Config) *Index {
+	if cfg.Model == "" {
+		cfg.Model = DefaultModel
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = filepath.Join(cfg.WorkDir, ".ollama-code", "index")
+	}
+	if cfg.MaxFileSize == 0 {
+		cfg.MaxFileSize = DefaultMaxFileSize
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+
+	return &Index{
+		cfg:      cfg,
+		embedder: embedder,
+		files:    make(map[string]*fileEntry),
+	}
+}
+
+// Model retorna o modelo de embeddings usado
+func (i *Index) Model() string {
+	return i.cfg.Model
+}
+
+// path arquivo do índice (vetores em gob: JSON ficaria ~3x maior)
+func (i *Index) path() string {
+	return filepath.Join(i.cfg.Dir, "embeddings.gob")
+}
+
+// load carrega o índice salvo, descartando-o se foi gerado por outro modelo
+func (i *Index) load() error {
+	if i.loaded {
+		return nil
+	}
+	i.loaded = true
+
+	file, err := os.Open(i.path())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+	defer file.Close()
+
+	var snap snapshot
+	if err := gob.NewDecoder(file).Decode(&snap); err != nil {
+		// Índice corrompido é reconstruído do zero
+		return nil
+	}
+
+	if snap.Version != indexVersion || snap.Model != i.cfg.Model || snap.Files == nil {
+		return nil
+	}
+
+	i.files = snap.Files
+	return nil
+}
+
+// save grava o índice em disco
+func (i *Index) save() error {
+	if err := os.MkdirAll(i.cfg.Dir, 0755); err != nil {
+		return fmt.Errorf("create index dir: %w", err)
+	}
+
+	tmp := i.path() + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create index: %w", err)
+	}
+
+	snap := snapshot{Version: indexVersion, Model: i.cfg.Model, Files: i.files}
+	if err := gob.NewEncoder(file).Encode(&snap); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("encode index: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close index: %w", err)
+	}
+
+	return os.Rename(tmp, i.path())
+}
+
+// Update sincroniza o índice com os arquivos do diretório de trabalho
+func (i *Index) Update(ctx context.Context) (UpdateStats, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.update(ctx)
+}
+
+// Refresh atualiza o índice se a última tentativa tiver mais de maxAge
+// (falhas também contam, para não repetir a varredura a cada pergunta)
+func (i *Index) Refresh(ctx context.Context, maxAge time.Duration) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if !i.checkedAt.IsZero() && time.Since(i.checkedAt) < maxAge {
+		return nil
+	}
+	i.checkedAt = time.Now()
+
+	_, err := i.update(ctx)
+	return err
+}
+
+// RefreshAsync dispara Refresh numa goroutine, sem bloquear quem chama e com os
+// embeddings em PriorityBackground. Pedidos durante uma atualização são ignorados.
+func (i *Index) RefreshAsync(ctx context.Context, maxAge time.Duration) {
+	if !i.updating.CompareAndSwap(false, true) {
+		return
+	}
+
+	// A atualização continua depois que a pergunta que a disparou termina
+	ctx = llm.WithPriority(context.WithoutCancel(ctx), llm.PriorityBackground)
+	go func() {
+		defer i.updating.Store(false)
+		i.Refresh(ctx, maxAge)
+	}()
+}
+
+// Ready indica se o índice já foi construído e não há RefreshAsync em andamento
+// (uma busca nesse momento esperaria a varredura terminar)
+func (i *Index) Ready() bool {
+	return i.warm.Load() && !i.updating.Load()
+}
+
+func (i *Index) update(ctx context.Context) (UpdateStats, error) {
+	var stats UpdateStats
+
+	if err := i.load(); err != nil {
+		return stats, err
+	}
+
+	seen := make(map[string]bool)
+	var pending []string // Arquivos com conteúdo novo
+
+	err := filepath.WalkDir(i.cfg.WorkDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Ignora arquivos ilegíveis
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != i.cfg.WorkDir && (strings.HasPrefix(name, ".") || skippedDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !indexedExtensions[strings.ToLower(filepath.Ext(name))] {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil || info.Size() > i.cfg.MaxFileSize || info.Size() == 0 {
+			return nil
+		}
+
+		rel, err := filepath.Rel(i.cfg.WorkDir, path)
+		if err != nil {
+			return nil
+		}
+		seen[rel] = true
+
+		existing := i.files[rel]
+		if existing != nil && existing.ModTime.Equal(info.ModTime()) && existing.Size == info.Size() {
+			stats.Unchanged++
+			return nil
+		}
+
+		pending = append(pending, rel)
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	for rel := range i.files {
+		if !seen[rel] {
+			delete(i.files, rel)
+			stats.Removed++
+		}
+	}
+
+	var embedErr error
+	for _, rel := range pending {
+		if err := i.indexFile(ctx, rel, &stats); err != nil {
+			embedErr = err
+			break
+		}
+	}
+
+	// Salva o progresso mesmo se o modelo falhar no meio
+	if stats.Changed() {
+		if err := i.save(); err != nil {
+			return stats, err
+		}
+	}
+	if embedErr == nil {
+		i.warm.Store(true)
+	}
+	return stats, embedErr
+}
+
+// indexFile relê um arquivo e gera embeddings se o conteúdo mudou
+func (i *Index) indexFile(ctx context.Context, rel string, stats *UpdateStats) error {
+	path := filepath.Join(i.cfg.WorkDir, rel)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	existing := i.files[rel]
+	if existing != nil && existing.Hash == hash {
+		// Só o mtime mudou (checkout, touch): mantém os vetores
+		existing.ModTime = info.ModTime()
+		existing.Size = info.Size()
+		stats.Unchanged++
+		return nil
+	}
+
+	chunks := ChunkFile(rel, string(data))
+	if err := i.embedChunks(ctx, chunks); err != nil {
+		return err
+	}
+	stats.Embedded += len(chunks)
+
+	if existing == nil {
+		stats.Added++
+	} else {
+		stats.Updated++
+	}
+
+	i.files[rel] = &fileEntry{
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+		Hash:    hash,
+		Chunks:  chunks,
+	}
+	return nil
+}
+
+// embedChunks preenche os vetores dos trechos em lotes
+func (i *Index) embedChunks(ctx context.Context, chunks []Chunk) error {
+	for start := 0; start < len(chunks); start += i.cfg.BatchSize {
+		end := start + i.cfg.BatchSize
+		if end > len(chunks) {
+			end = len(chunks)
+		}
+
+		inputs := make([]string, 0, end-start)
+		for _, chunk := range chunks[start:end] {
+			inputs = append(inputs, chunk.embeddingInput(maxEmbeddingChars))
+		}
+
+		// Indexação cede a vez às requisições do turno no scheduler
+		vectors, err := i.embedder.Embed(llm.WithPriority(ctx, llm.PriorityBackground), i.cfg.Model, inputs)
+		if err != nil {
+			return fmt.Errorf("embed %s: %w", chunks[start].Path, err)
+		}
+
+		for j, vector := range vectors {
+			chunks[start+j].Vector = normalize(vector)
+		}
+	}
+	return nil
+}
+
+// Search retorna os k trechos mais similares à consulta
+func (i *Index) Search(ctx context.Context, query string, k int) ([]Result, error) {
+	if k <= 0 {
+		k = DefaultTopK
+	}
+
+	vectors, err := i.embedder.Embed(ctx, i.cfg.Model, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	queryVector := normalize(vectors[0])
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if err := i.load(); err != nil {
+		return nil, err
+	}
+	if len(i.files) == 0 {
+		return nil, ErrEmptyIndex
+	}
+
+	var results []Result
+	for _, entry := range i.files {
+		for _, chunk := range entry.Chunks {
+			if len(chunk.Vector) != len(queryVector) {
+				continue
+			}
+			results = append(results, Result{Chunk: chunk, Score: dot(queryVector, chunk.Vector)})
+		}
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].Location() < results[b].Location()
+	})
+
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// Stats retorna número de arquivos e trechos indexados
+func (i *Index) Stats() (files, chunks int) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.load()
+	for _, entry := range i.files {
+		chunks += len(entry.Chunks)
+	}
+	return len(i.files), chunks
+}
+
+// FormatResults formata resultados como contexto para o modelo
+func FormatResults(results []Result, maxChars int) string {
+	var b strings.Builder
+	for _, result := range results {
+		header := result.Location()
+		if result.Symbol != "" {
+			header += " (" + result.Symbol + ")"
+		}
+
+		block := fmt.Sprintf("// %s\n%s\n\n", header, strings.TrimSpace(result.Text))
+		if maxChars > 0 && b.Len()+len(block) > maxChars {
+			break
+		}
+		b.WriteString(block)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// normalize converte para float32 com norma 1 (busca vira produto escalar)
+func normalize(vector []float64) []float32 {
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+
+	out := make([]float32, len(vector))
+	if norm == 0 {
+		return out
+	}
+	for i, v := range vector {
+		out[i] = float32(v / norm)
+	}
+	return out
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
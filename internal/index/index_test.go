@@ -0,0 +1,171 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/llmtest"
+)
+
+// countingEmbedder conta quantos textos foram enviados ao modelo
+type countingEmbedder struct {
+	llm.Embedder
+	inputs int
+}
+
+func (c *countingEmbedder) Embed(ctx context.Context, model string, inputs []string) ([][]float64, error) {
+	c.inputs += len(inputs)
+	return c.Embedder.Embed(ctx, model, inputs)
+}
+
+func newTestIndex(t *testing.T, files map[string]string) (*Index, *countingEmbedder, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+
+	server := llmtest.NewServer(t)
+	embedder := &countingEmbedder{Embedder: llm.NewClient(server.URL, "test-model")}
+	return New(embedder, Config{WorkDir: dir}), embedder, dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndex_UpdateAndSearch(t *testing.T) {
+	idx, _, _ := newTestIndex(t, map[string]string{
+		"db/conn.go":      "package db\n\n// OpenDatabase abre conexão com o banco postgres\nfunc OpenDatabase() {}\n",
+		"http/server.go":  "package http\n\n// StartServer inicia servidor http na porta\nfunc StartServer() {}\n",
+		"docs/guide.md":   "# Deploy\nenvie a imagem docker para o cluster\n",
+		"assets/logo.png": "binário",
+		".git/config":     "[core]",
+	})
+
+	stats, err := idx.Update(context.Background())
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if stats.Added != 3 || stats.Embedded != 3 {
+		t.Errorf("Expected 3 indexed files, got %+v", stats)
+	}
+
+	results, err := idx.Search(context.Background(), "abre conexão com o banco postgres", 2)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Symbol != "OpenDatabase" {
+		t.Fatalf("Expected OpenDatabase first, got %+v", results)
+	}
+	if results[0].Score <= results[1].Score {
+		t.Errorf("Results should be sorted by score: %+v", results)
+	}
+
+	formatted := FormatResults(results[:1], 0)
+	if !strings.Contains(formatted, "db/conn.go:3-4 (OpenDatabase)") {
+		t.Errorf("Unexpected formatted results: %q", formatted)
+	}
+}
+
+func TestIndex_IncrementalUpdate(t *testing.T) {
+	idx, embedder, dir := newTestIndex(t, map[string]string{
+		"a.go": "package a\n\nfunc A() {}\n",
+		"b.go": "package a\n\nfunc B() {}\n",
+	})
+	ctx := context.Background()
+
+	if _, err := idx.Update(ctx); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	embedder.inputs = 0
+
+	// Sem mudanças: nada é reenviado ao modelo
+	stats, err := idx.Update(ctx)
+	if err != nil || stats.Changed() || embedder.inputs != 0 {
+		t.Fatalf("Expected no-op update, got %+v, %d embeds, %v", stats, embedder.inputs, err)
+	}
+
+	writeFile(t, filepath.Join(dir, "a.go"), "package a\n\nfunc A() {}\n\nfunc A2() {}\n")
+	os.Remove(filepath.Join(dir, "b.go"))
+
+	stats, err = idx.Update(ctx)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if stats.Updated != 1 || stats.Removed != 1 || embedder.inputs != 2 {
+		t.Errorf("Expected only a.go re-embedded and b.go removed, got %+v, %d embeds", stats, embedder.inputs)
+	}
+
+	// Um novo índice carrega do disco sem reenviar nada
+	reloaded := New(embedder, Config{WorkDir: dir})
+	embedder.inputs = 0
+	stats, err = reloaded.Update(ctx)
+	if err != nil || stats.Changed() || embedder.inputs != 0 {
+		t.Errorf("Persisted index should be reused, got %+v, %d embeds, %v", stats, embedder.inputs, err)
+	}
+	if files, chunks := reloaded.Stats(); files != 1 || chunks != 2 {
+		t.Errorf("Expected 1 file / 2 chunks, got %d / %d", files, chunks)
+	}
+}
+
+func TestIndex_ModelChangeRebuilds(t *testing.T) {
+	idx, embedder, dir := newTestIndex(t, map[string]string{"a.go": "package a\n\nfunc A() {}\n"})
+	if _, err := idx.Update(context.Background()); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	other := New(embedder, Config{WorkDir: dir, Model: "mxbai-embed-large"})
+	stats, err := other.Update(context.Background())
+	if err != nil || stats.Added != 1 {
+		t.Errorf("Index from another model should be rebuilt, got %+v, %v", stats, err)
+	}
+}
+
+func TestIndex_SearchEmpty(t *testing.T) {
+	idx, _, _ := newTestIndex(t, nil)
+
+	if _, err := idx.Search(context.Background(), "qualquer", 3); !errors.Is(err, ErrEmptyIndex) {
+		t.Errorf("Expected ErrEmptyIndex, got %v", err)
+	}
+}
+
+func TestIndex_RefreshAsync(t *testing.T) {
+	idx, _, _ := newTestIndex(t, map[string]string{
+		"db/conn.go": "package db\n\n// OpenDatabase abre conexão com o banco\nfunc OpenDatabase() {}\n",
+	})
+
+	if idx.Ready() {
+		t.Fatal("New index should be cold")
+	}
+
+	// Pergunta cancelada não interrompe a construção em segundo plano
+	ctx, cancel := context.WithCancel(context.Background())
+	idx.RefreshAsync(ctx, time.Minute)
+	cancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !idx.Ready() {
+		if time.Now().After(deadline) {
+			t.Fatal("Index should become ready after the background build")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if files, _ := idx.Stats(); files != 1 {
+		t.Errorf("Expected 1 indexed file, got %d", files)
+	}
+}
@@ -2,25 +2,57 @@ package intent
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/johnpitter/ollama-code/internal/ctxwindow"
 	"github.com/johnpitter/ollama-code/internal/llm"
 )
 
+// DefaultHistoryBudget tokens de histórico enviados na detecção de intenção
+const DefaultHistoryBudget = 1024
+
+// detectionRetries novas tentativas quando a resposta não segue o schema
+const detectionRetries = 1
+
+// detectionSchema schema de DetectionResult enviado no campo "format"
+var detectionSchema = llm.GenerateSchema(DetectionResult{})
+
+// maxHistoryMessageChars limite por mensagem para uma saída longa não ocupar todo o orçamento
+const maxHistoryMessageChars = 1000
+
 // Detector detecta intenções usando LLM
 type Detector struct {
-	llmClient *llm.Client
+	llmClient     llm.Provider
+	historyBudget int
 }
 
 // NewDetector cria novo detector
-func NewDetector(llmClient *llm.Client) *Detector {
+func NewDetector(llmClient llm.Provider) *Detector {
 	return &Detector{
-		llmClient: llmClient,
+		llmClient:     llmClient,
+		historyBudget: DefaultHistoryBudget,
 	}
 }
 
+// SetHistoryBudget define quantos tokens de histórico entram no prompt
+func (d *Detector) SetHistoryBudget(tokens int) {
+	if tokens > 0 {
+		d.historyBudget = tokens
+	}
+}
+
+// WithClient retorna cópia do detector usando outro client (ex: modelo leve do multi-model)
+func (d *Detector) WithClient(llmClient llm.Provider) *Detector {
+	if d == nil {
+		return nil
+	}
+	clone := *d
+	clone.llmClient = llmClient
+	return &clone
+}
+
 // Detect detecta a intenção de uma mensagem
 func (d *Detector) Detect(ctx context.Context, userMessage, currentDir string, recentFiles []string) (*DetectionResult, error) {
 	return d.DetectWithHistory(ctx, userMessage, currentDir, recentFiles, []llm.Message{})
@@ -34,28 +66,11 @@ func (d *Detector) DetectWithHistory(ctx context.Context, userMessage, currentDi
 		filesContext = strings.Join(recentFiles, ", ")
 	}
 
-	// Preparar contexto de conversa
+	// Preparar contexto de conversa dentro do orçamento de tokens
 	conversationContext := ""
-	if len(history) > 0 {
-		// Pegar últimas 4 mensagens (2 trocas) para contexto
-		startIdx := len(history) - 4
-		if startIdx < 0 {
-			startIdx = 0
-		}
-
-		conversationContext = "\n\nHistórico recente da conversa:"
-		for i := startIdx; i < len(history); i++ {
-			role := "Usuário"
-			if history[i].Role == "assistant" {
-				role = "Assistente"
-			}
-			// Truncar mensagens muito longas
-			content := history[i].Content
-			if len(content) > 200 {
-				content = content[:200] + "..."
-			}
-			conversationContext += fmt.Sprintf("\n%s: %s", role, content)
-		}
+	if recent := d.recentHistory(history); len(recent) > 0 {
+		conversationContext = "\n\nHistórico recente da conversa:\n" +
+			strings.TrimRight(ctxwindow.FormatTranscript(recent, 0), "\n")
 	}
 
 	// Criar prompt do usuário
@@ -70,20 +85,15 @@ func (d *Detector) DetectWithHistory(ctx context.Context, userMessage, currentDi
 	}
 
 	opts := &llm.CompletionOptions{
-		Temperature:  0.1, // Baixa temperatura para respostas consistentes
+		Temperature:  llm.Float64(0), // Temperatura 0: classificação determinística (e cacheável)
 		MaxTokens:    500,
 		SystemPrompt: SystemPrompt,
 	}
 
-	response, err := d.llmClient.Complete(ctx, messages, opts)
-	if err != nil {
-		return nil, fmt.Errorf("llm completion: %w", err)
-	}
-
-	// Parse JSON response
-	result, err := d.parseResponse(response)
-	if err != nil {
-		// Se falhar parsing, retornar intenção de pergunta como fallback
+	var result DetectionResult
+	err := d.llmClient.CompleteStructured(ctx, messages, opts, detectionSchema, &result, detectionRetries)
+	if errors.Is(err, llm.ErrInvalidStructuredOutput) {
+		// Se o modelo não seguir o schema, retornar intenção de pergunta como fallback
 		return &DetectionResult{
 			Intent:     IntentQuestion,
 			Confidence: 0.5,
@@ -91,25 +101,33 @@ func (d *Detector) DetectWithHistory(ctx context.Context, userMessage, currentDi
 			Reasoning:  "Fallback: não foi possível detectar intenção específica",
 		}, nil
 	}
+	if err != nil {
+		return nil, fmt.Errorf("llm completion: %w", err)
+	}
 
-	return result, nil
-}
+	if result.Parameters == nil {
+		result.Parameters = map[string]interface{}{}
+	}
 
-// parseResponse faz parse da resposta JSON
-func (d *Detector) parseResponse(response string) (*DetectionResult, error) {
-	// Limpar possível markdown
-	response = strings.TrimSpace(response)
-	response = strings.TrimPrefix(response, "```json")
-	response = strings.TrimPrefix(response, "```")
-	response = strings.TrimSuffix(response, "```")
-	response = strings.TrimSpace(response)
+	return &result, nil
+}
 
-	var result DetectionResult
-	if err := json.Unmarshal([]byte(response), &result); err != nil {
-		return nil, fmt.Errorf("parse json: %w", err)
+// recentHistory mensagens mais recentes que cabem no orçamento de histórico
+func (d *Detector) recentHistory(history []llm.Message) []llm.Message {
+	truncated := make([]llm.Message, 0, len(history))
+	for _, msg := range history {
+		if msg.Content == "" {
+			continue
+		}
+		if runes := []rune(msg.Content); len(runes) > maxHistoryMessageChars {
+			msg.Content = string(runes[:maxHistoryMessageChars]) + "..."
+		}
+		msg.ToolCalls = nil
+		msg.Images = nil // Detecção usa modelo de texto
+		truncated = append(truncated, msg)
 	}
 
-	return &result, nil
+	return ctxwindow.TrimToBudget(truncated, d.historyBudget)
 }
 
 // DetectSimple detecta intenção de forma simplificada (sem contexto)
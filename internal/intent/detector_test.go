@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/johnpitter/ollama-code/internal/llm"
@@ -23,6 +24,20 @@ func TestNewDetector(t *testing.T) {
 	}
 }
 
+func TestDetector_WithClient(t *testing.T) {
+	detector := NewDetector(llm.NewClient("http://localhost:11434", "main-model"))
+	detector.SetHistoryBudget(2048)
+
+	fast := detector.WithClient(llm.NewClient("http://localhost:11434", "fast-model"))
+
+	if fast.llmClient.GetModel() != "fast-model" || fast.historyBudget != 2048 {
+		t.Errorf("Clone should use the new client and keep settings, got %s/%d", fast.llmClient.GetModel(), fast.historyBudget)
+	}
+	if detector.llmClient.GetModel() != "main-model" {
+		t.Error("Original detector should keep its client")
+	}
+}
+
 func TestDetect_ReadFile(t *testing.T) {
 	// Mock server that returns read_file intent
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -313,68 +328,87 @@ func TestDetect_InvalidJSON_Fallback(t *testing.T) {
 	}
 }
 
-func TestParseResponse_WithMarkdown(t *testing.T) {
-	client := llm.NewClient("http://localhost:11434", "test-model")
-	detector := NewDetector(client)
-
-	// Response wrapped in markdown code block
-	response := "```json\n{\n  \"intent\": \"read_file\",\n  \"confidence\": 0.95,\n  \"parameters\": {\"file_path\": \"test.txt\"},\n  \"reasoning\": \"Test\"\n}\n```"
-
-	result, err := detector.parseResponse(response)
-
-	if err != nil {
-		t.Fatalf("parseResponse failed: %v", err)
-	}
+// Helper function
+func contains(s, substr string) bool {
+	return len(s) > 0 && len(substr) > 0 && (s == substr || len(s) >= len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || findSubstring(s, substr)))
+}
 
-	if result.Intent != IntentReadFile {
-		t.Errorf("Expected intent 'read_file', got '%s'", result.Intent)
+func findSubstring(s, substr string) bool {
+	for i := 0; i <= len(s)-len(substr); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
 	}
+	return false
 }
 
-func TestParseResponse_PlainJSON(t *testing.T) {
-	client := llm.NewClient("http://localhost:11434", "test-model")
-	detector := NewDetector(client)
+func TestRecentHistory_RespectsTokenBudget(t *testing.T) {
+	detector := NewDetector(llm.NewClient("http://localhost:11434", "test-model"))
+	detector.SetHistoryBudget(100)
 
-	response := `{"intent": "question", "confidence": 1.0, "parameters": {}, "reasoning": "Test"}`
+	history := []llm.Message{
+		{Role: "user", Content: "mensagem antiga"},
+		{Role: "assistant", Content: "resposta antiga"},
+		llm.NewToolResultMessage("file_reader", strings.Repeat("x", 5000)),
+		{Role: "assistant", ToolCalls: []llm.ToolCall{{Function: llm.ToolCallFunction{Name: "file_reader"}}}},
+		{Role: "user", Content: "mensagem recente"},
+	}
 
-	result, err := detector.parseResponse(response)
+	recent := detector.recentHistory(history)
 
-	if err != nil {
-		t.Fatalf("parseResponse failed: %v", err)
+	if len(recent) == 0 || recent[len(recent)-1].Content != "mensagem recente" {
+		t.Fatalf("Most recent message should be kept, got %+v", recent)
 	}
 
-	if result.Intent != IntentQuestion {
-		t.Errorf("Expected intent 'question', got '%s'", result.Intent)
+	for _, msg := range recent {
+		if msg.Content == "" {
+			t.Error("Messages without content should be skipped")
+		}
+		if len(msg.Content) > maxHistoryMessageChars+3 {
+			t.Errorf("Long message should be truncated, got %d chars", len(msg.Content))
+		}
 	}
 
-	if result.Confidence != 1.0 {
-		t.Errorf("Expected confidence 1.0, got %.2f", result.Confidence)
+	if recent[0].Content == "mensagem antiga" {
+		t.Error("Old messages beyond the budget should be dropped")
 	}
 }
 
-func TestParseResponse_InvalidJSON(t *testing.T) {
-	client := llm.NewClient("http://localhost:11434", "test-model")
-	detector := NewDetector(client)
+func TestDetect_RetriesInvalidOutputAgainstSchema(t *testing.T) {
+	var requests []llm.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+
+		content := `{"intent": "read", "confidence": 0.9, "parameters": {}}`
+		if len(requests) > 1 {
+			content = `{"intent": "read_file", "confidence": 0.9, "parameters": {"file_path": "a.go"}}`
+		}
 
-	response := "This is not JSON at all"
+		json.NewEncoder(w).Encode(llm.Response{
+			Message: llm.Message{Content: content},
+			Done:    true,
+		})
+	}))
+	defer server.Close()
 
-	_, err := detector.parseResponse(response)
+	detector := NewDetector(llm.NewClient(server.URL, "test-model"))
 
-	if err == nil {
-		t.Fatal("Expected error for invalid JSON")
+	result, err := detector.Detect(context.Background(), "leia a.go", "/tmp", []string{})
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
 	}
-}
 
-// Helper function
-func contains(s, substr string) bool {
-	return len(s) > 0 && len(substr) > 0 && (s == substr || len(s) >= len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || findSubstring(s, substr)))
-}
+	if len(requests) != 2 {
+		t.Fatalf("Expected a retry after invalid intent, got %d requests", len(requests))
+	}
 
-func findSubstring(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
+	if !strings.Contains(string(requests[0].Format), `"enum"`) {
+		t.Errorf("Expected intent schema in format, got %s", requests[0].Format)
+	}
+
+	if result.Intent != IntentReadFile {
+		t.Errorf("Expected read_file after retry, got %s", result.Intent)
 	}
-	return false
 }
@@ -20,30 +20,41 @@ INTENÇÕES DISPONÍVEIS:
 
    IMPORTANTE: Verbos de ANÁLISE (analisa, explica, revisa, examina, review) + arquivo específico = read_file!
 
-2. write_file - Usuário quer criar, desenvolver, gerar, editar ou refatorar código/arquivo
+2. write_file - Usuário quer criar, desenvolver ou gerar código/arquivo novo, ou reescrever código por inteiro
    Exemplos:
    - "crie um arquivo test.go"
-   - "adicione logging no main.go"
-   - "corrija o bug no handler.go"
    - "desenvolve um site usando HTML"
    - "cria uma landing page"
    - "faz um script python"
    - "gera um componente React"
    - "escreve uma API REST"
    - "constrói uma aplicação"
-   - "refatora a função cleanCodeContent para ser mais eficiente" (REFATORAÇÃO)
+   - "refatora a função cleanCodeContent para ser mais eficiente" (REFATORAÇÃO sem arquivo específico)
    - "otimiza o código do projeto"
    - "melhora a performance da função X"
 
    IMPORTANTE:
    - CRIAR/DESENVOLVER/FAZER/GERAR código → write_file (NÃO web_search!)
-   - REFATORAR/OTIMIZAR/MELHORAR código existente → write_file (vai ler e reescrever)
+   - REFATORAR/OTIMIZAR/MELHORAR código sem arquivo específico → write_file (vai ler e reescrever)
    - Mas apenas ANALISAR/EXPLICAR/REVISAR código → read_file!
 
-3. execute_command - Usuário quer executar comando shell
+3. edit_file - Usuário quer alterar um TRECHO de um arquivo que já existe
+   Exemplos:
+   - "adicione logging no main.go"
+   - "corrija o bug no handler.go"
+   - "no main.go, troque a porta 8080 por 9090"
+   - "renomeia a variável cfg para config em server.go"
+   - "remove a função debugPrint de utils.go"
+   - "refatora a função parseArgs em cli.go"
+
+   IMPORTANTE:
+   - Verbo de MODIFICAÇÃO + arquivo existente específico → edit_file (altera só o trecho, NÃO reescreve o arquivo)
+   - Criar arquivo novo ou reescrever o arquivo inteiro → write_file
+
+4. execute_command - Usuário quer executar comando shell
    Exemplos: "rode os testes", "execute npm install", "faça build do projeto"
 
-4. search_code - Usuário quer buscar/localizar código no projeto existente
+5. search_code - Usuário quer buscar/localizar código no projeto existente
    Exemplos:
    - "onde está a função processUser"
    - "onde está a struct User"
@@ -53,13 +64,13 @@ INTENÇÕES DISPONÍVEIS:
 
    IMPORTANTE: "onde está X" = search_code (NÃO read_file!)
 
-5. analyze_project - Usuário quer entender estrutura do projeto
+6. analyze_project - Usuário quer entender estrutura do projeto
    Exemplos: "qual a estrutura do projeto", "quais arquivos temos", "me mostre a arquitetura"
 
-6. git_operation - Usuário quer fazer operação git
+7. git_operation - Usuário quer fazer operação git
    Exemplos: "commita essas mudanças", "crie uma branch", "mostra o diff"
 
-7. web_search - Usuário pede EXPLICITAMENTE para pesquisar/buscar INFORMAÇÕES na internet
+8. web_search - Usuário pede EXPLICITAMENTE para pesquisar/buscar INFORMAÇÕES na internet
    Exemplos:
    - "pesquise informações sobre React"
    - "busque documentação da API X"
@@ -76,7 +87,7 @@ INTENÇÕES DISPONÍVEIS:
 
    NÃO É web_search se usuário pede para CRIAR código! Isso é write_file.
 
-8. question - Apenas pergunta conceitual, sem ação específica OU mensagens de cortesia/sociais
+9. question - Apenas pergunta conceitual, sem ação específica OU mensagens de cortesia/sociais
    Exemplos:
    - Conceituais: "o que é REST", "como funciona async/await", "explique closures"
    - Cortesia/Sociais: "oi", "olá", "tudo bem", "obrigado", "valeu", "tchau", "até logo"
@@ -95,13 +106,14 @@ REGRAS DE PRIORIDADE:
    - EXEMPLOS: "quanto foi o placar do Sport", "resultado do Náutico", "clima em SP" → web_search
 
 2. Se usuário usa verbos de ANÁLISE (analisa, explica, revisa, examina, review) + arquivo específico → read_file
-3. Se usuário usa verbos de MODIFICAÇÃO (refatora, otimiza, melhora, corrige, fix, debug) + arquivo específico → write_file
+3. Se usuário usa verbos de MODIFICAÇÃO (adiciona, corrige, troca, renomeia, remove, refatora, fix) + arquivo existente específico → edit_file
 4. Se usuário usa verbos de CRIAÇÃO (criar, desenvolver, fazer, gerar, construir, escrever, implementar) + tecnologia → write_file
 5. Se usuário pede EXPLICITAMENTE para BUSCAR/PESQUISAR informações na internet → web_search
 6. Se usuário faz pergunta conceitual SEM pedir criação → question
 7. Em caso de dúvida entre análise e modificação:
    - "analisa/explica/revisa X" → read_file (apenas ler e explicar)
-   - "refatora/otimiza/corrige X" → write_file (ler e modificar)
+   - "corrige/troca/refatora X em arquivo.go" → edit_file (alterar só o trecho)
+   - "refatora/otimiza X" sem arquivo específico → write_file (ler e reescrever)
    - "encontra bugs em X" → read_file (apenas analisar, não corrigir)
 
 RESPONDA SEMPRE NO FORMATO JSON:
@@ -133,14 +145,16 @@ ATENÇÃO - REGRAS DE CLASSIFICAÇÃO:
 - CORTESIA/SAUDAÇÃO (< 15 palavras): "oi", "olá", "obrigado", "valeu", "tchau" → question
 - DADOS EM TEMPO REAL: palavras "placar", "resultado", "jogo", "clima", "temperatura" → web_search
 - Verbos de ANÁLISE (analisa, explica, revisa, review, examina) + arquivo → read_file
-- Verbos de MODIFICAÇÃO (refatora, otimiza, corrige, melhora, fix, debug) + arquivo → write_file
+- Verbos de MODIFICAÇÃO (adiciona, corrige, troca, renomeia, remove, refatora) + arquivo existente → edit_file
+- REFATORAÇÃO/OTIMIZAÇÃO sem arquivo específico → write_file
 - Verbos de CRIAÇÃO (cria, desenvolve, faz, gera, constrói) + tecnologia → write_file
 - EXPLÍCITA busca de informações na internet (temperatura, notícias, documentação) → web_search
 - Pergunta conceitual sem ação → question
 
 EXEMPLOS IMPORTANTES:
 - "analisa a função X" → read_file (apenas ler e explicar)
-- "refatora a função X" → write_file (ler e modificar)
+- "refatora a função X" → write_file (ler e reescrever)
+- "corrige o bug em handler.go" → edit_file (alterar só o trecho)
 - "faz code review de Y" → read_file (apenas revisar)
 - "encontra e corrige bugs" → write_file (modificar)
 - "encontra bugs" → read_file (apenas analisar)
@@ -7,9 +7,12 @@ const (
 	// IntentReadFile ler arquivo(s)
 	IntentReadFile Intent = "read_file"
 
-	// IntentWriteFile criar/editar arquivo
+	// IntentWriteFile criar/reescrever arquivo
 	IntentWriteFile Intent = "write_file"
 
+	// IntentEditFile alterar trecho de arquivo existente
+	IntentEditFile Intent = "edit_file"
+
 	// IntentExecuteCommand executar comando shell
 	IntentExecuteCommand Intent = "execute_command"
 
@@ -32,11 +35,12 @@ const (
 	IntentUnknown Intent = "unknown"
 )
 
-// DetectionResult resultado da detecção
+// DetectionResult resultado da detecção.
+// As tags enum/description alimentam o schema enviado no campo "format" do Ollama.
 type DetectionResult struct {
-	Intent      Intent                 `json:"intent"`
-	Confidence  float64                `json:"confidence"`
+	Intent      Intent                 `json:"intent" enum:"read_file,write_file,edit_file,execute_command,search_code,analyze_project,git_operation,web_search,question"`
+	Confidence  float64                `json:"confidence" description:"0.0 a 1.0"`
 	Parameters  map[string]interface{} `json:"parameters"`
-	Reasoning   string                 `json:"reasoning"`
-	UserMessage string                 `json:"user_message,omitempty"`
+	Reasoning   string                 `json:"reasoning,omitempty"`
+	UserMessage string                 `json:"user_message,omitempty" schema:"-"`
 }
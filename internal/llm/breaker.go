@@ -0,0 +1,119 @@
+package llm
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen servidor marcado como indisponível após falhas consecutivas
+var ErrCircuitOpen = errors.New("circuit breaker open: llm server unavailable")
+
+// CircuitState estado do circuit breaker
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // Requisições liberadas
+	CircuitOpen     CircuitState = "open"      // Falha rápida até o fim do cool-down
+	CircuitHalfOpen CircuitState = "half-open" // Uma requisição de teste liberada
+)
+
+// CircuitBreaker abre o circuito após falhas consecutivas e falha rápido
+// até o cool-down terminar; depois libera uma requisição de teste.
+// Um breaker nil libera todas as requisições.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	now       func() time.Time
+}
+
+// NewCircuitBreaker cria novo circuit breaker (threshold <= 0 desativa)
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     CircuitClosed,
+		now:       time.Now,
+	}
+}
+
+// Allow retorna ErrCircuitOpen se a requisição não deve ser feita
+func (b *CircuitBreaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.openedAt = b.now()
+		return nil
+	case CircuitHalfOpen:
+		// Requisição de teste em andamento; outra só se a anterior não terminou no cool-down
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.openedAt = b.now()
+		return nil
+	default:
+		return nil
+	}
+}
+
+// RecordSuccess fecha o circuito e zera as falhas
+func (b *CircuitBreaker) RecordSuccess() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = CircuitClosed
+	b.failures = 0
+}
+
+// RecordFailure registra falha e abre o circuito ao atingir o limite
+func (b *CircuitBreaker) RecordFailure() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// State retorna o estado atual
+func (b *CircuitBreaker) State() CircuitState {
+	if b == nil {
+		return CircuitClosed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
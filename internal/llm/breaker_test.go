@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_OpensAndRecovers(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(3, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		breaker.RecordFailure()
+	}
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Breaker should stay closed below the threshold: %v", err)
+	}
+
+	breaker.RecordFailure()
+	if !errors.Is(breaker.Allow(), ErrCircuitOpen) {
+		t.Fatal("Breaker should open at the threshold")
+	}
+	if breaker.State() != CircuitOpen {
+		t.Errorf("Expected open, got %s", breaker.State())
+	}
+
+	// Após o cool-down apenas uma requisição de teste é liberada
+	now = now.Add(time.Minute)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Breaker should allow a probe after cool-down: %v", err)
+	}
+	if !errors.Is(breaker.Allow(), ErrCircuitOpen) {
+		t.Error("Only one probe should be allowed while half-open")
+	}
+
+	breaker.RecordSuccess()
+	if breaker.State() != CircuitClosed || breaker.Allow() != nil {
+		t.Error("Successful probe should close the breaker")
+	}
+}
+
+func TestCircuitBreaker_FailedProbeReopens(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(1, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	breaker.RecordFailure()
+	now = now.Add(time.Minute)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Expected probe to be allowed: %v", err)
+	}
+
+	breaker.RecordFailure()
+	if !errors.Is(breaker.Allow(), ErrCircuitOpen) {
+		t.Error("Failed probe should reopen the breaker")
+	}
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	breaker := NewCircuitBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		breaker.RecordFailure()
+	}
+	if err := breaker.Allow(); err != nil || breaker.State() != CircuitClosed {
+		t.Error("Disabled breaker should always allow requests")
+	}
+}
@@ -4,154 +4,287 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
-	"time"
 )
 
+// ErrToolsNotSupported modelo não aceita o campo "tools" em /api/chat
+var ErrToolsNotSupported = errors.New("model does not support tools")
+
 // Client cliente para Ollama API
 type Client struct {
-	baseURL    string
-	model      string
-	httpClient *http.Client
+	*transport // Timeouts, retry e circuit breaker
+
+	baseURL  string
+	model    string
+	defaults CompletionOptions // Opções aplicadas a toda requisição
+	onUsage  func(model string, usage Usage)
 }
 
 // NewClient cria novo cliente Ollama
 func NewClient(baseURL, model string) *Client {
 	return &Client{
-		baseURL: baseURL,
-		model:   model,
-		httpClient: &http.Client{
-			Timeout: 300 * time.Second,
-		},
+		transport: newTransport(DefaultTransportConfig()),
+		baseURL:   baseURL,
+		model:     model,
 	}
 }
 
+// SetDefaultOptions define opções aplicadas a toda requisição.
+// Opções passadas na chamada sobrescrevem os campos não-zero.
+func (c *Client) SetDefaultOptions(opts CompletionOptions) {
+	c.defaults = opts
+}
+
+// DefaultOptions retorna as opções padrão do cliente
+func (c *Client) DefaultOptions() CompletionOptions {
+	return c.defaults
+}
+
+// WithOptions retorna cópia do cliente (mesma conexão e hooks) com outras opções padrão
+func (c *Client) WithOptions(opts CompletionOptions) Provider {
+	clone := *c
+	clone.defaults = c.defaults.Merge(&opts)
+	return &clone
+}
+
+// SetUsageHook define callback chamado com o uso de tokens de cada resposta
+func (c *Client) SetUsageHook(fn func(model string, usage Usage)) {
+	c.onUsage = fn
+}
+
 // Complete faz uma chamada completa (não streaming)
-func (c *Client) Complete(ctx context.Context, messages []Message, opts *CompletionOptions) (string, error) {
-	reqOpts := Options{}
-	if opts != nil {
-		reqOpts.Temperature = opts.Temperature
-		reqOpts.NumPredict = opts.MaxTokens
+func (c *Client) Complete(ctx context.Context, messages []Message, opts *CompletionOptions) (string, Usage, error) {
+	response, err := c.Chat(ctx, messages, opts)
+	if err != nil {
+		return "", Usage{}, err
 	}
 
-	// Adicionar system prompt se fornecido
-	if opts != nil && opts.SystemPrompt != "" {
-		messages = append([]Message{{
-			Role:    "system",
-			Content: opts.SystemPrompt,
-		}}, messages...)
+	return response.Message.Content, response.Usage, nil
+}
+
+// CompleteStreaming faz chamada com streaming
+func (c *Client) CompleteStreaming(ctx context.Context, messages []Message, opts *CompletionOptions, onChunk func(string)) (string, Usage, error) {
+	response, err := c.ChatStream(ctx, messages, opts, onChunk)
+	if err != nil {
+		return "", Usage{}, err
 	}
 
-	req := Request{
-		Model:    c.model,
-		Messages: messages,
-		Stream:   false,
-		Options:  reqOpts,
+	return response.Message.Content, response.Usage, nil
+}
+
+// Chat faz chamada não streaming e retorna a resposta completa (conteúdo e tool calls)
+func (c *Client) Chat(ctx context.Context, messages []Message, opts *CompletionOptions) (*Response, error) {
+	req := c.buildRequest(messages, opts, false)
+	cacheKey, cached := c.cachedResponse(ctx, req, req.Options.Temperature, req.Options.Seed, len(req.Tools) > 0)
+	if cached != nil {
+		return cached, nil
 	}
 
-	jsonData, err := json.Marshal(req)
+	resp, err := c.doChat(ctx, req, c.defaults.Merge(opts).Fallbacks)
 	if err != nil {
-		return "", fmt.Errorf("marshal request: %w", err)
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/chat", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+	var response Response
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
+	response.Message = separateThinking(response.Message)
+
+	c.reportUsage(&response)
+	c.storeResponse(cacheKey, req.Model, &response)
+
+	return &response, nil
+}
 
-	resp, err := c.httpClient.Do(httpReq)
+// ChatStream faz chamada com streaming e agrega os chunks em uma única resposta
+func (c *Client) ChatStream(ctx context.Context, messages []Message, opts *CompletionOptions, onChunk func(string)) (*Response, error) {
+	resp, err := c.doChat(ctx, c.buildRequest(messages, opts, true), c.defaults.Merge(opts).Fallbacks)
 	if err != nil {
-		return "", fmt.Errorf("do request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	var fullResponse, fullThinking strings.Builder
+	var toolCalls []ToolCall
+	final := &Response{}
+	decoder := json.NewDecoder(resp.Body)
+
+	// Raciocínio chega no campo "thinking" (think=true) ou em tags <think> no conteúdo
+	var onThinking func(string)
+	if opts != nil {
+		onThinking = opts.OnThinking
 	}
+	emit := func(content, thinking string) {
+		if thinking != "" {
+			fullThinking.WriteString(thinking)
+			if onThinking != nil {
+				onThinking(thinking)
+			}
+		}
+		if content != "" {
+			fullResponse.WriteString(content)
+			if onChunk != nil {
+				onChunk(content)
+			}
+		}
+	}
+	var splitter thinkingStream
 
-	var response Response
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("decode response: %w", err)
+	for {
+		var response Response
+		if err := decoder.Decode(&response); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("decode response: %w", err)
+		}
+
+		emit("", response.Message.Thinking)
+		emit(splitter.Write(response.Message.Content))
+
+		// Ollama envia tool calls em um chunk próprio
+		toolCalls = append(toolCalls, response.Message.ToolCalls...)
+
+		final.Model = response.Model
+		final.CreatedAt = response.CreatedAt
+
+		if response.Done {
+			final.Done = true
+			final.Usage = response.Usage
+			break
+		}
 	}
 
-	return response.Message.Content, nil
+	emit(splitter.Flush())
+
+	final.Message = separateThinking(Message{
+		Role:      "assistant",
+		Content:   fullResponse.String(),
+		Thinking:  fullThinking.String(),
+		ToolCalls: toolCalls,
+	})
+
+	c.reportUsage(final)
+
+	return final, nil
 }
 
-// CompleteStreaming faz chamada com streaming
-func (c *Client) CompleteStreaming(ctx context.Context, messages []Message, opts *CompletionOptions, onChunk func(string)) (string, error) {
-	reqOpts := Options{}
-	if opts != nil {
-		reqOpts.Temperature = opts.Temperature
-		reqOpts.NumPredict = opts.MaxTokens
+// reportUsage repassa o uso da resposta para o hook configurado
+func (c *Client) reportUsage(response *Response) {
+	if c.onUsage == nil {
+		return
 	}
 
+	model := response.Model
+	if model == "" {
+		model = c.model
+	}
+
+	c.onUsage(model, response.Usage)
+}
+
+// buildRequest monta a requisição para /api/chat
+func (c *Client) buildRequest(messages []Message, opts *CompletionOptions, stream bool) Request {
+	merged := c.defaults.Merge(opts)
+
 	// Adicionar system prompt se fornecido
-	if opts != nil && opts.SystemPrompt != "" {
+	if merged.SystemPrompt != "" {
 		messages = append([]Message{{
 			Role:    "system",
-			Content: opts.SystemPrompt,
+			Content: merged.SystemPrompt,
 		}}, messages...)
 	}
 
-	req := Request{
-		Model:    c.model,
-		Messages: messages,
-		Stream:   true,
-		Options:  reqOpts,
+	return Request{
+		Model:     c.model,
+		Messages:  messages,
+		Stream:    stream,
+		Tools:     merged.Tools,
+		Format:    merged.Format,
+		KeepAlive: merged.KeepAlive,
+		Think:     merged.Think,
+		Options: Options{
+			Temperature:   merged.Temperature,
+			NumPredict:    merged.MaxTokens,
+			TopK:          merged.TopK,
+			TopP:          merged.TopP,
+			NumCtx:        merged.NumCtx,
+			Seed:          merged.Seed,
+			Stop:          merged.Stop,
+			RepeatPenalty: merged.RepeatPenalty,
+			NumGPU:        merged.NumGPU,
+		},
 	}
+}
 
-	jsonData, err := json.Marshal(req)
-	if err != nil {
-		return "", fmt.Errorf("marshal request: %w", err)
-	}
+// doChat envia a requisição ao modelo ou, se ele estiver indisponível, aos fallbacks
+func (c *Client) doChat(ctx context.Context, req Request, fallbacks []string) (*http.Response, error) {
+	return c.withFallback(req.Model, fallbacks, func(model string) (*http.Response, error) {
+		req.Model = model
+		return c.sendChat(ctx, req)
+	})
+}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/chat", bytes.NewBuffer(jsonData))
+// sendChat envia a requisição (com retry de falhas transitórias) e valida o status HTTP
+func (c *Client) sendChat(ctx context.Context, req Request) (*http.Response, error) {
+	jsonData, err := json.Marshal(req)
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("marshal request: %w", err)
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(httpReq)
+	// Espera a vaga no scheduler, que fica ocupada até o fim da resposta
+	done, err := c.schedule(ctx, req.Model)
 	if err != nil {
-		return "", fmt.Errorf("do request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
-	var fullResponse strings.Builder
-	decoder := json.NewDecoder(resp.Body)
-
-	for {
-		var response Response
-		if err := decoder.Decode(&response); err != nil {
-			if err == io.EOF {
-				break
+	// Com pool de hosts, cada tentativa escolhe o host de novo. Só o host que não
+	// aceitou a conexão fica fora até o próximo health check; ocupado, carregando
+	// modelo ou lento apenas devolve a vaga e o pool escolhe pela carga
+	var lease *HostLease
+	resp, err := c.do(ctx, req.Model, func(ctx context.Context) (*http.Request, error) {
+		if lease != nil {
+			if previousRetryReason(ctx) == RetryReasonConnection {
+				lease.Fail(fmt.Errorf("request to %s failed", lease.URL))
+			} else {
+				lease.Release()
 			}
-			return "", fmt.Errorf("decode response: %w", err)
 		}
+		lease = c.acquireHost(ctx, c.baseURL, req.Model, req.Messages)
 
-		// Chamar callback com chunk
-		if onChunk != nil && response.Message.Content != "" {
-			onChunk(response.Message.Content)
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", lease.URL+"/api/chat", bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
 		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		return httpReq, nil
+	})
+	if err != nil {
+		lease.Release()
+		done()
+		return nil, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: func() {
+		lease.Release()
+		done()
+	}}
 
-		fullResponse.WriteString(response.Message.Content)
-
-		if response.Done {
-			break
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusBadRequest && strings.Contains(string(body), "does not support tools") {
+			return nil, fmt.Errorf("%w: unexpected status %d: %s", ErrToolsNotSupported, resp.StatusCode, string(body))
 		}
+		return nil, statusError(resp.StatusCode, body)
 	}
 
-	return fullResponse.String(), nil
+	return resp, nil
 }
 
 // GetModel retorna o modelo configurado
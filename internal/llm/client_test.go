@@ -3,6 +3,7 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -25,12 +26,17 @@ func TestNewClient(t *testing.T) {
 		t.Errorf("Expected model 'qwen2.5-coder:7b', got '%s'", client.model)
 	}
 
-	if client.httpClient == nil {
-		t.Error("HTTP client should not be nil")
+	if client.transport == nil || client.httpClient == nil {
+		t.Fatal("HTTP transport should not be nil")
 	}
 
-	if client.httpClient.Timeout != 300*time.Second {
-		t.Errorf("Expected timeout 300s, got %v", client.httpClient.Timeout)
+	// Timeouts por fase substituem o timeout global do http.Client
+	if client.httpClient.Timeout != 0 {
+		t.Errorf("Expected no global timeout, got %v", client.httpClient.Timeout)
+	}
+
+	if got := client.TransportConfig().Timeouts.FirstToken; got != 300*time.Second {
+		t.Errorf("Expected first token timeout 300s, got %v", got)
 	}
 }
 
@@ -103,8 +109,8 @@ func TestComplete_Success(t *testing.T) {
 		{Role: "user", Content: "Test question"},
 	}
 
-	response, err := client.Complete(context.Background(), messages, &CompletionOptions{
-		Temperature: 0.7,
+	response, _, err := client.Complete(context.Background(), messages, &CompletionOptions{
+		Temperature: Float64(0.7),
 		MaxTokens:   100,
 	})
 
@@ -149,7 +155,7 @@ func TestComplete_WithSystemPrompt(t *testing.T) {
 		{Role: "user", Content: "Hello"},
 	}
 
-	_, err := client.Complete(context.Background(), messages, &CompletionOptions{
+	_, _, err := client.Complete(context.Background(), messages, &CompletionOptions{
 		SystemPrompt: "You are a helpful assistant",
 	})
 
@@ -171,7 +177,7 @@ func TestComplete_ErrorStatus(t *testing.T) {
 		{Role: "user", Content: "Test"},
 	}
 
-	_, err := client.Complete(context.Background(), messages, nil)
+	_, _, err := client.Complete(context.Background(), messages, nil)
 
 	if err == nil {
 		t.Fatal("Expected error for 404 status")
@@ -198,7 +204,7 @@ func TestComplete_ContextCancellation(t *testing.T) {
 
 	messages := []Message{{Role: "user", Content: "Test"}}
 
-	_, err := client.Complete(ctx, messages, nil)
+	_, _, err := client.Complete(ctx, messages, nil)
 
 	if err == nil {
 		t.Fatal("Expected error due to context cancellation")
@@ -238,7 +244,7 @@ func TestCompleteStreaming_Success(t *testing.T) {
 
 	messages := []Message{{Role: "user", Content: "Test"}}
 
-	fullResponse, err := client.CompleteStreaming(context.Background(), messages, nil, onChunk)
+	fullResponse, _, err := client.CompleteStreaming(context.Background(), messages, nil, onChunk)
 
 	if err != nil {
 		t.Fatalf("CompleteStreaming failed: %v", err)
@@ -275,7 +281,7 @@ func TestCompleteStreaming_NoCallback(t *testing.T) {
 	messages := []Message{{Role: "user", Content: "Test"}}
 
 	// Should not panic with nil callback
-	fullResponse, err := client.CompleteStreaming(context.Background(), messages, nil, nil)
+	fullResponse, _, err := client.CompleteStreaming(context.Background(), messages, nil, nil)
 
 	if err != nil {
 		t.Fatalf("CompleteStreaming failed: %v", err)
@@ -320,7 +326,7 @@ func TestCompleteStreaming_EmptyChunks(t *testing.T) {
 
 	messages := []Message{{Role: "user", Content: "Test"}}
 
-	fullResponse, err := client.CompleteStreaming(context.Background(), messages, nil, onChunk)
+	fullResponse, _, err := client.CompleteStreaming(context.Background(), messages, nil, onChunk)
 
 	if err != nil {
 		t.Fatalf("CompleteStreaming failed: %v", err)
@@ -335,3 +341,299 @@ func TestCompleteStreaming_EmptyChunks(t *testing.T) {
 		t.Errorf("Expected 'Hello World', got '%s'", fullResponse)
 	}
 }
+
+func TestChat_SendsToolsAndParsesToolCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		json.NewDecoder(r.Body).Decode(&req)
+
+		if len(req.Tools) != 1 {
+			t.Fatalf("Expected 1 tool, got %d", len(req.Tools))
+		}
+
+		if req.Tools[0].Function.Name != "file_reader" {
+			t.Errorf("Unexpected tool name: %s", req.Tools[0].Function.Name)
+		}
+
+		resp := Response{
+			Message: Message{
+				Role: "assistant",
+				ToolCalls: []ToolCall{{
+					Function: ToolCallFunction{
+						Name:      "file_reader",
+						Arguments: map[string]interface{}{"file_path": "main.go"},
+					},
+				}},
+			},
+			Done: true,
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-model")
+
+	tool := NewTool("file_reader", "Lê arquivos", map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"file_path": map[string]interface{}{"type": "string"},
+		},
+	})
+
+	response, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "read main.go"}}, &CompletionOptions{
+		Tools: []Tool{tool},
+	})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	if !response.HasToolCalls() {
+		t.Fatal("Expected tool calls in response")
+	}
+
+	call := response.Message.ToolCalls[0]
+	if call.Function.Name != "file_reader" {
+		t.Errorf("Expected call to 'file_reader', got '%s'", call.Function.Name)
+	}
+
+	if call.Function.Arguments["file_path"] != "main.go" {
+		t.Errorf("Unexpected arguments: %v", call.Function.Arguments)
+	}
+}
+
+func TestChatStream_AggregatesToolCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoder := json.NewEncoder(w)
+		encoder.Encode(Response{Message: Message{Content: "Vou ler"}})
+		encoder.Encode(Response{Message: Message{ToolCalls: []ToolCall{{
+			Function: ToolCallFunction{Name: "test_runner", Arguments: map[string]interface{}{"action": "run"}},
+		}}}})
+		encoder.Encode(Response{Done: true})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-model")
+
+	response, err := client.ChatStream(context.Background(), []Message{{Role: "user", Content: "run tests"}}, nil, nil)
+	if err != nil {
+		t.Fatalf("ChatStream failed: %v", err)
+	}
+
+	if response.Message.Content != "Vou ler" {
+		t.Errorf("Unexpected content: %s", response.Message.Content)
+	}
+
+	if len(response.Message.ToolCalls) != 1 || response.Message.ToolCalls[0].Function.Name != "test_runner" {
+		t.Errorf("Unexpected tool calls: %+v", response.Message.ToolCalls)
+	}
+}
+
+func TestToolResultMessage_Serialization(t *testing.T) {
+	msg := NewToolResultMessage("file_reader", `{"success":true}`)
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if !strings.Contains(string(data), `"role":"tool"`) || !strings.Contains(string(data), `"tool_name":"file_reader"`) {
+		t.Errorf("Unexpected serialization: %s", data)
+	}
+
+	// Mensagens comuns não devem serializar campos de tool
+	plain, _ := json.Marshal(Message{Role: "user", Content: "oi"})
+	if strings.Contains(string(plain), "tool_calls") || strings.Contains(string(plain), "tool_name") {
+		t.Errorf("Plain message should omit tool fields: %s", plain)
+	}
+}
+
+func TestChat_ToolsNotSupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"registry.ollama.ai/library/gemma:2b does not support tools"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "gemma:2b")
+
+	_, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "Test"}}, &CompletionOptions{
+		Tools: []Tool{NewTool("file_reader", "Lê arquivos", nil)},
+	})
+	if !errors.Is(err, ErrToolsNotSupported) {
+		t.Fatalf("Expected ErrToolsNotSupported, got: %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "400") {
+		t.Errorf("Error should mention status 400, got: %v", err)
+	}
+}
+
+func TestChatStream_ReportsUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"test-model","message":{"content":"Oi"},"done":false}` + "\n"))
+		w.Write([]byte(`{"model":"test-model","message":{"content":""},"done":true,"prompt_eval_count":26,"eval_count":10,"total_duration":2000000000,"load_duration":500000000,"eval_duration":500000000}` + "\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-model")
+
+	var hookModel string
+	var hookUsage Usage
+	client.SetUsageHook(func(model string, usage Usage) {
+		hookModel = model
+		hookUsage = usage
+	})
+
+	text, usage, err := client.CompleteStreaming(context.Background(), []Message{{Role: "user", Content: "Oi"}}, nil, nil)
+	if err != nil {
+		t.Fatalf("CompleteStreaming failed: %v", err)
+	}
+
+	if text != "Oi" {
+		t.Errorf("Expected 'Oi', got '%s'", text)
+	}
+
+	if usage.PromptTokens != 26 || usage.CompletionTokens != 10 || usage.TotalTokens() != 36 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+
+	if usage.TotalDuration != 2*time.Second || usage.LoadDuration != 500*time.Millisecond {
+		t.Errorf("Unexpected durations: %+v", usage)
+	}
+
+	if usage.TokensPerSecond() != 20 {
+		t.Errorf("Expected 20 tok/s, got %.1f", usage.TokensPerSecond())
+	}
+
+	if hookModel != "test-model" || hookUsage != usage {
+		t.Errorf("Hook not called with usage: %s %+v", hookModel, hookUsage)
+	}
+}
+
+func TestComplete_ReturnsUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message":{"role":"assistant","content":"OK"},"done":true,"prompt_eval_count":5,"eval_count":3}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-model")
+
+	_, usage, err := client.Complete(context.Background(), []Message{{Role: "user", Content: "Test"}}, nil)
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	if usage.PromptTokens != 5 || usage.CompletionTokens != 3 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+}
+
+func TestChat_SendsGenerationOptions(t *testing.T) {
+	var raw map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&raw)
+		json.NewEncoder(w).Encode(Response{Message: Message{Content: "{}"}, Done: true})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-model")
+
+	seed, numGPU := 0, 35
+	client.SetDefaultOptions(CompletionOptions{
+		Temperature: Float64(0.7),
+		NumCtx:      16384,
+		KeepAlive:   "30m",
+		TopK:        40,
+		NumGPU:      &numGPU,
+	})
+
+	_, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "Test"}}, &CompletionOptions{
+		Temperature:   Float64(0.1),
+		Seed:          &seed,
+		Stop:          []string{"</end>"},
+		RepeatPenalty: 1.1,
+		TopP:          0.9,
+		Format:        FormatJSON,
+	})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	if raw["keep_alive"] != "30m" {
+		t.Errorf("Expected top-level keep_alive '30m', got %v", raw["keep_alive"])
+	}
+
+	if raw["format"] != "json" {
+		t.Errorf("Expected format 'json', got %v", raw["format"])
+	}
+
+	options := raw["options"].(map[string]interface{})
+	expected := map[string]interface{}{
+		"temperature":    0.1, // Chamada sobrescreve o padrão
+		"num_ctx":        float64(16384),
+		"top_k":          float64(40),
+		"top_p":          0.9,
+		"seed":           float64(0), // Seed 0 deve ser enviada
+		"repeat_penalty": 1.1,
+		"num_gpu":        float64(35),
+	}
+	for key, value := range expected {
+		if options[key] != value {
+			t.Errorf("Option %s: expected %v, got %v", key, value, options[key])
+		}
+	}
+
+	if stop, ok := options["stop"].([]interface{}); !ok || len(stop) != 1 || stop[0] != "</end>" {
+		t.Errorf("Unexpected stop: %v", options["stop"])
+	}
+}
+
+func TestChat_SendsZeroTemperature(t *testing.T) {
+	var raw map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&raw)
+		json.NewEncoder(w).Encode(Response{Message: Message{Content: "ok"}, Done: true})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-model")
+	client.SetDefaultOptions(CompletionOptions{Temperature: Float64(0.7)})
+
+	// Temperatura 0 da chamada sobrescreve o padrão e chega ao servidor
+	if _, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "Test"}}, &CompletionOptions{Temperature: Float64(0)}); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	options := raw["options"].(map[string]interface{})
+	if temperature, ok := options["temperature"]; !ok || temperature != float64(0) {
+		t.Errorf("Expected temperature 0 to be sent, got %v", options["temperature"])
+	}
+
+	// Sem temperatura o campo é omitido e o modelo usa a própria
+	raw = nil
+	if _, err := NewClient(server.URL, "test-model").Chat(context.Background(), []Message{{Role: "user", Content: "Test"}}, nil); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if _, ok := raw["options"].(map[string]interface{})["temperature"]; ok {
+		t.Error("Unset temperature should be omitted")
+	}
+}
+
+func TestWithOptions_SharesClientWithOtherDefaults(t *testing.T) {
+	base := NewClient("http://localhost:11434", "test-model")
+	base.SetDefaultOptions(CompletionOptions{NumCtx: 8192, TopK: 20})
+
+	derived := base.WithOptions(CompletionOptions{TopK: 50})
+
+	if derived.(*Client).httpClient != base.httpClient {
+		t.Error("Derived client should share the HTTP client")
+	}
+
+	if derived.DefaultOptions().NumCtx != 8192 || derived.DefaultOptions().TopK != 50 {
+		t.Errorf("Unexpected derived options: %+v", derived.DefaultOptions())
+	}
+
+	if base.DefaultOptions().TopK != 20 {
+		t.Error("Base client options should not change")
+	}
+}
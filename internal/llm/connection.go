@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Connection autenticação, TLS e proxy das conexões com o servidor. O valor
+// zero conecta direto, com os CAs do sistema e o proxy das variáveis de ambiente.
+type Connection struct {
+	Headers map[string]string // Headers enviados em toda requisição
+	APIKey  string            // Token enviado como "Authorization: Bearer" (vazio = sem token)
+	TLS     *tls.Config       // CA e certificado do cliente (nil = padrão do sistema)
+	Proxy   *url.URL          // Proxy explícito (nil = HTTP_PROXY/HTTPS_PROXY/NO_PROXY)
+}
+
+// LoadTLSConfig monta a configuração TLS com um bundle de CAs (PEM) somado aos
+// CAs do sistema e, para mTLS, o certificado do cliente. Sem arquivos retorna nil.
+func LoadTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	if caFile == "" && certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("client certificate requires both cert and key files")
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// HTTPTransport cria http.Transport com o TLS e o proxy da conexão
+func (c Connection) HTTPTransport() *http.Transport {
+	httpTransport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.TLS != nil {
+		httpTransport.TLSClientConfig = c.TLS.Clone()
+	}
+	if c.Proxy != nil {
+		httpTransport.Proxy = http.ProxyURL(c.Proxy)
+	}
+	return httpTransport
+}
+
+// RoundTripper envolve base adicionando os headers e o token em cada requisição
+func (c Connection) RoundTripper(base http.RoundTripper) http.RoundTripper {
+	if len(c.Headers) == 0 && c.APIKey == "" {
+		return base
+	}
+	return &headerTransport{base: base, headers: c.Headers, apiKey: c.APIKey}
+}
+
+// HTTPClient cliente HTTP com a conexão aplicada (health checks, diagnósticos)
+func (c Connection) HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: c.RoundTripper(c.HTTPTransport()), Timeout: timeout}
+}
+
+// headerTransport adiciona headers fixos e o token às requisições
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+	apiKey  string
+}
+
+// RoundTrip envia a requisição com os headers da conexão (sem alterar a original)
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+	if t.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
+	return t.base.RoundTrip(req)
+}
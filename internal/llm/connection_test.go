@@ -0,0 +1,133 @@
+package llm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeClientCert gera certificado de cliente autoassinado e grava cert e chave em PEM
+func writeClientCert(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ollama-code"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return cert, certFile, keyFile
+}
+
+func TestClient_ConnectionWithMTLSAndToken(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, certFile, keyFile := writeClientCert(t, dir)
+
+	var gotAuth, gotHeader string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotHeader = r.Header.Get("X-Team")
+		fmt.Fprint(w, `{"message":{"role":"assistant","content":"ok"},"done":true}`)
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)
+
+	tlsConfig, err := LoadTLSConfig(caFile, certFile, keyFile)
+	if err != nil {
+		t.Fatalf("LoadTLSConfig failed: %v", err)
+	}
+
+	cfg := fastTransport()
+	cfg.Retry.MaxRetries = 0
+	cfg.Connection = Connection{Headers: map[string]string{"X-Team": "platform"}, APIKey: "secret", TLS: tlsConfig}
+
+	client := NewClient(server.URL, "m")
+	client.SetTransportConfig(cfg)
+	if _, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil); err != nil {
+		t.Fatalf("Chat over mTLS failed: %v", err)
+	}
+	if gotAuth != "Bearer secret" || gotHeader != "platform" {
+		t.Errorf("Expected token and custom header, got auth=%q header=%q", gotAuth, gotHeader)
+	}
+
+	// Sem o certificado do cliente o servidor recusa a conexão
+	cfg.Connection.TLS, _ = LoadTLSConfig(caFile, "", "")
+	client.SetTransportConfig(cfg)
+	if _, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil); err == nil {
+		t.Error("Server should reject a client without certificate")
+	}
+}
+
+func TestClient_ConnectionProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		fmt.Fprint(w, `{"models":[]}`)
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	cfg := DefaultTransportConfig()
+	cfg.Connection = Connection{Proxy: proxyURL}
+
+	client := NewClient("http://ollama.internal:11434", "")
+	client.SetTransportConfig(cfg)
+	if _, err := client.ListModels(context.Background()); err != nil {
+		t.Fatalf("ListModels through proxy failed: %v", err)
+	}
+	if proxied != "http://ollama.internal:11434/api/tags" {
+		t.Errorf("Request should go through the proxy, got %q", proxied)
+	}
+}
+
+func TestLoadTLSConfig_Errors(t *testing.T) {
+	if cfg, err := LoadTLSConfig("", "", ""); cfg != nil || err != nil {
+		t.Errorf("No files should mean default TLS, got %v, %v", cfg, err)
+	}
+	if _, err := LoadTLSConfig("", "client.pem", ""); err == nil {
+		t.Error("Certificate without key should fail")
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(empty, []byte("not a certificate"), 0600)
+	if _, err := LoadTLSConfig(empty, "", ""); err == nil {
+		t.Error("CA bundle without certificates should fail")
+	}
+}
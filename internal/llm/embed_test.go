@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_Embed(t *testing.T) {
+	var received embedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"model":"nomic-embed-text","embeddings":[[0.1,0.2],[0.3,0.4]]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "qwen")
+	vectors, err := client.Embed(context.Background(), "nomic-embed-text", []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	if received.Model != "nomic-embed-text" || len(received.Input) != 2 {
+		t.Errorf("Unexpected request: %+v", received)
+	}
+	if len(vectors) != 2 || vectors[1][0] != 0.3 {
+		t.Errorf("Unexpected vectors: %v", vectors)
+	}
+}
+
+func TestClient_EmbedCountMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"embeddings":[[0.1]]}`))
+	}))
+	defer server.Close()
+
+	if _, err := NewClient(server.URL, "qwen").Embed(context.Background(), "", []string{"a", "b"}); err == nil {
+		t.Error("Expected error when server returns fewer embeddings")
+	}
+}
+
+func TestOpenAIClient_EmbedSortsByIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[2]},{"index":0,"embedding":[1]}]}`))
+	}))
+	defer server.Close()
+
+	vectors, err := NewOpenAIClient(server.URL, "qwen").Embed(context.Background(), "", []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if vectors[0][0] != 1 || vectors[1][0] != 2 {
+		t.Errorf("Embeddings should follow input order, got %v", vectors)
+	}
+}
@@ -0,0 +1,218 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrModelUnavailable o modelo não consegue atender: não está instalado, falhou
+// ao carregar (falta de memória) ou o servidor respondeu erro interno
+var ErrModelUnavailable = errors.New("model unavailable")
+
+// DefaultModelCooldown tempo que um modelo com falha fica fora da cadeia de fallback
+const DefaultModelCooldown = 2 * time.Minute
+
+// unavailableMarkers trechos de erro do Ollama/llama.cpp que indicam falha de carga do modelo
+var unavailableMarkers = []string{
+	"out of memory",
+	"requires more system memory",
+	"failed to load model",
+	"unable to load model",
+	"llama runner process has terminated",
+}
+
+// FallbackEvent descreve a troca de um modelo indisponível pelo próximo da cadeia
+type FallbackEvent struct {
+	From string // Modelo que falhou (ou está em cool-down)
+	To   string // Modelo que será tentado
+	Err  error  // Motivo da troca
+}
+
+// ModelHealth lembra modelos que falharam para não tentá-los de novo durante o
+// cool-down. Pode ser compartilhado entre clients (ex: o router e o client principal).
+type ModelHealth struct {
+	mu       sync.Mutex
+	cooldown time.Duration
+	failed   map[string]time.Time // modelo -> fim do cool-down
+	now      func() time.Time
+}
+
+// NewModelHealth cria registro de falhas com o cool-down informado (0 usa o padrão)
+func NewModelHealth(cooldown time.Duration) *ModelHealth {
+	if cooldown <= 0 {
+		cooldown = DefaultModelCooldown
+	}
+	return &ModelHealth{
+		cooldown: cooldown,
+		failed:   make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// MarkFailed coloca o modelo em cool-down
+func (h *ModelHealth) MarkFailed(model string) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failed[model] = h.now().Add(h.cooldown)
+}
+
+// MarkHealthy remove o modelo do cool-down após uma resposta bem-sucedida
+func (h *ModelHealth) MarkHealthy(model string) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.failed, model)
+}
+
+// Available indica se o modelo está fora do cool-down
+func (h *ModelHealth) Available(model string) bool {
+	if h == nil {
+		return true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	until, failed := h.failed[model]
+	if !failed {
+		return true
+	}
+	if h.now().After(until) {
+		delete(h.failed, model)
+		return true
+	}
+	return false
+}
+
+// Failing retorna os modelos em cool-down, ordenados, com o fim de cada cool-down
+func (h *ModelHealth) Failing() []ModelCooldown {
+	if h == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	var failing []ModelCooldown
+	for model, until := range h.failed {
+		if now.Before(until) {
+			failing = append(failing, ModelCooldown{Model: model, Until: until})
+		}
+	}
+	sort.Slice(failing, func(i, j int) bool { return failing[i].Model < failing[j].Model })
+	return failing
+}
+
+// ModelCooldown modelo fora da cadeia de fallback até Until
+type ModelCooldown struct {
+	Model string
+	Until time.Time
+}
+
+// SetModelHealth define o registro de falhas usado na cadeia de fallback
+func (t *transport) SetModelHealth(health *ModelHealth) {
+	t.health = health
+}
+
+// SetFallbackHook define callback chamado quando um fallback assume a requisição
+func (t *transport) SetFallbackHook(fn func(event FallbackEvent)) {
+	t.onFallback = fn
+}
+
+// withFallback envia a requisição para o modelo e, se ele estiver indisponível,
+// para os fallbacks em ordem. Modelos em cool-down são pulados, a menos que
+// todos estejam em cool-down.
+func (t *transport) withFallback(model string, fallbacks []string, send func(model string) (*http.Response, error)) (*http.Response, error) {
+	chain := fallbackChain(model, fallbacks)
+
+	candidates := make([]string, 0, len(chain))
+	for _, candidate := range chain {
+		if t.health.Available(candidate) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = chain
+	}
+
+	var lastErr error
+	previous := ""
+	if candidates[0] != model {
+		previous = model
+		lastErr = fmt.Errorf("%w: %s is cooling down after a failure", ErrModelUnavailable, model)
+	}
+
+	for _, candidate := range candidates {
+		if previous != "" && t.onFallback != nil {
+			t.onFallback(FallbackEvent{From: previous, To: candidate, Err: lastErr})
+		}
+
+		resp, err := send(candidate)
+		if err == nil {
+			t.health.MarkHealthy(candidate)
+			return resp, nil
+		}
+		if !errors.Is(err, ErrModelUnavailable) {
+			return nil, err
+		}
+
+		t.health.MarkFailed(candidate)
+		lastErr = err
+		previous = candidate
+	}
+
+	return nil, lastErr
+}
+
+// fallbackChain modelo seguido dos fallbacks, sem repetições
+func fallbackChain(model string, fallbacks []string) []string {
+	chain := []string{model}
+	seen := map[string]bool{model: true}
+	for _, fallback := range fallbacks {
+		if fallback != "" && !seen[fallback] {
+			seen[fallback] = true
+			chain = append(chain, fallback)
+		}
+	}
+	return chain
+}
+
+// statusError erro para resposta HTTP de falha; falhas do modelo (não instalado,
+// erro de carga, erro interno) embrulham ErrModelUnavailable
+func statusError(status int, body []byte) error {
+	if modelUnavailable(status, string(body)) {
+		return fmt.Errorf("%w: unexpected status %d: %s", ErrModelUnavailable, status, string(body))
+	}
+	return fmt.Errorf("unexpected status %d: %s", status, string(body))
+}
+
+// modelUnavailable classifica a falha como problema do modelo (vale tentar outro)
+func modelUnavailable(status int, body string) bool {
+	lower := strings.ToLower(body)
+
+	if status == http.StatusInternalServerError {
+		return true
+	}
+	if status == http.StatusNotFound && strings.Contains(lower, "not found") {
+		return true
+	}
+	for _, marker := range unavailableMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
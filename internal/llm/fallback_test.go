@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newFallbackServer servidor Ollama em que os modelos de failing respondem com erro
+func newFallbackServer(t *testing.T, failing map[string]int, requested *[]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		json.NewDecoder(r.Body).Decode(&req)
+		*requested = append(*requested, req.Model)
+
+		if status, ok := failing[req.Model]; ok {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"error":"model requires more system memory (9.1 GiB) than is available"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"model":%q,"message":{"role":"assistant","content":"ok"},"done":true,"prompt_eval_count":5,"eval_count":2}`, req.Model)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestChat_FallsBackWhenModelUnavailable(t *testing.T) {
+	var requested []string
+	server := newFallbackServer(t, map[string]int{"big": http.StatusInternalServerError}, &requested)
+
+	client := NewClient(server.URL, "big")
+	client.SetDefaultOptions(CompletionOptions{Fallbacks: []string{"medium", "small"}})
+
+	var events []FallbackEvent
+	client.SetFallbackHook(func(event FallbackEvent) { events = append(events, event) })
+
+	var usedModel string
+	client.SetUsageHook(func(model string, usage Usage) { usedModel = model })
+
+	response, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil)
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	if response.Model != "medium" || usedModel != "medium" {
+		t.Errorf("Expected the first fallback to serve the request, got %s/%s", response.Model, usedModel)
+	}
+	if len(events) != 1 || events[0].From != "big" || events[0].To != "medium" || !errors.Is(events[0].Err, ErrModelUnavailable) {
+		t.Errorf("Unexpected fallback events: %+v", events)
+	}
+	if fmt.Sprint(requested) != "[big medium]" {
+		t.Errorf("Unexpected request order: %v", requested)
+	}
+}
+
+func TestChat_SkipsModelsInCooldown(t *testing.T) {
+	var requested []string
+	server := newFallbackServer(t, map[string]int{"big": http.StatusInternalServerError}, &requested)
+
+	health := NewModelHealth(time.Minute)
+	client := NewClient(server.URL, "big")
+	client.SetModelHealth(health)
+	opts := &CompletionOptions{Fallbacks: []string{"small"}}
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := client.Complete(context.Background(), []Message{{Role: "user", Content: "oi"}}, opts); err != nil {
+			t.Fatalf("Complete %d failed: %v", i, err)
+		}
+	}
+
+	if fmt.Sprint(requested) != "[big small small small]" {
+		t.Errorf("Failing model should be skipped during cool-down, got %v", requested)
+	}
+	if failing := health.Failing(); len(failing) != 1 || failing[0].Model != "big" {
+		t.Errorf("Expected big in cool-down, got %+v", failing)
+	}
+
+	// Após o cool-down o modelo volta a ser tentado
+	health.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	requested = nil
+	client.Complete(context.Background(), []Message{{Role: "user", Content: "oi"}}, opts)
+	if len(requested) == 0 || requested[0] != "big" {
+		t.Errorf("Model should be retried after the cool-down, got %v", requested)
+	}
+}
+
+func TestChat_NoFallbackForOtherErrors(t *testing.T) {
+	var requested []string
+	server := newFallbackServer(t, map[string]int{}, &requested)
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, "big")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid options"}`))
+	})
+
+	client := NewClient(server.URL, "big")
+	_, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, &CompletionOptions{Fallbacks: []string{"small"}})
+
+	if err == nil || errors.Is(err, ErrModelUnavailable) {
+		t.Fatalf("Expected a plain request error, got %v", err)
+	}
+	if len(requested) != 1 {
+		t.Errorf("Bad requests should not fall back, got %d requests", len(requested))
+	}
+}
+
+func TestChat_AllFallbacksUnavailable(t *testing.T) {
+	var requested []string
+	server := newFallbackServer(t, map[string]int{"big": http.StatusInternalServerError, "small": http.StatusNotFound}, &requested)
+
+	client := NewClient(server.URL, "big")
+	_, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, &CompletionOptions{Fallbacks: []string{"small"}})
+
+	if !errors.Is(err, ErrModelUnavailable) {
+		t.Errorf("Expected ErrModelUnavailable from the last model, got %v", err)
+	}
+	if fmt.Sprint(requested) != "[big small]" {
+		t.Errorf("Every model in the chain should be tried once, got %v", requested)
+	}
+}
+
+func TestModelUnavailable(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   bool
+	}{
+		{http.StatusInternalServerError, `{"error":"llama runner process has terminated"}`, true},
+		{http.StatusNotFound, `{"error":"model \"qwen2.5-coder:7b\" not found, try pulling it first"}`, true},
+		{http.StatusServiceUnavailable, `{"error":"CUDA error: out of memory"}`, true},
+		{http.StatusBadRequest, `{"error":"invalid options"}`, false},
+		{http.StatusTooManyRequests, `{"error":"server busy"}`, false},
+	}
+
+	for _, tt := range tests {
+		if got := modelUnavailable(tt.status, tt.body); got != tt.want {
+			t.Errorf("modelUnavailable(%d, %s) = %v, want %v", tt.status, tt.body, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,388 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHostCheckInterval intervalo entre health checks dos hosts do pool
+const DefaultHostCheckInterval = 30 * time.Second
+
+// hostCheckTimeout tempo máximo do health check (/api/tags) de um host
+const hostCheckTimeout = 5 * time.Second
+
+// stickyTTL tempo sem requisições após o qual a afinidade de uma conversa expira
+const stickyTTL = 30 * time.Minute
+
+// HostConfig servidor Ollama do pool
+type HostConfig struct {
+	URL         string
+	MaxParallel int // Requisições simultâneas (OLLAMA_NUM_PARALLEL do host; 0 = 1)
+}
+
+// HostStatus estado de um host do pool
+type HostStatus struct {
+	URL         string
+	Healthy     bool
+	Active      int // Requisições em andamento
+	MaxParallel int
+	Models      []string // Modelos instalados (vazio antes do primeiro health check)
+	Err         error    // Última falha do host
+}
+
+// poolHost estado interno de um host
+type poolHost struct {
+	url         string
+	maxParallel int
+	active      int
+	healthy     bool
+	models      map[string]bool // nil = ainda não verificado
+	err         error
+}
+
+// hasModel indica se o host tem o modelo (host não verificado pode ter)
+func (h *poolHost) hasModel(model string) bool {
+	return h.models == nil || h.models[normalizeModelName(model)]
+}
+
+// load fração da capacidade em uso
+func (h *poolHost) load() float64 {
+	return float64(h.active) / float64(h.maxParallel)
+}
+
+// stickyEntry host que atende uma conversa e o último uso
+type stickyEntry struct {
+	host     *poolHost
+	lastUsed time.Time
+}
+
+// HostPool distribui as requisições entre vários servidores Ollama: cada uma
+// vai para o host menos carregado que tem o modelo, e requisições da mesma
+// conversa ficam no mesmo host para reaproveitar o KV cache.
+type HostPool struct {
+	mu         sync.Mutex
+	hosts      []*poolHost
+	sticky     map[string]*stickyEntry // conversa+modelo -> host
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewHostPool cria pool com os hosts informados (todos começam saudáveis)
+func NewHostPool(hosts []HostConfig) *HostPool {
+	pool := &HostPool{
+		sticky:     make(map[string]*stickyEntry),
+		httpClient: &http.Client{Timeout: hostCheckTimeout},
+		now:        time.Now,
+	}
+
+	seen := make(map[string]bool)
+	for _, cfg := range hosts {
+		url := strings.TrimSuffix(cfg.URL, "/")
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+
+		maxParallel := cfg.MaxParallel
+		if maxParallel <= 0 {
+			maxParallel = 1
+		}
+		pool.hosts = append(pool.hosts, &poolHost{url: url, maxParallel: maxParallel, healthy: true})
+	}
+	return pool
+}
+
+// SetConnection aplica headers, token, TLS e proxy aos health checks
+func (p *HostPool) SetConnection(conn Connection) {
+	p.httpClient = conn.HTTPClient(hostCheckTimeout)
+}
+
+// Has indica se a URL é de um host do pool
+func (p *HostPool) Has(url string) bool {
+	if p == nil {
+		return false
+	}
+
+	url = strings.TrimSuffix(url, "/")
+	for _, host := range p.hosts {
+		if host.url == url {
+			return true
+		}
+	}
+	return false
+}
+
+// Start faz o health check dos hosts agora e depois a cada interval, até o contexto terminar
+func (p *HostPool) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultHostCheckInterval
+	}
+
+	p.Refresh(ctx)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.Refresh(ctx)
+			}
+		}
+	}()
+}
+
+// Refresh consulta /api/tags de cada host: atualiza a saúde e os modelos instalados
+func (p *HostPool) Refresh(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, host := range p.hosts {
+		wg.Add(1)
+		go func(host *poolHost) {
+			defer wg.Done()
+			models, err := p.fetchModels(ctx, host.url)
+
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			host.healthy = err == nil
+			host.err = err
+			if err == nil {
+				host.models = models
+			}
+		}(host)
+	}
+	wg.Wait()
+}
+
+// fetchModels lista os modelos instalados no host
+func (p *HostPool) fetchModels(ctx context.Context, url string) (map[string]bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var tags struct {
+		Models []ModelInfo `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	models := make(map[string]bool, len(tags.Models))
+	for _, model := range tags.Models {
+		models[normalizeModelName(model.Name)] = true
+	}
+	return models, nil
+}
+
+// Acquire reserva um host para a requisição. A conversa volta ao host que a
+// atendeu antes enquanto ele tiver o modelo e capacidade livre; senão vai para
+// o host menos carregado. O lease deve ser liberado com Release ou Fail.
+func (p *HostPool) Acquire(model, conversation string) *HostLease {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	for key, entry := range p.sticky {
+		if now.Sub(entry.lastUsed) > stickyTTL {
+			delete(p.sticky, key)
+		}
+	}
+
+	candidates := p.candidates(model)
+	if len(candidates) == 0 {
+		return &HostLease{}
+	}
+	key := conversation + "\x00" + model
+
+	var chosen *poolHost
+	if entry, ok := p.sticky[key]; ok && conversation != "" && entry.host.active < entry.host.maxParallel {
+		for _, host := range candidates {
+			if host == entry.host {
+				chosen = host
+				break
+			}
+		}
+	}
+	if chosen == nil {
+		for _, host := range candidates {
+			if chosen == nil || host.load() < chosen.load() {
+				chosen = host
+			}
+		}
+	}
+
+	chosen.active++
+	if conversation != "" {
+		p.sticky[key] = &stickyEntry{host: chosen, lastUsed: now}
+	}
+	return &HostLease{URL: chosen.url, pool: p, host: chosen}
+}
+
+// candidates hosts que podem atender o modelo. Sem host saudável com o modelo,
+// usa os saudáveis (o servidor responde "not found" e o fallback assume); com
+// todos fora do ar, tenta todos.
+func (p *HostPool) candidates(model string) []*poolHost {
+	var withModel, healthy []*poolHost
+	for _, host := range p.hosts {
+		if !host.healthy {
+			continue
+		}
+		healthy = append(healthy, host)
+		if host.hasModel(model) {
+			withModel = append(withModel, host)
+		}
+	}
+
+	switch {
+	case len(withModel) > 0:
+		return withModel
+	case len(healthy) > 0:
+		return healthy
+	default:
+		return p.hosts
+	}
+}
+
+// Status estado atual dos hosts, na ordem da configuração
+func (p *HostPool) Status() []HostStatus {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := make([]HostStatus, 0, len(p.hosts))
+	for _, host := range p.hosts {
+		models := make([]string, 0, len(host.models))
+		for model := range host.models {
+			models = append(models, model)
+		}
+		sort.Strings(models)
+
+		status = append(status, HostStatus{
+			URL:         host.url,
+			Healthy:     host.healthy,
+			Active:      host.active,
+			MaxParallel: host.maxParallel,
+			Models:      models,
+			Err:         host.err,
+		})
+	}
+	return status
+}
+
+// HostLease reserva de capacidade em um host do pool
+type HostLease struct {
+	URL string
+
+	pool *HostPool
+	host *poolHost
+	once sync.Once
+}
+
+// Release libera a capacidade reservada (seguro chamar mais de uma vez e em nil)
+func (l *HostLease) Release() {
+	if l == nil || l.pool == nil {
+		return
+	}
+
+	l.once.Do(func() {
+		l.pool.mu.Lock()
+		defer l.pool.mu.Unlock()
+		l.host.active--
+	})
+}
+
+// Fail libera a reserva e tira o host do pool até o próximo health check
+func (l *HostLease) Fail(err error) {
+	if l == nil || l.pool == nil {
+		return
+	}
+
+	l.Release()
+	l.pool.mu.Lock()
+	defer l.pool.mu.Unlock()
+	l.host.healthy = false
+	l.host.err = err
+}
+
+// releaseOnClose libera o host e a vaga do scheduler quando o corpo da resposta é fechado
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close fecha o corpo e libera os recursos da requisição
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
+
+// conversationKey chave de contexto com o identificador da conversa
+type conversationKey struct{}
+
+// WithConversation marca o contexto com o identificador da conversa. No pool de
+// hosts, requisições da mesma conversa vão para o mesmo host (reuso do KV cache).
+func WithConversation(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, conversationKey{}, id)
+}
+
+// conversationID identificador da conversa: o do contexto ou, sem ele, um hash
+// do início da conversa (system e primeira mensagem, o prefixo que o KV cache reaproveita)
+func conversationID(ctx context.Context, messages []Message) string {
+	if id, ok := ctx.Value(conversationKey{}).(string); ok && id != "" {
+		return id
+	}
+	if len(messages) == 0 {
+		return ""
+	}
+
+	h := fnv.New64a()
+	for i, msg := range messages {
+		if i == 2 {
+			break
+		}
+		h.Write([]byte(msg.Role))
+		h.Write([]byte{0})
+		h.Write([]byte(msg.Content))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("prefix-%x", h.Sum64())
+}
+
+// SetHostPool define o pool de servidores Ollama. Só requisições para URLs do
+// pool são distribuídas; clients com endpoint próprio não são afetados.
+func (t *transport) SetHostPool(pool *HostPool) {
+	t.pool = pool
+}
+
+// acquireHost reserva o host da requisição: um do pool, se baseURL fizer parte
+// dele, ou o próprio baseURL
+func (t *transport) acquireHost(ctx context.Context, baseURL, model string, messages []Message) *HostLease {
+	if !t.pool.Has(baseURL) {
+		return &HostLease{URL: baseURL}
+	}
+	return t.pool.Acquire(model, conversationID(ctx, messages))
+}
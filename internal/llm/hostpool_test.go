@@ -0,0 +1,200 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newPoolHost servidor Ollama com os modelos informados que conta as requisições de chat
+func newPoolHost(t *testing.T, chats *int32, models ...string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			var list []string
+			for _, model := range models {
+				list = append(list, fmt.Sprintf(`{"name":%q}`, model))
+			}
+			fmt.Fprintf(w, `{"models":[%s]}`, strings.Join(list, ","))
+		case "/api/chat":
+			atomic.AddInt32(chats, 1)
+			fmt.Fprint(w, `{"message":{"role":"assistant","content":"ok"},"done":true}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHostPool_RoutesToHostWithModel(t *testing.T) {
+	var chatsA, chatsB int32
+	hostA := newPoolHost(t, &chatsA, "qwen2.5-coder:7b")
+	hostB := newPoolHost(t, &chatsB, "qwen2.5-coder:7b", "llama3")
+
+	pool := NewHostPool([]HostConfig{{URL: hostA.URL}, {URL: hostB.URL + "/"}})
+	pool.Refresh(context.Background())
+
+	lease := pool.Acquire("llama3:latest", "")
+	if lease.URL != hostB.URL {
+		t.Errorf("Only host B has llama3, got %s", lease.URL)
+	}
+	lease.Release()
+	lease.Release()
+
+	for _, status := range pool.Status() {
+		if status.Active != 0 || !status.Healthy {
+			t.Errorf("Lease released twice should leave the host idle and healthy, got %+v", status)
+		}
+	}
+	if !pool.Has(hostB.URL+"/") || pool.Has("http://other:11434") {
+		t.Error("Has should match pool URLs only")
+	}
+}
+
+func TestHostPool_LeastLoadedAndSticky(t *testing.T) {
+	pool := NewHostPool([]HostConfig{{URL: "http://a", MaxParallel: 2}, {URL: "http://b", MaxParallel: 2}})
+
+	first := pool.Acquire("m", "conv-1")
+	second := pool.Acquire("m", "conv-2")
+	if first.URL != "http://a" || second.URL != "http://b" {
+		t.Fatalf("Requests should spread across hosts, got %s and %s", first.URL, second.URL)
+	}
+	first.Release()
+	second.Release()
+
+	// Com os dois hosts livres, cada conversa volta para o seu host
+	if lease := pool.Acquire("m", "conv-2"); lease.URL != "http://b" {
+		t.Errorf("conv-2 should stick to host b, got %s", lease.URL)
+	}
+
+	// Host da conversa sem capacidade: vai para o menos carregado
+	busy := pool.Acquire("m", "conv-2")
+	if busy.URL != "http://b" {
+		t.Fatalf("Second request of conv-2 should still fit on b, got %s", busy.URL)
+	}
+	if lease := pool.Acquire("m", "conv-2"); lease.URL != "http://a" {
+		t.Errorf("Full host should not take more requests, got %s", lease.URL)
+	}
+}
+
+func TestHostPool_FailedHostLeavesPool(t *testing.T) {
+	pool := NewHostPool([]HostConfig{{URL: "http://a"}, {URL: "http://b"}})
+
+	lease := pool.Acquire("m", "conv")
+	lease.Fail(fmt.Errorf("connection refused"))
+
+	if next := pool.Acquire("m", "conv"); next.URL != "http://b" {
+		t.Errorf("Failed host should be skipped, got %s", next.URL)
+	}
+
+	status := pool.Status()
+	if status[0].Healthy || status[0].Err == nil || status[0].Active != 0 {
+		t.Errorf("Unexpected status for failed host: %+v", status[0])
+	}
+}
+
+func TestClient_UsesHostPool(t *testing.T) {
+	var chatsA, chatsB int32
+	hostA := newPoolHost(t, &chatsA, "small")
+	hostB := newPoolHost(t, &chatsB, "big")
+
+	pool := NewHostPool([]HostConfig{{URL: hostA.URL}, {URL: hostB.URL}})
+	pool.Refresh(context.Background())
+
+	client := NewClient(hostA.URL, "big")
+	client.SetHostPool(pool)
+
+	if _, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if atomic.LoadInt32(&chatsA) != 0 || atomic.LoadInt32(&chatsB) != 1 {
+		t.Errorf("Request should go to the host with the model, got a=%d b=%d", chatsA, chatsB)
+	}
+	if status := pool.Status(); status[1].Active != 0 {
+		t.Errorf("Host should be released after the response, got %+v", status[1])
+	}
+
+	// Client com endpoint fora do pool não é afetado
+	other := newPoolHost(t, new(int32))
+	direct := NewClient(other.URL, "big")
+	direct.SetHostPool(pool)
+	direct.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil)
+	if atomic.LoadInt32(&chatsB) != 1 {
+		t.Error("Client outside the pool should call its own endpoint")
+	}
+}
+
+func TestClient_HostPoolRetriesOnAnotherHost(t *testing.T) {
+	var chats int32
+	alive := newPoolHost(t, &chats, "m")
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	pool := NewHostPool([]HostConfig{{URL: dead.URL}, {URL: alive.URL}})
+	client := NewClient(dead.URL, "m")
+	client.SetTransportConfig(fastTransport())
+	client.SetHostPool(pool)
+
+	if _, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil); err != nil {
+		t.Fatalf("Retry should reach the live host: %v", err)
+	}
+	if atomic.LoadInt32(&chats) != 1 {
+		t.Errorf("Expected one request on the live host, got %d", chats)
+	}
+	if pool.Status()[0].Healthy {
+		t.Error("Unreachable host should be marked unhealthy")
+	}
+}
+
+func TestClient_HostPoolKeepsBusyHostHealthy(t *testing.T) {
+	var chats int32
+	host := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			fmt.Fprint(w, `{"models":[{"name":"m"}]}`)
+		case "/api/chat":
+			if atomic.AddInt32(&chats, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprint(w, `{"error":"llm server loading model"}`)
+				return
+			}
+			fmt.Fprint(w, `{"message":{"role":"assistant","content":"ok"},"done":true}`)
+		}
+	}))
+	defer host.Close()
+
+	pool := NewHostPool([]HostConfig{{URL: host.URL}})
+	pool.Refresh(context.Background())
+	client := NewClient(host.URL, "m")
+	client.SetTransportConfig(fastTransport())
+	client.SetHostPool(pool)
+
+	if _, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil); err != nil {
+		t.Fatalf("Retry should succeed on the same host: %v", err)
+	}
+	if atomic.LoadInt32(&chats) != 2 {
+		t.Errorf("Expected 503 then 200 on the same host, got %d requests", chats)
+	}
+	if status := pool.Status()[0]; !status.Healthy || status.Active != 0 {
+		t.Errorf("Host loading a model should stay healthy and idle, got %+v", status)
+	}
+}
+
+func TestConversationID(t *testing.T) {
+	messages := []Message{{Role: "system", Content: "sys"}, {Role: "user", Content: "oi"}}
+	longer := append(messages, Message{Role: "assistant", Content: "olá"})
+
+	if conversationID(context.Background(), messages) != conversationID(context.Background(), longer) {
+		t.Error("Same conversation prefix should give the same id")
+	}
+
+	ctx := WithConversation(context.Background(), "session-1")
+	if got := conversationID(ctx, messages); got != "session-1" {
+		t.Errorf("Context id should take precedence, got %s", got)
+	}
+}
@@ -0,0 +1,301 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrModelNotFound modelo não está instalado no servidor Ollama
+var ErrModelNotFound = errors.New("model not found")
+
+// ModelDetails detalhes de formato e quantização de um modelo
+type ModelDetails struct {
+	Format            string   `json:"format,omitempty"`
+	Family            string   `json:"family,omitempty"`
+	Families          []string `json:"families,omitempty"`
+	ParameterSize     string   `json:"parameter_size,omitempty"`
+	QuantizationLevel string   `json:"quantization_level,omitempty"`
+}
+
+// ModelInfo modelo instalado (/api/tags)
+type ModelInfo struct {
+	Name       string       `json:"name"`
+	Model      string       `json:"model,omitempty"`
+	ModifiedAt time.Time    `json:"modified_at"`
+	Size       int64        `json:"size"`
+	Digest     string       `json:"digest"`
+	Details    ModelDetails `json:"details"`
+}
+
+// ModelShow informações detalhadas de um modelo (/api/show)
+type ModelShow struct {
+	Modelfile    string                 `json:"modelfile,omitempty"`
+	Parameters   string                 `json:"parameters,omitempty"`
+	Template     string                 `json:"template,omitempty"`
+	Details      ModelDetails           `json:"details"`
+	ModelInfo    map[string]interface{} `json:"model_info,omitempty"`
+	Capabilities []string               `json:"capabilities,omitempty"`
+}
+
+// RunningModel modelo carregado em memória (/api/ps)
+type RunningModel struct {
+	Name      string       `json:"name"`
+	Model     string       `json:"model,omitempty"`
+	Size      int64        `json:"size"`
+	SizeVRAM  int64        `json:"size_vram"`
+	Digest    string       `json:"digest"`
+	Details   ModelDetails `json:"details"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+// PullProgress progresso de download (/api/pull)
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Percent porcentagem concluída da camada atual (0 se desconhecida)
+func (p PullProgress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return float64(p.Completed) / float64(p.Total) * 100
+}
+
+// modelRequest corpo das requisições que recebem apenas o nome do modelo
+type modelRequest struct {
+	Model  string `json:"model"`
+	Stream *bool  `json:"stream,omitempty"`
+}
+
+// ListModels lista modelos instalados
+func (c *Client) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	var result struct {
+		Models []ModelInfo `json:"models"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, "/api/tags", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Models, nil
+}
+
+// ShowModel retorna detalhes de um modelo instalado
+func (c *Client) ShowModel(ctx context.Context, name string) (*ModelShow, error) {
+	var result ModelShow
+	if err := c.doJSON(ctx, http.MethodPost, "/api/show", modelRequest{Model: name}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteModel remove um modelo instalado
+func (c *Client) DeleteModel(ctx context.Context, name string) error {
+	return c.doJSON(ctx, http.MethodDelete, "/api/delete", modelRequest{Model: name}, nil)
+}
+
+// RunningModels lista modelos carregados em memória
+func (c *Client) RunningModels(ctx context.Context) ([]RunningModel, error) {
+	var result struct {
+		Models []RunningModel `json:"models"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, "/api/ps", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Models, nil
+}
+
+// ModelLister provider capaz de listar os modelos disponíveis no servidor
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]ModelInfo, error)
+}
+
+// ModelPuller provider capaz de baixar modelos (apenas Ollama)
+type ModelPuller interface {
+	PullModel(ctx context.Context, name string, onProgress func(PullProgress)) error
+}
+
+// HasModel verifica se o modelo está disponível.
+// Nomes sem tag equivalem a ":latest", como no Ollama.
+func HasModel(ctx context.Context, lister ModelLister, name string) (bool, error) {
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return containsModel(models, name), nil
+}
+
+// MissingModels retorna os nomes não disponíveis entre os informados
+func MissingModels(ctx context.Context, lister ModelLister, names ...string) ([]string, error) {
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if !containsModel(models, name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
+
+// PullModel baixa um modelo, reportando progresso a cada linha do stream
+func (c *Client) PullModel(ctx context.Context, name string, onProgress func(PullProgress)) error {
+	stream := true
+	jsonData, err := json.Marshal(modelRequest{Model: name, Stream: &stream})
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/pull", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Downloads podem levar mais que os timeouts das requisições de chat
+	pullClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := pullClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var progress PullProgress
+		if err := json.Unmarshal(line, &progress); err != nil {
+			return fmt.Errorf("decode progress: %w", err)
+		}
+		if progress.Error != "" {
+			return fmt.Errorf("pull %s: %s", name, progress.Error)
+		}
+		if onProgress != nil {
+			onProgress(progress)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read stream: %w", err)
+	}
+	return nil
+}
+
+// doJSON executa uma requisição JSON simples nas rotas de gerenciamento
+func (c *Client) doJSON(ctx context.Context, method, path string, body, out interface{}) error {
+	var jsonData []byte
+	if body != nil {
+		var err error
+		jsonData, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+	}
+
+	resp, err := c.do(ctx, c.model, func(ctx context.Context) (*http.Request, error) {
+		var reader io.Reader
+		if jsonData != nil {
+			reader = bytes.NewReader(jsonData)
+		}
+
+		httpReq, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+		if err != nil {
+			return nil, err
+		}
+		if jsonData != nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+		return httpReq, nil
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// checkStatus converte status HTTP de erro em error
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrModelNotFound, strings.TrimSpace(string(body)))
+	}
+	return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+}
+
+// containsModel compara nomes considerando a tag implícita ":latest"
+func containsModel(models []ModelInfo, name string) bool {
+	want := normalizeModelName(name)
+	for _, model := range models {
+		if normalizeModelName(model.Name) == want || normalizeModelName(model.Model) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeModelName adiciona ":latest" a nomes sem tag
+func normalizeModelName(name string) string {
+	if name == "" || strings.Contains(name[strings.LastIndex(name, "/")+1:], ":") {
+		return name
+	}
+	return name + ":latest"
+}
+
+// FormatSize formata tamanho em bytes (ex: "4.7 GB")
+func FormatSize(bytes int64) string {
+	const unit = 1000
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,150 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newModelsServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/tags", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"models": [
+			{"name": "qwen2.5-coder:7b", "size": 4700000000, "details": {"parameter_size": "7.6B"}},
+			{"name": "llama3:latest", "size": 1000}
+		]}`))
+	})
+	mux.HandleFunc("/api/show", func(w http.ResponseWriter, r *http.Request) {
+		var req modelRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "qwen2.5-coder:7b" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "model not found"}`))
+			return
+		}
+		w.Write([]byte(`{"details": {"family": "qwen2"}, "capabilities": ["completion", "tools"]}`))
+	})
+	mux.HandleFunc("/api/pull", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": "pulling manifest"}
+{"status": "downloading", "digest": "sha256:abc", "total": 100, "completed": 50}
+{"status": "success"}
+`))
+	})
+	mux.HandleFunc("/api/delete", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("Expected DELETE, got %s", r.Method)
+		}
+	})
+	mux.HandleFunc("/api/ps", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"models": [{"name": "qwen2.5-coder:7b", "size_vram": 5000}]}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestListModels(t *testing.T) {
+	client := NewClient(newModelsServer(t).URL, "")
+
+	models, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+
+	if len(models) != 2 || models[0].Details.ParameterSize != "7.6B" {
+		t.Errorf("Unexpected models: %+v", models)
+	}
+}
+
+func TestMissingModels_NormalizesLatestTag(t *testing.T) {
+	client := NewClient(newModelsServer(t).URL, "")
+
+	missing, err := MissingModels(context.Background(), client, "llama3", "qwen2.5-coder:7b", "mistral", "mistral")
+	if err != nil {
+		t.Fatalf("MissingModels failed: %v", err)
+	}
+
+	if len(missing) != 1 || missing[0] != "mistral" {
+		t.Errorf("Expected only mistral missing, got %v", missing)
+	}
+}
+
+func TestShowModel(t *testing.T) {
+	client := NewClient(newModelsServer(t).URL, "")
+
+	info, err := client.ShowModel(context.Background(), "qwen2.5-coder:7b")
+	if err != nil {
+		t.Fatalf("ShowModel failed: %v", err)
+	}
+	if info.Details.Family != "qwen2" || len(info.Capabilities) != 2 {
+		t.Errorf("Unexpected details: %+v", info)
+	}
+
+	if _, err := client.ShowModel(context.Background(), "missing"); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound, got %v", err)
+	}
+}
+
+func TestPullModel_ReportsProgress(t *testing.T) {
+	client := NewClient(newModelsServer(t).URL, "")
+
+	var progress []PullProgress
+	err := client.PullModel(context.Background(), "qwen2.5-coder:7b", func(p PullProgress) {
+		progress = append(progress, p)
+	})
+	if err != nil {
+		t.Fatalf("PullModel failed: %v", err)
+	}
+
+	if len(progress) != 3 {
+		t.Fatalf("Expected 3 progress updates, got %d", len(progress))
+	}
+	if progress[1].Percent() != 50 {
+		t.Errorf("Expected 50%%, got %.1f", progress[1].Percent())
+	}
+}
+
+func TestPullModel_StreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error": "pull model manifest: file does not exist"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "")
+	if err := client.PullModel(context.Background(), "nope", nil); err == nil {
+		t.Error("Expected error from stream")
+	}
+}
+
+func TestDeleteAndRunningModels(t *testing.T) {
+	client := NewClient(newModelsServer(t).URL, "")
+
+	if err := client.DeleteModel(context.Background(), "llama3"); err != nil {
+		t.Errorf("DeleteModel failed: %v", err)
+	}
+
+	running, err := client.RunningModels(context.Background())
+	if err != nil {
+		t.Fatalf("RunningModels failed: %v", err)
+	}
+	if len(running) != 1 || running[0].SizeVRAM != 5000 {
+		t.Errorf("Unexpected running models: %+v", running)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		512:        "512 B",
+		1500:       "1.5 KB",
+		4700000000: "4.7 GB",
+	}
+	for bytes, expected := range tests {
+		if got := FormatSize(bytes); got != expected {
+			t.Errorf("FormatSize(%d) = %s, expected %s", bytes, got, expected)
+		}
+	}
+}
@@ -0,0 +1,637 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OpenAIClient cliente para servidores compatíveis com a API OpenAI
+// (/v1/chat/completions), como llama.cpp server e vLLM.
+//
+// Opções exclusivas do Ollama (num_ctx, num_gpu, keep_alive, repeat_penalty)
+// são configuradas no próprio servidor e não são enviadas.
+type OpenAIClient struct {
+	*transport // Timeouts, retry e circuit breaker
+
+	baseURL  string // Inclui o prefixo /v1
+	model    string
+	defaults CompletionOptions
+	onUsage  func(model string, usage Usage)
+}
+
+// NewOpenAIClient cria novo cliente OpenAI-compatible.
+// baseURL pode ou não terminar em /v1 (ex: "http://localhost:8080").
+func NewOpenAIClient(baseURL, model string) *OpenAIClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	if !strings.HasSuffix(baseURL, "/v1") {
+		baseURL += "/v1"
+	}
+
+	return &OpenAIClient{
+		transport: newTransport(DefaultTransportConfig()),
+		baseURL:   baseURL,
+		model:     model,
+	}
+}
+
+// openAIMessage mensagem no formato OpenAI
+type openAIMessage struct {
+	Role       string           `json:"role,omitempty"`
+	Content    *string          `json:"content,omitempty"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+	Name       string           `json:"name,omitempty"`
+
+	// ReasoningContent raciocínio separado (vLLM e llama.cpp com --reasoning-format)
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+
+	// Parts conteúdo multimodal (texto + imagens); substitui Content no JSON
+	Parts []openAIContentPart `json:"-"`
+}
+
+// openAIContentPart parte de conteúdo multimodal
+type openAIContentPart struct {
+	Type     string          `json:"type"` // "text" ou "image_url"
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+// openAIImageURL imagem referenciada por URL (aqui sempre data URL em base64)
+type openAIImageURL struct {
+	URL string `json:"url"`
+}
+
+// MarshalJSON envia content como lista de partes quando a mensagem tem imagens
+func (m openAIMessage) MarshalJSON() ([]byte, error) {
+	type plain openAIMessage
+	if len(m.Parts) == 0 {
+		return json.Marshal(plain(m))
+	}
+
+	return json.Marshal(struct {
+		plain
+		Content []openAIContentPart `json:"content"`
+	}{plain(m), m.Parts})
+}
+
+// openAIToolCall chamada de ferramenta (argumentos chegam como string JSON)
+type openAIToolCall struct {
+	Index    *int   `json:"index,omitempty"` // Presente apenas nos deltas de streaming
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments,omitempty"`
+	} `json:"function"`
+}
+
+// openAIRequest corpo de /v1/chat/completions
+type openAIRequest struct {
+	Model          string                 `json:"model"`
+	Messages       []openAIMessage        `json:"messages"`
+	Stream         bool                   `json:"stream"`
+	StreamOptions  *openAIStreamOptions   `json:"stream_options,omitempty"`
+	Tools          []Tool                 `json:"tools,omitempty"`
+	Temperature    *float64               `json:"temperature,omitempty"`
+	MaxTokens      int                    `json:"max_tokens,omitempty"`
+	TopP           float64                `json:"top_p,omitempty"`
+	TopK           int                    `json:"top_k,omitempty"` // Extensão aceita por llama.cpp e vLLM
+	Seed           *int                   `json:"seed,omitempty"`
+	Stop           []string               `json:"stop,omitempty"`
+	ResponseFormat map[string]interface{} `json:"response_format,omitempty"`
+
+	// ChatTemplateKwargs liga/desliga o raciocínio no template (enable_thinking do qwen3)
+	ChatTemplateKwargs map[string]interface{} `json:"chat_template_kwargs,omitempty"`
+}
+
+// openAIStreamOptions pede o uso de tokens no último chunk do stream
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// openAIResponse resposta (ou chunk de streaming) de /v1/chat/completions
+type openAIResponse struct {
+	Model   string `json:"model"`
+	Created int64  `json:"created"`
+	Choices []struct {
+		Message      openAIMessage `json:"message"`
+		Delta        openAIMessage `json:"delta"`
+		FinishReason string        `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// SetDefaultOptions define opções aplicadas a toda requisição
+func (c *OpenAIClient) SetDefaultOptions(opts CompletionOptions) {
+	c.defaults = opts
+}
+
+// DefaultOptions retorna as opções padrão do cliente
+func (c *OpenAIClient) DefaultOptions() CompletionOptions {
+	return c.defaults
+}
+
+// WithOptions retorna cópia do cliente (mesma conexão e hooks) com outras opções padrão
+func (c *OpenAIClient) WithOptions(opts CompletionOptions) Provider {
+	clone := *c
+	clone.defaults = c.defaults.Merge(&opts)
+	return &clone
+}
+
+// SetUsageHook define callback chamado com o uso de tokens de cada resposta
+func (c *OpenAIClient) SetUsageHook(fn func(model string, usage Usage)) {
+	c.onUsage = fn
+}
+
+// GetModel retorna o modelo configurado
+func (c *OpenAIClient) GetModel() string {
+	return c.model
+}
+
+// SetModel altera o modelo
+func (c *OpenAIClient) SetModel(model string) {
+	c.model = model
+}
+
+// Complete faz uma chamada completa (não streaming)
+func (c *OpenAIClient) Complete(ctx context.Context, messages []Message, opts *CompletionOptions) (string, Usage, error) {
+	response, err := c.Chat(ctx, messages, opts)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	return response.Message.Content, response.Usage, nil
+}
+
+// CompleteStreaming faz chamada com streaming
+func (c *OpenAIClient) CompleteStreaming(ctx context.Context, messages []Message, opts *CompletionOptions, onChunk func(string)) (string, Usage, error) {
+	response, err := c.ChatStream(ctx, messages, opts, onChunk)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	return response.Message.Content, response.Usage, nil
+}
+
+// CompleteStructured pede resposta no formato do schema (response_format),
+// valida e decodifica em target, reenviando o erro ao modelo até maxRetries vezes
+func (c *OpenAIClient) CompleteStructured(ctx context.Context, messages []Message, opts *CompletionOptions, schema map[string]interface{}, target interface{}, maxRetries int) error {
+	return completeStructured(ctx, c, messages, opts, schema, target, maxRetries)
+}
+
+// Chat faz chamada não streaming e retorna a resposta completa (conteúdo e tool calls)
+func (c *OpenAIClient) Chat(ctx context.Context, messages []Message, opts *CompletionOptions) (*Response, error) {
+	start := time.Now()
+
+	req := c.buildRequest(messages, opts, false)
+	cacheKey, cached := c.cachedResponse(ctx, req, req.Temperature, req.Seed, len(req.Tools) > 0)
+	if cached != nil {
+		return cached, nil
+	}
+
+	resp, err := c.doChat(ctx, req, c.defaults.Merge(opts).Fallbacks)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var raw openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(raw.Choices) == 0 {
+		return nil, fmt.Errorf("empty response: no choices")
+	}
+
+	message := raw.Choices[0].Message
+	content := ""
+	if message.Content != nil {
+		content = *message.Content
+	}
+
+	response := &Response{
+		Model:     raw.Model,
+		CreatedAt: formatCreated(raw.Created),
+		Done:      true,
+		Message: separateThinking(Message{
+			Role:      "assistant",
+			Content:   content,
+			Thinking:  message.ReasoningContent,
+			ToolCalls: fromOpenAIToolCalls(message.ToolCalls),
+		}),
+	}
+
+	elapsed := time.Since(start)
+	response.Usage = openAIUsage(raw, elapsed, elapsed)
+	c.reportUsage(response)
+	c.storeResponse(cacheKey, req.Model, response)
+
+	return response, nil
+}
+
+// ChatStream faz chamada com streaming (SSE) e agrega os chunks em uma única resposta
+func (c *OpenAIClient) ChatStream(ctx context.Context, messages []Message, opts *CompletionOptions, onChunk func(string)) (*Response, error) {
+	start := time.Now()
+
+	req := c.buildRequest(messages, opts, true)
+	resp, err := c.doChat(ctx, req, c.defaults.Merge(opts).Fallbacks)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var fullResponse, fullThinking strings.Builder
+	var firstToken time.Time
+	calls := map[int]*openAIToolCall{}
+	final := &Response{Done: true}
+	var last openAIResponse
+
+	var onThinking func(string)
+	if opts != nil {
+		onThinking = opts.OnThinking
+	}
+	emit := func(content, thinking string) {
+		if thinking != "" {
+			fullThinking.WriteString(thinking)
+			if onThinking != nil {
+				onThinking(thinking)
+			}
+		}
+		if content != "" {
+			fullResponse.WriteString(content)
+			if onChunk != nil {
+				onChunk(content)
+			}
+		}
+	}
+	var splitter thinkingStream
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk openAIResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("decode response: %w", err)
+		}
+
+		if chunk.Model != "" {
+			final.Model = chunk.Model
+		}
+		if chunk.Created != 0 {
+			final.CreatedAt = formatCreated(chunk.Created)
+		}
+		if chunk.Usage != nil {
+			last.Usage = chunk.Usage
+		}
+
+		for _, choice := range chunk.Choices {
+			delta := choice.Delta
+			if (delta.Content != nil && *delta.Content != "") || delta.ReasoningContent != "" {
+				if firstToken.IsZero() {
+					firstToken = time.Now()
+				}
+			}
+			emit("", delta.ReasoningContent)
+			if delta.Content != nil {
+				emit(splitter.Write(*delta.Content))
+			}
+
+			// Tool calls chegam fragmentadas: nome no primeiro delta, argumentos em pedaços
+			for _, part := range delta.ToolCalls {
+				index := len(calls)
+				if part.Index != nil {
+					index = *part.Index
+				}
+				call, exists := calls[index]
+				if !exists {
+					call = &openAIToolCall{}
+					calls[index] = call
+				}
+				if part.ID != "" {
+					call.ID = part.ID
+				}
+				if part.Function.Name != "" {
+					call.Function.Name = part.Function.Name
+				}
+				call.Function.Arguments += part.Function.Arguments
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read stream: %w", err)
+	}
+
+	indexes := make([]int, 0, len(calls))
+	for index := range calls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	ordered := make([]openAIToolCall, 0, len(indexes))
+	for _, index := range indexes {
+		ordered = append(ordered, *calls[index])
+	}
+
+	emit(splitter.Flush())
+
+	final.Message = separateThinking(Message{
+		Role:      "assistant",
+		Content:   fullResponse.String(),
+		Thinking:  fullThinking.String(),
+		ToolCalls: fromOpenAIToolCalls(ordered),
+	})
+
+	// Sem durações no protocolo: geração medida a partir do primeiro token
+	total := time.Since(start)
+	generation := total
+	if !firstToken.IsZero() {
+		generation = time.Since(firstToken)
+	}
+	final.Usage = openAIUsage(last, total, generation)
+	c.reportUsage(final)
+
+	return final, nil
+}
+
+// ListModels lista modelos servidos (/v1/models)
+func (c *OpenAIClient) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	resp, err := c.do(ctx, c.model, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/models", nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []struct {
+			ID      string `json:"id"`
+			Created int64  `json:"created"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	models := make([]ModelInfo, 0, len(result.Data))
+	for _, model := range result.Data {
+		info := ModelInfo{Name: model.ID, Model: model.ID}
+		if model.Created > 0 {
+			info.ModifiedAt = time.Unix(model.Created, 0)
+		}
+		models = append(models, info)
+	}
+	return models, nil
+}
+
+// reportUsage repassa o uso da resposta para o hook configurado
+func (c *OpenAIClient) reportUsage(response *Response) {
+	if c.onUsage == nil {
+		return
+	}
+
+	model := response.Model
+	if model == "" {
+		model = c.model
+	}
+
+	c.onUsage(model, response.Usage)
+}
+
+// buildRequest monta a requisição para /v1/chat/completions
+func (c *OpenAIClient) buildRequest(messages []Message, opts *CompletionOptions, stream bool) openAIRequest {
+	merged := c.defaults.Merge(opts)
+
+	if merged.SystemPrompt != "" {
+		messages = append([]Message{{
+			Role:    "system",
+			Content: merged.SystemPrompt,
+		}}, messages...)
+	}
+
+	req := openAIRequest{
+		Model:       c.model,
+		Messages:    toOpenAIMessages(messages),
+		Stream:      stream,
+		Tools:       merged.Tools,
+		Temperature: merged.Temperature,
+		MaxTokens:   merged.MaxTokens,
+		TopP:        merged.TopP,
+		TopK:        merged.TopK,
+		Seed:        merged.Seed,
+		Stop:        merged.Stop,
+	}
+
+	if stream {
+		req.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+
+	if len(merged.Format) > 0 {
+		req.ResponseFormat = openAIResponseFormat(merged.Format)
+	}
+
+	if merged.Think != nil {
+		req.ChatTemplateKwargs = map[string]interface{}{"enable_thinking": *merged.Think}
+	}
+
+	return req
+}
+
+// doChat envia a requisição ao modelo ou, se ele estiver indisponível, aos fallbacks
+func (c *OpenAIClient) doChat(ctx context.Context, req openAIRequest, fallbacks []string) (*http.Response, error) {
+	return c.withFallback(req.Model, fallbacks, func(model string) (*http.Response, error) {
+		req.Model = model
+		return c.sendChat(ctx, req)
+	})
+}
+
+// sendChat envia a requisição (com retry de falhas transitórias) e valida o status HTTP
+func (c *OpenAIClient) sendChat(ctx context.Context, req openAIRequest) (*http.Response, error) {
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	// Espera a vaga no scheduler, que fica ocupada até o fim da resposta
+	done, err := c.schedule(ctx, req.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, req.Model, func(ctx context.Context) (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		if req.Stream {
+			httpReq.Header.Set("Accept", "text/event-stream")
+		}
+		return httpReq, nil
+	})
+	if err != nil {
+		done()
+		return nil, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: done}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		// llama.cpp sem --jinja e vLLM sem --enable-auto-tool-choice recusam "tools"
+		if resp.StatusCode == http.StatusBadRequest && len(req.Tools) > 0 && strings.Contains(strings.ToLower(string(body)), "tool") {
+			return nil, fmt.Errorf("%w: unexpected status %d: %s", ErrToolsNotSupported, resp.StatusCode, string(body))
+		}
+		return nil, statusError(resp.StatusCode, body)
+	}
+
+	return resp, nil
+}
+
+// toOpenAIMessages converte mensagens para o formato OpenAI
+func toOpenAIMessages(messages []Message) []openAIMessage {
+	converted := make([]openAIMessage, 0, len(messages))
+	for _, msg := range messages {
+		content := msg.Content
+		out := openAIMessage{
+			Role:    msg.Role,
+			Content: &content,
+		}
+
+		if msg.Role == "tool" {
+			out.ToolCallID = msg.ToolCallID
+			out.Name = msg.ToolName
+		}
+
+		for i, call := range msg.ToolCalls {
+			args, _ := json.Marshal(call.Function.Arguments)
+			if call.Function.Arguments == nil {
+				args = []byte("{}")
+			}
+
+			id := call.ID
+			if id == "" {
+				id = fmt.Sprintf("call_%d", i)
+			}
+
+			wire := openAIToolCall{ID: id, Type: "function"}
+			wire.Function.Name = call.Function.Name
+			wire.Function.Arguments = string(args)
+			out.ToolCalls = append(out.ToolCalls, wire)
+		}
+
+		// Assistente que só chama ferramentas envia content null
+		if msg.Role == "assistant" && msg.Content == "" && len(out.ToolCalls) > 0 {
+			out.Content = nil
+		}
+
+		if len(msg.Images) > 0 {
+			out.Parts = append(out.Parts, openAIContentPart{Type: "text", Text: msg.Content})
+			for _, image := range msg.Images {
+				out.Parts = append(out.Parts, openAIContentPart{
+					Type:     "image_url",
+					ImageURL: &openAIImageURL{URL: imageDataURL(image)},
+				})
+			}
+		}
+
+		converted = append(converted, out)
+	}
+	return converted
+}
+
+// imageDataURL converte imagem em base64 para data URL (tipo detectado pelo conteúdo)
+func imageDataURL(image string) string {
+	header := image
+	if len(header) > 64 {
+		header = header[:64]
+	}
+	data, _ := base64.StdEncoding.DecodeString(header[:len(header)/4*4])
+
+	return "data:" + http.DetectContentType(data) + ";base64," + image
+}
+
+// fromOpenAIToolCalls converte tool calls OpenAI (argumentos em string JSON)
+func fromOpenAIToolCalls(calls []openAIToolCall) []ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	converted := make([]ToolCall, 0, len(calls))
+	for _, call := range calls {
+		args := map[string]interface{}{}
+		if strings.TrimSpace(call.Function.Arguments) != "" {
+			json.Unmarshal([]byte(call.Function.Arguments), &args)
+		}
+
+		converted = append(converted, ToolCall{
+			ID: call.ID,
+			Function: ToolCallFunction{
+				Name:      call.Function.Name,
+				Arguments: args,
+			},
+		})
+	}
+	return converted
+}
+
+// openAIResponseFormat converte o "format" do Ollama ("json" ou schema) em response_format
+func openAIResponseFormat(format json.RawMessage) map[string]interface{} {
+	if bytes.Equal(bytes.TrimSpace(format), FormatJSON) {
+		return map[string]interface{}{"type": "json_object"}
+	}
+
+	return map[string]interface{}{
+		"type": "json_schema",
+		"json_schema": map[string]interface{}{
+			"name":   "response",
+			"schema": format,
+		},
+	}
+}
+
+// openAIUsage converte o uso OpenAI, com durações medidas pelo cliente
+func openAIUsage(raw openAIResponse, total, generation time.Duration) Usage {
+	usage := Usage{TotalDuration: total}
+	if raw.Usage != nil {
+		usage.PromptTokens = raw.Usage.PromptTokens
+		usage.CompletionTokens = raw.Usage.CompletionTokens
+		usage.EvalDuration = generation
+	}
+	return usage
+}
+
+// formatCreated converte timestamp unix para o formato de Response.CreatedAt
+func formatCreated(created int64) string {
+	if created == 0 {
+		return ""
+	}
+	return time.Unix(created, 0).UTC().Format(time.RFC3339)
+}
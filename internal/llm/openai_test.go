@@ -0,0 +1,235 @@
+package llm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewOpenAIClient_NormalizesBaseURL(t *testing.T) {
+	for _, url := range []string{"http://localhost:8080", "http://localhost:8080/", "http://localhost:8080/v1"} {
+		if client := NewOpenAIClient(url, "m"); client.baseURL != "http://localhost:8080/v1" {
+			t.Errorf("Unexpected base URL for %s: %s", url, client.baseURL)
+		}
+	}
+}
+
+func TestOpenAIChat_SendsToolsAndParsesToolCalls(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+
+		w.Write([]byte(`{
+			"model": "qwen",
+			"choices": [{"message": {"role": "assistant", "content": null, "tool_calls": [
+				{"id": "call_1", "type": "function", "function": {"name": "file_reader", "arguments": "{\"file_path\": \"main.go\"}"}}
+			]}, "finish_reason": "tool_calls"}],
+			"usage": {"prompt_tokens": 20, "completion_tokens": 5}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL, "qwen")
+	var reported Usage
+	client.SetUsageHook(func(model string, usage Usage) { reported = usage })
+
+	tools := []Tool{NewTool("file_reader", "Read a file", map[string]interface{}{"type": "object"})}
+	history := []Message{
+		{Role: "user", Content: "leia"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_0", Function: ToolCallFunction{Name: "file_reader", Arguments: map[string]interface{}{"file_path": "a.go"}}}}},
+		{Role: "tool", Content: "ok", ToolName: "file_reader", ToolCallID: "call_0"},
+	}
+
+	response, err := client.Chat(context.Background(), history, &CompletionOptions{Tools: tools, MaxTokens: 100})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	if len(response.Message.ToolCalls) != 1 || response.Message.ToolCalls[0].Function.Arguments["file_path"] != "main.go" {
+		t.Fatalf("Unexpected tool calls: %+v", response.Message.ToolCalls)
+	}
+	if response.Message.ToolCalls[0].ID != "call_1" {
+		t.Errorf("Tool call ID should be preserved")
+	}
+	if reported.PromptTokens != 20 || reported.CompletionTokens != 5 {
+		t.Errorf("Unexpected usage: %+v", reported)
+	}
+
+	if received["max_tokens"] != float64(100) || received["tools"] == nil {
+		t.Errorf("Unexpected request: %v", received)
+	}
+
+	messages := received["messages"].([]interface{})
+	assistant := messages[1].(map[string]interface{})
+	if assistant["content"] != nil {
+		t.Errorf("Assistant tool call message should send null content, got %v", assistant["content"])
+	}
+	call := assistant["tool_calls"].([]interface{})[0].(map[string]interface{})
+	if args := call["function"].(map[string]interface{})["arguments"]; args != `{"file_path":"a.go"}` {
+		t.Errorf("Arguments should be sent as JSON string, got %v", args)
+	}
+	if tool := messages[2].(map[string]interface{}); tool["tool_call_id"] != "call_0" {
+		t.Errorf("Tool result should carry tool_call_id, got %v", tool)
+	}
+}
+
+func TestOpenAIChatStream_AggregatesSSE(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream || req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Error("Streaming request should ask for usage")
+		}
+
+		chunks := []string{
+			`{"model":"qwen","choices":[{"delta":{"role":"assistant","content":"Ol"}}]}`,
+			`{"choices":[{"delta":{"content":"á"}}]}`,
+			`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_9","type":"function","function":{"name":"code_searcher","arguments":"{\"que"}}]}}]}`,
+			`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"ry\":\"main\"}"}}]}}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":7}}`,
+		}
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL, "qwen")
+
+	var streamed strings.Builder
+	response, err := client.ChatStream(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil, func(chunk string) {
+		streamed.WriteString(chunk)
+	})
+	if err != nil {
+		t.Fatalf("ChatStream failed: %v", err)
+	}
+
+	if streamed.String() != "Olá" || response.Message.Content != "Olá" {
+		t.Errorf("Unexpected content: %q / %q", streamed.String(), response.Message.Content)
+	}
+
+	if len(response.Message.ToolCalls) != 1 {
+		t.Fatalf("Expected 1 aggregated tool call, got %+v", response.Message.ToolCalls)
+	}
+	call := response.Message.ToolCalls[0]
+	if call.ID != "call_9" || call.Function.Name != "code_searcher" || call.Function.Arguments["query"] != "main" {
+		t.Errorf("Unexpected tool call: %+v", call)
+	}
+
+	if response.Model != "qwen" || response.PromptTokens != 12 || response.CompletionTokens != 7 {
+		t.Errorf("Unexpected usage/model: %+v", response)
+	}
+}
+
+func TestOpenAIChat_ResponseFormat(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "{\"answer\": \"yes\"}"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL, "qwen")
+
+	var result structuredTestResult
+	if err := client.CompleteStructured(context.Background(), []Message{{Role: "user", Content: "?"}}, nil, GenerateSchema(result), &result, 0); err != nil {
+		t.Fatalf("CompleteStructured failed: %v", err)
+	}
+
+	format := received["response_format"].(map[string]interface{})
+	if format["type"] != "json_schema" {
+		t.Errorf("Expected json_schema response format, got %v", format)
+	}
+	if result.Answer != "yes" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	client.Complete(context.Background(), []Message{{Role: "user", Content: "?"}}, &CompletionOptions{Format: FormatJSON})
+	if received["response_format"].(map[string]interface{})["type"] != "json_object" {
+		t.Errorf("FormatJSON should map to json_object, got %v", received["response_format"])
+	}
+}
+
+func TestOpenAIChat_ToolsNotSupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"message": "tools param requires --jinja flag"}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL, "qwen")
+	tools := []Tool{NewTool("x", "x", map[string]interface{}{"type": "object"})}
+
+	_, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, &CompletionOptions{Tools: tools})
+	if !errors.Is(err, ErrToolsNotSupported) {
+		t.Errorf("Expected ErrToolsNotSupported, got %v", err)
+	}
+}
+
+func TestOpenAIListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"object": "list", "data": [{"id": "qwen2.5-coder-7b", "object": "model"}]}`))
+	}))
+	defer server.Close()
+
+	installed, err := HasModel(context.Background(), NewOpenAIClient(server.URL, ""), "qwen2.5-coder-7b")
+	if err != nil || !installed {
+		t.Errorf("Expected model listed, got %v (%v)", installed, err)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	if p, err := NewProvider("", "http://localhost:11434", "m"); err != nil {
+		t.Errorf("Empty kind should default to Ollama: %v", err)
+	} else if _, ok := p.(*Client); !ok {
+		t.Errorf("Expected *Client, got %T", p)
+	}
+
+	if p, _ := NewProvider("openai", "http://localhost:8080", "m"); p == nil {
+		t.Error("Expected OpenAI provider")
+	} else if _, ok := p.(*OpenAIClient); !ok {
+		t.Errorf("Expected *OpenAIClient, got %T", p)
+	}
+
+	if _, err := NewProvider("bogus", "", "m"); err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+}
+
+func TestToOpenAIMessages_ImagesAsContentParts(t *testing.T) {
+	png := base64.StdEncoding.EncodeToString([]byte("\x89PNG\r\n\x1a\n0000000000"))
+	messages := toOpenAIMessages([]Message{
+		{Role: "user", Content: "o que é isso?", Images: []string{png}},
+		{Role: "assistant", Content: "um diagrama"},
+	})
+
+	data, err := json.Marshal(messages)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var wire []map[string]interface{}
+	json.Unmarshal(data, &wire)
+
+	parts, ok := wire[0]["content"].([]interface{})
+	if !ok || len(parts) != 2 {
+		t.Fatalf("Expected text + image parts, got %s", data)
+	}
+	image := parts[1].(map[string]interface{})["image_url"].(map[string]interface{})["url"].(string)
+	if image != "data:image/png;base64,"+png {
+		t.Errorf("Unexpected image URL: %s", image)
+	}
+	if wire[1]["content"] != "um diagrama" {
+		t.Errorf("Text-only messages should keep string content, got %s", data)
+	}
+}
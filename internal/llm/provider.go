@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Tipos de provider suportados
+const (
+	ProviderOllama = "ollama" // API nativa do Ollama (/api/chat)
+	ProviderOpenAI = "openai" // API compatível com OpenAI (/v1/chat/completions): llama.cpp, vLLM...
+)
+
+// Provider backend de chat usado pelo agente, detector, subagentes e router
+type Provider interface {
+	// Chat faz chamada não streaming e retorna a resposta completa (conteúdo e tool calls)
+	Chat(ctx context.Context, messages []Message, opts *CompletionOptions) (*Response, error)
+
+	// ChatStream faz chamada com streaming e agrega os chunks em uma única resposta
+	ChatStream(ctx context.Context, messages []Message, opts *CompletionOptions, onChunk func(string)) (*Response, error)
+
+	// Complete retorna apenas o conteúdo e o uso de uma chamada não streaming
+	Complete(ctx context.Context, messages []Message, opts *CompletionOptions) (string, Usage, error)
+
+	// CompleteStreaming retorna apenas o conteúdo e o uso de uma chamada com streaming
+	CompleteStreaming(ctx context.Context, messages []Message, opts *CompletionOptions, onChunk func(string)) (string, Usage, error)
+
+	// CompleteStructured pede resposta no formato do schema e decodifica em target
+	CompleteStructured(ctx context.Context, messages []Message, opts *CompletionOptions, schema map[string]interface{}, target interface{}, maxRetries int) error
+
+	GetModel() string
+	SetModel(model string)
+	DefaultOptions() CompletionOptions
+	SetDefaultOptions(opts CompletionOptions)
+
+	// WithOptions retorna cópia do provider (mesma conexão e hooks) com outras opções padrão
+	WithOptions(opts CompletionOptions) Provider
+
+	// SetUsageHook define callback chamado com o uso de tokens de cada resposta
+	SetUsageHook(fn func(model string, usage Usage))
+
+	// SetRetryHook define callback chamado antes de cada nova tentativa
+	SetRetryHook(fn func(event RetryEvent))
+
+	// SetFallbackHook define callback chamado quando um modelo de fallback assume a requisição
+	SetFallbackHook(fn func(event FallbackEvent))
+
+	// SetModelHealth define o registro de modelos em cool-down usado nos fallbacks
+	SetModelHealth(health *ModelHealth)
+
+	// SetHostPool define o pool de servidores Ollama entre os quais as requisições são distribuídas
+	SetHostPool(pool *HostPool)
+
+	// SetScheduler define o scheduler compartilhado que limita as requisições simultâneas
+	SetScheduler(scheduler *Scheduler)
+
+	// SetResponseCache define o cache em disco das respostas de prompts determinísticos
+	SetResponseCache(cache *ResponseCache)
+
+	// SetTransportConfig altera timeouts, retry, circuit breaker e conexão (headers, TLS, proxy)
+	SetTransportConfig(cfg TransportConfig)
+	TransportConfig() TransportConfig
+}
+
+// NewProvider cria provider pelo tipo ("ollama" quando vazio)
+func NewProvider(kind, baseURL, model string) (Provider, error) {
+	switch strings.ToLower(kind) {
+	case "", ProviderOllama:
+		return NewClient(baseURL, model), nil
+	case ProviderOpenAI:
+		return NewOpenAIClient(baseURL, model), nil
+	default:
+		return nil, fmt.Errorf("unknown provider: %s", kind)
+	}
+}
+
+// IsValidProvider indica se o tipo de provider é suportado
+func IsValidProvider(kind string) bool {
+	switch strings.ToLower(kind) {
+	case "", ProviderOllama, ProviderOpenAI:
+		return true
+	default:
+		return false
+	}
+}
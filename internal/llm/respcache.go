@@ -0,0 +1,284 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheMaxBytes tamanho máximo padrão do cache de respostas em disco
+const DefaultCacheMaxBytes = 100 << 20
+
+// ResponseCacheConfig configuração do cache de respostas
+type ResponseCacheConfig struct {
+	Dir      string // Diretório das respostas
+	MaxBytes int64  // Tamanho máximo; acima dele sai a resposta usada há mais tempo (0 = padrão)
+}
+
+// ResponseCacheStats estado atual do cache de respostas
+type ResponseCacheStats struct {
+	Entries  int
+	Bytes    int64
+	MaxBytes int64
+}
+
+// cacheEntry resposta gravada em disco
+type cacheEntry struct {
+	size     int64
+	lastUsed time.Time
+}
+
+// ResponseCache cache em disco de respostas não streaming para prompts
+// determinísticos. A chave combina modelo, opções e mensagens; requisições
+// com ferramentas ou sem temperatura 0 explícita não passam pelo cache.
+type ResponseCache struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	entries  map[string]*cacheEntry
+	total    int64
+	wrapGet  func(ctx context.Context, key string, get func() (interface{}, bool)) (interface{}, bool)
+	now      func() time.Time
+}
+
+// NewResponseCache abre (ou cria) o cache no diretório, recuperando as respostas já gravadas
+func NewResponseCache(cfg ResponseCacheConfig) (*ResponseCache, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("response cache dir is required")
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultCacheMaxBytes
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+
+	c := &ResponseCache{
+		dir:      cfg.Dir,
+		maxBytes: cfg.MaxBytes,
+		entries:  make(map[string]*cacheEntry),
+		now:      time.Now,
+	}
+
+	files, err := os.ReadDir(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("read cache dir: %w", err)
+	}
+	for _, file := range files {
+		key, ok := strings.CutSuffix(file.Name(), ".json")
+		if !ok || file.IsDir() {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		c.entries[key] = &cacheEntry{size: info.Size(), lastUsed: info.ModTime()}
+		c.total += info.Size()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict()
+
+	return c, nil
+}
+
+// SetGetWrapper define wrapper das consultas ao cache (ex: observability.CacheWrapper.WrapCacheGet)
+func (c *ResponseCache) SetGetWrapper(fn func(ctx context.Context, key string, get func() (interface{}, bool)) (interface{}, bool)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.wrapGet = fn
+}
+
+// Cacheable indica se a requisição pode usar o cache: só temperatura 0 explícita
+// gera sempre a mesma resposta. Sem temperatura (nil) o modelo usa a própria, e
+// seed negativa pede uma aleatória a cada chamada.
+func (c *ResponseCache) Cacheable(temperature *float64, seed *int, hasTools bool) bool {
+	if c == nil || hasTools || temperature == nil || *temperature != 0 {
+		return false
+	}
+	return seed == nil || *seed >= 0
+}
+
+// Key chave da requisição: hash do corpo, que inclui modelo, opções e mensagens
+func (c *ResponseCache) Key(request interface{}) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("marshal cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Get busca a resposta gravada para a chave
+func (c *ResponseCache) Get(ctx context.Context, key string) (*Response, bool) {
+	c.mu.Lock()
+	wrap := c.wrapGet
+	c.mu.Unlock()
+
+	get := func() (interface{}, bool) { return c.load(key) }
+	if wrap == nil {
+		value, hit := get()
+		response, _ := value.(*Response)
+		return response, hit
+	}
+
+	value, hit := wrap(ctx, "llm:"+key[:12], get)
+	response, ok := value.(*Response)
+	return response, hit && ok
+}
+
+// load lê a resposta do disco e marca a entrada como usada
+func (c *ResponseCache) load(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	var response Response
+	if err == nil {
+		err = json.Unmarshal(data, &response)
+	}
+	if err != nil {
+		c.remove(key)
+		return nil, false
+	}
+
+	entry.lastUsed = c.now()
+	os.Chtimes(c.path(key), entry.lastUsed, entry.lastUsed)
+	return &response, true
+}
+
+// Put grava a resposta e remove as usadas há mais tempo se o limite for excedido
+func (c *ResponseCache) Put(key string, response *Response) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("marshal cached response: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tmp := c.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write cached response: %w", err)
+	}
+	if err := os.Rename(tmp, c.path(key)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write cached response: %w", err)
+	}
+
+	if old, ok := c.entries[key]; ok {
+		c.total -= old.size
+	}
+	c.entries[key] = &cacheEntry{size: int64(len(data)), lastUsed: c.now()}
+	c.total += int64(len(data))
+	c.evict()
+
+	return nil
+}
+
+// Clear apaga todas as respostas gravadas
+func (c *ResponseCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		c.remove(key)
+	}
+}
+
+// Stats retorna quantidade e tamanho das respostas gravadas
+func (c *ResponseCache) Stats() ResponseCacheStats {
+	if c == nil {
+		return ResponseCacheStats{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ResponseCacheStats{Entries: len(c.entries), Bytes: c.total, MaxBytes: c.maxBytes}
+}
+
+// evict remove as respostas usadas há mais tempo até caber no limite (chamado com o lock)
+func (c *ResponseCache) evict() {
+	if c.total <= c.maxBytes {
+		return
+	}
+
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].lastUsed.Before(c.entries[keys[j]].lastUsed)
+	})
+
+	for _, key := range keys {
+		if c.total <= c.maxBytes {
+			return
+		}
+		c.remove(key)
+	}
+}
+
+// remove apaga a resposta do disco e do índice (chamado com o lock)
+func (c *ResponseCache) remove(key string) {
+	if entry, ok := c.entries[key]; ok {
+		c.total -= entry.size
+		delete(c.entries, key)
+	}
+	os.Remove(c.path(key))
+}
+
+// path arquivo da resposta
+func (c *ResponseCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// SetResponseCache define o cache de respostas em disco (nil desativa)
+func (t *transport) SetResponseCache(cache *ResponseCache) {
+	t.cache = cache
+}
+
+// cachedResponse busca a requisição no cache. key vazia indica que ela não é
+// cacheável; a resposta devolvida não tem uso de tokens (não houve geração).
+func (t *transport) cachedResponse(ctx context.Context, request interface{}, temperature *float64, seed *int, hasTools bool) (string, *Response) {
+	if !t.cache.Cacheable(temperature, seed, hasTools) {
+		return "", nil
+	}
+
+	key, err := t.cache.Key(request)
+	if err != nil {
+		return "", nil
+	}
+	if response, ok := t.cache.Get(ctx, key); ok {
+		response.Usage = Usage{}
+		return key, response
+	}
+	return key, nil
+}
+
+// storeResponse grava a resposta do modelo pedido (respostas de fallback não são cacheadas)
+func (t *transport) storeResponse(key, model string, response *Response) {
+	if key == "" || len(response.Message.ToolCalls) > 0 {
+		return
+	}
+	if response.Model != "" && normalizeModelName(response.Model) != normalizeModelName(model) {
+		return
+	}
+	t.cache.Put(key, response)
+}
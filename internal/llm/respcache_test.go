@@ -0,0 +1,131 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_ResponseCache(t *testing.T) {
+	var chats int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&chats, 1)
+		fmt.Fprintf(w, `{"model":"m","message":{"role":"assistant","content":"resposta %d"},"done":true,"eval_count":5}`, n)
+	}))
+	defer server.Close()
+
+	cache, err := NewResponseCache(ResponseCacheConfig{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewResponseCache failed: %v", err)
+	}
+	var hits, misses int
+	cache.SetGetWrapper(func(ctx context.Context, key string, get func() (interface{}, bool)) (interface{}, bool) {
+		value, hit := get()
+		if hit {
+			hits++
+		} else {
+			misses++
+		}
+		return value, hit
+	})
+
+	client := NewClient(server.URL, "m")
+	client.SetResponseCache(cache)
+	messages := []Message{{Role: "user", Content: "classifique: leia main.go"}}
+
+	first, _ := client.Chat(context.Background(), messages, &CompletionOptions{Temperature: Float64(0)})
+	second, _ := client.Chat(context.Background(), messages, &CompletionOptions{Temperature: Float64(0)})
+	if atomic.LoadInt32(&chats) != 1 || second.Message.Content != first.Message.Content {
+		t.Fatalf("Repeated deterministic prompt should be served from cache, got %d requests", chats)
+	}
+	if second.Usage.CompletionTokens != 0 {
+		t.Errorf("Cached response should not report token usage, got %+v", second.Usage)
+	}
+	if hits != 1 || misses != 1 {
+		t.Errorf("Expected 1 hit and 1 miss through the wrapper, got %d/%d", hits, misses)
+	}
+
+	// Outro prompt, temperatura acima de 0, temperatura padrão, seed aleatória ou
+	// ferramentas: vai ao servidor
+	randomSeed := -1
+	client.Chat(context.Background(), []Message{{Role: "user", Content: "outro"}}, &CompletionOptions{Temperature: Float64(0)})
+	client.Chat(context.Background(), messages, &CompletionOptions{Temperature: Float64(0.1)})
+	client.Chat(context.Background(), messages, &CompletionOptions{Temperature: Float64(0.1)})
+	client.Chat(context.Background(), messages, nil)
+	client.Chat(context.Background(), messages, &CompletionOptions{Temperature: Float64(0), Seed: &randomSeed})
+	client.Chat(context.Background(), messages, &CompletionOptions{Temperature: Float64(0), Tools: []Tool{{Type: "function"}}})
+	if got := atomic.LoadInt32(&chats); got != 7 {
+		t.Errorf("Non-cacheable requests should reach the server, got %d requests", got)
+	}
+	if stats := cache.Stats(); stats.Entries != 2 {
+		t.Errorf("Expected 2 cached responses, got %+v", stats)
+	}
+}
+
+func TestResponseCache_Cacheable(t *testing.T) {
+	cache, _ := NewResponseCache(ResponseCacheConfig{Dir: t.TempDir()})
+	fixed, random := 42, -1
+
+	tests := []struct {
+		name        string
+		temperature *float64
+		seed        *int
+		hasTools    bool
+		want        bool
+	}{
+		{"temperature 0", Float64(0), nil, false, true},
+		{"temperature 0 with fixed seed", Float64(0), &fixed, false, true},
+		{"temperature 0 with random seed", Float64(0), &random, false, false},
+		{"low temperature", Float64(0.1), nil, false, false},
+		{"default temperature", nil, nil, false, false},
+		{"tools", Float64(0), nil, true, false},
+	}
+
+	for _, tt := range tests {
+		if got := cache.Cacheable(tt.temperature, tt.seed, tt.hasTools); got != tt.want {
+			t.Errorf("%s: expected cacheable=%v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestResponseCache_LRUEviction(t *testing.T) {
+	dir := t.TempDir()
+	cache, _ := NewResponseCache(ResponseCacheConfig{Dir: dir})
+	now := time.Now()
+	cache.now = func() time.Time { now = now.Add(time.Second); return now }
+
+	response := &Response{Message: Message{Role: "assistant", Content: strings.Repeat("x", 100)}}
+	cache.Put("a", response)
+	size := cache.Stats().Bytes
+	cache.maxBytes = 3 * size
+
+	cache.Put("b", response)
+	cache.Put("c", response)
+	if _, ok := cache.Get(context.Background(), "a"); !ok {
+		t.Fatal("Entry a should be cached")
+	}
+
+	cache.Put("d", response)
+	if _, ok := cache.Get(context.Background(), "b"); ok {
+		t.Error("Least recently used entry should be evicted")
+	}
+	for _, key := range []string{"a", "c", "d"} {
+		if _, ok := cache.Get(context.Background(), key); !ok {
+			t.Errorf("Entry %s should still be cached", key)
+		}
+	}
+
+	// Reabrir o diretório recupera as respostas e aplica o limite
+	reopened, err := NewResponseCache(ResponseCacheConfig{Dir: dir, MaxBytes: 2 * size})
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if stats := reopened.Stats(); stats.Entries != 2 || stats.Bytes > 2*size {
+		t.Errorf("Reopened cache should keep 2 entries, got %+v", stats)
+	}
+}
@@ -0,0 +1,243 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Priority classe de prioridade de uma requisição no Scheduler
+type Priority int
+
+const (
+	// PriorityBackground trabalho em segundo plano (ex: indexação de embeddings)
+	PriorityBackground Priority = iota
+
+	// PrioritySubagent requisições de subagents
+	PrioritySubagent
+
+	// PriorityIntent detecção de intenção
+	PriorityIntent
+
+	// PriorityInteractive turno do usuário (padrão)
+	PriorityInteractive
+)
+
+// String nome da prioridade usado em métricas e logs
+func (p Priority) String() string {
+	switch p {
+	case PriorityBackground:
+		return "background"
+	case PrioritySubagent:
+		return "subagent"
+	case PriorityIntent:
+		return "intent"
+	default:
+		return "interactive"
+	}
+}
+
+// priorityAging espera após a qual uma requisição sobe uma classe (evita starvation)
+const priorityAging = 30 * time.Second
+
+// priorityKey chave de contexto com a prioridade da requisição
+type priorityKey struct{}
+
+// WithPriority define a prioridade das requisições feitas com o contexto
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// PriorityFrom prioridade do contexto (PriorityInteractive se não definida)
+func PriorityFrom(ctx context.Context) Priority {
+	if priority, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return priority
+	}
+	return PriorityInteractive
+}
+
+// SchedulerEvent requisição que saiu da fila e começou a ser enviada
+type SchedulerEvent struct {
+	Model      string
+	Priority   Priority
+	Wait       time.Duration // Tempo na fila
+	QueueDepth int           // Requisições que continuam na fila
+}
+
+// SchedulerStats estado atual do Scheduler
+type SchedulerStats struct {
+	InFlight    int
+	Queued      int
+	MaxInFlight int
+}
+
+// waiter requisição na fila do Scheduler
+type waiter struct {
+	model    string
+	priority Priority
+	enqueued time.Time
+	ready    chan struct{}
+	granted  bool
+}
+
+// Scheduler limita as requisições simultâneas aos servidores Ollama, compartilhado
+// por todos os clients (agente, router e subagents). Quando há vaga, atende primeiro
+// a maior prioridade; dentro da mesma classe, prefere o modelo que já está rodando
+// para evitar troca de modelos no servidor.
+type Scheduler struct {
+	mu          sync.Mutex
+	maxInFlight int
+	inFlight    int
+	running     map[string]int // modelo -> requisições em andamento
+	lastModel   string         // Último modelo a receber vaga
+	queue       []*waiter
+	onDispatch  func(event SchedulerEvent)
+	now         func() time.Time
+}
+
+// NewScheduler cria scheduler com o máximo de requisições simultâneas (mínimo 1).
+// Use o OLLAMA_NUM_PARALLEL do servidor (somado entre os hosts do pool).
+func NewScheduler(maxInFlight int) *Scheduler {
+	if maxInFlight <= 0 {
+		maxInFlight = 1
+	}
+	return &Scheduler{
+		maxInFlight: maxInFlight,
+		running:     make(map[string]int),
+		now:         time.Now,
+	}
+}
+
+// SetHook define callback chamado quando cada requisição sai da fila
+func (s *Scheduler) SetHook(fn func(event SchedulerEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onDispatch = fn
+}
+
+// Stats retorna requisições em andamento e na fila
+func (s *Scheduler) Stats() SchedulerStats {
+	if s == nil {
+		return SchedulerStats{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SchedulerStats{InFlight: s.inFlight, Queued: len(s.queue), MaxInFlight: s.maxInFlight}
+}
+
+// Acquire espera uma vaga para o modelo, com a prioridade do contexto. A função
+// retornada libera a vaga (pode ser chamada mais de uma vez).
+func (s *Scheduler) Acquire(ctx context.Context, model string) (func(), error) {
+	priority := PriorityFrom(ctx)
+
+	s.mu.Lock()
+	w := &waiter{model: model, priority: priority, enqueued: s.now(), ready: make(chan struct{})}
+
+	if s.inFlight < s.maxInFlight {
+		s.grant(w)
+	} else {
+		s.queue = append(s.queue, w)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+	case <-ctx.Done():
+		s.mu.Lock()
+		if !w.granted {
+			s.remove(w)
+			s.mu.Unlock()
+			return nil, ctx.Err()
+		}
+		s.mu.Unlock()
+		// A vaga chegou junto com o cancelamento: devolve
+		s.release(model)
+		return nil, ctx.Err()
+	}
+
+	s.mu.Lock()
+	event := SchedulerEvent{Model: model, Priority: priority, Wait: s.now().Sub(w.enqueued), QueueDepth: len(s.queue)}
+	hook := s.onDispatch
+	s.mu.Unlock()
+	if hook != nil {
+		hook(event)
+	}
+
+	var once sync.Once
+	return func() { once.Do(func() { s.release(model) }) }, nil
+}
+
+// grant ocupa uma vaga para o waiter (chamado com o lock)
+func (s *Scheduler) grant(w *waiter) {
+	s.inFlight++
+	s.running[w.model]++
+	s.lastModel = w.model
+	w.granted = true
+	close(w.ready)
+}
+
+// release libera a vaga do modelo e passa para os próximos da fila
+func (s *Scheduler) release(model string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inFlight--
+	if s.running[model]--; s.running[model] <= 0 {
+		delete(s.running, model)
+	}
+
+	for s.inFlight < s.maxInFlight && len(s.queue) > 0 {
+		next := s.next()
+		s.remove(next)
+		s.grant(next)
+	}
+}
+
+// next escolhe o próximo da fila: maior prioridade (com envelhecimento), depois
+// modelo já carregado, depois ordem de chegada (chamado com o lock)
+func (s *Scheduler) next() *waiter {
+	now := s.now()
+	var best *waiter
+	var bestPriority Priority
+	var bestAffinity bool
+
+	for _, w := range s.queue {
+		priority := w.priority + Priority(now.Sub(w.enqueued)/priorityAging)
+		if priority > PriorityInteractive {
+			priority = PriorityInteractive
+		}
+		affinity := s.running[w.model] > 0 || w.model == s.lastModel
+
+		switch {
+		case best == nil,
+			priority > bestPriority,
+			priority == bestPriority && affinity && !bestAffinity:
+			best, bestPriority, bestAffinity = w, priority, affinity
+		}
+	}
+	return best
+}
+
+// remove tira o waiter da fila (chamado com o lock)
+func (s *Scheduler) remove(w *waiter) {
+	for i, queued := range s.queue {
+		if queued == w {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetScheduler define o scheduler que limita as requisições simultâneas
+func (t *transport) SetScheduler(scheduler *Scheduler) {
+	t.scheduler = scheduler
+}
+
+// schedule espera a vaga da requisição no scheduler (sem scheduler, não espera)
+func (t *transport) schedule(ctx context.Context, model string) (func(), error) {
+	if t.scheduler == nil {
+		return func() {}, nil
+	}
+	return t.scheduler.Acquire(ctx, model)
+}
@@ -0,0 +1,166 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitQueued espera até a fila do scheduler ter n requisições
+func waitQueued(t *testing.T, s *Scheduler, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for s.Stats().Queued != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d queued requests, got %+v", n, s.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// enqueue pede vaga em goroutine e registra o rótulo quando ela é concedida
+func enqueue(s *Scheduler, ctx context.Context, model, label string, order chan<- string) {
+	go func() {
+		release, err := s.Acquire(ctx, model)
+		if err != nil {
+			order <- "error:" + label
+			return
+		}
+		order <- label
+		release()
+	}()
+}
+
+func TestScheduler_PriorityOrder(t *testing.T) {
+	s := NewScheduler(1)
+	hold, _ := s.Acquire(context.Background(), "m")
+
+	order := make(chan string, 3)
+	enqueue(s, WithPriority(context.Background(), PriorityBackground), "m", "background", order)
+	waitQueued(t, s, 1)
+	enqueue(s, WithPriority(context.Background(), PrioritySubagent), "m", "subagent", order)
+	waitQueued(t, s, 2)
+	enqueue(s, context.Background(), "m", "interactive", order)
+	waitQueued(t, s, 3)
+
+	hold()
+	got := []string{<-order, <-order, <-order}
+	if fmt.Sprint(got) != "[interactive subagent background]" {
+		t.Errorf("Unexpected dispatch order: %v", got)
+	}
+	if stats := s.Stats(); stats.InFlight != 0 || stats.Queued != 0 {
+		t.Errorf("Scheduler should be idle, got %+v", stats)
+	}
+}
+
+func TestScheduler_PrefersRunningModel(t *testing.T) {
+	s := NewScheduler(2)
+	holdA, _ := s.Acquire(context.Background(), "model-a")
+	holdB, _ := s.Acquire(context.Background(), "model-a")
+
+	order := make(chan string, 2)
+	enqueue(s, context.Background(), "model-b", "b", order)
+	waitQueued(t, s, 1)
+	enqueue(s, context.Background(), "model-a", "a", order)
+	waitQueued(t, s, 2)
+
+	// model-a continua rodando na outra vaga: a requisição dele passa na frente
+	holdA()
+	if first := <-order; first != "a" {
+		t.Errorf("Request for the loaded model should go first, got %s", first)
+	}
+	holdB()
+	<-order
+}
+
+func TestScheduler_AgingPreventsStarvation(t *testing.T) {
+	s := NewScheduler(1)
+	start := time.Now()
+	s.now = func() time.Time { return start }
+
+	old := &waiter{model: "m", priority: PriorityBackground, enqueued: start.Add(-2 * time.Minute)}
+	fresh := &waiter{model: "m", priority: PriorityIntent, enqueued: start}
+	s.queue = []*waiter{fresh, old}
+
+	if next := s.next(); next != old {
+		t.Error("Background request waiting for minutes should be promoted")
+	}
+}
+
+func TestScheduler_CancelWhileQueued(t *testing.T) {
+	s := NewScheduler(1)
+	hold, _ := s.Acquire(context.Background(), "m")
+	defer hold()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	order := make(chan string, 1)
+	enqueue(s, ctx, "m", "canceled", order)
+	waitQueued(t, s, 1)
+
+	cancel()
+	if got := <-order; got != "error:canceled" {
+		t.Errorf("Canceled request should fail, got %s", got)
+	}
+	if stats := s.Stats(); stats.Queued != 0 || stats.InFlight != 1 {
+		t.Errorf("Canceled request should leave the queue, got %+v", stats)
+	}
+}
+
+func TestClient_SchedulerLimitsInFlight(t *testing.T) {
+	var current, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		defer atomic.AddInt32(&current, -1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprint(w, `{"message":{"role":"assistant","content":"ok"},"done":true}`)
+	}))
+	defer server.Close()
+
+	scheduler := NewScheduler(1)
+	var mu sync.Mutex
+	var events []SchedulerEvent
+	scheduler.SetHook(func(event SchedulerEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client := NewClient(server.URL, "m")
+			client.SetScheduler(scheduler)
+			if _, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil); err != nil {
+				t.Errorf("Chat failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak != 1 {
+		t.Errorf("Server should see one request at a time, peak was %d", peak)
+	}
+
+	var waited bool
+	for _, event := range events {
+		if event.Wait > 0 && event.Priority == PriorityInteractive {
+			waited = true
+		}
+	}
+	if len(events) != 3 || !waited {
+		t.Errorf("Expected a dispatch event per request with queue wait, got %+v", events)
+	}
+}
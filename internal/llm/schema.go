@@ -0,0 +1,251 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// GenerateSchema gera JSON Schema a partir de um tipo Go (struct, slice, map ou primitivo).
+//
+// Campos seguem a tag json; campos sem omitempty são obrigatórios.
+// Tags extras: `enum:"a,b,c"`, `description:"..."` e `schema:"-"` para ignorar o campo.
+func GenerateSchema(v interface{}) map[string]interface{} {
+	return schemaForType(reflect.TypeOf(v))
+}
+
+// schemaForType gera schema recursivamente para um tipo
+func schemaForType(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return schemaForStruct(t)
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": schemaForType(t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// schemaForStruct gera schema de objeto a partir dos campos exportados
+func schemaForStruct(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("schema") == "-" {
+			continue
+		}
+
+		name := field.Name
+		omitEmpty := false
+		if tag := field.Tag.Get("json"); tag != "" {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				if opt == "omitempty" {
+					omitEmpty = true
+				}
+			}
+		}
+
+		prop := schemaForType(field.Type)
+		if enum := field.Tag.Get("enum"); enum != "" {
+			values := []interface{}{}
+			for _, value := range strings.Split(enum, ",") {
+				values = append(values, value)
+			}
+			prop["enum"] = values
+		}
+		if desc := field.Tag.Get("description"); desc != "" {
+			prop["description"] = desc
+		}
+
+		properties[name] = prop
+		if !omitEmpty {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// SchemaFormat converte schema no valor do campo "format" de /api/chat
+func SchemaFormat(schema map[string]interface{}) json.RawMessage {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return FormatJSON
+	}
+	return data
+}
+
+// ValidateJSON valida um documento JSON contra o schema.
+// Suporta o subconjunto gerado por GenerateSchema: type, properties,
+// required, items e enum.
+func ValidateJSON(data []byte, schema map[string]interface{}) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	return validateValue("$", value, schema)
+}
+
+// validateValue valida um valor decodificado recursivamente
+func validateValue(path string, value interface{}, schema map[string]interface{}) error {
+	if expected, ok := schema["type"].(string); ok {
+		if !matchesType(value, expected) {
+			return fmt.Errorf("%s: expected %s, got %s", path, expected, jsonTypeName(value))
+		}
+	}
+
+	if enum := schemaList(schema["enum"]); len(enum) > 0 {
+		found := false
+		for _, allowed := range enum {
+			if reflect.DeepEqual(value, allowed) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: value %v is not one of %v", path, value, enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range schemaList(schema["required"]) {
+			key, _ := name.(string)
+			if _, exists := v[key]; !exists {
+				return fmt.Errorf("%s: missing required field %q", path, key)
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			propSchema, ok := properties[key].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if err := validateValue(path+"."+key, v[key], propSchema); err != nil {
+				return err
+			}
+		}
+
+	case []interface{}:
+		itemSchema, ok := schema["items"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for i, item := range v {
+			if err := validateValue(fmt.Sprintf("%s[%d]", path, i), item, itemSchema); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// schemaList normaliza listas do schema ([]string gerado ou []interface{} decodificado)
+func schemaList(raw interface{}) []interface{} {
+	switch list := raw.(type) {
+	case []interface{}:
+		return list
+	case []string:
+		values := make([]interface{}, len(list))
+		for i, item := range list {
+			values[i] = item
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// matchesType verifica o tipo JSON de um valor decodificado
+func matchesType(value interface{}, expected string) bool {
+	switch expected {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == float64(int64(number))
+	case "null":
+		return value == nil
+	default:
+		return true
+	}
+}
+
+// jsonTypeName nome do tipo JSON de um valor decodificado
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type schemaTestItem struct {
+	Name  string   `json:"name"`
+	Kind  string   `json:"kind" enum:"a,b"`
+	Tags  []string `json:"tags,omitempty"`
+	Count int      `json:"count" description:"quantidade"`
+	Skip  string   `json:"skip" schema:"-"`
+}
+
+func TestGenerateSchema_Struct(t *testing.T) {
+	schema := GenerateSchema(schemaTestItem{})
+
+	if schema["type"] != "object" {
+		t.Fatalf("Expected object schema, got %v", schema["type"])
+	}
+
+	props := schema["properties"].(map[string]interface{})
+	if _, exists := props["skip"]; exists {
+		t.Error("Field tagged schema:\"-\" should be skipped")
+	}
+
+	kind := props["kind"].(map[string]interface{})
+	if !reflect.DeepEqual(kind["enum"], []interface{}{"a", "b"}) {
+		t.Errorf("Unexpected enum: %v", kind["enum"])
+	}
+
+	if props["count"].(map[string]interface{})["type"] != "integer" {
+		t.Error("int should map to integer")
+	}
+
+	tags := props["tags"].(map[string]interface{})
+	if tags["type"] != "array" || tags["items"].(map[string]interface{})["type"] != "string" {
+		t.Errorf("Unexpected slice schema: %v", tags)
+	}
+
+	required := schema["required"].([]string)
+	if !reflect.DeepEqual(required, []string{"name", "kind", "count"}) {
+		t.Errorf("Unexpected required fields: %v", required)
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	schema := GenerateSchema(struct {
+		Items []schemaTestItem `json:"items"`
+	}{})
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"valid", `{"items": [{"name": "x", "kind": "a", "count": 2}]}`, ""},
+		{"invalid json", `{"items": [`, "invalid JSON"},
+		{"missing field", `{"items": [{"name": "x", "kind": "a"}]}`, `$.items[0]: missing required field "count"`},
+		{"wrong type", `{"items": [{"name": 1, "kind": "a", "count": 2}]}`, "$.items[0].name: expected string"},
+		{"enum", `{"items": [{"name": "x", "kind": "c", "count": 2}]}`, "$.items[0].kind: value c is not one of"},
+		{"integer", `{"items": [{"name": "x", "kind": "a", "count": 1.5}]}`, "$.items[0].count: expected integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateJSON([]byte(tt.data), schema)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
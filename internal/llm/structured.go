@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidStructuredOutput modelo não produziu JSON válido para o schema
+var ErrInvalidStructuredOutput = errors.New("invalid structured output")
+
+// DefaultStructuredRetries novas tentativas após resposta inválida
+const DefaultStructuredRetries = 2
+
+// retryPrompt pede correção ao modelo informando o erro de validação
+const retryPrompt = "A resposta anterior não é válida: %v\nResponda novamente APENAS com o JSON corrigido, seguindo exatamente o schema."
+
+// CompleteStructured pede resposta no formato do schema (campo "format" de /api/chat),
+// valida e decodifica em target. Se a resposta for inválida, reenvia ao modelo
+// com o erro de validação até maxRetries vezes.
+func (c *Client) CompleteStructured(ctx context.Context, messages []Message, opts *CompletionOptions, schema map[string]interface{}, target interface{}, maxRetries int) error {
+	return completeStructured(ctx, c, messages, opts, schema, target, maxRetries)
+}
+
+// completeStructured implementa validação e retry sobre qualquer Provider
+func completeStructured(ctx context.Context, p Provider, messages []Message, opts *CompletionOptions, schema map[string]interface{}, target interface{}, maxRetries int) error {
+	callOpts := CompletionOptions{}
+	if opts != nil {
+		callOpts = *opts
+	}
+	callOpts.Format = SchemaFormat(schema)
+
+	conversation := append([]Message{}, messages...)
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		response, _, err := p.Complete(ctx, conversation, &callOpts)
+		if err != nil {
+			return err
+		}
+
+		payload := CleanJSON(response)
+		if lastErr = ValidateJSON([]byte(payload), schema); lastErr == nil {
+			if err := json.Unmarshal([]byte(payload), target); err != nil {
+				lastErr = err
+			} else {
+				return nil
+			}
+		}
+
+		conversation = append(conversation,
+			Message{Role: "assistant", Content: response},
+			Message{Role: "user", Content: fmt.Sprintf(retryPrompt, lastErr)},
+		)
+	}
+
+	return fmt.Errorf("%w: %v", ErrInvalidStructuredOutput, lastErr)
+}
+
+// CleanJSON remove raciocínio (<think>), cercas de markdown e texto ao redor do JSON
+func CleanJSON(response string) string {
+	response = strings.TrimSpace(StripThinking(response))
+	response = strings.TrimPrefix(response, "```json")
+	response = strings.TrimPrefix(response, "```")
+	response = strings.TrimSuffix(response, "```")
+	return strings.TrimSpace(response)
+}
@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type structuredTestResult struct {
+	Answer string `json:"answer" enum:"yes,no"`
+}
+
+func newStructuredServer(t *testing.T, replies ...string) (*httptest.Server, *[]Request) {
+	var requests []Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+
+		reply := replies[len(replies)-1]
+		if len(requests) <= len(replies) {
+			reply = replies[len(requests)-1]
+		}
+
+		json.NewEncoder(w).Encode(Response{
+			Message: Message{Role: "assistant", Content: reply},
+			Done:    true,
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestCompleteStructured_SendsSchemaFormat(t *testing.T) {
+	server, requests := newStructuredServer(t, "```json\n{\"answer\": \"yes\"}\n```")
+	client := NewClient(server.URL, "test-model")
+
+	var result structuredTestResult
+	schema := GenerateSchema(result)
+	err := client.CompleteStructured(context.Background(), []Message{{Role: "user", Content: "?"}}, nil, schema, &result, 1)
+	if err != nil {
+		t.Fatalf("CompleteStructured failed: %v", err)
+	}
+
+	if result.Answer != "yes" {
+		t.Errorf("Expected answer yes, got %q", result.Answer)
+	}
+
+	var sent map[string]interface{}
+	if err := json.Unmarshal((*requests)[0].Format, &sent); err != nil {
+		t.Fatalf("Format should be a JSON schema, got %s", (*requests)[0].Format)
+	}
+	if sent["type"] != "object" {
+		t.Errorf("Unexpected schema sent: %v", sent)
+	}
+}
+
+func TestCompleteStructured_RetriesWithValidationError(t *testing.T) {
+	server, requests := newStructuredServer(t, `{"answer": "maybe"}`, `{"answer": "no"}`)
+	client := NewClient(server.URL, "test-model")
+
+	var result structuredTestResult
+	err := client.CompleteStructured(context.Background(), []Message{{Role: "user", Content: "?"}}, nil, GenerateSchema(result), &result, 1)
+	if err != nil {
+		t.Fatalf("CompleteStructured failed: %v", err)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(*requests))
+	}
+
+	retry := (*requests)[1].Messages
+	last := retry[len(retry)-1]
+	if last.Role != "user" || !strings.Contains(last.Content, "not one of") {
+		t.Errorf("Retry should include validation error, got %+v", last)
+	}
+	if result.Answer != "no" {
+		t.Errorf("Expected answer no, got %q", result.Answer)
+	}
+}
+
+func TestCompleteStructured_GivesUpAfterRetries(t *testing.T) {
+	server, requests := newStructuredServer(t, "not json")
+	client := NewClient(server.URL, "test-model")
+
+	var result structuredTestResult
+	err := client.CompleteStructured(context.Background(), []Message{{Role: "user", Content: "?"}}, nil, GenerateSchema(result), &result, 2)
+	if !errors.Is(err, ErrInvalidStructuredOutput) {
+		t.Fatalf("Expected ErrInvalidStructuredOutput, got %v", err)
+	}
+
+	if len(*requests) != 3 {
+		t.Errorf("Expected 3 attempts, got %d", len(*requests))
+	}
+}
@@ -0,0 +1,139 @@
+package llm
+
+import "strings"
+
+// Tags usadas por modelos de raciocínio (deepseek-r1, qwen3) quando o servidor
+// não separa o raciocínio no campo "thinking"
+const (
+	thinkOpenTag  = "<think>"
+	thinkCloseTag = "</think>"
+)
+
+// SplitThinking separa blocos <think>...</think> do conteúdo da resposta.
+// Um "</think>" sem abertura (template que já abre o bloco) marca tudo antes dele como raciocínio.
+func SplitThinking(content string) (thinking, answer string) {
+	if !strings.Contains(content, thinkOpenTag) && !strings.Contains(content, thinkCloseTag) {
+		return "", content
+	}
+
+	var thinkingParts []string
+	var answerParts strings.Builder
+
+	rest := content
+	if closeIdx := strings.Index(rest, thinkCloseTag); closeIdx >= 0 {
+		if openIdx := strings.Index(rest, thinkOpenTag); openIdx < 0 || openIdx > closeIdx {
+			thinkingParts = append(thinkingParts, rest[:closeIdx])
+			rest = rest[closeIdx+len(thinkCloseTag):]
+		}
+	}
+
+	for {
+		openIdx := strings.Index(rest, thinkOpenTag)
+		if openIdx < 0 {
+			answerParts.WriteString(rest)
+			break
+		}
+		answerParts.WriteString(rest[:openIdx])
+		rest = rest[openIdx+len(thinkOpenTag):]
+
+		closeIdx := strings.Index(rest, thinkCloseTag)
+		if closeIdx < 0 {
+			// Bloco não fechado (resposta cortada): tudo é raciocínio
+			thinkingParts = append(thinkingParts, rest)
+			break
+		}
+		thinkingParts = append(thinkingParts, rest[:closeIdx])
+		rest = rest[closeIdx+len(thinkCloseTag):]
+	}
+
+	for i, part := range thinkingParts {
+		thinkingParts[i] = strings.TrimSpace(part)
+	}
+	return strings.Join(thinkingParts, "\n\n"), strings.TrimSpace(answerParts.String())
+}
+
+// StripThinking remove o raciocínio e retorna só a resposta
+func StripThinking(content string) string {
+	_, answer := SplitThinking(content)
+	return answer
+}
+
+// separateThinking move blocos <think> do conteúdo para Message.Thinking
+func separateThinking(msg Message) Message {
+	thinking, answer := SplitThinking(msg.Content)
+	if thinking == "" && answer == msg.Content {
+		return msg
+	}
+
+	if msg.Thinking != "" && thinking != "" {
+		thinking = msg.Thinking + "\n\n" + thinking
+	} else if msg.Thinking != "" {
+		thinking = msg.Thinking
+	}
+	msg.Thinking = thinking
+	msg.Content = answer
+	return msg
+}
+
+// thinkingStream separa <think> em streaming, onde as tags podem chegar
+// quebradas entre chunks
+type thinkingStream struct {
+	inThink bool
+	pending string // Possível início de tag aguardando o próximo chunk
+}
+
+// Write processa um chunk e retorna as partes de resposta e de raciocínio
+func (s *thinkingStream) Write(chunk string) (content, thinking string) {
+	data := s.pending + chunk
+	s.pending = ""
+
+	var contentOut, thinkingOut strings.Builder
+	emit := func(text string) {
+		if s.inThink {
+			thinkingOut.WriteString(text)
+		} else {
+			contentOut.WriteString(text)
+		}
+	}
+
+	for data != "" {
+		tag := thinkOpenTag
+		if s.inThink {
+			tag = thinkCloseTag
+		}
+
+		if idx := strings.Index(data, tag); idx >= 0 {
+			emit(data[:idx])
+			data = data[idx+len(tag):]
+			s.inThink = !s.inThink
+			continue
+		}
+
+		keep := partialTagSuffix(data, tag)
+		emit(data[:len(data)-keep])
+		s.pending = data[len(data)-keep:]
+		break
+	}
+
+	return contentOut.String(), thinkingOut.String()
+}
+
+// Flush libera o que ficou retido no fim do stream
+func (s *thinkingStream) Flush() (content, thinking string) {
+	pending := s.pending
+	s.pending = ""
+	if s.inThink {
+		return "", pending
+	}
+	return pending, ""
+}
+
+// partialTagSuffix tamanho do maior sufixo de data que é prefixo de tag
+func partialTagSuffix(data, tag string) int {
+	for n := len(tag) - 1; n > 0; n-- {
+		if len(data) >= n && strings.HasSuffix(data, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
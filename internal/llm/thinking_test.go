@@ -0,0 +1,125 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSplitThinking(t *testing.T) {
+	tests := []struct {
+		name, content, thinking, answer string
+	}{
+		{"no tags", "resposta", "", "resposta"},
+		{"block", "<think>\nanalisando\n</think>\n\nresposta", "analisando", "resposta"},
+		{"close only", "analisando o pedido</think>{\"intent\":\"question\"}", "analisando o pedido", `{"intent":"question"}`},
+		{"unclosed", "<think>cortado no meio", "cortado no meio", ""},
+		{"two blocks", "<think>a</think>x<think>b</think>y", "a\n\nb", "xy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thinking, answer := SplitThinking(tt.content)
+			if thinking != tt.thinking || answer != tt.answer {
+				t.Errorf("SplitThinking(%q) = %q, %q; want %q, %q", tt.content, thinking, answer, tt.thinking, tt.answer)
+			}
+		})
+	}
+}
+
+func TestThinkingStream_TagsAcrossChunks(t *testing.T) {
+	var splitter thinkingStream
+	var content, thinking strings.Builder
+
+	for _, chunk := range []string{"<thi", "nk>pen", "sando</th", "ink>Olá", " <", "mundo"} {
+		c, th := splitter.Write(chunk)
+		content.WriteString(c)
+		thinking.WriteString(th)
+	}
+	c, th := splitter.Flush()
+	content.WriteString(c)
+	thinking.WriteString(th)
+
+	if thinking.String() != "pensando" || content.String() != "Olá <mundo" {
+		t.Errorf("Unexpected split: thinking=%q content=%q", thinking.String(), content.String())
+	}
+}
+
+func TestCleanJSON_StripsThinking(t *testing.T) {
+	if got := CleanJSON("<think>qual intent?</think>\n```json\n{\"a\":1}\n```"); got != `{"a":1}` {
+		t.Errorf("Unexpected CleanJSON result: %q", got)
+	}
+}
+
+func TestClient_ChatStreamSeparatesThinking(t *testing.T) {
+	var received Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		for _, chunk := range []string{
+			`{"message":{"role":"assistant","thinking":"campo "}}`,
+			`{"message":{"role":"assistant","content":"<think>tag</think>"}}`,
+			`{"message":{"role":"assistant","content":"resposta"}}`,
+			`{"message":{"role":"assistant"},"done":true}`,
+		} {
+			w.Write([]byte(chunk + "\n"))
+		}
+	}))
+	defer server.Close()
+
+	think := true
+	var thoughts, chunks []string
+	response, err := NewClient(server.URL, "qwen3").ChatStream(context.Background(), []Message{{Role: "user", Content: "oi"}},
+		&CompletionOptions{Think: &think, OnThinking: func(s string) { thoughts = append(thoughts, s) }},
+		func(s string) { chunks = append(chunks, s) })
+	if err != nil {
+		t.Fatalf("ChatStream failed: %v", err)
+	}
+
+	if received.Think == nil || !*received.Think {
+		t.Error("Expected think=true in the request")
+	}
+	if strings.Join(thoughts, "") != "campo tag" || strings.Join(chunks, "") != "resposta" {
+		t.Errorf("Unexpected streaming: thoughts=%q chunks=%q", thoughts, chunks)
+	}
+	if response.Message.Content != "resposta" || response.Message.Thinking != "campo tag" {
+		t.Errorf("Unexpected final message: %+v", response.Message)
+	}
+}
+
+func TestClient_CompleteStripsThinking(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message":{"role":"assistant","content":"<think>hmm</think>{\"ok\":true}"},"done":true}`))
+	}))
+	defer server.Close()
+
+	content, _, err := NewClient(server.URL, "deepseek-r1").Complete(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil)
+	if err != nil || content != `{"ok":true}` {
+		t.Errorf("Expected content without thinking, got %q, %v", content, err)
+	}
+}
+
+func TestOpenAIClient_ReasoningContent(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","reasoning_content":"pensando","content":"pronto"}}]}`))
+	}))
+	defer server.Close()
+
+	think := false
+	response, err := NewOpenAIClient(server.URL, "qwen3").Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, &CompletionOptions{Think: &think})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	kwargs, _ := received["chat_template_kwargs"].(map[string]interface{})
+	if kwargs["enable_thinking"] != false {
+		t.Errorf("Expected enable_thinking=false, got %v", received["chat_template_kwargs"])
+	}
+	if response.Message.Thinking != "pensando" || response.Message.Content != "pronto" {
+		t.Errorf("Unexpected message: %+v", response.Message)
+	}
+}
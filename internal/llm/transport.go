@@ -0,0 +1,347 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrFirstTokenTimeout servidor não começou a responder dentro do prazo
+	ErrFirstTokenTimeout = errors.New("timed out waiting for first token")
+
+	// ErrStreamStalled resposta parou de chegar no meio do stream
+	ErrStreamStalled = errors.New("response stream stalled")
+)
+
+// Motivos de retry reportados no RetryEvent
+const (
+	RetryReasonConnection   = "connection"
+	RetryReasonFirstToken   = "first_token_timeout"
+	RetryReasonStatus       = "status"
+	RetryReasonModelLoading = "model_loading"
+)
+
+// RetryPolicy retry com backoff exponencial e jitter
+type RetryPolicy struct {
+	MaxRetries     int           // Tentativas extras após a primeira (0 desativa)
+	InitialBackoff time.Duration // Espera antes do primeiro retry
+	MaxBackoff     time.Duration // Limite da espera entre tentativas
+	Jitter         float64       // Fração aleatória aplicada à espera (0-1)
+}
+
+// Backoff espera antes do retry de número attempt (1 = primeiro retry)
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	wait := p.InitialBackoff
+	for i := 1; i < attempt && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 && wait > 0 {
+		delta := float64(wait) * p.Jitter
+		wait += time.Duration(delta * (2*rand.Float64() - 1))
+	}
+	return wait
+}
+
+// Timeouts timeouts separados por fase da requisição
+type Timeouts struct {
+	Connect time.Duration // Estabelecer a conexão TCP/TLS
+
+	// FirstToken até o servidor começar a responder. Inclui o tempo de
+	// carga do modelo no Ollama; em chamadas não streaming cobre a geração inteira.
+	FirstToken time.Duration
+
+	Idle time.Duration // Máximo sem receber dados depois que a resposta começou
+}
+
+// TransportConfig configuração de resiliência e conexão das requisições ao servidor
+type TransportConfig struct {
+	Retry            RetryPolicy
+	Timeouts         Timeouts
+	BreakerThreshold int           // Falhas consecutivas para abrir o circuito (0 desativa)
+	BreakerCooldown  time.Duration // Tempo com o circuito aberto antes de testar de novo
+	Connection       Connection    // Headers, token, TLS e proxy
+}
+
+// DefaultTransportConfig retorna a configuração padrão de transporte
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		Retry: RetryPolicy{
+			MaxRetries:     3,
+			InitialBackoff: 500 * time.Millisecond,
+			MaxBackoff:     10 * time.Second,
+			Jitter:         0.2,
+		},
+		Timeouts: Timeouts{
+			Connect:    10 * time.Second,
+			FirstToken: 300 * time.Second,
+			Idle:       60 * time.Second,
+		},
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// RetryEvent descreve uma nova tentativa de requisição
+type RetryEvent struct {
+	Model   string
+	Attempt int    // Número do retry (1 = primeiro retry)
+	Reason  string // RetryReason*
+	Err     error  // Falha da tentativa anterior
+	Wait    time.Duration
+}
+
+// transport executa requisições com timeouts, retry e circuit breaker.
+// É compartilhado entre o cliente e as cópias criadas por WithOptions.
+type transport struct {
+	config     TransportConfig
+	httpClient *http.Client
+	breaker    *CircuitBreaker
+	onRetry    func(event RetryEvent)
+	health     *ModelHealth // Modelos em cool-down na cadeia de fallback (nil = sem memória)
+	onFallback func(event FallbackEvent)
+	pool       *HostPool      // Servidores Ollama entre os quais as requisições são distribuídas
+	scheduler  *Scheduler     // Limite de requisições simultâneas e prioridades (nil = sem limite)
+	cache      *ResponseCache // Respostas de prompts determinísticos (nil = sem cache)
+}
+
+// newTransport cria transporte com a configuração informada
+func newTransport(cfg TransportConfig) *transport {
+	t := &transport{}
+	t.SetTransportConfig(cfg)
+	return t
+}
+
+// SetTransportConfig altera retry, timeouts, circuit breaker e conexão
+func (t *transport) SetTransportConfig(cfg TransportConfig) {
+	dialer := &net.Dialer{
+		Timeout:   cfg.Timeouts.Connect,
+		KeepAlive: 30 * time.Second,
+	}
+
+	httpTransport := cfg.Connection.HTTPTransport()
+	httpTransport.DialContext = dialer.DialContext
+	if cfg.Timeouts.Connect > 0 {
+		httpTransport.TLSHandshakeTimeout = cfg.Timeouts.Connect
+	}
+
+	t.config = cfg
+	// Sem Timeout global: first-token e idle são controlados por requisição
+	t.httpClient = &http.Client{Transport: cfg.Connection.RoundTripper(httpTransport)}
+	t.breaker = NewCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown)
+}
+
+// TransportConfig retorna a configuração de transporte atual
+func (t *transport) TransportConfig() TransportConfig {
+	return t.config
+}
+
+// SetRetryHook define callback chamado antes de cada nova tentativa
+func (t *transport) SetRetryHook(fn func(event RetryEvent)) {
+	t.onRetry = fn
+}
+
+// retryReasonKey chave de contexto com o motivo da falha da tentativa anterior
+type retryReasonKey struct{}
+
+// previousRetryReason motivo (RetryReason*) da tentativa anterior; vazio na primeira
+func previousRetryReason(ctx context.Context) string {
+	reason, _ := ctx.Value(retryReasonKey{}).(string)
+	return reason
+}
+
+// do executa a requisição criada por newRequest, repetindo falhas transitórias.
+// Só há retry antes de a resposta começar; respostas com status não
+// transitório são devolvidas para o chamador tratar.
+func (t *transport) do(ctx context.Context, model string, newRequest func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	var lastErr error
+	attemptCtx := ctx
+
+	for attempt := 0; ; attempt++ {
+		if err := t.breaker.Allow(); err != nil {
+			if lastErr != nil {
+				return nil, fmt.Errorf("%w (last error: %v)", err, lastErr)
+			}
+			return nil, err
+		}
+
+		resp, reason, retryAfter, err := t.attempt(attemptCtx, newRequest)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+
+		if reason == "" || attempt >= t.config.Retry.MaxRetries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		wait := t.config.Retry.Backoff(attempt + 1)
+		if retryAfter > wait {
+			wait = retryAfter
+			if t.config.Retry.MaxBackoff > 0 && wait > t.config.Retry.MaxBackoff {
+				wait = t.config.Retry.MaxBackoff
+			}
+		}
+
+		if t.onRetry != nil {
+			t.onRetry(RetryEvent{Model: model, Attempt: attempt + 1, Reason: reason, Err: err, Wait: wait})
+		}
+		attemptCtx = context.WithValue(ctx, retryReasonKey{}, reason)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, lastErr
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt faz uma única tentativa. reason não vazio indica falha transitória.
+func (t *transport) attempt(ctx context.Context, newRequest func(ctx context.Context) (*http.Request, error)) (*http.Response, string, time.Duration, error) {
+	reqCtx, cancel := context.WithCancel(ctx)
+
+	httpReq, err := newRequest(reqCtx)
+	if err != nil {
+		cancel()
+		return nil, "", 0, fmt.Errorf("create request: %w", err)
+	}
+
+	var firstTokenExpired atomic.Bool
+	var timer *time.Timer
+	if timeout := t.config.Timeouts.FirstToken; timeout > 0 {
+		timer = time.AfterFunc(timeout, func() {
+			firstTokenExpired.Store(true)
+			cancel()
+		})
+	}
+
+	resp, err := t.httpClient.Do(httpReq)
+	if timer != nil {
+		timer.Stop()
+	}
+
+	if err != nil {
+		cancel()
+		if firstTokenExpired.Load() {
+			t.breaker.RecordFailure()
+			return nil, RetryReasonFirstToken, 0, fmt.Errorf("do request: %w after %s", ErrFirstTokenTimeout, t.config.Timeouts.FirstToken)
+		}
+		if ctx.Err() != nil {
+			return nil, "", 0, fmt.Errorf("do request: %w", err)
+		}
+		t.breaker.RecordFailure()
+		return nil, RetryReasonConnection, 0, fmt.Errorf("do request: %w", err)
+	}
+
+	if isRetryableStatus(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		cancel()
+
+		statusErr := fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+
+		// Servidor vivo (carregando modelo ou com fila cheia) não conta como falha do circuito
+		switch {
+		case isModelLoading(resp.StatusCode, string(body)):
+			t.breaker.RecordSuccess()
+			return nil, RetryReasonModelLoading, retryAfter, statusErr
+		case isServerBusy(resp.StatusCode, string(body)):
+			t.breaker.RecordSuccess()
+			return nil, RetryReasonStatus, retryAfter, statusErr
+		}
+		t.breaker.RecordFailure()
+		return nil, RetryReasonStatus, retryAfter, statusErr
+	}
+
+	t.breaker.RecordSuccess()
+	resp.Body = newIdleReader(resp.Body, t.config.Timeouts.Idle, cancel)
+	return resp, "", 0, nil
+}
+
+// isRetryableStatus status HTTP que indicam indisponibilidade temporária
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// isModelLoading indica 503 enviado enquanto o servidor carrega o modelo (llama.cpp "Loading model")
+func isModelLoading(code int, body string) bool {
+	return code == http.StatusServiceUnavailable && strings.Contains(strings.ToLower(body), "loading")
+}
+
+// isServerBusy indica 503 do Ollama com a fila de requisições cheia
+func isServerBusy(code int, body string) bool {
+	return code == http.StatusServiceUnavailable && strings.Contains(strings.ToLower(body), "server busy")
+}
+
+// parseRetryAfter lê o header Retry-After em segundos (0 se ausente ou inválido)
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// idleReader detecta streams parados: cancela a requisição quando nenhum
+// dado chega dentro do timeout de inatividade.
+type idleReader struct {
+	body    io.ReadCloser
+	idle    time.Duration
+	timer   *time.Timer
+	stalled atomic.Bool
+	cancel  context.CancelFunc
+}
+
+// newIdleReader envolve o corpo da resposta (idle 0 desativa a detecção)
+func newIdleReader(body io.ReadCloser, idle time.Duration, cancel context.CancelFunc) *idleReader {
+	r := &idleReader{body: body, idle: idle, cancel: cancel}
+	if idle > 0 {
+		r.timer = time.AfterFunc(idle, func() {
+			r.stalled.Store(true)
+			cancel()
+		})
+	}
+	return r
+}
+
+// Read lê do corpo e reinicia o timer de inatividade a cada dado recebido
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if r.stalled.Load() {
+		return n, fmt.Errorf("%w: no data for %s", ErrStreamStalled, r.idle)
+	}
+	if n > 0 && r.timer != nil {
+		r.timer.Reset(r.idle)
+	}
+	return n, err
+}
+
+// Close encerra o corpo e libera o contexto da requisição
+func (r *idleReader) Close() error {
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	err := r.body.Close()
+	r.cancel()
+	return err
+}
@@ -0,0 +1,288 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastTransport configuração com esperas curtas para testes
+func fastTransport() TransportConfig {
+	cfg := DefaultTransportConfig()
+	cfg.Retry.InitialBackoff = time.Millisecond
+	cfg.Retry.MaxBackoff = 5 * time.Millisecond
+	cfg.Retry.Jitter = 0
+	return cfg
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, want := range expected {
+		if got := policy.Backoff(i + 1); got != want {
+			t.Errorf("Backoff(%d) = %v, want %v", i+1, got, want)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 50; i++ {
+		if got := policy.Backoff(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("Jittered backoff out of range: %v", got)
+		}
+	}
+}
+
+func TestChat_RetriesTransientStatus(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":"llama runner is loading model"}`))
+			return
+		}
+		w.Write([]byte(`{"model":"m","message":{"role":"assistant","content":"ok"},"done":true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "m")
+	client.SetTransportConfig(fastTransport())
+
+	var events []RetryEvent
+	client.SetRetryHook(func(event RetryEvent) { events = append(events, event) })
+
+	content, _, err := client.Complete(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil)
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if content != "ok" {
+		t.Errorf("Unexpected content %q", content)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 retry events, got %d", len(events))
+	}
+	if events[0].Reason != RetryReasonModelLoading || events[0].Model != "m" || events[1].Attempt != 2 {
+		t.Errorf("Unexpected retry events: %+v", events)
+	}
+}
+
+func TestChat_DoesNotRetryNonTransientStatus(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":"boom"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "m")
+	client.SetTransportConfig(fastTransport())
+
+	if _, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil); err == nil {
+		t.Fatal("Expected error")
+	}
+	if calls != 1 {
+		t.Errorf("Expected a single attempt, got %d", calls)
+	}
+}
+
+func TestChat_GivesUpAfterMaxRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	cfg := fastTransport()
+	cfg.Retry.MaxRetries = 2
+	client := NewClient(server.URL, "m")
+	client.SetTransportConfig(cfg)
+
+	_, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil)
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("Expected 502 error, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestChat_RetriesConnectionErrors(t *testing.T) {
+	// Servidor fechado: conexão recusada
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	cfg := fastTransport()
+	cfg.Retry.MaxRetries = 2
+	cfg.BreakerThreshold = 0
+	client := NewClient(url, "m")
+	client.SetTransportConfig(cfg)
+
+	var retries int
+	client.SetRetryHook(func(event RetryEvent) {
+		if event.Reason != RetryReasonConnection {
+			t.Errorf("Unexpected reason %s", event.Reason)
+		}
+		retries++
+	})
+
+	if _, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil); err == nil {
+		t.Fatal("Expected connection error")
+	}
+	if retries != 2 {
+		t.Errorf("Expected 2 retries, got %d", retries)
+	}
+}
+
+func TestChat_CircuitBreakerFastFails(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := fastTransport()
+	cfg.Retry.MaxRetries = 0
+	cfg.BreakerThreshold = 2
+	cfg.BreakerCooldown = time.Hour
+	client := NewClient(server.URL, "m")
+	client.SetTransportConfig(cfg)
+
+	for i := 0; i < 2; i++ {
+		client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil)
+	}
+
+	_, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Open circuit should not reach the server, got %d calls", calls)
+	}
+}
+
+func TestChat_FirstTokenTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg := fastTransport()
+	cfg.Retry.MaxRetries = 0
+	cfg.Timeouts.FirstToken = 20 * time.Millisecond
+	client := NewClient(server.URL, "m")
+	client.SetTransportConfig(cfg)
+
+	_, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil)
+	if !errors.Is(err, ErrFirstTokenTimeout) {
+		t.Fatalf("Expected ErrFirstTokenTimeout, got %v", err)
+	}
+}
+
+func TestChatStream_DetectsStalledStream(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"m","message":{"role":"assistant","content":"Olá"},"done":false}` + "\n"))
+		w.(http.Flusher).Flush()
+
+		// Para de enviar dados sem encerrar a resposta
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg := fastTransport()
+	cfg.Timeouts.Idle = 30 * time.Millisecond
+	client := NewClient(server.URL, "m")
+	client.SetTransportConfig(cfg)
+
+	var retries int
+	client.SetRetryHook(func(RetryEvent) { retries++ })
+
+	var chunks []string
+	_, err := client.ChatStream(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil, func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if !errors.Is(err, ErrStreamStalled) {
+		t.Fatalf("Expected ErrStreamStalled, got %v", err)
+	}
+	if len(chunks) != 1 {
+		t.Errorf("Expected the chunk sent before the stall, got %v", chunks)
+	}
+	if retries != 0 {
+		t.Error("Streams that already started must not be retried")
+	}
+}
+
+func TestChat_CancelledContextIsNotRetried(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := fastTransport()
+	cfg.Retry.InitialBackoff = time.Hour
+	cfg.Retry.MaxBackoff = time.Hour
+	client := NewClient(server.URL, "m")
+	client.SetTransportConfig(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client.SetRetryHook(func(RetryEvent) { cancel() })
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Chat(ctx, []Message{{Role: "user", Content: "oi"}}, nil)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "503") {
+			t.Errorf("Expected last 503 error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Cancelled context should interrupt the backoff")
+	}
+}
+
+func TestOpenAIChat_RetriesTransientStatus(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":{"message":"Loading model"}}`))
+			return
+		}
+		w.Write([]byte(`{"model":"m","choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL, "m")
+	client.SetTransportConfig(fastTransport())
+
+	content, _, err := client.Complete(context.Background(), []Message{{Role: "user", Content: "oi"}}, nil)
+	if err != nil || content != "ok" {
+		t.Fatalf("Expected retry to succeed, got %q, %v", content, err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls)
+	}
+}
@@ -1,25 +1,69 @@
 package llm
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // Message representa uma mensagem na conversa
 type Message struct {
-	Role    string `json:"role"`    // "user", "assistant", "system"
-	Content string `json:"content"` // Conteúdo da mensagem
+	Role       string     `json:"role"`                   // "user", "assistant", "system", "tool"
+	Content    string     `json:"content"`                // Conteúdo da mensagem
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // Chamadas de ferramenta feitas pelo modelo
+	ToolName   string     `json:"tool_name,omitempty"`    // Ferramenta que gerou o resultado (role "tool")
+	ToolCallID string     `json:"tool_call_id,omitempty"` // ID da chamada respondida (exigido por APIs OpenAI)
+	Images     []string   `json:"images,omitempty"`       // Imagens em base64 (modelos multimodais)
+	Thinking   string     `json:"thinking,omitempty"`     // Raciocínio de modelos como deepseek-r1 e qwen3 (fora do Content)
+}
+
+// Tool definição de ferramenta enviada ao modelo (formato /api/chat)
+type Tool struct {
+	Type     string       `json:"type"` // Sempre "function"
+	Function ToolFunction `json:"function"`
+}
+
+// ToolFunction descreve a função exposta ao modelo
+type ToolFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"` // JSON Schema dos parâmetros
+}
+
+// ToolCall chamada de ferramenta retornada pelo modelo
+type ToolCall struct {
+	ID       string           `json:"id,omitempty"`
+	Function ToolCallFunction `json:"function"`
+}
+
+// ToolCallFunction nome e argumentos da chamada
+type ToolCallFunction struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
 }
 
 // Request estrutura da requisição para Ollama
 type Request struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
-	Stream   bool      `json:"stream"`
-	Options  Options   `json:"options,omitempty"`
+	Model     string          `json:"model"`
+	Messages  []Message       `json:"messages"`
+	Stream    bool            `json:"stream"`
+	Tools     []Tool          `json:"tools,omitempty"`
+	Format    json.RawMessage `json:"format,omitempty"`     // "json" ou JSON Schema
+	KeepAlive string          `json:"keep_alive,omitempty"` // Tempo que o modelo fica carregado (ex: "5m", "-1")
+	Think     *bool           `json:"think,omitempty"`      // Pede (true) ou suprime (false) o raciocínio
+	Options   Options         `json:"options,omitempty"`
 }
 
-// Options opções da requisição
+// Options opções de geração (campo "options" de /api/chat)
 type Options struct {
-	Temperature float64 `json:"temperature,omitempty"`
-	NumPredict  int     `json:"num_predict,omitempty"`
-	TopK        int     `json:"top_k,omitempty"`
-	TopP        float64 `json:"top_p,omitempty"`
+	Temperature   *float64 `json:"temperature,omitempty"` // Ponteiro: temperatura 0 é válida
+	NumPredict    int      `json:"num_predict,omitempty"`
+	TopK          int      `json:"top_k,omitempty"`
+	TopP          float64  `json:"top_p,omitempty"`
+	NumCtx        int      `json:"num_ctx,omitempty"`
+	Seed          *int     `json:"seed,omitempty"` // Ponteiro: seed 0 é válida
+	Stop          []string `json:"stop,omitempty"`
+	RepeatPenalty float64  `json:"repeat_penalty,omitempty"`
+	NumGPU        *int     `json:"num_gpu,omitempty"` // Layers enviadas à GPU (nil = automático)
 }
 
 // Response estrutura da resposta do Ollama
@@ -28,11 +72,175 @@ type Response struct {
 	CreatedAt string  `json:"created_at"`
 	Message   Message `json:"message"`
 	Done      bool    `json:"done"`
+	Usage             // Contadores enviados no chunk final (done=true)
+}
+
+// Usage consumo de tokens e tempos reportados pelo Ollama.
+// Durações chegam em nanossegundos e decodificam direto em time.Duration.
+type Usage struct {
+	PromptTokens       int           `json:"prompt_eval_count,omitempty"`
+	CompletionTokens   int           `json:"eval_count,omitempty"`
+	TotalDuration      time.Duration `json:"total_duration,omitempty"`
+	LoadDuration       time.Duration `json:"load_duration,omitempty"`
+	PromptEvalDuration time.Duration `json:"prompt_eval_duration,omitempty"`
+	EvalDuration       time.Duration `json:"eval_duration,omitempty"`
+}
+
+// TotalTokens soma tokens de prompt e de resposta
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// TokensPerSecond velocidade de geração (tokens de resposta por segundo)
+func (u Usage) TokensPerSecond() float64 {
+	if u.EvalDuration <= 0 {
+		return 0
+	}
+	return float64(u.CompletionTokens) / u.EvalDuration.Seconds()
+}
+
+// Add acumula outro uso neste
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalDuration += other.TotalDuration
+	u.LoadDuration += other.LoadDuration
+	u.PromptEvalDuration += other.PromptEvalDuration
+	u.EvalDuration += other.EvalDuration
 }
 
-// CompletionOptions opções para completar
+// HasToolCalls indica se o modelo pediu execução de ferramentas
+func (r *Response) HasToolCalls() bool {
+	return len(r.Message.ToolCalls) > 0
+}
+
+// Float64 retorna ponteiro para v (ex: CompletionOptions.Temperature)
+func Float64(v float64) *float64 {
+	return &v
+}
+
+// FormatJSON força resposta em JSON (sem schema)
+var FormatJSON = json.RawMessage(`"json"`)
+
+// CompletionOptions opções para completar.
+// Campos zero não são enviados e o Ollama usa o padrão do modelo.
+//
+// Flash attention, número de GPUs e requisições paralelas são configurados
+// no servidor (OLLAMA_FLASH_ATTENTION, CUDA_VISIBLE_DEVICES, OLLAMA_NUM_PARALLEL)
+// e não por requisição.
 type CompletionOptions struct {
-	Temperature  float64
-	MaxTokens    int
-	SystemPrompt string
+	Temperature   *float64 // Temperatura (nil usa o padrão; 0 é enviada)
+	MaxTokens     int
+	SystemPrompt  string
+	Tools         []Tool // Ferramentas disponíveis para o modelo chamar
+	TopK          int
+	TopP          float64
+	NumCtx        int             // Janela de contexto em tokens
+	Seed          *int            // Seed para respostas reproduzíveis
+	Stop          []string        // Sequências que encerram a geração
+	RepeatPenalty float64         // Penalidade para repetições
+	NumGPU        *int            // Layers enviadas à GPU (nil = o Ollama decide)
+	KeepAlive     string          // Tempo que o modelo fica carregado após a requisição
+	Format        json.RawMessage // "json" ou JSON Schema da resposta
+	Think         *bool           // Pede (true) ou suprime (false) o raciocínio; nil usa o padrão do modelo
+	OnThinking    func(string)    // Recebe o raciocínio em streaming, separado do conteúdo
+	Fallbacks     []string        // Modelos tentados em ordem se o modelo estiver indisponível
+}
+
+// Merge retorna cópia de base com os campos não-zero de override aplicados
+func (base CompletionOptions) Merge(override *CompletionOptions) CompletionOptions {
+	merged := base
+	if override == nil {
+		return merged
+	}
+
+	if override.Temperature != nil {
+		merged.Temperature = override.Temperature
+	}
+	if override.MaxTokens != 0 {
+		merged.MaxTokens = override.MaxTokens
+	}
+	if override.SystemPrompt != "" {
+		merged.SystemPrompt = override.SystemPrompt
+	}
+	if override.Tools != nil {
+		merged.Tools = override.Tools
+	}
+	if override.TopK != 0 {
+		merged.TopK = override.TopK
+	}
+	if override.TopP != 0 {
+		merged.TopP = override.TopP
+	}
+	if override.NumCtx != 0 {
+		merged.NumCtx = override.NumCtx
+	}
+	if override.Seed != nil {
+		merged.Seed = override.Seed
+	}
+	if override.Stop != nil {
+		merged.Stop = override.Stop
+	}
+	if override.RepeatPenalty != 0 {
+		merged.RepeatPenalty = override.RepeatPenalty
+	}
+	if override.NumGPU != nil {
+		merged.NumGPU = override.NumGPU
+	}
+	if override.KeepAlive != "" {
+		merged.KeepAlive = override.KeepAlive
+	}
+	if override.Format != nil {
+		merged.Format = override.Format
+	}
+	if override.Think != nil {
+		merged.Think = override.Think
+	}
+	if override.OnThinking != nil {
+		merged.OnThinking = override.OnThinking
+	}
+	if override.Fallbacks != nil {
+		merged.Fallbacks = override.Fallbacks
+	}
+
+	return merged
+}
+
+// NewTool cria definição de ferramenta a partir do schema de parâmetros
+func NewTool(name, description string, parameters map[string]interface{}) Tool {
+	if parameters == nil {
+		parameters = map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		}
+	}
+
+	return Tool{
+		Type: "function",
+		Function: ToolFunction{
+			Name:        name,
+			Description: description,
+			Parameters:  parameters,
+		},
+	}
+}
+
+// NewToolResultMessage cria mensagem "tool" com o resultado de uma chamada
+func NewToolResultMessage(toolName, content string) Message {
+	return Message{
+		Role:     "tool",
+		Content:  content,
+		ToolName: toolName,
+	}
+}
+
+// TurnHasImages indica se a mensagem atual do usuário (a última com role "user")
+// carrega imagens e exige modelo multimodal. Imagens de turnos anteriores não contam.
+func TurnHasImages(messages []Message) bool {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return len(messages[i].Images) > 0
+		}
+	}
+	return false
 }
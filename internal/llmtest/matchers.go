@@ -0,0 +1,90 @@
+package llmtest
+
+import (
+	"strings"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
+
+// Matcher decide se uma regra responde a requisição
+type Matcher func(req *llm.Request) bool
+
+// Any aceita qualquer requisição
+func Any() Matcher {
+	return func(req *llm.Request) bool { return true }
+}
+
+// All aceita quando todos os matchers aceitam
+func All(matchers ...Matcher) Matcher {
+	return func(req *llm.Request) bool {
+		for _, match := range matchers {
+			if !match(req) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Not inverte um matcher
+func Not(match Matcher) Matcher {
+	return func(req *llm.Request) bool { return !match(req) }
+}
+
+// LastUserContains última mensagem do usuário contém substr
+func LastUserContains(substr string) Matcher {
+	return func(req *llm.Request) bool {
+		return strings.Contains(lastUserContent(req), substr)
+	}
+}
+
+// SystemContains alguma mensagem de sistema contém substr
+func SystemContains(substr string) Matcher {
+	return func(req *llm.Request) bool {
+		for _, msg := range req.Messages {
+			if msg.Role == "system" && strings.Contains(msg.Content, substr) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// LastMessageRole última mensagem tem o papel informado ("user", "tool"...)
+func LastMessageRole(role string) Matcher {
+	return func(req *llm.Request) bool {
+		return len(req.Messages) > 0 && req.Messages[len(req.Messages)-1].Role == role
+	}
+}
+
+// WithTools requisição oferece ferramentas
+func WithTools() Matcher {
+	return func(req *llm.Request) bool { return len(req.Tools) > 0 }
+}
+
+// WithTool requisição oferece a ferramenta name
+func WithTool(name string) Matcher {
+	return func(req *llm.Request) bool {
+		for _, tool := range req.Tools {
+			if tool.Function.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WithFormat requisição pede saída estruturada ("json" ou JSON Schema)
+func WithFormat() Matcher {
+	return func(req *llm.Request) bool { return len(req.Format) > 0 }
+}
+
+// Streaming requisição com stream=true
+func Streaming() Matcher {
+	return func(req *llm.Request) bool { return req.Stream }
+}
+
+// Model requisição para o modelo informado
+func Model(name string) Matcher {
+	return func(req *llm.Request) bool { return req.Model == name }
+}
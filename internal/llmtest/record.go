@@ -0,0 +1,268 @@
+package llmtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Variáveis de ambiente do modo de gravação
+const (
+	RecordEnv     = "LLMTEST_RECORD"     // "1" grava fixtures em vez de reproduzir
+	TargetEnv     = "LLMTEST_OLLAMA_URL" // Servidor real usado na gravação
+	DefaultTarget = "http://localhost:11434"
+)
+
+// Interaction requisição e resposta gravadas
+type Interaction struct {
+	Method      string          `json:"method"`
+	Path        string          `json:"path"`
+	Request     json.RawMessage `json:"request,omitempty"`
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type,omitempty"`
+	Response    string          `json:"response"` // Corpo exato, incluindo todos os chunks do stream
+}
+
+// Fixture sessão gravada
+type Fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadFixture lê fixture de arquivo
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fixture: %w", err)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("parse fixture: %w", err)
+	}
+	return &fixture, nil
+}
+
+// Save grava fixture em arquivo, criando o diretório se necessário
+func (f *Fixture) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create fixture dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal fixture: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Cassette servidor que grava ou reproduz uma fixture
+type Cassette struct {
+	*httptest.Server
+
+	t         testing.TB
+	path      string
+	recording bool
+	target    string
+
+	mu       sync.Mutex
+	fixture  Fixture
+	position int
+	requests []Interaction // Requisições recebidas (apenas método, path e corpo)
+}
+
+// Open reproduz a fixture em path. Com LLMTEST_RECORD=1, grava uma nova
+// sessão contra LLMTEST_OLLAMA_URL (padrão localhost:11434).
+func Open(t testing.TB, path string) *Cassette {
+	if os.Getenv(RecordEnv) == "1" {
+		target := os.Getenv(TargetEnv)
+		if target == "" {
+			target = DefaultTarget
+		}
+		return Record(t, target, path)
+	}
+	return Replay(t, path)
+}
+
+// Record encaminha as requisições para target e grava a sessão em path no fim do teste
+func Record(t testing.TB, target, path string) *Cassette {
+	c := &Cassette{t: t, path: path, recording: true, target: strings.TrimRight(target, "/")}
+
+	// Cleanups rodam em ordem inversa: o servidor fecha antes de salvar
+	t.Cleanup(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if err := c.fixture.Save(path); err != nil {
+			t.Errorf("llmtest: %v", err)
+		}
+	})
+
+	c.Server = httptest.NewServer(http.HandlerFunc(c.handleRecord))
+	t.Cleanup(c.Close)
+	return c
+}
+
+// Replay reproduz a fixture em path na ordem gravada
+func Replay(t testing.TB, path string) *Cassette {
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		t.Fatalf("llmtest: %v (record it with %s=1)", err, RecordEnv)
+	}
+
+	c := &Cassette{t: t, path: path, fixture: *fixture}
+
+	t.Cleanup(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if remaining := len(c.fixture.Interactions) - c.position; remaining > 0 && !t.Failed() {
+			t.Errorf("llmtest: %d recorded interactions were not replayed from %s", remaining, path)
+		}
+	})
+
+	c.Server = httptest.NewServer(http.HandlerFunc(c.handleReplay))
+	t.Cleanup(c.Close)
+	return c
+}
+
+// Recording indica se a cassette está gravando
+func (c *Cassette) Recording() bool {
+	return c.recording
+}
+
+// Requests retorna as requisições recebidas (método, path e corpo)
+func (c *Cassette) Requests() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Interaction(nil), c.requests...)
+}
+
+// Fixture retorna cópia das interações gravadas até agora
+func (c *Cassette) Fixture() Fixture {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Fixture{Interactions: append([]Interaction(nil), c.fixture.Interactions...)}
+}
+
+func (c *Cassette) handleRecord(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	c.trackRequest(r, body)
+
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, c.target+r.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	req.Header = r.Header.Clone()
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Errorf("llmtest: record %s %s: %v", r.Method, r.URL.Path, err)
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	// Repassa o stream enquanto grava, preservando o ritmo dos chunks
+	var captured bytes.Buffer
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			captured.Write(buf[:n])
+			w.Write(buf[:n])
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if readErr != nil {
+			break
+		}
+	}
+
+	interaction := Interaction{
+		Method:      r.Method,
+		Path:        r.URL.Path,
+		Status:      resp.StatusCode,
+		ContentType: contentType,
+		Response:    captured.String(),
+	}
+	if json.Valid(body) {
+		interaction.Request = json.RawMessage(body)
+	}
+
+	c.mu.Lock()
+	c.fixture.Interactions = append(c.fixture.Interactions, interaction)
+	c.mu.Unlock()
+}
+
+func (c *Cassette) handleReplay(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	c.trackRequest(r, body)
+
+	c.mu.Lock()
+	if c.position >= len(c.fixture.Interactions) {
+		c.mu.Unlock()
+		c.t.Errorf("llmtest: unexpected request %s %s: all %d recorded interactions were replayed",
+			r.Method, r.URL.Path, len(c.fixture.Interactions))
+		writeError(w, http.StatusInternalServerError, "llmtest: fixture exhausted")
+		return
+	}
+	interaction := c.fixture.Interactions[c.position]
+	c.position++
+	c.mu.Unlock()
+
+	if interaction.Method != r.Method || interaction.Path != r.URL.Path {
+		c.t.Errorf("llmtest: request #%d is %s %s, fixture recorded %s %s",
+			c.position, r.Method, r.URL.Path, interaction.Method, interaction.Path)
+		writeError(w, http.StatusInternalServerError, "llmtest: request does not match fixture")
+		return
+	}
+
+	if interaction.ContentType != "" {
+		w.Header().Set("Content-Type", interaction.ContentType)
+	}
+	w.WriteHeader(interaction.Status)
+
+	// Streams são reenviados linha a linha para o cliente ver chunks separados
+	flusher, _ := w.(http.Flusher)
+	reader := bufio.NewReader(strings.NewReader(interaction.Response))
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			io.WriteString(w, line)
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+}
+
+// trackRequest registra a requisição recebida
+func (c *Cassette) trackRequest(r *http.Request, body []byte) {
+	interaction := Interaction{Method: r.Method, Path: r.URL.Path}
+	if json.Valid(body) {
+		interaction.Request = json.RawMessage(append([]byte(nil), body...))
+	}
+
+	c.mu.Lock()
+	c.requests = append(c.requests, interaction)
+	c.mu.Unlock()
+}
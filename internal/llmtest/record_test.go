@@ -0,0 +1,98 @@
+package llmtest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	fixture := filepath.Join(t.TempDir(), "testdata", "session.json")
+	messages := []llm.Message{{Role: "user", Content: "explique Go"}}
+
+	var recordedStream string
+	t.Run("record", func(t *testing.T) {
+		upstream := NewServer(t, Text("Go é simples"), Text("e rápido"))
+		cassette := Record(t, upstream.URL, fixture)
+		client := llm.NewClient(cassette.URL, "test-model")
+
+		if _, _, err := client.Complete(context.Background(), messages, nil); err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+		if _, _, err := client.CompleteStreaming(context.Background(), messages, nil, nil); err != nil {
+			t.Fatalf("CompleteStreaming failed: %v", err)
+		}
+
+		recorded := cassette.Fixture().Interactions
+		if len(recorded) != 2 || recorded[1].Path != "/api/chat" {
+			t.Fatalf("Expected 2 recorded chat interactions, got %+v", recorded)
+		}
+		recordedStream = recorded[1].Response
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		cassette := Replay(t, fixture)
+
+		loaded, err := LoadFixture(fixture)
+		if err != nil {
+			t.Fatalf("LoadFixture failed: %v", err)
+		}
+		if loaded.Interactions[1].Response != recordedStream {
+			t.Fatal("Fixture should keep the response body byte-for-byte")
+		}
+
+		client := llm.NewClient(cassette.URL, "test-model")
+		content, _, err := client.Complete(context.Background(), messages, nil)
+		if err != nil || content != "Go é simples" {
+			t.Fatalf("Unexpected replay: %q, %v", content, err)
+		}
+
+		var chunks []string
+		streamed, _, err := client.CompleteStreaming(context.Background(), messages, nil, func(chunk string) {
+			chunks = append(chunks, chunk)
+		})
+		if err != nil || streamed != "e rápido" || len(chunks) != 2 {
+			t.Errorf("Unexpected streamed replay: %q %q, %v", streamed, chunks, err)
+		}
+
+		if len(cassette.Requests()) != 2 || !strings.Contains(string(cassette.Requests()[0].Request), "explique Go") {
+			t.Errorf("Replay should track received requests, got %+v", cassette.Requests())
+		}
+	})
+}
+
+func TestReplay_ReturnsExactBytes(t *testing.T) {
+	fixture := filepath.Join(t.TempDir(), "raw.json")
+	raw := "{\"model\":\"m\",\"done\":false}\n{\"model\":\"m\",\"done\":true}\n"
+
+	f := &Fixture{Interactions: []Interaction{{
+		Method:      http.MethodPost,
+		Path:        "/api/chat",
+		Status:      http.StatusOK,
+		ContentType: "application/x-ndjson",
+		Response:    raw,
+	}}}
+	if err := f.Save(fixture); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	cassette := Replay(t, fixture)
+	resp, err := http.Post(cassette.URL+"/api/chat", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != raw {
+		t.Errorf("Expected exact bytes %q, got %q", raw, body)
+	}
+	if resp.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Errorf("Content type should be replayed, got %s", resp.Header.Get("Content-Type"))
+	}
+}
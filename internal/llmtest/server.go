@@ -0,0 +1,424 @@
+// Package llmtest fornece um servidor Ollama fake e determinístico para testes.
+//
+// O servidor fala o protocolo real (/api/chat com e sem streaming, /api/tags e
+// /api/embed), então testes exercitam o llm.Client de ponta a ponta sem GPU.
+// Respostas vêm de uma fila roteirizada ou de regras com matchers; Record e
+// Replay gravam sessões reais em fixtures e as reproduzem byte a byte.
+package llmtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
+
+// CreatedAt timestamp fixo das respostas, para saídas reproduzíveis
+const CreatedAt = "2024-01-01T00:00:00Z"
+
+// EmbeddingDimensions dimensão dos vetores do embedder padrão
+const EmbeddingDimensions = 32
+
+// Reply resposta roteirizada para uma chamada a /api/chat
+type Reply struct {
+	Content   string
+	Thinking  string // Raciocínio enviado no campo "thinking" (antes do conteúdo)
+	ToolCalls []llm.ToolCall
+	Usage     llm.Usage // Zerado: calculado de forma determinística
+	Chunks    []string  // Divisão do conteúdo no streaming (padrão: por palavra)
+	Status    int       // Status HTTP de erro (0 = 200)
+	Error     string    // Mensagem enviada em {"error": ...} junto com Status
+}
+
+// Text resposta com conteúdo de texto
+func Text(content string) Reply {
+	return Reply{Content: content}
+}
+
+// JSON resposta cujo conteúdo é v serializado (intents, saídas estruturadas)
+func JSON(v interface{}) Reply {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("llmtest: marshal reply: %v", err))
+	}
+	return Reply{Content: string(data)}
+}
+
+// ToolCall resposta com uma chamada de ferramenta
+func ToolCall(name string, args map[string]interface{}) Reply {
+	return Reply{ToolCalls: []llm.ToolCall{{
+		Function: llm.ToolCallFunction{Name: name, Arguments: args},
+	}}}
+}
+
+// Fail resposta de erro HTTP
+func Fail(status int, message string) Reply {
+	return Reply{Status: status, Error: message}
+}
+
+// rule resposta usada sempre que o matcher aceita a requisição
+type rule struct {
+	match Matcher
+	reply Reply
+	once  bool
+}
+
+// Server servidor Ollama fake
+type Server struct {
+	*httptest.Server
+
+	t        testing.TB
+	mu       sync.Mutex
+	queue    []Reply
+	rules    []rule
+	fallback *Reply
+	requests []llm.Request
+	models   []llm.ModelInfo
+	embedder func(text string) []float64
+}
+
+// NewServer inicia servidor fake que responde /api/chat com replies em ordem.
+// O servidor é encerrado no fim do teste.
+func NewServer(t testing.TB, replies ...Reply) *Server {
+	s := &Server{
+		t:        t,
+		queue:    replies,
+		embedder: HashEmbedding,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/chat", s.handleChat)
+	mux.HandleFunc("/api/tags", s.handleTags)
+	mux.HandleFunc("/api/embed", s.handleEmbed)
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Enqueue adiciona respostas ao fim da fila
+func (s *Server) Enqueue(replies ...Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = append(s.queue, replies...)
+}
+
+// On responde reply a toda requisição aceita por match.
+// Regras têm prioridade sobre a fila, na ordem em que foram registradas.
+func (s *Server) On(match Matcher, reply Reply) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = append(s.rules, rule{match: match, reply: reply})
+	return s
+}
+
+// Once responde reply apenas à primeira requisição aceita por match
+func (s *Server) Once(match Matcher, reply Reply) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = append(s.rules, rule{match: match, reply: reply, once: true})
+	return s
+}
+
+// Default define resposta usada quando nenhuma regra casa e a fila está vazia
+func (s *Server) Default(reply Reply) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallback = &reply
+	return s
+}
+
+// SetModels define os modelos listados em /api/tags
+func (s *Server) SetModels(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.models = make([]llm.ModelInfo, 0, len(names))
+	for _, name := range names {
+		s.models = append(s.models, llm.ModelInfo{
+			Name:       name,
+			Model:      name,
+			ModifiedAt: mustParseTime(CreatedAt),
+			Digest:     fmt.Sprintf("%016x", hashString(name)),
+		})
+	}
+}
+
+// SetEmbedder define a função que gera os vetores de /api/embed
+func (s *Server) SetEmbedder(fn func(text string) []float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.embedder = fn
+}
+
+// Requests retorna cópia das requisições recebidas em /api/chat
+func (s *Server) Requests() []llm.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]llm.Request(nil), s.requests...)
+}
+
+// LastRequest retorna a última requisição recebida em /api/chat
+func (s *Server) LastRequest() llm.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.requests) == 0 {
+		s.t.Errorf("llmtest: no chat request received")
+		return llm.Request{}
+	}
+	return s.requests[len(s.requests)-1]
+}
+
+// Pending número de respostas da fila ainda não consumidas
+func (s *Server) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
+
+// next escolhe a resposta: regras, depois fila, depois resposta padrão
+func (s *Server) next(req *llm.Request) (Reply, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, *req)
+
+	for i, r := range s.rules {
+		if r.match(req) {
+			if r.once {
+				s.rules = append(s.rules[:i], s.rules[i+1:]...)
+			}
+			return r.reply, true
+		}
+	}
+
+	if len(s.queue) > 0 {
+		reply := s.queue[0]
+		s.queue = s.queue[1:]
+		return reply, true
+	}
+
+	if s.fallback != nil {
+		return *s.fallback, true
+	}
+	return Reply{}, false
+}
+
+func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
+	var req llm.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+		return
+	}
+
+	reply, ok := s.next(&req)
+	if !ok {
+		s.t.Errorf("llmtest: no scripted reply for chat request #%d (last user message: %q)",
+			len(s.Requests()), lastUserContent(&req))
+		writeError(w, http.StatusInternalServerError, "llmtest: no scripted reply")
+		return
+	}
+
+	if reply.Status != 0 && reply.Status != http.StatusOK {
+		writeError(w, reply.Status, reply.Error)
+		return
+	}
+
+	usage := reply.Usage
+	if usage == (llm.Usage{}) {
+		usage = estimateUsage(&req, &reply)
+	}
+
+	if !req.Stream {
+		writeJSON(w, llm.Response{
+			Model:     req.Model,
+			CreatedAt: CreatedAt,
+			Message:   llm.Message{Role: "assistant", Content: reply.Content, Thinking: reply.Thinking, ToolCalls: reply.ToolCalls},
+			Done:      true,
+			Usage:     usage,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+
+	send := func(resp llm.Response) {
+		resp.Model = req.Model
+		resp.CreatedAt = CreatedAt
+		encoder.Encode(resp)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if reply.Thinking != "" {
+		for _, chunk := range strings.SplitAfter(reply.Thinking, " ") {
+			send(llm.Response{Message: llm.Message{Role: "assistant", Thinking: chunk}})
+		}
+	}
+
+	for _, chunk := range reply.chunks() {
+		send(llm.Response{Message: llm.Message{Role: "assistant", Content: chunk}})
+	}
+
+	// Ollama envia as tool calls em um chunk próprio
+	if len(reply.ToolCalls) > 0 {
+		send(llm.Response{Message: llm.Message{Role: "assistant", ToolCalls: reply.ToolCalls}})
+	}
+
+	send(llm.Response{Message: llm.Message{Role: "assistant"}, Done: true, Usage: usage})
+}
+
+func (s *Server) handleTags(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	models := append([]llm.ModelInfo{}, s.models...)
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]interface{}{"models": models})
+}
+
+// embedRequest corpo de /api/embed (input é string ou lista de strings)
+type embedRequest struct {
+	Model string          `json:"model"`
+	Input json.RawMessage `json:"input"`
+}
+
+func (s *Server) handleEmbed(w http.ResponseWriter, r *http.Request) {
+	var req embedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+		return
+	}
+
+	var inputs []string
+	if err := json.Unmarshal(req.Input, &inputs); err != nil {
+		var single string
+		if err := json.Unmarshal(req.Input, &single); err != nil {
+			writeError(w, http.StatusBadRequest, "input must be a string or a list of strings")
+			return
+		}
+		inputs = []string{single}
+	}
+
+	s.mu.Lock()
+	embedder := s.embedder
+	s.mu.Unlock()
+
+	embeddings := make([][]float64, 0, len(inputs))
+	promptTokens := 0
+	for _, input := range inputs {
+		embeddings = append(embeddings, embedder(input))
+		promptTokens += len(strings.Fields(input))
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"model":             req.Model,
+		"embeddings":        embeddings,
+		"prompt_eval_count": promptTokens,
+	})
+}
+
+// chunks divide o conteúdo para streaming
+func (r Reply) chunks() []string {
+	if len(r.Chunks) > 0 {
+		return r.Chunks
+	}
+	if r.Content == "" {
+		return nil
+	}
+	return strings.SplitAfter(r.Content, " ")
+}
+
+// estimateUsage uso determinístico: ~1 token a cada 4 caracteres, 10ms por token gerado
+func estimateUsage(req *llm.Request, reply *Reply) llm.Usage {
+	promptChars := 0
+	for _, msg := range req.Messages {
+		promptChars += len(msg.Content)
+	}
+
+	completion := len(reply.Content)/4 + len(reply.ToolCalls)*8
+	if completion == 0 {
+		completion = 1
+	}
+	prompt := promptChars/4 + 1
+
+	return llm.Usage{
+		PromptTokens:       prompt,
+		CompletionTokens:   completion,
+		PromptEvalDuration: time.Duration(prompt) * time.Millisecond,
+		EvalDuration:       time.Duration(completion) * 10 * time.Millisecond,
+		TotalDuration:      time.Duration(prompt)*time.Millisecond + time.Duration(completion)*10*time.Millisecond,
+	}
+}
+
+// HashEmbedding embedder padrão: bag-of-words com hashing, normalizado.
+// Textos com palavras em comum têm similaridade de cosseno maior.
+func HashEmbedding(text string) []float64 {
+	vector := make([]float64, EmbeddingDimensions)
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		word = strings.Trim(word, ".,;:!?\"'()[]{}")
+		if word == "" {
+			continue
+		}
+		vector[hashString(word)%EmbeddingDimensions]++
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	if norm == 0 {
+		return vector
+	}
+
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func lastUserContent(req *llm.Request) string {
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			return req.Messages[i].Content
+		}
+	}
+	return ""
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+func mustParseTime(value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
@@ -0,0 +1,150 @@
+package llmtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
+
+func TestServer_ScriptedRepliesInOrder(t *testing.T) {
+	server := NewServer(t, Text("primeira"), Text("segunda"))
+	client := llm.NewClient(server.URL, "test-model")
+
+	for _, want := range []string{"primeira", "segunda"} {
+		content, usage, err := client.Complete(context.Background(), []llm.Message{{Role: "user", Content: "oi"}}, nil)
+		if err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+		if content != want {
+			t.Errorf("Expected %q, got %q", want, content)
+		}
+		if usage.CompletionTokens == 0 || usage.EvalDuration == 0 {
+			t.Errorf("Expected deterministic usage, got %+v", usage)
+		}
+	}
+
+	if len(server.Requests()) != 2 || server.LastRequest().Model != "test-model" {
+		t.Errorf("Unexpected requests: %+v", server.Requests())
+	}
+}
+
+func TestServer_StreamsChunksAndToolCalls(t *testing.T) {
+	reply := Text("Olá mundo feliz")
+	reply.ToolCalls = ToolCall("file_reader", map[string]interface{}{"file_path": "main.go"}).ToolCalls
+	server := NewServer(t, reply)
+	client := llm.NewClient(server.URL, "test-model")
+
+	var chunks []string
+	response, err := client.ChatStream(context.Background(), []llm.Message{{Role: "user", Content: "oi"}}, nil, func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("ChatStream failed: %v", err)
+	}
+
+	if len(chunks) != 3 || strings.Join(chunks, "") != "Olá mundo feliz" {
+		t.Errorf("Unexpected chunks: %q", chunks)
+	}
+	if len(response.Message.ToolCalls) != 1 || response.Message.ToolCalls[0].Function.Name != "file_reader" {
+		t.Errorf("Expected tool call, got %+v", response.Message.ToolCalls)
+	}
+	if !response.Done || response.CreatedAt != CreatedAt {
+		t.Errorf("Unexpected final response: %+v", response)
+	}
+}
+
+func TestServer_RulesTakePriorityOverQueue(t *testing.T) {
+	server := NewServer(t, Text("fila"))
+	server.On(WithFormat(), JSON(map[string]interface{}{"intent": "question", "confidence": 0.9}))
+	server.Once(LastUserContains("erro"), Fail(http.StatusBadRequest, "model does not support tools"))
+
+	client := llm.NewClient(server.URL, "test-model")
+	ctx := context.Background()
+
+	structured, _, err := client.Complete(ctx, []llm.Message{{Role: "user", Content: "x"}}, &llm.CompletionOptions{Format: json.RawMessage(`"json"`)})
+	if err != nil || !strings.Contains(structured, `"intent":"question"`) {
+		t.Fatalf("Format rule should answer, got %q, %v", structured, err)
+	}
+
+	if _, _, err := client.Complete(ctx, []llm.Message{{Role: "user", Content: "erro"}}, nil); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Once rule should fail the request, got %v", err)
+	}
+
+	// Regra Once consumida: a próxima cai na fila
+	content, _, err := client.Complete(ctx, []llm.Message{{Role: "user", Content: "erro"}}, nil)
+	if err != nil || content != "fila" {
+		t.Errorf("Expected queued reply, got %q, %v", content, err)
+	}
+	if server.Pending() != 0 {
+		t.Errorf("Queue should be empty, got %d", server.Pending())
+	}
+}
+
+func TestServer_DefaultReply(t *testing.T) {
+	server := NewServer(t).Default(Text("sempre"))
+	client := llm.NewClient(server.URL, "test-model")
+
+	for i := 0; i < 3; i++ {
+		if content, _, err := client.Complete(context.Background(), []llm.Message{{Role: "user", Content: "oi"}}, nil); err != nil || content != "sempre" {
+			t.Fatalf("Expected default reply, got %q, %v", content, err)
+		}
+	}
+}
+
+func TestServer_ListsModels(t *testing.T) {
+	server := NewServer(t)
+	server.SetModels("qwen2.5-coder:7b", "nomic-embed-text")
+	client := llm.NewClient(server.URL, "")
+
+	missing, err := llm.MissingModels(context.Background(), client, "qwen2.5-coder:7b", "nomic-embed-text:latest", "llama3")
+	if err != nil {
+		t.Fatalf("MissingModels failed: %v", err)
+	}
+	if len(missing) != 1 || missing[0] != "llama3" {
+		t.Errorf("Expected only llama3 missing, got %v", missing)
+	}
+}
+
+func TestServer_Embed(t *testing.T) {
+	server := NewServer(t)
+
+	body := bytes.NewBufferString(`{"model":"nomic-embed-text","input":["ler arquivo go","ler arquivo go","previsão do tempo"]}`)
+	resp, err := http.Post(server.URL+"/api/embed", "application/json", body)
+	if err != nil {
+		t.Fatalf("Embed request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	if len(result.Embeddings) != 3 || len(result.Embeddings[0]) != EmbeddingDimensions {
+		t.Fatalf("Unexpected embeddings shape: %d", len(result.Embeddings))
+	}
+	if cosine(result.Embeddings[0], result.Embeddings[1]) < 0.999 {
+		t.Error("Equal inputs should produce equal embeddings")
+	}
+	if cosine(result.Embeddings[0], result.Embeddings[2]) >= 0.999 {
+		t.Error("Different inputs should produce different embeddings")
+	}
+}
+
+func cosine(a, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
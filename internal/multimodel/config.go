@@ -1,6 +1,12 @@
 package multimodel
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
 
 // Config configuração de modelos múltiplos
 type Config struct {
@@ -12,6 +18,9 @@ type Config struct {
 
 	// Enabled se multi-model está habilitado
 	Enabled bool
+
+	// Cooldown tempo que um modelo com falha fica fora das cadeias de fallback (0 = padrão)
+	Cooldown time.Duration
 }
 
 // NewConfig cria nova configuração multi-model
@@ -31,7 +40,7 @@ func DefaultConfig() *Config {
 			TaskTypeIntent: {
 				Name:        "qwen2.5-coder:1.5b",
 				MaxTokens:   512,
-				Temperature: 0.3,
+				Temperature: llm.Float64(0.3),
 				Description: "Fast model for intent detection",
 			},
 
@@ -39,7 +48,7 @@ func DefaultConfig() *Config {
 			TaskTypeCode: {
 				Name:        "qwen2.5-coder:7b",
 				MaxTokens:   4096,
-				Temperature: 0.7,
+				Temperature: llm.Float64(0.7),
 				Description: "Precise model for code generation",
 			},
 
@@ -47,7 +56,7 @@ func DefaultConfig() *Config {
 			TaskTypeSearch: {
 				Name:        "qwen2.5-coder:3b",
 				MaxTokens:   2048,
-				Temperature: 0.5,
+				Temperature: llm.Float64(0.5),
 				Description: "Balanced model for web search summarization",
 			},
 
@@ -55,7 +64,7 @@ func DefaultConfig() *Config {
 			TaskTypeAnalysis: {
 				Name:        "qwen2.5-coder:7b",
 				MaxTokens:   8192,
-				Temperature: 0.5,
+				Temperature: llm.Float64(0.5),
 				Description: "Precise model for code analysis",
 			},
 
@@ -63,7 +72,7 @@ func DefaultConfig() *Config {
 			TaskTypeDefault: {
 				Name:        "qwen2.5-coder:7b",
 				MaxTokens:   4096,
-				Temperature: 0.7,
+				Temperature: llm.Float64(0.7),
 				Description: "Default general-purpose model",
 			},
 		},
@@ -94,6 +103,16 @@ func (c *Config) GetModel(taskType TaskType) (ModelSpec, error) {
 	return model, nil
 }
 
+// HasModel indica se o task type tem modelo próprio configurado
+// (usado para tarefas como vision, que o modelo padrão pode não suportar)
+func (c *Config) HasModel(taskType TaskType) bool {
+	if !c.Enabled {
+		return false
+	}
+	_, ok := c.Models[taskType]
+	return ok
+}
+
 // SetModel define modelo para um task type
 func (c *Config) SetModel(taskType TaskType, spec ModelSpec) error {
 	if !taskType.IsValid() {
@@ -104,6 +123,10 @@ func (c *Config) SetModel(taskType TaskType, spec ModelSpec) error {
 		return fmt.Errorf("model name cannot be empty")
 	}
 
+	if !llm.IsValidProvider(spec.Provider) {
+		return fmt.Errorf("invalid provider: %s", spec.Provider)
+	}
+
 	c.Models[taskType] = spec
 
 	// Se for default, atualizar DefaultModel também
@@ -114,6 +137,36 @@ func (c *Config) SetModel(taskType TaskType, spec ModelSpec) error {
 	return nil
 }
 
+// ModelNames retorna os nomes distintos de modelos usados pela configuração
+func (c *Config) ModelNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	addSpec := func(spec ModelSpec) {
+		add(spec.Name)
+		for _, fallback := range spec.Fallbacks {
+			add(fallback)
+		}
+	}
+
+	addSpec(c.DefaultModel)
+	if c.Enabled {
+		for _, spec := range c.Models {
+			addSpec(spec)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
 // Enable habilita multi-model
 func (c *Config) Enable() {
 	c.Enabled = true
@@ -135,6 +188,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("default model must be configured")
 	}
 
+	if !llm.IsValidProvider(c.DefaultModel.Provider) {
+		return fmt.Errorf("invalid provider for default model: %s", c.DefaultModel.Provider)
+	}
+
 	if c.Enabled {
 		// Validar que todos os task types têm modelos configurados
 		requiredTypes := []TaskType{
@@ -159,8 +216,12 @@ func (c *Config) Validate() error {
 				return fmt.Errorf("invalid MaxTokens for task type %s: %d", taskType, model.MaxTokens)
 			}
 
-			if model.Temperature < 0 || model.Temperature > 1 {
-				return fmt.Errorf("invalid Temperature for task type %s: %f", taskType, model.Temperature)
+			if t := model.Temperature; t != nil && (*t < 0 || *t > 1) {
+				return fmt.Errorf("invalid Temperature for task type %s: %f", taskType, *t)
+			}
+
+			if !llm.IsValidProvider(model.Provider) {
+				return fmt.Errorf("invalid provider for task type %s: %s", taskType, model.Provider)
 			}
 		}
 	}
@@ -174,6 +235,7 @@ func (c *Config) Clone() *Config {
 		Enabled:      c.Enabled,
 		DefaultModel: c.DefaultModel,
 		Models:       make(map[TaskType]ModelSpec, len(c.Models)),
+		Cooldown:     c.Cooldown,
 	}
 
 	for taskType, spec := range c.Models {
@@ -1,6 +1,10 @@
 package multimodel
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
 
 // TestNewConfig testa criação de nova configuração
 func TestNewConfig(t *testing.T) {
@@ -54,8 +58,8 @@ func TestDefaultConfig(t *testing.T) {
 			t.Errorf("Invalid MaxTokens for task type %s: %d", taskType, spec.MaxTokens)
 		}
 
-		if spec.Temperature < 0 || spec.Temperature > 1 {
-			t.Errorf("Invalid Temperature for task type %s: %f", taskType, spec.Temperature)
+		if spec.Temperature == nil || *spec.Temperature < 0 || *spec.Temperature > 1 {
+			t.Errorf("Invalid Temperature for task type %s: %v", taskType, spec.Temperature)
 		}
 	}
 
@@ -118,7 +122,7 @@ func TestConfig_SetModel(t *testing.T) {
 	customSpec := ModelSpec{
 		Name:        "custom-model",
 		MaxTokens:   2048,
-		Temperature: 0.8,
+		Temperature: llm.Float64(0.8),
 		Description: "Custom model",
 	}
 
@@ -167,7 +171,7 @@ func TestConfig_SetModel_UpdatesDefault(t *testing.T) {
 	customSpec := ModelSpec{
 		Name:        "new-default",
 		MaxTokens:   1024,
-		Temperature: 0.5,
+		Temperature: llm.Float64(0.5),
 	}
 
 	err := cfg.SetModel(TaskTypeDefault, customSpec)
@@ -241,7 +245,7 @@ func TestConfig_Validate_EmptyModelName(t *testing.T) {
 	cfg.Models[TaskTypeCode] = ModelSpec{
 		Name:        "",
 		MaxTokens:   1024,
-		Temperature: 0.7,
+		Temperature: llm.Float64(0.7),
 	}
 
 	err := cfg.Validate()
@@ -257,7 +261,7 @@ func TestConfig_Validate_InvalidMaxTokens(t *testing.T) {
 	cfg.Models[TaskTypeCode] = ModelSpec{
 		Name:        "test",
 		MaxTokens:   0,
-		Temperature: 0.7,
+		Temperature: llm.Float64(0.7),
 	}
 
 	err := cfg.Validate()
@@ -283,7 +287,7 @@ func TestConfig_Validate_InvalidTemperature(t *testing.T) {
 			cfg.Models[TaskTypeCode] = ModelSpec{
 				Name:        "test",
 				MaxTokens:   1024,
-				Temperature: tc.temperature,
+				Temperature: llm.Float64(tc.temperature),
 			}
 
 			err := cfg.Validate()
@@ -303,7 +307,7 @@ func TestConfig_Validate_DisabledSkipsValidation(t *testing.T) {
 	cfg.DefaultModel = ModelSpec{
 		Name:        "default",
 		MaxTokens:   1024,
-		Temperature: 0.7,
+		Temperature: llm.Float64(0.7),
 	}
 
 	// Não configurar outros models - quando disabled, não deve validar
@@ -380,3 +384,76 @@ func TestDefaultConfig_ModelOptimization(t *testing.T) {
 		t.Errorf("Analysis should use precise model, got %s", analysisSpec.Name)
 	}
 }
+
+func TestConfig_ModelNames(t *testing.T) {
+	cfg := DefaultConfig()
+
+	names := cfg.ModelNames()
+	expected := []string{"qwen2.5-coder:1.5b", "qwen2.5-coder:3b", "qwen2.5-coder:7b"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	for i, name := range expected {
+		if names[i] != name {
+			t.Errorf("Expected %s at %d, got %s", name, i, names[i])
+		}
+	}
+
+	cfg.Disable()
+	if names := cfg.ModelNames(); len(names) != 1 || names[0] != "qwen2.5-coder:7b" {
+		t.Errorf("Disabled config should only report default model, got %v", names)
+	}
+}
+
+func TestConfig_ModelNamesIncludesFallbacks(t *testing.T) {
+	cfg := NewConfig()
+	cfg.DefaultModel = ModelSpec{Name: "qwen2.5-coder:7b", Fallbacks: []string{"qwen2.5-coder:3b"}}
+	cfg.SetModel(TaskTypeCode, ModelSpec{Name: "qwen2.5-coder:14b", Fallbacks: []string{"qwen2.5-coder:7b"}})
+	cfg.Enable()
+
+	names := cfg.ModelNames()
+	if len(names) != 3 || names[0] != "qwen2.5-coder:14b" || names[1] != "qwen2.5-coder:3b" {
+		t.Errorf("Fallback models should be reported once, got %v", names)
+	}
+
+	spec, _ := cfg.GetModel(TaskTypeCode)
+	if opts := spec.CompletionOptions(); len(opts.Fallbacks) != 1 || opts.Fallbacks[0] != "qwen2.5-coder:7b" {
+		t.Errorf("Fallbacks should be passed in the completion options, got %+v", opts.Fallbacks)
+	}
+}
+
+func TestConfig_RejectsUnknownProvider(t *testing.T) {
+	cfg := DefaultConfig()
+
+	if err := cfg.SetModel(TaskTypeCode, ModelSpec{Name: "x", Provider: "bogus"}); err == nil {
+		t.Error("SetModel should reject unknown provider")
+	}
+
+	spec := cfg.Models[TaskTypeSearch]
+	spec.Provider = "bogus"
+	cfg.Models[TaskTypeSearch] = spec
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate should reject unknown provider")
+	}
+}
+
+// TestConfig_HasModel testa detecção de modelo próprio por task type
+func TestConfig_HasModel(t *testing.T) {
+	cfg := NewConfig()
+	cfg.DefaultModel = ModelSpec{Name: "qwen2.5-coder:7b"}
+	cfg.Enable()
+
+	if cfg.HasModel(TaskTypeVision) {
+		t.Error("Vision should not be configured yet")
+	}
+
+	cfg.SetModel(TaskTypeVision, ModelSpec{Name: "llava:7b"})
+	if !cfg.HasModel(TaskTypeVision) {
+		t.Error("Expected vision model to be configured")
+	}
+
+	cfg.Disable()
+	if cfg.HasModel(TaskTypeVision) {
+		t.Error("Disabled config should always use the default model")
+	}
+}
@@ -9,46 +9,124 @@ import (
 
 // Router gerencia roteamento de requests para modelos específicos
 type Router struct {
-	config  *Config
-	clients map[string]*llm.Client // cache de clients por model name
-	baseURL string
-	mu      sync.RWMutex
+	config      *Config
+	clients     map[string]llm.Provider   // cache de clients por model name
+	taskClients map[TaskType]llm.Provider // clients com as opções do ModelSpec de cada task
+	baseURL     string
+	onCreate    func(client llm.Provider) // Configura cada client novo (transporte, hooks)
+	health      *llm.ModelHealth          // Modelos com falha em cool-down, compartilhado pelos clients
+	mu          sync.RWMutex
 }
 
 // NewRouter cria novo router
 func NewRouter(baseURL string, config *Config) *Router {
 	return &Router{
-		config:  config,
-		clients: make(map[string]*llm.Client),
-		baseURL: baseURL,
+		config:      config,
+		clients:     make(map[string]llm.Provider),
+		taskClients: make(map[TaskType]llm.Provider),
+		baseURL:     baseURL,
+		health:      llm.NewModelHealth(config.Cooldown),
 	}
 }
 
+// Health retorna o registro de modelos em cool-down. Clients criados fora do
+// router (ex: o principal) devem usá-lo para compartilhar a memória de falhas.
+func (r *Router) Health() *llm.ModelHealth {
+	return r.health
+}
+
+// SetClientHook define callback chamado para cada client criado pelo router
+// (ex: aplicar transporte e opções padrão do client principal)
+func (r *Router) SetClientHook(fn func(client llm.Provider)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.onCreate = fn
+}
+
 // GetClient retorna LLM client para um task type
-func (r *Router) GetClient(taskType TaskType) (*llm.Client, error) {
+func (r *Router) GetClient(taskType TaskType) (llm.Provider, error) {
 	// Obter modelo para o task type
 	modelSpec, err := r.config.GetModel(taskType)
 	if err != nil {
 		return nil, fmt.Errorf("get model for task type %s: %w", taskType, err)
 	}
 
-	// Retornar client (criar se não existir)
-	return r.getOrCreateClient(modelSpec.Name), nil
+	r.mu.RLock()
+	client, ok := r.taskClients[taskType]
+	r.mu.RUnlock()
+
+	if ok && client.GetModel() == modelSpec.Name {
+		return client, nil
+	}
+
+	// Client do modelo (conexão compartilhada) com as opções da task
+	base, err := r.getOrCreateClient(modelSpec)
+	if err != nil {
+		return nil, fmt.Errorf("create client for %s: %w", modelSpec.Name, err)
+	}
+	client = base.WithOptions(modelSpec.CompletionOptions())
+
+	r.mu.Lock()
+	r.taskClients[taskType] = client
+	r.mu.Unlock()
+
+	return client, nil
+}
+
+// GetConfiguredClient retorna client do task type apenas se ele tiver modelo
+// próprio configurado (nil caso contrário, para o chamador manter o client atual)
+func (r *Router) GetConfiguredClient(taskType TaskType) llm.Provider {
+	r.mu.RLock()
+	configured := r.config.HasModel(taskType)
+	r.mu.RUnlock()
+
+	if !configured {
+		return nil
+	}
+
+	client, err := r.GetClient(taskType)
+	if err != nil {
+		return nil
+	}
+	return client
 }
 
-// GetClientForModel retorna LLM client para um modelo específico
-func (r *Router) GetClientForModel(modelName string) *llm.Client {
-	return r.getOrCreateClient(modelName)
+// GetClientForModel retorna LLM client para um modelo específico.
+// Usa provider e endpoint do ModelSpec configurado com esse nome, se houver.
+// Retorna nil se o provider configurado for inválido.
+func (r *Router) GetClientForModel(modelName string) llm.Provider {
+	client, err := r.getOrCreateClient(r.specForModel(modelName))
+	if err != nil {
+		return nil
+	}
+	return client
 }
 
-// getOrCreateClient obtém client do cache ou cria novo
-func (r *Router) getOrCreateClient(modelName string) *llm.Client {
+// specForModel procura o ModelSpec de um modelo pelo nome
+func (r *Router) specForModel(modelName string) ModelSpec {
 	r.mu.RLock()
-	client, ok := r.clients[modelName]
+	defer r.mu.RUnlock()
+
+	if r.config.DefaultModel.Name == modelName {
+		return r.config.DefaultModel
+	}
+	for _, spec := range r.config.Models {
+		if spec.Name == modelName {
+			return spec
+		}
+	}
+	return ModelSpec{Name: modelName}
+}
+
+// getOrCreateClient obtém client do cache ou cria novo com o provider do spec
+func (r *Router) getOrCreateClient(spec ModelSpec) (llm.Provider, error) {
+	r.mu.RLock()
+	client, ok := r.clients[spec.Name]
 	r.mu.RUnlock()
 
 	if ok {
-		return client
+		return client, nil
 	}
 
 	// Criar novo client
@@ -56,15 +134,27 @@ func (r *Router) getOrCreateClient(modelName string) *llm.Client {
 	defer r.mu.Unlock()
 
 	// Double-check (outro goroutine pode ter criado enquanto esperávamos lock)
-	if client, ok := r.clients[modelName]; ok {
-		return client
+	if client, ok := r.clients[spec.Name]; ok {
+		return client, nil
+	}
+
+	baseURL := spec.BaseURL
+	if baseURL == "" {
+		baseURL = r.baseURL
 	}
 
 	// Criar e cachear
-	client = llm.NewClient(r.baseURL, modelName)
-	r.clients[modelName] = client
+	client, err := llm.NewProvider(spec.Provider, baseURL, spec.Name)
+	if err != nil {
+		return nil, err
+	}
+	client.SetModelHealth(r.health)
+	if r.onCreate != nil {
+		r.onCreate(client)
+	}
+	r.clients[spec.Name] = client
 
-	return client
+	return client, nil
 }
 
 // GetModelSpec retorna especificação do modelo para um task type
@@ -72,9 +162,13 @@ func (r *Router) GetModelSpec(taskType TaskType) (ModelSpec, error) {
 	return r.config.GetModel(taskType)
 }
 
-// GetDefaultClient retorna client do modelo padrão
-func (r *Router) GetDefaultClient() *llm.Client {
-	return r.getOrCreateClient(r.config.DefaultModel.Name)
+// GetDefaultClient retorna client do modelo padrão (nil se o provider for inválido)
+func (r *Router) GetDefaultClient() llm.Provider {
+	client, err := r.getOrCreateClient(r.config.DefaultModel)
+	if err != nil {
+		return nil
+	}
+	return client
 }
 
 // SetConfig atualiza configuração
@@ -87,6 +181,7 @@ func (r *Router) SetConfig(config *Config) error {
 	defer r.mu.Unlock()
 
 	r.config = config
+	r.taskClients = make(map[TaskType]llm.Provider)
 
 	return nil
 }
@@ -128,7 +223,8 @@ func (r *Router) ClearCache() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.clients = make(map[string]*llm.Client)
+	r.clients = make(map[string]llm.Provider)
+	r.taskClients = make(map[TaskType]llm.Provider)
 }
 
 // GetCachedModels retorna lista de modelos em cache
@@ -1,7 +1,14 @@
 package multimodel
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
 )
 
 // TestNewRouter testa criação de router
@@ -171,7 +178,7 @@ func TestRouter_SetConfig(t *testing.T) {
 	newCfg.DefaultModel = ModelSpec{
 		Name:        "new-default",
 		MaxTokens:   1024,
-		Temperature: 0.5,
+		Temperature: llm.Float64(0.5),
 	}
 
 	err := router.SetConfig(newCfg)
@@ -363,3 +370,142 @@ func TestRouter_DisabledUsesDefault(t *testing.T) {
 		t.Errorf("Expected default model when disabled, got %s", client.GetModel())
 	}
 }
+
+// TestRouter_GetClient_AppliesModelSpecOptions testa que opções do ModelSpec chegam ao client
+func TestRouter_GetClient_AppliesModelSpecOptions(t *testing.T) {
+	cfg := DefaultConfig()
+	spec := cfg.Models[TaskTypeCode]
+	spec.Options.NumCtx = 16384
+	spec.Options.TopK = 30
+	cfg.SetModel(TaskTypeCode, spec)
+
+	router := NewRouter("http://localhost:11434", cfg)
+
+	codeClient, _ := router.GetClient(TaskTypeCode)
+	analysisClient, _ := router.GetClient(TaskTypeAnalysis)
+
+	opts := codeClient.DefaultOptions()
+	if opts.NumCtx != 16384 || opts.TopK != 30 {
+		t.Errorf("Expected spec options on code client, got %+v", opts)
+	}
+
+	if opts.MaxTokens != spec.MaxTokens || opts.Temperature == nil || *opts.Temperature != *spec.Temperature {
+		t.Errorf("Expected MaxTokens/Temperature from spec, got %+v", opts)
+	}
+
+	// Mesmo modelo, opções diferentes por task
+	if analysisClient.DefaultOptions().MaxTokens != cfg.Models[TaskTypeAnalysis].MaxTokens {
+		t.Errorf("Analysis client should use its own spec, got %+v", analysisClient.DefaultOptions())
+	}
+}
+
+func TestRouter_GetClient_UsesSpecProvider(t *testing.T) {
+	cfg := DefaultConfig()
+	spec := cfg.Models[TaskTypeCode]
+	spec.Name = "qwen2.5-coder-32b"
+	spec.Provider = llm.ProviderOpenAI
+	spec.BaseURL = "http://localhost:8000"
+	cfg.SetModel(TaskTypeCode, spec)
+
+	router := NewRouter("http://localhost:11434", cfg)
+
+	codeClient, err := router.GetClient(TaskTypeCode)
+	if err != nil {
+		t.Fatalf("GetClient failed: %v", err)
+	}
+	if _, ok := codeClient.(*llm.OpenAIClient); !ok {
+		t.Errorf("Expected OpenAI client for code task, got %T", codeClient)
+	}
+
+	intentClient, _ := router.GetClient(TaskTypeIntent)
+	if _, ok := intentClient.(*llm.Client); !ok {
+		t.Errorf("Expected Ollama client for intent task, got %T", intentClient)
+	}
+
+	if _, ok := router.GetClientForModel("qwen2.5-coder-32b").(*llm.OpenAIClient); !ok {
+		t.Error("GetClientForModel should reuse the spec provider")
+	}
+}
+
+// TestRouter_GetConfiguredClient testa que vision só roteia quando configurado
+func TestRouter_GetConfiguredClient(t *testing.T) {
+	cfg := DefaultConfig()
+	router := NewRouter("http://localhost:11434", cfg)
+
+	if client := router.GetConfiguredClient(TaskTypeVision); client != nil {
+		t.Errorf("Expected nil without vision model, got %s", client.GetModel())
+	}
+
+	cfg.SetModel(TaskTypeVision, ModelSpec{Name: "llava:7b", MaxTokens: 1024})
+	client := router.GetConfiguredClient(TaskTypeVision)
+	if client == nil || client.GetModel() != "llava:7b" {
+		t.Fatalf("Expected llava:7b client, got %v", client)
+	}
+}
+
+// TestRouter_SetClientHook testa que o hook configura cada client novo uma vez
+func TestRouter_SetClientHook(t *testing.T) {
+	router := NewRouter("http://localhost:11434", DefaultConfig())
+
+	var created []string
+	router.SetClientHook(func(client llm.Provider) {
+		client.SetDefaultOptions(llm.CompletionOptions{NumCtx: 16384})
+		created = append(created, client.GetModel())
+	})
+
+	client, err := router.GetClient(TaskTypeCode)
+	if err != nil {
+		t.Fatalf("GetClient failed: %v", err)
+	}
+	router.GetClient(TaskTypeAnalysis) // mesmo modelo: client em cache
+
+	if len(created) != 1 || created[0] != "qwen2.5-coder:7b" {
+		t.Errorf("Hook should run once per model, got %v", created)
+	}
+
+	opts := client.(*llm.Client).DefaultOptions()
+	if opts.NumCtx != 16384 || opts.MaxTokens != 4096 {
+		t.Errorf("Task options should be merged over the hook defaults, got %+v", opts)
+	}
+}
+
+// TestRouter_SharesModelHealth testa que os clients do router compartilham o cool-down
+func TestRouter_SharesModelHealth(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		requested = append(requested, req.Model)
+
+		if req.Model == "big" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"llama runner process has terminated"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"model":%q,"message":{"role":"assistant","content":"ok"},"done":true}`, req.Model)
+	}))
+	defer server.Close()
+
+	cfg := NewConfig()
+	cfg.DefaultModel = ModelSpec{Name: "small"}
+	cfg.SetModel(TaskTypeCode, ModelSpec{Name: "big", Fallbacks: []string{"small"}})
+	cfg.SetModel(TaskTypeAnalysis, ModelSpec{Name: "big", Fallbacks: []string{"small"}, MaxTokens: 8192})
+	cfg.Enable()
+	router := NewRouter(server.URL, cfg)
+
+	messages := []llm.Message{{Role: "user", Content: "oi"}}
+	for _, task := range []TaskType{TaskTypeCode, TaskTypeAnalysis} {
+		client, _ := router.GetClient(task)
+		if _, _, err := client.Complete(context.Background(), messages, nil); err != nil {
+			t.Fatalf("%s: Complete failed: %v", task, err)
+		}
+	}
+
+	// A falha vista pela tarefa code evita a tentativa na tarefa analysis
+	if fmt.Sprint(requested) != "[big small small]" {
+		t.Errorf("Expected big to be skipped after the first failure, got %v", requested)
+	}
+	if router.Health().Available("big") {
+		t.Error("Failed model should be in cool-down")
+	}
+}
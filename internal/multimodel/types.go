@@ -1,5 +1,7 @@
 package multimodel
 
+import "github.com/johnpitter/ollama-code/internal/llm"
+
 // TaskType representa tipos de tarefas que podem usar modelos diferentes
 type TaskType string
 
@@ -16,6 +18,9 @@ const (
 	// TaskTypeAnalysis - Análise de código (modelo preciso)
 	TaskTypeAnalysis TaskType = "analysis"
 
+	// TaskTypeVision - Mensagens com imagens (modelo multimodal)
+	TaskTypeVision TaskType = "vision"
+
 	// TaskTypeDefault - Tarefas gerais (modelo padrão)
 	TaskTypeDefault TaskType = "default"
 )
@@ -23,7 +28,7 @@ const (
 // IsValid verifica se o task type é válido
 func (t TaskType) IsValid() bool {
 	switch t {
-	case TaskTypeIntent, TaskTypeCode, TaskTypeSearch, TaskTypeAnalysis, TaskTypeDefault:
+	case TaskTypeIntent, TaskTypeCode, TaskTypeSearch, TaskTypeAnalysis, TaskTypeVision, TaskTypeDefault:
 		return true
 	default:
 		return false
@@ -37,8 +42,24 @@ func (t TaskType) String() string {
 
 // ModelSpec especificação de um modelo
 type ModelSpec struct {
-	Name        string  // Nome do modelo (ex: "qwen2.5-coder:1.5b")
-	MaxTokens   int     // Tokens máximos
-	Temperature float64 // Temperatura
-	Description string  // Descrição do propósito
+	Name        string                // Nome do modelo (ex: "qwen2.5-coder:1.5b")
+	MaxTokens   int                   // Tokens máximos
+	Temperature *float64              // Temperatura (nil = a de Options ou a padrão do modelo)
+	Description string                // Descrição do propósito
+	Options     llm.CompletionOptions // Demais opções de geração (num_ctx, top_k, seed, stop...)
+	Provider    string                // "ollama" (padrão) ou "openai" (llama.cpp, vLLM...)
+	BaseURL     string                // Endpoint do provider (vazio = URL do router)
+	Fallbacks   []string              // Modelos tentados em ordem se este estiver indisponível
+}
+
+// CompletionOptions opções de geração completas do modelo
+func (s ModelSpec) CompletionOptions() llm.CompletionOptions {
+	opts := s.Options.Merge(&llm.CompletionOptions{
+		MaxTokens: s.MaxTokens,
+		Fallbacks: s.Fallbacks,
+	})
+	if s.Temperature != nil {
+		opts.Temperature = s.Temperature
+	}
+	return opts
 }
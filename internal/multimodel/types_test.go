@@ -1,6 +1,10 @@
 package multimodel
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
 
 // TestTaskType_IsValid testa validação de task types
 func TestTaskType_IsValid(t *testing.T) {
@@ -13,6 +17,7 @@ func TestTaskType_IsValid(t *testing.T) {
 		{"Code is valid", TaskTypeCode, true},
 		{"Search is valid", TaskTypeSearch, true},
 		{"Analysis is valid", TaskTypeAnalysis, true},
+		{"Vision is valid", TaskTypeVision, true},
 		{"Default is valid", TaskTypeDefault, true},
 		{"Invalid type", TaskType("invalid"), false},
 		{"Empty type", TaskType(""), false},
@@ -38,6 +43,7 @@ func TestTaskType_String(t *testing.T) {
 		{TaskTypeCode, "code"},
 		{TaskTypeSearch, "search"},
 		{TaskTypeAnalysis, "analysis"},
+		{TaskTypeVision, "vision"},
 		{TaskTypeDefault, "default"},
 	}
 
@@ -79,7 +85,7 @@ func TestModelSpec_Fields(t *testing.T) {
 	spec := ModelSpec{
 		Name:        "test-model",
 		MaxTokens:   1024,
-		Temperature: 0.7,
+		Temperature: llm.Float64(0.7),
 		Description: "Test model",
 	}
 
@@ -91,8 +97,8 @@ func TestModelSpec_Fields(t *testing.T) {
 		t.Errorf("Expected MaxTokens=1024, got %d", spec.MaxTokens)
 	}
 
-	if spec.Temperature != 0.7 {
-		t.Errorf("Expected Temperature=0.7, got %f", spec.Temperature)
+	if spec.Temperature == nil || *spec.Temperature != 0.7 {
+		t.Errorf("Expected Temperature=0.7, got %v", spec.Temperature)
 	}
 
 	if spec.Description != "Test model" {
@@ -100,6 +106,23 @@ func TestModelSpec_Fields(t *testing.T) {
 	}
 }
 
+// TestModelSpec_CompletionOptionsTemperature testa temperatura 0 e não definida
+func TestModelSpec_CompletionOptionsTemperature(t *testing.T) {
+	zero := ModelSpec{Name: "test-model", Temperature: llm.Float64(0)}
+	if opts := zero.CompletionOptions(); opts.Temperature == nil || *opts.Temperature != 0 {
+		t.Errorf("Temperature 0 should be sent, got %v", opts.Temperature)
+	}
+
+	unset := ModelSpec{Name: "test-model", Options: llm.CompletionOptions{Temperature: llm.Float64(0.4)}}
+	if opts := unset.CompletionOptions(); opts.Temperature == nil || *opts.Temperature != 0.4 {
+		t.Errorf("Unset temperature should keep the one from Options, got %v", opts.Temperature)
+	}
+
+	if opts := (ModelSpec{Name: "test-model"}).CompletionOptions(); opts.Temperature != nil {
+		t.Errorf("Unset temperature should be left to the model, got %v", *opts.Temperature)
+	}
+}
+
 // TestTaskType_AllValid testa que todos os tipos definidos são válidos
 func TestTaskType_AllValid(t *testing.T) {
 	allTypes := []TaskType{
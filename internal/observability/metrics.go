@@ -2,6 +2,7 @@ package observability
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
@@ -41,6 +42,32 @@ type MetricsCollector struct {
 	toolCounts    map[string]int64
 	cacheHits     int64
 	cacheMisses   int64
+
+	// Uso de tokens por modelo
+	llmUsage map[string]*TokenUsage
+
+	// Retries de requisições LLM por modelo e motivo
+	llmRetries map[string]map[string]int64
+
+	// Espera na fila do scheduler LLM por prioridade (ms) e maior fila observada
+	llmQueueWaits    map[string][]float64
+	llmMaxQueueDepth int
+}
+
+// TokenUsage tokens acumulados de um modelo
+type TokenUsage struct {
+	Requests         int64
+	PromptTokens     int64
+	CompletionTokens int64
+	EvalDuration     time.Duration
+}
+
+// TokensPerSecond velocidade média de geração
+func (u *TokenUsage) TokensPerSecond() float64 {
+	if u.EvalDuration <= 0 {
+		return 0
+	}
+	return float64(u.CompletionTokens) / u.EvalDuration.Seconds()
 }
 
 // NewMetricsCollector cria novo coletor de métricas
@@ -54,6 +81,9 @@ func NewMetricsCollector() *MetricsCollector {
 		handlerCounts:    make(map[string]int64),
 		handlerErrors:    make(map[string]int64),
 		toolCounts:       make(map[string]int64),
+		llmUsage:         make(map[string]*TokenUsage),
+		llmRetries:       make(map[string]map[string]int64),
+		llmQueueWaits:    make(map[string][]float64),
 	}
 }
 
@@ -106,6 +136,96 @@ func (m *MetricsCollector) RecordLLMDuration(duration time.Duration) {
 	}
 }
 
+// RecordLLMUsage registra tokens consumidos por uma requisição LLM
+func (m *MetricsCollector) RecordLLMUsage(model string, promptTokens, completionTokens int, evalDuration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usage, exists := m.llmUsage[model]
+	if !exists {
+		usage = &TokenUsage{}
+		m.llmUsage[model] = usage
+	}
+
+	usage.Requests++
+	usage.PromptTokens += int64(promptTokens)
+	usage.CompletionTokens += int64(completionTokens)
+	usage.EvalDuration += evalDuration
+}
+
+// GetLLMUsage retorna uso de tokens de um modelo
+func (m *MetricsCollector) GetLLMUsage(model string) *TokenUsage {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	usage, exists := m.llmUsage[model]
+	if !exists {
+		return nil
+	}
+
+	copied := *usage
+	return &copied
+}
+
+// RecordLLMRetry registra nova tentativa de requisição LLM
+func (m *MetricsCollector) RecordLLMRetry(model, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	reasons, exists := m.llmRetries[model]
+	if !exists {
+		reasons = make(map[string]int64)
+		m.llmRetries[model] = reasons
+	}
+	reasons[reason]++
+}
+
+// GetLLMRetries retorna os retries de um modelo por motivo
+func (m *MetricsCollector) GetLLMRetries(model string) map[string]int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	retries := make(map[string]int64)
+	for reason, count := range m.llmRetries[model] {
+		retries[reason] = count
+	}
+	return retries
+}
+
+// RecordLLMQueueWait registra a espera de uma requisição LLM na fila do scheduler
+// e quantas continuaram na fila
+func (m *MetricsCollector) RecordLLMQueueWait(priority string, wait time.Duration, queueDepth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.llmQueueWaits[priority] = append(m.llmQueueWaits[priority], float64(wait.Milliseconds()))
+	if len(m.llmQueueWaits[priority]) > 1000 {
+		m.llmQueueWaits[priority] = m.llmQueueWaits[priority][1:]
+	}
+	if queueDepth > m.llmMaxQueueDepth {
+		m.llmMaxQueueDepth = queueDepth
+	}
+}
+
+// GetLLMQueueStats retorna estatísticas de espera na fila para a prioridade
+func (m *MetricsCollector) GetLLMQueueStats(priority string) *Stats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.llmQueueWaits[priority]) == 0 {
+		return nil
+	}
+
+	return calculateStats(m.llmQueueWaits[priority])
+}
+
+// GetLLMMaxQueueDepth retorna a maior fila do scheduler observada
+func (m *MetricsCollector) GetLLMMaxQueueDepth() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.llmMaxQueueDepth
+}
+
 // RecordIntentDuration registra duração de detecção de intenção
 func (m *MetricsCollector) RecordIntentDuration(duration time.Duration) {
 	m.mu.Lock()
@@ -265,6 +385,10 @@ func (m *MetricsCollector) Reset() {
 	m.handlerCounts = make(map[string]int64)
 	m.handlerErrors = make(map[string]int64)
 	m.toolCounts = make(map[string]int64)
+	m.llmUsage = make(map[string]*TokenUsage)
+	m.llmRetries = make(map[string]map[string]int64)
+	m.llmQueueWaits = make(map[string][]float64)
+	m.llmMaxQueueDepth = 0
 	m.cacheHits = 0
 	m.cacheMisses = 0
 }
@@ -315,6 +439,68 @@ func (m *MetricsCollector) PrintSummary() string {
 			len(m.llmDurations), stats.P50, stats.P95, stats.P99)
 	}
 
+	// Tokens por modelo
+	if len(m.llmUsage) > 0 {
+		summary += "🔢 Tokens:\n"
+		models := make([]string, 0, len(m.llmUsage))
+		for model := range m.llmUsage {
+			models = append(models, model)
+		}
+		sort.Strings(models)
+
+		for _, model := range models {
+			usage := m.llmUsage[model]
+			summary += fmt.Sprintf("  • %s: %d requisições - %d prompt, %d resposta - %.1f tok/s\n",
+				model, usage.Requests, usage.PromptTokens, usage.CompletionTokens, usage.TokensPerSecond())
+		}
+		summary += "\n"
+	}
+
+	// Retries por modelo
+	if len(m.llmRetries) > 0 {
+		summary += "🔁 Retries:\n"
+		models := make([]string, 0, len(m.llmRetries))
+		for model := range m.llmRetries {
+			models = append(models, model)
+		}
+		sort.Strings(models)
+
+		for _, model := range models {
+			reasons := m.llmRetries[model]
+			names := make([]string, 0, len(reasons))
+			for reason := range reasons {
+				names = append(names, reason)
+			}
+			sort.Strings(names)
+
+			var total int64
+			details := ""
+			for _, reason := range names {
+				total += reasons[reason]
+				details += fmt.Sprintf(" %s=%d", reason, reasons[reason])
+			}
+			summary += fmt.Sprintf("  • %s: %d retries -%s\n", model, total, details)
+		}
+		summary += "\n"
+	}
+
+	// Fila do scheduler por prioridade
+	if len(m.llmQueueWaits) > 0 {
+		summary += fmt.Sprintf("⏳ Fila LLM (máx. %d na fila):\n", m.llmMaxQueueDepth)
+		priorities := make([]string, 0, len(m.llmQueueWaits))
+		for priority := range m.llmQueueWaits {
+			priorities = append(priorities, priority)
+		}
+		sortQueuePriorities(priorities)
+
+		for _, priority := range priorities {
+			stats := calculateStats(m.llmQueueWaits[priority])
+			summary += fmt.Sprintf("  • %s: %d requisições - espera p50: %.0fms, p95: %.0fms\n",
+				priority, stats.Count, stats.P50, stats.P95)
+		}
+		summary += "\n"
+	}
+
 	// Cache
 	cacheStats := m.GetCacheStats()
 	if cacheStats.Total > 0 {
@@ -325,6 +511,31 @@ func (m *MetricsCollector) PrintSummary() string {
 	return summary
 }
 
+// queuePriorityRank posição de cada prioridade do scheduler LLM no resumo (maior primeiro)
+var queuePriorityRank = map[string]int{
+	"interactive": 0,
+	"intent":      1,
+	"subagent":    2,
+	"background":  3,
+}
+
+// sortQueuePriorities ordena prioridades na ordem do scheduler; desconhecidas vão
+// ao final em ordem alfabética
+func sortQueuePriorities(priorities []string) {
+	sort.Slice(priorities, func(i, j int) bool {
+		ri, okI := queuePriorityRank[priorities[i]]
+		rj, okJ := queuePriorityRank[priorities[j]]
+		switch {
+		case okI && okJ:
+			return ri < rj
+		case okI != okJ:
+			return okI
+		default:
+			return priorities[i] < priorities[j]
+		}
+	})
+}
+
 // Stats estatísticas calculadas
 type Stats struct {
 	Count  int
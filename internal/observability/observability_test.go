@@ -3,6 +3,7 @@ package observability
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -87,6 +88,69 @@ func TestMetricsCacheStats(t *testing.T) {
 	}
 }
 
+func TestMetricsLLMUsage(t *testing.T) {
+	metrics := NewMetricsCollector()
+
+	metrics.RecordLLMUsage("qwen2.5-coder:7b", 100, 50, time.Second)
+	metrics.RecordLLMUsage("qwen2.5-coder:7b", 200, 150, time.Second)
+
+	usage := metrics.GetLLMUsage("qwen2.5-coder:7b")
+	if usage == nil {
+		t.Fatal("Expected usage to be available")
+	}
+
+	if usage.Requests != 2 || usage.PromptTokens != 300 || usage.CompletionTokens != 200 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+
+	if usage.TokensPerSecond() != 100 {
+		t.Errorf("Expected 100 tok/s, got %.1f", usage.TokensPerSecond())
+	}
+
+	if metrics.GetLLMUsage("other") != nil {
+		t.Error("Expected nil usage for unknown model")
+	}
+}
+
+func TestMetricsLLMRetries(t *testing.T) {
+	metrics := NewMetricsCollector()
+
+	metrics.RecordLLMRetry("qwen2.5-coder:7b", "connection")
+	metrics.RecordLLMRetry("qwen2.5-coder:7b", "connection")
+	metrics.RecordLLMRetry("qwen2.5-coder:7b", "model_loading")
+
+	retries := metrics.GetLLMRetries("qwen2.5-coder:7b")
+	if retries["connection"] != 2 || retries["model_loading"] != 1 {
+		t.Errorf("Unexpected retries: %v", retries)
+	}
+
+	if len(metrics.GetLLMRetries("other")) != 0 {
+		t.Error("Expected no retries for unknown model")
+	}
+}
+
+func TestMetricsLLMQueueWait(t *testing.T) {
+	metrics := NewMetricsCollector()
+
+	metrics.RecordLLMQueueWait("subagent", 200*time.Millisecond, 3)
+	metrics.RecordLLMQueueWait("subagent", 400*time.Millisecond, 1)
+	metrics.RecordLLMQueueWait("interactive", 0, 0)
+
+	stats := metrics.GetLLMQueueStats("subagent")
+	if stats == nil || stats.Count != 2 || stats.Max != 400 {
+		t.Errorf("Unexpected subagent queue stats: %+v", stats)
+	}
+	if metrics.GetLLMMaxQueueDepth() != 3 {
+		t.Errorf("Expected max queue depth 3, got %d", metrics.GetLLMMaxQueueDepth())
+	}
+	if metrics.GetLLMQueueStats("background") != nil {
+		t.Error("Expected no stats for a priority without requests")
+	}
+	if summary := metrics.PrintSummary(); !strings.Contains(summary, "Fila LLM") {
+		t.Errorf("Summary should include the queue, got:\n%s", summary)
+	}
+}
+
 func TestTracer(t *testing.T) {
 	logger := NewDefaultLogger()
 	tracer := NewTracer(logger)
@@ -181,6 +245,52 @@ func TestPrintSummary(t *testing.T) {
 	t.Logf("Summary:\n%s", summary)
 }
 
+func TestPrintSummary_ModelsSorted(t *testing.T) {
+	metrics := NewMetricsCollector()
+	for _, model := range []string{"qwen2.5-coder:7b", "llava:7b", "nomic-embed-text"} {
+		metrics.RecordLLMUsage(model, 10, 5, time.Second)
+	}
+
+	summary := metrics.PrintSummary()
+	llava := strings.Index(summary, "• llava:7b")
+	nomic := strings.Index(summary, "• nomic-embed-text")
+	qwen := strings.Index(summary, "• qwen2.5-coder:7b")
+	if llava < 0 || !(llava < nomic && nomic < qwen) {
+		t.Errorf("Models should be listed in alphabetical order, got:\n%s", summary)
+	}
+}
+
+func TestPrintSummary_RetriesSorted(t *testing.T) {
+	metrics := NewMetricsCollector()
+	metrics.RecordLLMRetry("qwen2.5-coder:7b", "status")
+	metrics.RecordLLMRetry("qwen2.5-coder:7b", "connection")
+	metrics.RecordLLMRetry("qwen2.5-coder:7b", "model_loading")
+	metrics.RecordLLMRetry("llava:7b", "first_token_timeout")
+
+	summary := metrics.PrintSummary()
+	llava := strings.Index(summary, "• llava:7b: 1 retries")
+	qwen := strings.Index(summary, "• qwen2.5-coder:7b: 3 retries - connection=1 model_loading=1 status=1")
+	if llava < 0 || qwen < 0 || llava > qwen {
+		t.Errorf("Retries should be listed by model and reason in alphabetical order, got:\n%s", summary)
+	}
+}
+
+func TestPrintSummary_QueuePrioritiesInSchedulerOrder(t *testing.T) {
+	metrics := NewMetricsCollector()
+	for _, priority := range []string{"background", "subagent", "interactive", "intent"} {
+		metrics.RecordLLMQueueWait(priority, 10*time.Millisecond, 0)
+	}
+
+	summary := metrics.PrintSummary()
+	interactive := strings.Index(summary, "• interactive:")
+	intent := strings.Index(summary, "• intent:")
+	subagent := strings.Index(summary, "• subagent:")
+	background := strings.Index(summary, "• background:")
+	if interactive < 0 || !(interactive < intent && intent < subagent && subagent < background) {
+		t.Errorf("Queue priorities should follow scheduler order, got:\n%s", summary)
+	}
+}
+
 // Helper function
 func performFailingOperation() error {
 	return fmt.Errorf("operation failed")
@@ -0,0 +1,372 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
+
+// Formatos de exportação da transcrição
+const (
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+	FormatJSONL    = "jsonl"
+)
+
+// Tipos de registro no JSONL
+const (
+	recordSession = "session"
+	recordMessage = "message"
+)
+
+// transcriptRecord linha do JSONL: cabeçalho da sessão ou uma mensagem
+type transcriptRecord struct {
+	Type    string       `json:"type"`
+	Session *Session     `json:"session,omitempty"` // Sem Messages (vão em linhas próprias)
+	Message *llm.Message `json:"message,omitempty"`
+}
+
+// Export escreve a transcrição da sessão no formato (md, html ou jsonl)
+func Export(w io.Writer, s *Session, format string) error {
+	switch format {
+	case FormatMarkdown:
+		_, err := io.WriteString(w, renderMarkdown(s))
+		return err
+	case FormatHTML:
+		_, err := io.WriteString(w, renderHTML(s))
+		return err
+	case FormatJSONL:
+		return writeJSONL(w, s)
+	default:
+		return fmt.Errorf("unsupported export format %q (use md, html or jsonl)", format)
+	}
+}
+
+// Import lê uma transcrição JSONL e a salva como nova sessão (inativa, mais
+// recente na listagem). Linhas com uma llm.Message pura também são aceitas.
+func (m *Manager) Import(r io.Reader) (*Session, error) {
+	imported, err := readJSONL(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if imported.Metadata == nil {
+		imported.Metadata = make(map[string]interface{})
+	}
+	if imported.ID != "" {
+		imported.Metadata["imported_from"] = imported.ID
+	}
+	imported.ID = generateSessionID()
+	imported.Active = false
+	imported.LastActivity = time.Now()
+	if imported.StartTime.IsZero() {
+		imported.StartTime = imported.LastActivity
+	}
+
+	if err := m.saveSession(imported); err != nil {
+		return nil, fmt.Errorf("save session: %w", err)
+	}
+	return imported, nil
+}
+
+// writeJSONL cabeçalho e uma linha por mensagem
+func writeJSONL(w io.Writer, s *Session) error {
+	header := *s
+	header.Messages = nil
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(transcriptRecord{Type: recordSession, Session: &header}); err != nil {
+		return err
+	}
+	for i := range s.Messages {
+		if err := encoder.Encode(transcriptRecord{Type: recordMessage, Message: &s.Messages[i]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readJSONL monta a sessão a partir das linhas da transcrição
+func readJSONL(r io.Reader) (*Session, error) {
+	imported := &Session{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024) // Mensagens com imagens em base64
+	for line := 1; scanner.Scan(); line++ {
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+
+		var record transcriptRecord
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		switch {
+		case record.Type == recordSession && record.Session != nil:
+			messages := imported.Messages
+			*imported = *record.Session
+			imported.Messages = messages
+		case record.Type == recordMessage && record.Message != nil:
+			imported.Messages = append(imported.Messages, *record.Message)
+		case record.Type == "":
+			var msg llm.Message
+			if err := json.Unmarshal([]byte(data), &msg); err != nil || msg.Role == "" {
+				return nil, fmt.Errorf("line %d: not a transcript record or message", line)
+			}
+			imported.Messages = append(imported.Messages, msg)
+		default:
+			return nil, fmt.Errorf("line %d: unknown record type %q", line, record.Type)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read transcript: %w", err)
+	}
+
+	if len(imported.Messages) == 0 {
+		return nil, fmt.Errorf("transcript has no messages")
+	}
+	return imported, nil
+}
+
+// transcriptField linha de metadados (modelo, modo, duração, tokens...)
+type transcriptField struct {
+	Name  string
+	Value string
+}
+
+// transcriptFields metadados exibidos no topo da transcrição
+func transcriptFields(s *Session) []transcriptField {
+	fields := []transcriptField{
+		{"Session", s.ID},
+		{"Started", s.StartTime.Format("2006-01-02 15:04:05")},
+		{"Duration", s.LastActivity.Sub(s.StartTime).Round(time.Second).String()},
+		{"Messages", fmt.Sprintf("%d", len(s.Messages))},
+	}
+	if models := sessionModels(s); len(models) > 0 {
+		fields = append(fields, transcriptField{"Model", strings.Join(models, ", ")})
+	}
+	if s.Mode != "" {
+		fields = append(fields, transcriptField{"Mode", s.Mode})
+	}
+	if s.WorkDir != "" {
+		fields = append(fields, transcriptField{"Work dir", s.WorkDir})
+	}
+	if usage, ok := s.Metadata["usage"].(map[string]interface{}); ok {
+		fields = append(fields, transcriptField{"Tokens", fmt.Sprintf("%s (prompt %s, completion %s)",
+			number(usage["total_tokens"]), number(usage["prompt_tokens"]), number(usage["completion_tokens"]))})
+	}
+	if len(s.Tags) > 0 {
+		fields = append(fields, transcriptField{"Tags", strings.Join(s.Tags, ", ")})
+	}
+	return fields
+}
+
+// sessionModels modelos usados, do uso salvo em Metadata["usage"]["models"]
+func sessionModels(s *Session) []string {
+	usage, _ := s.Metadata["usage"].(map[string]interface{})
+	models, _ := usage["models"].(map[string]interface{})
+
+	names := make([]string, 0, len(models))
+	for name := range models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// number formata contadores (int em memória, float64 depois de ler o JSON)
+func number(v interface{}) string {
+	switch n := v.(type) {
+	case int:
+		return fmt.Sprintf("%d", n)
+	case float64:
+		return fmt.Sprintf("%.0f", n)
+	default:
+		return "?"
+	}
+}
+
+// sessionTitle nome da sessão ou primeiro prompt
+func sessionTitle(s *Session) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	if prompt := s.FirstPrompt(); prompt != "" {
+		return prompt
+	}
+	return s.ID
+}
+
+// outputLanguage linguagem do bloco de saída de ferramenta (diff é destacado)
+func outputLanguage(content string) string {
+	if strings.HasPrefix(content, "--- ") || strings.HasPrefix(content, "diff --git") || strings.Contains(content, "\n@@ ") {
+		return "diff"
+	}
+	return "text"
+}
+
+// callSummary comando executado ou argumentos JSON da chamada de ferramenta
+func callSummary(call llm.ToolCall) (string, string) {
+	if command, ok := call.Function.Arguments["command"].(string); ok && command != "" {
+		return "console", "$ " + command
+	}
+	args, _ := json.MarshalIndent(call.Function.Arguments, "", "  ")
+	return "json", string(args)
+}
+
+// roleTitle cabeçalho de cada mensagem
+func roleTitle(msg llm.Message) string {
+	switch msg.Role {
+	case "user":
+		return "👤 User"
+	case "assistant":
+		return "🤖 Assistant"
+	case "tool":
+		if msg.ToolName != "" {
+			return "🔧 Tool output: " + msg.ToolName
+		}
+		return "🔧 Tool output"
+	default:
+		return msg.Role
+	}
+}
+
+// renderMarkdown transcrição em Markdown (para code reviews e documentos)
+func renderMarkdown(s *Session) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# %s\n\n", sessionTitle(s)))
+	sb.WriteString("| | |\n|---|---|\n")
+	for _, field := range transcriptFields(s) {
+		sb.WriteString(fmt.Sprintf("| **%s** | %s |\n", field.Name, strings.ReplaceAll(field.Value, "|", "\\|")))
+	}
+	sb.WriteString("\n")
+
+	for _, msg := range s.Messages {
+		sb.WriteString(fmt.Sprintf("## %s\n\n", roleTitle(msg)))
+
+		if msg.Thinking != "" {
+			sb.WriteString("<details><summary>Thinking</summary>\n\n")
+			sb.WriteString(msg.Thinking)
+			sb.WriteString("\n\n</details>\n\n")
+		}
+
+		if msg.Role == "tool" {
+			writeFence(&sb, outputLanguage(msg.Content), msg.Content)
+		} else if msg.Content != "" {
+			sb.WriteString(msg.Content)
+			sb.WriteString("\n\n")
+		}
+
+		if len(msg.Images) > 0 {
+			sb.WriteString(fmt.Sprintf("_%d image(s) attached_\n\n", len(msg.Images)))
+		}
+
+		for _, call := range msg.ToolCalls {
+			sb.WriteString(fmt.Sprintf("**Tool call:** `%s`\n\n", call.Function.Name))
+			language, body := callSummary(call)
+			writeFence(&sb, language, body)
+		}
+	}
+
+	return sb.String()
+}
+
+// writeFence bloco de código com cerca maior que qualquer sequência de crases do conteúdo
+func writeFence(sb *strings.Builder, language, content string) {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	sb.WriteString(fmt.Sprintf("%s%s\n%s\n%s\n\n", fence, language, strings.TrimRight(content, "\n"), fence))
+}
+
+// htmlStyle estilo da página exportada
+const htmlStyle = `body{font-family:system-ui,sans-serif;max-width:960px;margin:2em auto;padding:0 1em;color:#222}
+table{border-collapse:collapse}td{padding:2px 12px 2px 0;vertical-align:top}
+.msg{border-left:4px solid #ccc;margin:1.5em 0;padding:0 1em}.user{border-color:#2a7ae2}.assistant{border-color:#2da44e}.tool{border-color:#999}
+pre{background:#f6f8fa;padding:.8em;overflow-x:auto;white-space:pre-wrap}.text{white-space:pre-wrap}
+.add{color:#1a7f37}.del{color:#cf222e}.hunk{color:#8250df}`
+
+// renderHTML transcrição em HTML autocontido
+func renderHTML(s *Session) string {
+	var sb strings.Builder
+
+	title := html.EscapeString(sessionTitle(s))
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString(fmt.Sprintf("<title>%s</title>\n<style>%s</style>\n</head>\n<body>\n", title, htmlStyle))
+	sb.WriteString(fmt.Sprintf("<h1>%s</h1>\n<table>\n", title))
+	for _, field := range transcriptFields(s) {
+		sb.WriteString(fmt.Sprintf("<tr><td><b>%s</b></td><td>%s</td></tr>\n", html.EscapeString(field.Name), html.EscapeString(field.Value)))
+	}
+	sb.WriteString("</table>\n")
+
+	for _, msg := range s.Messages {
+		sb.WriteString(fmt.Sprintf("<div class=\"msg %s\">\n<h2>%s</h2>\n", html.EscapeString(msg.Role), html.EscapeString(roleTitle(msg))))
+
+		if msg.Thinking != "" {
+			sb.WriteString(fmt.Sprintf("<details><summary>Thinking</summary><div class=\"text\">%s</div></details>\n", html.EscapeString(msg.Thinking)))
+		}
+
+		if msg.Role == "tool" {
+			writePre(&sb, outputLanguage(msg.Content), msg.Content)
+		} else if msg.Content != "" {
+			sb.WriteString(fmt.Sprintf("<div class=\"text\">%s</div>\n", html.EscapeString(msg.Content)))
+		}
+
+		if len(msg.Images) > 0 {
+			sb.WriteString(fmt.Sprintf("<p><i>%d image(s) attached</i></p>\n", len(msg.Images)))
+		}
+
+		for _, call := range msg.ToolCalls {
+			sb.WriteString(fmt.Sprintf("<p><b>Tool call:</b> <code>%s</code></p>\n", html.EscapeString(call.Function.Name)))
+			language, body := callSummary(call)
+			writePre(&sb, language, body)
+		}
+
+		sb.WriteString("</div>\n")
+	}
+
+	sb.WriteString("</body>\n</html>\n")
+	return sb.String()
+}
+
+// writePre bloco <pre>, com linhas de diff coloridas
+func writePre(sb *strings.Builder, language, content string) {
+	content = strings.TrimRight(content, "\n")
+	if language != "diff" {
+		sb.WriteString(fmt.Sprintf("<pre>%s</pre>\n", html.EscapeString(content)))
+		return
+	}
+
+	sb.WriteString("<pre>")
+	for i, line := range strings.Split(content, "\n") {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		class := ""
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			class = "hunk"
+		case strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++"):
+			class = "add"
+		case strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---"):
+			class = "del"
+		}
+		if class == "" {
+			sb.WriteString(html.EscapeString(line))
+		} else {
+			sb.WriteString(fmt.Sprintf("<span class=\"%s\">%s</span>", class, html.EscapeString(line)))
+		}
+	}
+	sb.WriteString("</pre>\n")
+}
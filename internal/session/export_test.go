@@ -0,0 +1,134 @@
+package session
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
+
+// transcriptSession sessão com prompt, chamada de ferramenta, diff e saída de comando
+func transcriptSession() *Session {
+	start := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	return &Session{
+		ID:           "session_1",
+		StartTime:    start,
+		LastActivity: start.Add(90 * time.Second),
+		WorkDir:      "/work",
+		Mode:         "autonomous",
+		Tags:         []string{"bugfix"},
+		Metadata: map[string]interface{}{
+			"usage": map[string]interface{}{
+				"prompt_tokens":     1000,
+				"completion_tokens": 200,
+				"total_tokens":      1200,
+				"models":            map[string]interface{}{"qwen2.5-coder:7b": map[string]interface{}{}},
+			},
+		},
+		Messages: []llm.Message{
+			{Role: "user", Content: "corrija o <parser>"},
+			{Role: "assistant", ToolCalls: []llm.ToolCall{
+				{Function: llm.ToolCallFunction{Name: "command_executor", Arguments: map[string]interface{}{"command": "go test ./..."}}},
+			}},
+			{Role: "tool", ToolName: "command_executor", Content: "ok  \tparser\t0.01s"},
+			{Role: "tool", ToolName: "file_writer", Content: "--- a/parser.go\n+++ b/parser.go\n@@ -1,1 +1,1 @@\n-old\n+new"},
+			{Role: "assistant", Content: "Pronto, use ```go test```"},
+		},
+	}
+}
+
+func TestExport_Markdown(t *testing.T) {
+	var out bytes.Buffer
+	if err := Export(&out, transcriptSession(), FormatMarkdown); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	md := out.String()
+
+	for _, want := range []string{
+		"# corrija o <parser>",
+		"| **Model** | qwen2.5-coder:7b |",
+		"| **Duration** | 1m30s |",
+		"| **Tokens** | 1200 (prompt 1000, completion 200) |",
+		"**Tool call:** `command_executor`",
+		"```console\n$ go test ./...\n```",
+		"```diff\n--- a/parser.go",
+		"## 🔧 Tool output: command_executor",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown should contain %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestExport_HTML(t *testing.T) {
+	var out bytes.Buffer
+	if err := Export(&out, transcriptSession(), FormatHTML); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	page := out.String()
+
+	if strings.Contains(page, "<parser>") || !strings.Contains(page, "&lt;parser&gt;") {
+		t.Error("HTML should escape message content")
+	}
+	if !strings.Contains(page, `<span class="add">+new</span>`) || !strings.Contains(page, `<span class="del">-old</span>`) {
+		t.Errorf("HTML should color diff lines:\n%s", page)
+	}
+}
+
+func TestExport_UnknownFormat(t *testing.T) {
+	if err := Export(&bytes.Buffer{}, transcriptSession(), "pdf"); err == nil {
+		t.Error("Unknown format should fail")
+	}
+}
+
+func TestImport_JSONLRoundTrip(t *testing.T) {
+	original := transcriptSession()
+	var out bytes.Buffer
+	if err := Export(&out, original, FormatJSONL); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != len(original.Messages)+1 {
+		t.Errorf("Expected header plus one line per message, got %d lines", lines)
+	}
+
+	mgr := NewManager(t.TempDir())
+	imported, err := mgr.Import(&out)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if imported.ID == original.ID || imported.Metadata["imported_from"] != original.ID {
+		t.Errorf("Import should create a new session pointing to the original, got %+v", imported)
+	}
+	if imported.Mode != "autonomous" || imported.WorkDir != "/work" || len(imported.Messages) != len(original.Messages) {
+		t.Errorf("Import should keep session data, got %+v", imported)
+	}
+	if call := imported.Messages[1].ToolCalls[0]; call.Function.Arguments["command"] != "go test ./..." {
+		t.Errorf("Tool calls should survive the round trip, got %+v", call)
+	}
+
+	// Salva: aparece primeiro na listagem para chat --continue
+	latest, err := mgr.Find("1")
+	if err != nil || latest.ID != imported.ID {
+		t.Errorf("Imported session should be the most recent, got %v (%v)", latest, err)
+	}
+}
+
+func TestImport_PlainMessages(t *testing.T) {
+	transcript := `{"role":"user","content":"oi"}
+{"role":"assistant","content":"olá"}
+`
+	imported, err := NewManager(t.TempDir()).Import(strings.NewReader(transcript))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(imported.Messages) != 2 || imported.FirstPrompt() != "oi" {
+		t.Errorf("Plain message lines should be imported, got %+v", imported.Messages)
+	}
+
+	if _, err := NewManager(t.TempDir()).Import(strings.NewReader(`{"foo":1}`)); err == nil {
+		t.Error("Lines without a role should be rejected")
+	}
+}
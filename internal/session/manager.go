@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/johnpitter/ollama-code/internal/llm"
@@ -120,6 +121,51 @@ func (m *Manager) AddMessage(msg llm.Message) error {
 	return m.Save()
 }
 
+// Update substitui mensagens, diretório, modo e arquivos recentes da sessão atual
+// pelo estado do agente (o histórico pode ter sido compactado) e salva
+func (m *Manager) Update(messages []llm.Message, workDir, mode string, recentFiles []string) error {
+	if m.currentSession == nil {
+		return fmt.Errorf("no active session")
+	}
+
+	m.currentSession.Messages = messages
+	m.currentSession.WorkDir = workDir
+	m.currentSession.Mode = mode
+	m.currentSession.RecentFiles = recentFiles
+
+	return m.Save()
+}
+
+// Rename renomeia a sessão atual
+func (m *Manager) Rename(name string) error {
+	if m.currentSession == nil {
+		return fmt.Errorf("no active session")
+	}
+
+	m.currentSession.Name = name
+	return m.Save()
+}
+
+// Find busca sessão pelo ID ou pela posição (1 = mais recente) na listagem
+func (m *Manager) Find(ref string) (*Session, error) {
+	if n, err := strconv.Atoi(ref); err == nil {
+		sessions, err := m.List(0)
+		if err != nil {
+			return nil, err
+		}
+		if n < 1 || n > len(sessions) {
+			return nil, fmt.Errorf("no session #%d (%d sessions)", n, len(sessions))
+		}
+		return sessions[n-1], nil
+	}
+
+	session, err := m.loadSession(ref)
+	if err != nil {
+		return nil, fmt.Errorf("session %s not found", ref)
+	}
+	return session, nil
+}
+
 // GetCurrent retorna sessão atual
 func (m *Manager) GetCurrent() *Session {
 	return m.currentSession
@@ -175,6 +221,9 @@ func (m *Manager) UpdateMetadata(key string, value interface{}) error {
 		return fmt.Errorf("no active session")
 	}
 
+	if m.currentSession.Metadata == nil {
+		m.currentSession.Metadata = make(map[string]interface{})
+	}
 	m.currentSession.Metadata[key] = value
 	return m.Save()
 }
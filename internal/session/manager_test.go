@@ -3,6 +3,8 @@ package session
 import (
 	"testing"
 	"time"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
 )
 
 func TestNewManager(t *testing.T) {
@@ -238,3 +240,38 @@ func TestSessionTimestamps(t *testing.T) {
 		t.Error("Last activity should be set to current time")
 	}
 }
+
+func TestUpdateAndFind(t *testing.T) {
+	mgr := NewManager(t.TempDir())
+
+	first, _ := mgr.New("", "/test", "interactive")
+	mgr.End()
+	time.Sleep(10 * time.Millisecond)
+	mgr.New("", "/test", "interactive")
+
+	messages := []llm.Message{
+		{Role: "user", Content: "  corrija o bug\nno parser"},
+		{Role: "assistant", Content: "Feito"},
+	}
+	if err := mgr.Update(messages, "/work", "autonomous", []string{"parser.go"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	latest, err := mgr.Find("1")
+	if err != nil {
+		t.Fatalf("Find by number failed: %v", err)
+	}
+	if len(latest.Messages) != 2 || latest.WorkDir != "/work" || latest.RecentFiles[0] != "parser.go" {
+		t.Errorf("Update should be persisted, got %+v", latest)
+	}
+	if prompt := latest.FirstPrompt(); prompt != "corrija o bug" {
+		t.Errorf("Expected first prompt line, got %q", prompt)
+	}
+
+	if found, err := mgr.Find(first.ID); err != nil || found.ID != first.ID {
+		t.Errorf("Find by ID failed: %v", err)
+	}
+	if _, err := mgr.Find("3"); err == nil {
+		t.Error("Out of range number should fail")
+	}
+}
@@ -1,6 +1,7 @@
 package session
 
 import (
+	"strings"
 	"time"
 
 	"github.com/johnpitter/ollama-code/internal/llm"
@@ -15,11 +16,27 @@ type Session struct {
 	Messages     []llm.Message          `json:"messages"`
 	WorkDir      string                 `json:"work_dir"`
 	Mode         string                 `json:"mode"`
+	RecentFiles  []string               `json:"recent_files,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 	Tags         []string               `json:"tags,omitempty"`
 	Active       bool                   `json:"active"`
 }
 
+// FirstPrompt primeira linha da primeira mensagem do usuário (para identificar a sessão)
+func (s *Session) FirstPrompt() string {
+	for _, msg := range s.Messages {
+		if msg.Role != "user" {
+			continue
+		}
+		prompt := strings.TrimSpace(msg.Content)
+		if i := strings.IndexByte(prompt, '\n'); i >= 0 {
+			prompt = prompt[:i]
+		}
+		return prompt
+	}
+	return ""
+}
+
 // SessionList lista de sessões
 type SessionList []*Session
 
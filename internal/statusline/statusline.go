@@ -62,6 +62,11 @@ func (s *StatusLine) Update(tokensUsed int, responseTime time.Duration, task str
 	s.activeTask = task
 }
 
+// SetModel altera o modelo exibido
+func (s *StatusLine) SetModel(model string) {
+	s.model = model
+}
+
 // SetTask define a tarefa ativa
 func (s *StatusLine) SetTask(task string) {
 	s.activeTask = task
@@ -10,8 +10,10 @@ import (
 
 // Executor executa subagents usando LLM
 type Executor struct {
-	llmClient *llm.Client
 	ollamaURL string
+	pool      *llm.HostPool        // Pool de servidores Ollama (nil usa só ollamaURL)
+	scheduler *llm.Scheduler       // Limite de requisições simultâneas compartilhado com o agente
+	transport *llm.TransportConfig // Timeouts, retry e conexão (nil usa o padrão)
 }
 
 // NewExecutor cria novo executor
@@ -21,18 +23,47 @@ func NewExecutor(ollamaURL string) *Executor {
 	}
 }
 
+// SetHostPool distribui os subagents entre os servidores do pool
+func (e *Executor) SetHostPool(pool *llm.HostPool) {
+	e.pool = pool
+}
+
+// SetScheduler faz os subagents disputarem as vagas do scheduler com prioridade de subagent
+func (e *Executor) SetScheduler(scheduler *llm.Scheduler) {
+	e.scheduler = scheduler
+}
+
+// SetTransportConfig aplica timeouts, retry e conexão (headers, TLS, proxy) aos subagents
+func (e *Executor) SetTransportConfig(cfg llm.TransportConfig) {
+	e.transport = &cfg
+}
+
 // Execute executa um subagent
 func (e *Executor) Execute(ctx context.Context, agent *Subagent) (string, error) {
-	// Criar LLM client específico para este agent (com modelo customizado)
-	client := llm.NewClient(e.ollamaURL, agent.Model)
+	// Criar LLM client específico para este agent (com modelo e provider customizados)
+	client, err := llm.NewProvider(agent.Provider, e.ollamaURL, agent.Model)
+	if err != nil {
+		return "", fmt.Errorf("create provider: %w", err)
+	}
+	if e.transport != nil {
+		client.SetTransportConfig(*e.transport)
+	}
+	client.SetHostPool(e.pool)
+	client.SetScheduler(e.scheduler)
+
+	// Cada subagent é uma conversa própria no pool de hosts e cede a vez ao turno do usuário
+	ctx = llm.WithConversation(ctx, agent.ID)
+	ctx = llm.WithPriority(ctx, llm.PrioritySubagent)
 
 	// Construir prompt baseado no tipo de agent
 	prompt := e.buildPrompt(agent)
 
 	// Construir opções de completion
-	opts := &llm.CompletionOptions{
-		Temperature: agent.Temperature,
-		MaxTokens:   agent.MaxTokens,
+	opts := agent.Options.Merge(&llm.CompletionOptions{
+		MaxTokens: agent.MaxTokens,
+	})
+	if agent.Temperature != 0 {
+		opts.Temperature = llm.Float64(agent.Temperature)
 	}
 
 	// Executar com LLM
@@ -44,7 +75,7 @@ func (e *Executor) Execute(ctx context.Context, agent *Subagent) (string, error)
 	}
 
 	// Executar com context do agent (para timeout/cancel)
-	response, err := client.Complete(ctx, messages, opts)
+	response, _, err := client.Complete(ctx, messages, &opts)
 	if err != nil {
 		return "", fmt.Errorf("llm execution failed: %w", err)
 	}
@@ -60,10 +60,12 @@ func (m *Manager) Spawn(cfg AgentConfig) (*Subagent, error) {
 		Type:        cfg.Type,
 		Prompt:      cfg.Prompt,
 		Model:       cfg.Model,
+		Provider:    cfg.Provider,
 		status:      StatusPending,
 		WorkDir:     cfg.WorkDir,
 		MaxTokens:   cfg.MaxTokens,
 		Temperature: cfg.Temperature,
+		Options:     cfg.Options,
 		Timeout:     cfg.Timeout,
 		MaxMemoryMB: cfg.MaxMemoryMB,
 		MaxCPUCores: cfg.MaxCPUCores,
@@ -4,6 +4,8 @@ import (
 	"context"
 	"sync"
 	"time"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
 )
 
 // AgentType define o tipo de subagent
@@ -41,6 +43,7 @@ type Subagent struct {
 	Type        AgentType
 	Prompt      string
 	Model       string
+	Provider    string      // "ollama" (padrão) ou "openai"
 	status      AgentStatus // Changed to unexported, use GetStatus/SetStatus
 	result      string      // Changed to unexported, use GetResult/SetResult
 	err         error       // Changed to unexported, use GetError/SetError
@@ -53,6 +56,7 @@ type Subagent struct {
 	WorkDir     string
 	MaxTokens   int
 	Temperature float64
+	Options     llm.CompletionOptions // Demais opções de geração
 	Timeout     time.Duration
 
 	// Resource limits
@@ -70,9 +74,11 @@ type AgentConfig struct {
 	Type        AgentType
 	Prompt      string
 	Model       string
+	Provider    string // "ollama" (padrão) ou "openai"
 	WorkDir     string
 	MaxTokens   int
 	Temperature float64
+	Options     llm.CompletionOptions // Demais opções de geração (num_ctx, seed, stop...)
 	Timeout     time.Duration
 	MaxMemoryMB int
 	MaxCPUCores int
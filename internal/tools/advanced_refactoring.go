@@ -38,6 +38,11 @@ func (a *AdvancedRefactoring) RequiresConfirmation() bool {
 	return false
 }
 
+// Mutating indica se modifica arquivos ou executa comandos
+func (a *AdvancedRefactoring) Mutating() bool {
+	return true // Reescreve arquivos
+}
+
 // Execute executa refatoração
 func (a *AdvancedRefactoring) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
 	refactorType, ok := params["type"].(string)
@@ -72,6 +72,11 @@ func (b *BackgroundTaskManager) RequiresConfirmation() bool {
 	return false
 }
 
+// Mutating indica se modifica arquivos ou executa comandos
+func (b *BackgroundTaskManager) Mutating() bool {
+	return true // Executa comandos em background
+}
+
 // Execute executa operação de background task
 func (b *BackgroundTaskManager) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
 	action, ok := params["action"].(string)
@@ -35,6 +35,11 @@ func (c *CodeFormatter) RequiresConfirmation() bool {
 	return false
 }
 
+// Mutating indica se modifica arquivos ou executa comandos
+func (c *CodeFormatter) Mutating() bool {
+	return true // Reescreve arquivos formatados
+}
+
 // Execute executa operação de formatação
 func (c *CodeFormatter) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
 	action, ok := params["action"].(string)
@@ -37,6 +37,11 @@ func (c *CodeSearcher) RequiresConfirmation() bool {
 	return false // Busca não precisa confirmação
 }
 
+// Mutating indica se modifica arquivos ou executa comandos
+func (c *CodeSearcher) Mutating() bool {
+	return false // Só leitura
+}
+
 // Execute executa a busca
 func (c *CodeSearcher) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
 	// Obter query de busca
@@ -225,3 +230,21 @@ func (c *CodeSearcher) SearchFiles(pattern string) ([]string, error) {
 
 	return files, err
 }
+
+// Schema retorna schema JSON da tool
+func (c *CodeSearcher) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "Texto ou regex a buscar no código",
+			},
+			"file_pattern": map[string]interface{}{
+				"type":        "string",
+				"description": "Glob de arquivos (ex: *.go)",
+			},
+		},
+		"required": []string{"query"},
+	}
+}
@@ -43,6 +43,11 @@ func (c *CommandExecutor) RequiresConfirmation() bool {
 	return true // Comandos requerem confirmação
 }
 
+// Mutating indica se modifica arquivos ou executa comandos
+func (c *CommandExecutor) Mutating() bool {
+	return true // Executa comandos shell
+}
+
 // Execute executa o comando
 func (c *CommandExecutor) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
 	// Obter comando
@@ -99,25 +104,48 @@ func (c *CommandExecutor) Execute(ctx context.Context, params map[string]interfa
 	return NewSuccessResult(message, result), nil
 }
 
-// IsDangerous verifica se comando é potencialmente perigoso
-func (c *CommandExecutor) IsDangerous(command string) bool {
-	dangerousPatterns := []string{
-		"rm -rf",
-		"del /f",
-		"format",
-		"mkfs",
-		"dd if=",
-		"> /dev/",
-		":(){ :|:& };:", // Fork bomb
-	}
+// dangerousPatterns trechos de comandos destrutivos (comparação sem caixa)
+var dangerousPatterns = []string{
+	"rm -rf",
+	"rm -fr",
+	"del /f",
+	"mkfs",
+	"dd if=",
+	"> /dev/",
+	":(){ :|:& };:", // Fork bomb
+	"chmod -r 777",
+	"chown -r",
+}
 
+// IsDangerousCommand verifica se comando é potencialmente perigoso. Usado tanto
+// pelo handler de execução quanto pelo loop de tool calling nativo.
+func IsDangerousCommand(command string) bool {
 	commandLower := strings.ToLower(command)
 
 	for _, pattern := range dangerousPatterns {
-		if strings.Contains(commandLower, strings.ToLower(pattern)) {
+		if strings.Contains(commandLower, pattern) {
 			return true
 		}
 	}
 
 	return false
 }
+
+// IsDangerous verifica se comando é potencialmente perigoso
+func (c *CommandExecutor) IsDangerous(command string) bool {
+	return IsDangerousCommand(command)
+}
+
+// Schema retorna schema JSON da tool
+func (c *CommandExecutor) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"command": map[string]interface{}{
+				"type":        "string",
+				"description": "Comando shell a executar no diretório de trabalho",
+			},
+		},
+		"required": []string{"command"},
+	}
+}
@@ -36,6 +36,11 @@ func (d *DependencyManager) RequiresConfirmation() bool {
 	return false
 }
 
+// Mutating indica se modifica arquivos ou executa comandos
+func (d *DependencyManager) Mutating() bool {
+	return true // npm install, go get, pip install
+}
+
 // Execute executa operação de gerenciamento de dependências
 func (d *DependencyManager) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
 	operation, ok := params["operation"].(string)
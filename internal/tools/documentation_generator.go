@@ -36,6 +36,11 @@ func (d *DocumentationGenerator) RequiresConfirmation() bool {
 	return false
 }
 
+// Mutating indica se modifica arquivos ou executa comandos
+func (d *DocumentationGenerator) Mutating() bool {
+	return true // Grava README e executa go install
+}
+
 // Execute executa geração de documentação
 func (d *DocumentationGenerator) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
 	docType, ok := params["type"].(string)
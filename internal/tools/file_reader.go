@@ -36,6 +36,11 @@ func (f *FileReader) RequiresConfirmation() bool {
 	return false // Leitura não precisa confirmação
 }
 
+// Mutating indica se modifica arquivos ou executa comandos
+func (f *FileReader) Mutating() bool {
+	return false // Só leitura
+}
+
 // Execute executa a leitura
 func (f *FileReader) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
 	// Obter caminho do arquivo
@@ -141,3 +146,17 @@ func (f *FileReader) getMimeType(path string) string {
 
 	return "application/octet-stream"
 }
+
+// Schema retorna schema JSON da tool
+func (f *FileReader) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"file_path": map[string]interface{}{
+				"type":        "string",
+				"description": "Caminho do arquivo relativo ao diretório de trabalho",
+			},
+		},
+		"required": []string{"file_path"},
+	}
+}
@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileReader_ImageAttachedOutsideObservation(t *testing.T) {
+	dir := t.TempDir()
+	png := []byte("\x89PNG\r\n\x1a\nfake image data")
+	if err := os.WriteFile(filepath.Join(dir, "screen.png"), png, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewFileReader(dir).Execute(context.Background(), map[string]interface{}{"file_path": "screen.png"})
+	if err != nil || !result.Success {
+		t.Fatalf("Execute failed: %v, %+v", err, result)
+	}
+
+	images := result.Images()
+	if len(images) != 1 || images[0] != result.Data["base64"] {
+		t.Fatalf("Expected the image in Images(), got %v", images)
+	}
+
+	observation := result.Observation()
+	if strings.Contains(observation, images[0]) || !strings.Contains(observation, "image/png") {
+		t.Errorf("Observation should keep metadata but not the base64 data: %s", observation)
+	}
+	if _, ok := result.Data["base64"]; !ok {
+		t.Error("Observation must not modify the result data")
+	}
+}
+
+func TestFileReader_TextHasNoImages(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644)
+
+	result, _ := NewFileReader(dir).Execute(context.Background(), map[string]interface{}{"file_path": "main.go"})
+	if images := result.Images(); images != nil {
+		t.Errorf("Text files should not carry images, got %v", images)
+	}
+}
@@ -35,6 +35,11 @@ func (f *FileWriter) RequiresConfirmation() bool {
 	return true // Escrita requer confirmação
 }
 
+// Mutating indica se modifica arquivos ou executa comandos
+func (f *FileWriter) Mutating() bool {
+	return true // Escreve e edita arquivos
+}
+
 // Execute executa a escrita
 func (f *FileWriter) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
 	// Obter parâmetros
@@ -149,3 +154,34 @@ func (f *FileWriter) replaceInFile(path string, params map[string]interface{}) (
 		},
 	), nil
 }
+
+// Schema retorna schema JSON da tool
+func (f *FileWriter) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"file_path": map[string]interface{}{
+				"type":        "string",
+				"description": "Caminho do arquivo relativo ao diretório de trabalho",
+			},
+			"content": map[string]interface{}{
+				"type":        "string",
+				"description": "Conteúdo a escrever (modos create e append)",
+			},
+			"mode": map[string]interface{}{
+				"type":        "string",
+				"description": "Modo: create, append, replace",
+				"enum":        []string{"create", "append", "replace"},
+			},
+			"old_text": map[string]interface{}{
+				"type":        "string",
+				"description": "Texto a substituir (para replace)",
+			},
+			"new_text": map[string]interface{}{
+				"type":        "string",
+				"description": "Texto novo (para replace)",
+			},
+		},
+		"required": []string{"file_path"},
+	}
+}
@@ -34,6 +34,11 @@ func (g *GitHelper) RequiresConfirmation() bool {
 	return false
 }
 
+// Mutating indica se modifica arquivos ou executa comandos
+func (g *GitHelper) Mutating() bool {
+	return false // Só consulta o repositório
+}
+
 // Execute executa operação de Git
 func (g *GitHelper) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
 	action, ok := params["action"].(string)
@@ -35,6 +35,11 @@ func (g *GitOperations) RequiresConfirmation() bool {
 	return true // Operações git requerem confirmação
 }
 
+// Mutating indica se modifica arquivos ou executa comandos
+func (g *GitOperations) Mutating() bool {
+	return true // Commits, checkout, push etc.
+}
+
 // Execute executa a operação git
 func (g *GitOperations) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
 	// Tipo de operação
@@ -220,3 +225,39 @@ func (g *GitOperations) IsGitRepository() bool {
 	_, err := g.runGitCommand("rev-parse", "--git-dir")
 	return err == nil
 }
+
+// Schema retorna schema JSON da tool
+func (g *GitOperations) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"description": "Operação: status, diff, log, add, commit, branch",
+				"enum":        []string{"status", "diff", "log", "add", "commit", "branch"},
+			},
+			"limit": map[string]interface{}{
+				"type":        "number",
+				"description": "Número de commits (para log)",
+			},
+			"files": map[string]interface{}{
+				"type":        "string",
+				"description": "Arquivos a adicionar (para add)",
+			},
+			"message": map[string]interface{}{
+				"type":        "string",
+				"description": "Mensagem do commit (para commit)",
+			},
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Ação de branch: list, create, checkout",
+				"enum":        []string{"list", "create", "checkout"},
+			},
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Nome da branch (para create, checkout)",
+			},
+		},
+		"required": []string{"operation"},
+	}
+}
@@ -37,6 +37,11 @@ func (p *PerformanceProfiler) RequiresConfirmation() bool {
 	return false
 }
 
+// Mutating indica se modifica arquivos ou executa comandos
+func (p *PerformanceProfiler) Mutating() bool {
+	return true // Executa benchmarks do projeto
+}
+
 // Execute executa profiling
 func (p *PerformanceProfiler) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
 	profileType, ok := params["type"].(string)
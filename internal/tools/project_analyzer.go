@@ -35,6 +35,11 @@ func (p *ProjectAnalyzer) RequiresConfirmation() bool {
 	return false
 }
 
+// Mutating indica se modifica arquivos ou executa comandos
+func (p *ProjectAnalyzer) Mutating() bool {
+	return false // Só leitura
+}
+
 // Execute executa a análise
 func (p *ProjectAnalyzer) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
 	// Tipo de análise
@@ -206,3 +211,17 @@ func (p *ProjectAnalyzer) listFiles() (Result, error) {
 		},
 	), nil
 }
+
+// Schema retorna schema JSON da tool
+func (p *ProjectAnalyzer) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{
+				"type":        "string",
+				"description": "Tipo de análise: structure, stats, files",
+				"enum":        []string{"structure", "stats", "files"},
+			},
+		},
+	}
+}
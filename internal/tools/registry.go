@@ -3,13 +3,21 @@ package tools
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
 )
 
+// BeforeExecuteHook chamado antes de executar ferramentas que modificam algo
+// (Mutating), mesmo as que não pedem confirmação, ex: para criar checkpoints
+type BeforeExecuteHook func(ctx context.Context, tool Tool, params map[string]interface{})
+
 // Registry registro de ferramentas
 type Registry struct {
-	tools map[string]Tool
-	mu    sync.RWMutex
+	tools         map[string]Tool
+	beforeExecute BeforeExecuteHook
+	mu            sync.RWMutex
 }
 
 // NewRegistry cria novo registro
@@ -59,6 +67,13 @@ func (r *Registry) List() []Tool {
 	return tools
 }
 
+// SetBeforeExecute define o hook chamado antes de ferramentas que modificam algo (nil remove)
+func (r *Registry) SetBeforeExecute(hook BeforeExecuteHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.beforeExecute = hook
+}
+
 // Execute executa uma ferramenta
 func (r *Registry) Execute(ctx context.Context, toolName string, params map[string]interface{}) (Result, error) {
 	tool, err := r.Get(toolName)
@@ -66,5 +81,46 @@ func (r *Registry) Execute(ctx context.Context, toolName string, params map[stri
 		return NewErrorResult(err), err
 	}
 
+	r.mu.RLock()
+	hook := r.beforeExecute
+	r.mu.RUnlock()
+	if hook != nil && tool.Mutating() {
+		hook(ctx, tool, params)
+	}
+
 	return tool.Execute(ctx, params)
 }
+
+// ToolDefinitions retorna as ferramentas no formato de tool-calling do LLM,
+// ordenadas por nome para manter o prompt estável entre requisições.
+// Com readOnly, as ferramentas que modificam algo ficam de fora.
+func (r *Registry) ToolDefinitions(readOnly bool) []llm.Tool {
+	tools := r.List()
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name() < tools[j].Name()
+	})
+
+	definitions := make([]llm.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if readOnly && tool.Mutating() {
+			continue
+		}
+		var schema map[string]interface{}
+		if sp, ok := tool.(SchemaProvider); ok {
+			schema = sp.Schema()
+		}
+		definitions = append(definitions, llm.NewTool(tool.Name(), tool.Description(), schema))
+	}
+
+	return definitions
+}
+
+// ExecuteToolCall executa uma chamada de ferramenta feita pelo modelo
+func (r *Registry) ExecuteToolCall(ctx context.Context, call llm.ToolCall) (Result, error) {
+	params := call.Function.Arguments
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	return r.Execute(ctx, call.Function.Name, params)
+}
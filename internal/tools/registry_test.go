@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/llm"
+)
+
+func TestRegistry_ToolDefinitions(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(NewTestRunner("."))
+	registry.Register(NewFileReader("."))
+
+	definitions := registry.ToolDefinitions(false)
+	if len(definitions) != 2 {
+		t.Fatalf("Expected 2 definitions, got %d", len(definitions))
+	}
+
+	// Ordenado por nome
+	if definitions[0].Function.Name != "file_reader" {
+		t.Errorf("Expected first tool 'file_reader', got '%s'", definitions[0].Function.Name)
+	}
+
+	for _, def := range definitions {
+		if def.Type != "function" {
+			t.Errorf("Expected type 'function', got '%s'", def.Type)
+		}
+		if def.Function.Description == "" {
+			t.Errorf("Tool %s should have description", def.Function.Name)
+		}
+		if _, ok := def.Function.Parameters["properties"]; !ok {
+			t.Errorf("Tool %s should expose parameter properties", def.Function.Name)
+		}
+	}
+}
+
+func TestRegistry_ToolDefinitionsReadOnly(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(NewFileReader("."))
+	registry.Register(NewCodeFormatter("."))
+	registry.Register(NewDependencyManager("."))
+
+	definitions := registry.ToolDefinitions(true)
+	if len(definitions) != 1 || definitions[0].Function.Name != "file_reader" {
+		t.Errorf("Read-only definitions should leave mutating tools out, got %+v", definitions)
+	}
+}
+
+func TestTools_MutatingClassification(t *testing.T) {
+	mutating := []Tool{
+		NewFileWriter("."), NewCommandExecutor(".", 0), NewGitOperations("."),
+		NewDependencyManager("."), NewDocumentationGenerator("."), NewAdvancedRefactoring("."),
+		NewTestRunner("."), NewBackgroundTaskManager("."), NewPerformanceProfiler("."), NewCodeFormatter("."),
+	}
+	for _, tool := range mutating {
+		if !tool.Mutating() {
+			t.Errorf("%s changes files or runs commands and should be mutating", tool.Name())
+		}
+	}
+
+	readOnly := []Tool{
+		NewFileReader("."), NewCodeSearcher("."), NewProjectAnalyzer("."), NewSecurityScanner("."), NewGitHelper("."),
+	}
+	for _, tool := range readOnly {
+		if tool.Mutating() {
+			t.Errorf("%s only reads and should not be mutating", tool.Name())
+		}
+	}
+}
+
+func TestRegistry_ExecuteToolCall(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "hello.txt"), []byte("hello"), 0644)
+
+	registry := NewRegistry()
+	registry.Register(NewFileReader(tmpDir))
+
+	result, err := registry.ExecuteToolCall(context.Background(), llm.ToolCall{
+		Function: llm.ToolCallFunction{
+			Name:      "file_reader",
+			Arguments: map[string]interface{}{"file_path": "hello.txt"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteToolCall failed: %v", err)
+	}
+
+	if !result.Success {
+		t.Fatalf("Expected success, got error: %s", result.Error)
+	}
+
+	if result.Data["content"] != "hello" {
+		t.Errorf("Unexpected content: %v", result.Data["content"])
+	}
+}
+
+func TestRegistry_ExecuteToolCall_UnknownTool(t *testing.T) {
+	registry := NewRegistry()
+
+	_, err := registry.ExecuteToolCall(context.Background(), llm.ToolCall{
+		Function: llm.ToolCallFunction{Name: "missing"},
+	})
+	if err == nil {
+		t.Error("Expected error for unknown tool")
+	}
+}
+
+func TestRegistry_BeforeExecuteHook(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "hello.txt"), []byte("hello"), 0644)
+
+	registry := NewRegistry()
+	registry.Register(NewFileReader(tmpDir))
+	registry.Register(NewFileWriter(tmpDir))
+	registry.Register(NewCodeFormatter(tmpDir))
+
+	var hooked []string
+	registry.SetBeforeExecute(func(ctx context.Context, tool Tool, params map[string]interface{}) {
+		// O arquivo ainda não foi alterado quando o hook roda
+		content, _ := os.ReadFile(filepath.Join(tmpDir, "hello.txt"))
+		hooked = append(hooked, tool.Name()+":"+string(content))
+	})
+
+	registry.Execute(context.Background(), "file_reader", map[string]interface{}{"file_path": "hello.txt"})
+	registry.Execute(context.Background(), "file_writer", map[string]interface{}{"file_path": "hello.txt", "content": "bye"})
+
+	if len(hooked) != 1 || hooked[0] != "file_writer:hello" {
+		t.Errorf("Hook should run only before modifying tools, got %v", hooked)
+	}
+
+	// code_formatter não pede confirmação, mas reescreve arquivos
+	registry.Execute(context.Background(), "code_formatter", map[string]interface{}{"file_path": "hello.txt"})
+
+	if len(hooked) != 2 || hooked[1] != "code_formatter:bye" {
+		t.Errorf("Hook should run before mutating tools without confirmation, got %v", hooked)
+	}
+}
@@ -37,6 +37,11 @@ func (s *SecurityScanner) RequiresConfirmation() bool {
 	return false
 }
 
+// Mutating indica se modifica arquivos ou executa comandos
+func (s *SecurityScanner) Mutating() bool {
+	return false // Só analisa o código
+}
+
 // Execute executa scan de segurança
 func (s *SecurityScanner) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
 	scanType, ok := params["type"].(string)
@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/johnpitter/ollama-code/internal/index"
+)
+
+// semanticRefreshInterval intervalo mínimo entre reindexações automáticas
+const semanticRefreshInterval = 30 * time.Second
+
+// SemanticSearch busca trechos de código por significado (embeddings)
+type SemanticSearch struct {
+	index *index.Index
+}
+
+// NewSemanticSearch cria ferramenta de busca semântica
+func NewSemanticSearch(idx *index.Index) *SemanticSearch {
+	return &SemanticSearch{
+		index: idx,
+	}
+}
+
+// Name retorna nome da ferramenta
+func (s *SemanticSearch) Name() string {
+	return "semantic_search"
+}
+
+// Description retorna descrição
+func (s *SemanticSearch) Description() string {
+	return "Busca trechos de código por significado (ex: \"onde a conexão com o banco é aberta\"), usando o índice de embeddings do projeto"
+}
+
+// RequiresConfirmation indica se requer confirmação
+func (s *SemanticSearch) RequiresConfirmation() bool {
+	return false
+}
+
+// Mutating indica se modifica arquivos ou executa comandos
+func (s *SemanticSearch) Mutating() bool {
+	return false // Consulta o índice
+}
+
+// Execute executa a busca
+func (s *SemanticSearch) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
+	query, ok := params["query"].(string)
+	if !ok || query == "" {
+		return NewErrorResult(fmt.Errorf("query parameter required: describe what the code does")), nil
+	}
+
+	limit := index.DefaultTopK
+	if l, ok := params["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+
+	// Atualização incremental: só arquivos alterados são reenviados ao modelo
+	if err := s.index.Refresh(ctx, semanticRefreshInterval); err != nil {
+		return NewErrorResult(fmt.Errorf("update index: %w", err)), nil
+	}
+
+	results, err := s.index.Search(ctx, query, limit)
+	if errors.Is(err, index.ErrEmptyIndex) {
+		return NewSuccessResult("Nenhum arquivo indexado", map[string]interface{}{
+			"results": []map[string]interface{}{},
+			"count":   0,
+		}), nil
+	}
+	if err != nil {
+		return NewErrorResult(err), nil
+	}
+
+	items := make([]map[string]interface{}, 0, len(results))
+	for _, result := range results {
+		items = append(items, map[string]interface{}{
+			"file":       result.Path,
+			"start_line": result.StartLine,
+			"end_line":   result.EndLine,
+			"symbol":     result.Symbol,
+			"score":      result.Score,
+			"content":    result.Text,
+		})
+	}
+
+	return NewSuccessResult(
+		fmt.Sprintf("Encontrados %d trechos relevantes\n\n%s", len(results), index.FormatResults(results, 0)),
+		map[string]interface{}{
+			"results": items,
+			"count":   len(items),
+		},
+	), nil
+}
+
+// Schema retorna schema JSON da tool
+func (s *SemanticSearch) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "Descrição em linguagem natural do código procurado",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"description": "Número máximo de trechos (padrão: 5)",
+			},
+		},
+		"required": []string{"query"},
+	}
+}
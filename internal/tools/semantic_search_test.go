@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johnpitter/ollama-code/internal/index"
+	"github.com/johnpitter/ollama-code/internal/llm"
+	"github.com/johnpitter/ollama-code/internal/llmtest"
+)
+
+func newTestSemanticSearch(t *testing.T, files map[string]string) *SemanticSearch {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := llmtest.NewServer(t)
+	client := llm.NewClient(server.URL, "test-model")
+	return NewSemanticSearch(index.New(client, index.Config{WorkDir: dir}))
+}
+
+func TestSemanticSearch_Execute(t *testing.T) {
+	tool := newTestSemanticSearch(t, map[string]string{
+		"auth.go":  "package app\n\n// ValidateToken valida token jwt do usuário\nfunc ValidateToken() {}\n",
+		"cache.go": "package app\n\n// EvictCache remove entradas antigas do cache\nfunc EvictCache() {}\n",
+	})
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{
+		"query": "valida token jwt",
+		"limit": float64(1),
+	})
+	if err != nil || !result.Success {
+		t.Fatalf("Execute failed: %v, %+v", err, result)
+	}
+
+	items := result.Data["results"].([]map[string]interface{})
+	if len(items) != 1 || items[0]["file"] != "auth.go" || items[0]["symbol"] != "ValidateToken" {
+		t.Errorf("Expected auth.go first, got %+v", items)
+	}
+	if !strings.Contains(result.Message, "auth.go:3-4") {
+		t.Errorf("Message should include the snippet location, got %q", result.Message)
+	}
+}
+
+func TestSemanticSearch_EmptyProject(t *testing.T) {
+	tool := newTestSemanticSearch(t, nil)
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"query": "qualquer"})
+	if err != nil || !result.Success || result.Data["count"] != 0 {
+		t.Errorf("Empty project should return no results, got %+v, %v", result, err)
+	}
+}
+
+func TestSemanticSearch_RequiresQuery(t *testing.T) {
+	tool := newTestSemanticSearch(t, nil)
+
+	result, _ := tool.Execute(context.Background(), map[string]interface{}{})
+	if result.Success {
+		t.Error("Expected error without query")
+	}
+	if tool.Schema()["required"].([]string)[0] != "query" {
+		t.Error("Schema should require query")
+	}
+}
@@ -36,6 +36,11 @@ func (t *TestRunner) RequiresConfirmation() bool {
 	return false
 }
 
+// Mutating indica se modifica arquivos ou executa comandos
+func (t *TestRunner) Mutating() bool {
+	return true // Executa código do projeto
+}
+
 // Execute executa testes
 func (t *TestRunner) Execute(ctx context.Context, params map[string]interface{}) (Result, error) {
 	action, ok := params["action"].(string)
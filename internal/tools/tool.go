@@ -1,6 +1,9 @@
 package tools
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+)
 
 // Tool interface para todas as ferramentas
 type Tool interface {
@@ -15,6 +18,16 @@ type Tool interface {
 
 	// RequiresConfirmation indica se requer confirmação
 	RequiresConfirmation() bool
+
+	// Mutating indica se modifica arquivos, dependências ou executa comandos
+	// (bloqueada em modo somente leitura, confirmada no interativo, com checkpoint antes)
+	Mutating() bool
+}
+
+// SchemaProvider ferramentas que descrevem seus parâmetros em JSON Schema
+type SchemaProvider interface {
+	// Schema retorna schema JSON dos parâmetros aceitos por Execute
+	Schema() map[string]interface{}
 }
 
 // Result resultado da execução de ferramenta
@@ -41,3 +54,34 @@ func NewErrorResult(err error) Result {
 		Error:   err.Error(),
 	}
 }
+
+// Observation serializa o resultado para ser devolvido ao modelo como mensagem "tool".
+// Imagens em base64 ficam de fora: são anexadas à conversa (ver Images)
+func (r Result) Observation() string {
+	if _, ok := r.Data["base64"]; ok {
+		data := make(map[string]interface{}, len(r.Data))
+		for key, value := range r.Data {
+			if key != "base64" {
+				data[key] = value
+			}
+		}
+		r.Data = data
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		if r.Error != "" {
+			return r.Error
+		}
+		return r.Message
+	}
+	return string(data)
+}
+
+// Images retorna as imagens (base64) do resultado, como as lidas pelo file_reader
+func (r Result) Images() []string {
+	if image, ok := r.Data["base64"].(string); ok && image != "" {
+		return []string{image}
+	}
+	return nil
+}